@@ -0,0 +1,446 @@
+package gosnowflake
+
+import (
+	"bytes"
+	"context"
+	"database/sql/driver"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+)
+
+const arrowBindTimestampTzFormat = "2006-01-02 15:04:05.999999999 -07:00"
+
+// compatibleArrowBindTargets lists the target column types an arrow column of a given bind type can be inserted into.
+var compatibleArrowBindTargets = map[snowflakeType][]snowflakeType{
+	fixedType:        {fixedType, realType, textType, booleanType, variantType},
+	realType:         {realType, fixedType, textType, variantType},
+	booleanType:      {booleanType, fixedType, textType, variantType},
+	textType:         {textType, fixedType, realType, booleanType, dateType, timeType, timestampNtzType, timestampLtzType, timestampTzType, binaryType, variantType, objectType, arrayType, mapType},
+	binaryType:       {binaryType, textType},
+	dateType:         {dateType, timestampNtzType, timestampLtzType, timestampTzType, textType, variantType},
+	timeType:         {timeType, textType, variantType},
+	timestampNtzType: {timestampNtzType, timestampLtzType, timestampTzType, dateType, textType, variantType},
+	timestampTzType:  {timestampTzType, timestampLtzType, timestampNtzType, dateType, textType, variantType},
+}
+
+func isArrowBind(bindings []driver.NamedValue) bool {
+	if len(bindings) != 1 {
+		return false
+	}
+	return supportedArrowBind(&bindings[0])
+}
+
+// checkArrowBindings returns an error if an arrow bind is mixed with other binds,
+// because the columns of an arrow bind supply the values of all the bind variables.
+func checkArrowBindings(bindings []driver.NamedValue) error {
+	if len(bindings) < 2 {
+		return nil
+	}
+	for i := range bindings {
+		if supportedArrowBind(&bindings[i]) {
+			return errArrowBindNotSingle(len(bindings))
+		}
+	}
+	return nil
+}
+
+func supportedArrowBind(nv *driver.NamedValue) bool {
+	switch nv.Value.(type) {
+	case arrow.Record, array.RecordReader:
+		return true
+	}
+	return false
+}
+
+// arrowBindType returns the Snowflake type the arrow data type is bound as.
+func arrowBindType(dt arrow.DataType) snowflakeType {
+	switch dt.ID() {
+	case arrow.INT8, arrow.INT16, arrow.INT32, arrow.INT64,
+		arrow.UINT8, arrow.UINT16, arrow.UINT32, arrow.UINT64, arrow.DECIMAL128:
+		return fixedType
+	case arrow.FLOAT32, arrow.FLOAT64:
+		return realType
+	case arrow.BOOL:
+		return booleanType
+	case arrow.STRING, arrow.LARGE_STRING:
+		return textType
+	case arrow.BINARY, arrow.LARGE_BINARY, arrow.FIXED_SIZE_BINARY:
+		return binaryType
+	case arrow.DATE32, arrow.DATE64:
+		return dateType
+	case arrow.TIME32, arrow.TIME64:
+		return timeType
+	case arrow.TIMESTAMP:
+		if dt.(*arrow.TimestampType).TimeZone == "" {
+			return timestampNtzType
+		}
+		return timestampTzType
+	}
+	return unSupportedType
+}
+
+// arrowBindSchemaTypes validates the schema of the bound arrow data and returns the bind type of every column.
+func arrowBindSchemaTypes(schema *arrow.Schema) ([]snowflakeType, error) {
+	types := make([]snowflakeType, schema.NumFields())
+	for i, field := range schema.Fields() {
+		if types[i] = arrowBindType(field.Type); types[i] == unSupportedType {
+			return nil, &SnowflakeError{
+				Number:      ErrBindSerialization,
+				Message:     errMsgArrowBindUnsupportedType,
+				MessageArgs: []interface{}{field.Name, field.Type},
+			}
+		}
+	}
+	return types, nil
+}
+
+// checkArrowBindCompatibility compares the bind types with the target types described by the server.
+// The check is skipped if the server doesn't describe the binds.
+func checkArrowBindCompatibility(schema *arrow.Schema, types []snowflakeType, targets []execResponseRowType) error {
+	if len(targets) == 0 {
+		return nil
+	}
+	if len(targets) != len(types) {
+		return &SnowflakeError{
+			Number:      ErrBindTypeMismatch,
+			Message:     errMsgArrowBindColumnCountMismatch,
+			MessageArgs: []interface{}{len(types), len(targets)},
+		}
+	}
+	for i, target := range targets {
+		targetType, ok := snowflakeToDriverType[strings.ToUpper(target.Type)]
+		if !ok {
+			continue
+		}
+		compatible := false
+		for _, t := range compatibleArrowBindTargets[types[i]] {
+			if t == targetType {
+				compatible = true
+				break
+			}
+		}
+		if !compatible {
+			return &SnowflakeError{
+				Number:      ErrBindTypeMismatch,
+				Message:     errMsgArrowBindTypeMismatch,
+				MessageArgs: []interface{}{schema.Field(i).Name, schema.Field(i).Type, target.Type},
+			}
+		}
+	}
+	return nil
+}
+
+// appendArrowBindValue appends the string representation of the non-null value at idx.
+// The representation differs whether it's directly bound or uploaded via stream.
+func appendArrowBindValue(dst []byte, col arrow.Array, idx int, stream bool) ([]byte, error) {
+	switch c := col.(type) {
+	case *array.Int8:
+		return strconv.AppendInt(dst, int64(c.Value(idx)), 10), nil
+	case *array.Int16:
+		return strconv.AppendInt(dst, int64(c.Value(idx)), 10), nil
+	case *array.Int32:
+		return strconv.AppendInt(dst, int64(c.Value(idx)), 10), nil
+	case *array.Int64:
+		return strconv.AppendInt(dst, c.Value(idx), 10), nil
+	case *array.Uint8:
+		return strconv.AppendUint(dst, uint64(c.Value(idx)), 10), nil
+	case *array.Uint16:
+		return strconv.AppendUint(dst, uint64(c.Value(idx)), 10), nil
+	case *array.Uint32:
+		return strconv.AppendUint(dst, uint64(c.Value(idx)), 10), nil
+	case *array.Uint64:
+		return strconv.AppendUint(dst, c.Value(idx), 10), nil
+	case *array.Decimal128:
+		return append(dst, c.Value(idx).ToString(c.DataType().(*arrow.Decimal128Type).Scale)...), nil
+	case *array.Float32:
+		return strconv.AppendFloat(dst, float64(c.Value(idx)), 'g', -1, 32), nil
+	case *array.Float64:
+		return strconv.AppendFloat(dst, c.Value(idx), 'g', -1, 64), nil
+	case *array.Boolean:
+		return strconv.AppendBool(dst, c.Value(idx)), nil
+	case *array.String:
+		return appendArrowBindString(dst, c.Value(idx), stream), nil
+	case *array.LargeString:
+		return appendArrowBindString(dst, c.Value(idx), stream), nil
+	case *array.Binary:
+		return hex.AppendEncode(dst, c.Value(idx)), nil
+	case *array.LargeBinary:
+		return hex.AppendEncode(dst, c.Value(idx)), nil
+	case *array.FixedSizeBinary:
+		return hex.AppendEncode(dst, c.Value(idx)), nil
+	case *array.Date32:
+		return appendArrowBindDate(dst, c.Value(idx).ToTime(), stream), nil
+	case *array.Date64:
+		return appendArrowBindDate(dst, c.Value(idx).ToTime(), stream), nil
+	case *array.Time32:
+		unit := c.DataType().(*arrow.Time32Type).Unit
+		return appendArrowBindTime(dst, int64(c.Value(idx))*int64(unit.Multiplier()), stream), nil
+	case *array.Time64:
+		unit := c.DataType().(*arrow.Time64Type).Unit
+		return appendArrowBindTime(dst, int64(c.Value(idx))*int64(unit.Multiplier()), stream), nil
+	case *array.Timestamp:
+		tsType := c.DataType().(*arrow.TimestampType)
+		tm := c.Value(idx).ToTime(tsType.Unit)
+		if tsType.TimeZone == "" {
+			if stream {
+				return tm.AppendFormat(dst, format), nil
+			}
+			return strconv.AppendInt(dst, tm.UnixNano(), 10), nil
+		}
+		tm = tm.In(arrowTimestampLocation(tsType.TimeZone))
+		if stream {
+			return tm.AppendFormat(dst, arrowBindTimestampTzFormat), nil
+		}
+		s, err := convertTimeToTimeStamp(tm, timestampTzType)
+		return append(dst, s...), err
+	}
+	return dst, fmt.Errorf("unsupported arrow type for binding: %v", col.DataType())
+}
+
+func appendArrowBindString(dst []byte, value string, stream bool) []byte {
+	if stream {
+		return append(dst, escapeForCSV(value)...)
+	}
+	return append(dst, value...)
+}
+
+func appendArrowBindDate(dst []byte, tm time.Time, stream bool) []byte {
+	if stream {
+		return tm.AppendFormat(dst, "2006-01-02")
+	}
+	return strconv.AppendInt(dst, tm.UnixMilli(), 10)
+}
+
+func appendArrowBindTime(dst []byte, nanos int64, stream bool) []byte {
+	if stream {
+		return time.Time{}.Add(time.Duration(nanos)).AppendFormat(dst, "15:04:05.000000000")
+	}
+	return strconv.AppendInt(dst, nanos, 10)
+}
+
+// arrowTimestampLocation resolves the time zone of an arrow timestamp type,
+// which is either an IANA time zone name or an offset like +01:00. Unknown time zones are UTC,
+// see warnUnknownArrowTimeZones.
+func arrowTimestampLocation(tz string) *time.Location {
+	loc, _ := lookupArrowTimestampLocation(tz)
+	return loc
+}
+
+func lookupArrowTimestampLocation(tz string) (*time.Location, bool) {
+	if loc, err := LocationWithName(tz); err == nil {
+		return loc, true
+	}
+	if loc, err := LocationWithOffsetString(strings.ReplaceAll(tz, ":", "")); err == nil {
+		return loc, true
+	}
+	return time.UTC, false
+}
+
+// warnUnknownArrowTimeZones logs the timestamp columns of the schema with a time zone bound as UTC.
+func warnUnknownArrowTimeZones(log SFLogger, schema *arrow.Schema) {
+	for i, field := range schema.Fields() {
+		tsType, ok := field.Type.(*arrow.TimestampType)
+		if !ok || tsType.TimeZone == "" {
+			continue
+		}
+		if _, ok = lookupArrowTimestampLocation(tsType.TimeZone); !ok {
+			log.Warnf("unknown time zone %v of arrow column %v, using UTC", tsType.TimeZone, i+1)
+		}
+	}
+}
+
+// arrowRecordToCSV writes the rows of the record to the buffer in the CSV format used by the bind stage.
+func arrowRecordToCSV(b *bytes.Buffer, record arrow.Record, startRow int, maxBytes int) (int, error) {
+	var err error
+	line := make([]byte, 0, 1024)
+	rowIdx := startRow
+	for ; rowIdx < int(record.NumRows()) && b.Len() < maxBytes; rowIdx++ {
+		line = line[:0]
+		for colIdx, col := range record.Columns() {
+			if colIdx > 0 {
+				line = append(line, ',')
+			}
+			if col.IsNull(rowIdx) {
+				continue
+			}
+			if line, err = appendArrowBindValue(line, col, rowIdx, true); err != nil {
+				return rowIdx, err
+			}
+		}
+		line = append(line, '\n')
+		b.Write(line)
+	}
+	return rowIdx, nil
+}
+
+// arrowRecordsToBindValues converts the records column by column to the inline bindings.
+func arrowRecordsToBindValues(records []arrow.Record, types []snowflakeType) (map[string]execBindParameter, error) {
+	var numRows int64
+	for _, record := range records {
+		numRows += record.NumRows()
+	}
+	bindValues := make(map[string]execBindParameter, len(types))
+	for colIdx, t := range types {
+		values := make([]*string, 0, numRows)
+		for _, record := range records {
+			col := record.Column(colIdx)
+			for rowIdx := 0; rowIdx < col.Len(); rowIdx++ {
+				if col.IsNull(rowIdx) {
+					values = append(values, nil)
+					continue
+				}
+				v, err := appendArrowBindValue(nil, col, rowIdx, false)
+				if err != nil {
+					return nil, err
+				}
+				s := string(v)
+				values = append(values, &s)
+			}
+		}
+		bindValues[strconv.Itoa(colIdx+1)] = execBindParameter{
+			Type:  t.String(),
+			Value: values,
+		}
+	}
+	return bindValues, nil
+}
+
+// arrowBindRecords returns an iterator over the bound records. Records read
+// from a RecordReader are retained and have to be released by the caller.
+func arrowBindRecords(value driver.Value) (*arrow.Schema, func() (arrow.Record, bool)) {
+	switch v := value.(type) {
+	case arrow.Record:
+		done := false
+		return v.Schema(), func() (arrow.Record, bool) {
+			if done {
+				return nil, false
+			}
+			done = true
+			v.Retain()
+			return v, true
+		}
+	case array.RecordReader:
+		return v.Schema(), func() (arrow.Record, bool) {
+			if !v.Next() {
+				return nil, false
+			}
+			record := v.Record()
+			record.Retain()
+			return record, true
+		}
+	}
+	return nil, func() (arrow.Record, bool) { return nil, false }
+}
+
+func (sc *snowflakeConn) processArrowBindings(
+	ctx context.Context,
+	binding driver.NamedValue,
+	describeOnly bool,
+	requestID UUID,
+	req *execRequest) error {
+	schema, nextRecord := arrowBindRecords(binding.Value)
+	types, err := arrowBindSchemaTypes(schema)
+	if err != nil {
+		return err
+	}
+	if describeOnly {
+		return nil
+	}
+	// the describe is an internal request with its own request ID, so that it is neither recorded as a statement
+	// of the application nor deduplicated with the statement by the server
+	describeData, err := sc.exec(WithRequestID(ctx, NewUUID()), req.SQLText, false, true, true, []driver.NamedValue{})
+	if err != nil {
+		return err
+	}
+	if err = checkArrowBindCompatibility(schema, types, describeData.Data.MetaDataOfBinds); err != nil {
+		return err
+	}
+	warnUnknownArrowTimeZones(sc.getLogger(), schema)
+
+	arrayBindThreshold := sc.getArrayBindStageThreshold()
+	record, ok := nextRecord()
+	if !ok {
+		return &SnowflakeError{
+			Number:  ErrBindSerialization,
+			Message: "no records found in the arrow binding",
+		}
+	}
+	records := []arrow.Record{record}
+	defer func() {
+		for _, r := range records {
+			r.Release()
+		}
+	}()
+	_, isReader := binding.Value.(array.RecordReader)
+	if arrayBindThreshold > 0 && (isReader || arrayBindThreshold <= int(record.NumRows())*len(types)) {
+		uploader := bindUploader{
+			sc:        sc,
+			ctx:       ctx,
+			stagePath: "@" + bindStageName + "/" + requestID.String(),
+		}
+		if err = uploader.uploadArrow(record, nextRecord); err != nil {
+			return err
+		}
+		req.Bindings = nil
+		req.BindStage = uploader.stagePath
+		return nil
+	}
+	for record, ok = nextRecord(); ok; record, ok = nextRecord() {
+		records = append(records, record)
+	}
+	req.Bindings, err = arrowRecordsToBindValues(records, types)
+	req.BindStage = ""
+	return err
+}
+
+// uploadArrow streams the rows of the records to the bind stage without converting them to driver values.
+func (bu *bindUploader) uploadArrow(first arrow.Record, nextRecord func() (arrow.Record, bool)) error {
+	var b bytes.Buffer
+	bu.fileCount = 0
+	flush := func() error {
+		bu.fileCount++
+		_, err := bu.uploadStreamInternal(&b, bu.fileCount, true)
+		b.Reset()
+		return err
+	}
+	writeRecord := func(record arrow.Record) error {
+		rowIdx := 0
+		for rowIdx < int(record.NumRows()) {
+			var err error
+			if rowIdx, err = arrowRecordToCSV(&b, record, rowIdx, inputStreamBufferSize); err != nil {
+				return err
+			}
+			if b.Len() >= inputStreamBufferSize {
+				if err = flush(); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+	record := first
+	for {
+		err := writeRecord(record)
+		// the first record is released by the caller
+		if record != first {
+			record.Release()
+		}
+		if err != nil {
+			return err
+		}
+		var ok bool
+		if record, ok = nextRecord(); !ok {
+			break
+		}
+	}
+	if b.Len() > 0 || bu.fileCount == 0 {
+		return flush()
+	}
+	return nil
+}
@@ -0,0 +1,263 @@
+package gosnowflake
+
+import (
+	"bytes"
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/decimal128"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+)
+
+func buildArrowBindTestRecord(pool memory.Allocator) arrow.Record {
+	schema := arrow.NewSchema([]arrow.Field{
+		{Name: "i", Type: arrow.PrimitiveTypes.Int64, Nullable: true},
+		{Name: "d", Type: &arrow.Decimal128Type{Precision: 10, Scale: 2}},
+		{Name: "f", Type: arrow.PrimitiveTypes.Float64},
+		{Name: "b", Type: arrow.FixedWidthTypes.Boolean},
+		{Name: "s", Type: arrow.BinaryTypes.String, Nullable: true},
+		{Name: "bin", Type: arrow.BinaryTypes.Binary},
+		{Name: "date", Type: arrow.FixedWidthTypes.Date32},
+		{Name: "time", Type: arrow.FixedWidthTypes.Time64ns},
+		{Name: "ntz", Type: &arrow.TimestampType{Unit: arrow.Nanosecond}},
+		{Name: "tz", Type: &arrow.TimestampType{Unit: arrow.Millisecond, TimeZone: "+01:00"}},
+	}, nil)
+	b := array.NewRecordBuilder(pool, schema)
+	defer b.Release()
+	tm := time.Date(2024, time.January, 19, 3, 42, 33, 123456789, time.UTC)
+	b.Field(0).(*array.Int64Builder).AppendValues([]int64{1, 0}, []bool{true, false})
+	b.Field(1).(*array.Decimal128Builder).AppendValues([]decimal128.Num{decimal128.FromI64(12345), decimal128.FromI64(-5)}, nil)
+	b.Field(2).(*array.Float64Builder).AppendValues([]float64{1.5, -0.25}, nil)
+	b.Field(3).(*array.BooleanBuilder).AppendValues([]bool{true, false}, nil)
+	b.Field(4).(*array.StringBuilder).AppendValues([]string{`a "quoted", text`, ""}, []bool{true, false})
+	b.Field(5).(*array.BinaryBuilder).AppendValues([][]byte{{0x01, 0xab}, {}}, nil)
+	b.Field(6).(*array.Date32Builder).AppendValues([]arrow.Date32{arrow.Date32FromTime(tm), 0}, nil)
+	b.Field(7).(*array.Time64Builder).AppendValues([]arrow.Time64{arrow.Time64((3*3600 + 42*60 + 33) * 1e9), 0}, nil)
+	b.Field(8).(*array.TimestampBuilder).AppendValues([]arrow.Timestamp{arrow.Timestamp(tm.UnixNano()), 0}, nil)
+	b.Field(9).(*array.TimestampBuilder).AppendValues([]arrow.Timestamp{arrow.Timestamp(tm.UnixMilli()), 0}, nil)
+	return b.NewRecord()
+}
+
+func TestArrowRecordToCSV(t *testing.T) {
+	pool := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer pool.AssertSize(t, 0)
+	record := buildArrowBindTestRecord(pool)
+	defer record.Release()
+
+	var b bytes.Buffer
+	rowIdx, err := arrowRecordToCSV(&b, record, 0, inputStreamBufferSize)
+	assertNilF(t, err)
+	assertEqualE(t, rowIdx, 2)
+	assertEqualE(t, b.String(),
+		`1,123.45,1.5,true,"a ""quoted"", text",01ab,2024-01-19,03:42:33.000000000,2024-01-19 03:42:33.123456789,2024-01-19 04:42:33.123 +01:00`+"\n"+
+			`,-0.05,-0.25,false,,,1970-01-01,00:00:00.000000000,1970-01-01 00:00:00,1970-01-01 01:00:00 +01:00`+"\n")
+
+	b.Reset()
+	rowIdx, err = arrowRecordToCSV(&b, record, 0, 1)
+	assertNilF(t, err)
+	assertEqualE(t, rowIdx, 1, "should stop after the buffer is full")
+}
+
+func TestArrowRecordsToBindValues(t *testing.T) {
+	pool := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer pool.AssertSize(t, 0)
+	record := buildArrowBindTestRecord(pool)
+	defer record.Release()
+
+	types, err := arrowBindSchemaTypes(record.Schema())
+	assertNilF(t, err)
+	bindValues, err := arrowRecordsToBindValues([]arrow.Record{record, record}, types)
+	assertNilF(t, err)
+	assertEqualE(t, len(bindValues), 10)
+
+	ints := bindValues["1"]
+	assertEqualE(t, ints.Type, "FIXED")
+	intValues := ints.Value.([]*string)
+	assertEqualE(t, len(intValues), 4)
+	assertEqualE(t, *intValues[0], "1")
+	assertNilE(t, intValues[1])
+
+	assertEqualE(t, bindValues["5"].Type, "TEXT")
+	assertEqualE(t, *bindValues["5"].Value.([]*string)[0], `a "quoted", text`)
+	assertEqualE(t, *bindValues["7"].Value.([]*string)[0], "1705622400000")
+	assertEqualE(t, *bindValues["8"].Value.([]*string)[0], "13353000000000")
+	assertEqualE(t, bindValues["10"].Type, "TIMESTAMP_TZ")
+	assertEqualE(t, *bindValues["10"].Value.([]*string)[0], "1705635753123000000 1500")
+}
+
+func TestArrowBindSchemaTypes(t *testing.T) {
+	_, err := arrowBindSchemaTypes(arrow.NewSchema([]arrow.Field{{Name: "l", Type: arrow.ListOf(arrow.PrimitiveTypes.Int64)}}, nil))
+	assertNotNilF(t, err)
+	driverErr, ok := err.(*SnowflakeError)
+	assertTrueF(t, ok)
+	assertEqualE(t, driverErr.Number, ErrBindSerialization)
+}
+
+func TestCheckArrowBindCompatibility(t *testing.T) {
+	schema := arrow.NewSchema([]arrow.Field{
+		{Name: "i", Type: arrow.PrimitiveTypes.Int64},
+		{Name: "ts", Type: &arrow.TimestampType{Unit: arrow.Nanosecond}},
+	}, nil)
+	types, err := arrowBindSchemaTypes(schema)
+	assertNilF(t, err)
+
+	assertNilE(t, checkArrowBindCompatibility(schema, types, nil))
+	assertNilE(t, checkArrowBindCompatibility(schema, types, []execResponseRowType{{Type: "fixed"}, {Type: "timestamp_ltz"}}))
+
+	err = checkArrowBindCompatibility(schema, types, []execResponseRowType{{Type: "fixed"}})
+	assertNotNilF(t, err)
+	assertEqualE(t, err.(*SnowflakeError).Number, ErrBindTypeMismatch)
+
+	err = checkArrowBindCompatibility(schema, types, []execResponseRowType{{Type: "binary"}, {Type: "timestamp_ntz"}})
+	assertNotNilF(t, err)
+	assertEqualE(t, err.(*SnowflakeError).Number, ErrBindTypeMismatch)
+}
+
+func TestSupportedArrowBind(t *testing.T) {
+	pool := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer pool.AssertSize(t, 0)
+	record := buildArrowBindTestRecord(pool)
+	defer record.Release()
+	reader, err := array.NewRecordReader(record.Schema(), []arrow.Record{record})
+	assertNilF(t, err)
+	defer reader.Release()
+
+	assertTrueE(t, isArrowBind([]driver.NamedValue{{Value: record}}))
+	assertTrueE(t, isArrowBind([]driver.NamedValue{{Value: reader}}))
+	assertFalseE(t, isArrowBind([]driver.NamedValue{{Value: record}, {Value: record}}))
+	assertFalseE(t, isArrowBind([]driver.NamedValue{{Value: int64(1)}}))
+}
+
+func TestCheckArrowBindings(t *testing.T) {
+	pool := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer pool.AssertSize(t, 0)
+	record := buildArrowBindTestRecord(pool)
+	defer record.Release()
+
+	assertNilE(t, checkArrowBindings([]driver.NamedValue{{Value: record}}))
+	assertNilE(t, checkArrowBindings([]driver.NamedValue{{Value: int64(1)}, {Value: "a"}}))
+	err := checkArrowBindings([]driver.NamedValue{{Value: int64(1)}, {Value: record}})
+	assertNotNilF(t, err)
+	assertEqualE(t, err.(*SnowflakeError).Number, ErrBindSerialization)
+}
+
+func TestUploadArrowReleasesRecordsOnError(t *testing.T) {
+	pool := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer pool.AssertSize(t, 0)
+	first := buildArrowBindTestRecord(pool)
+	defer first.Release()
+
+	schema := arrow.NewSchema([]arrow.Field{{Name: "l", Type: arrow.ListOf(arrow.PrimitiveTypes.Int64)}}, nil)
+	b := array.NewRecordBuilder(pool, schema)
+	defer b.Release()
+	lb := b.Field(0).(*array.ListBuilder)
+	lb.Append(true)
+	lb.ValueBuilder().(*array.Int64Builder).Append(1)
+	unsupported := b.NewRecord()
+	next := []arrow.Record{unsupported}
+	nextRecord := func() (arrow.Record, bool) {
+		if len(next) == 0 {
+			return nil, false
+		}
+		record := next[0]
+		next = next[1:]
+		return record, true
+	}
+
+	err := (&bindUploader{}).uploadArrow(first, nextRecord)
+	assertNotNilE(t, err, "a record which cannot be converted should fail the upload")
+}
+
+func TestArrowBindDescribeIsInternal(t *testing.T) {
+	pool := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer pool.AssertSize(t, 0)
+	record := buildArrowBindTestRecord(pool)
+	defer record.Release()
+
+	var requests []execRequest
+	var requestIDs []UUID
+	postQueryMock := func(_ context.Context, _ *snowflakeRestful, _ *url.Values, _ map[string]string,
+		body []byte, _ time.Duration, requestID UUID, _ *Config) (*execResponse, error) {
+		var req execRequest
+		if err := json.Unmarshal(body, &req); err != nil {
+			return nil, err
+		}
+		requests = append(requests, req)
+		requestIDs = append(requestIDs, requestID)
+		return &execResponse{Data: execResponseData{QueryID: "01b2c3"}, Success: true}, nil
+	}
+	hooks := &testEventHooks{}
+	sc := &snowflakeConn{
+		cfg:               &Config{Params: map[string]*string{}, EventHooks: hooks},
+		rest:              &snowflakeRestful{FuncPostQuery: postQueryMock},
+		telemetry:         &snowflakeTelemetry{enabled: false},
+		queryContextCache: (&queryContextCache{}).init(),
+		queryHistory:      newQueryHistory(10),
+	}
+	requestID := NewUUID()
+	_, err := sc.exec(WithRequestID(context.Background(), requestID), "insert into t values (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)",
+		false, false, false, []driver.NamedValue{{Ordinal: 1, Value: record}})
+	assertNilF(t, err)
+
+	assertEqualF(t, len(requests), 2)
+	assertTrueE(t, requests[0].DescribeOnly)
+	assertTrueE(t, requests[0].IsInternal, "the describe should be internal")
+	assertNotEqualE(t, requestIDs[0], requestID, "the describe should have its own request ID")
+	assertFalseE(t, requests[1].DescribeOnly)
+	assertEqualE(t, requestIDs[1], requestID)
+	assertEqualE(t, len(sc.QueryHistory()), 1, "only the statement should be recorded")
+	assertEqualE(t, len(hooks.queryStarts), 1, "the hooks should be called for the statement only")
+	assertEqualE(t, len(hooks.queryFinishes), 1)
+}
+
+func TestBindingArrowRecord(t *testing.T) {
+	testBindingArrowRecord(t, false)
+}
+
+func TestBindingBulkArrowRecord(t *testing.T) {
+	if runningOnGithubAction() {
+		t.Skip("client_stage_array_binding_threshold value is internal")
+	}
+	testBindingArrowRecord(t, true)
+}
+
+func testBindingArrowRecord(t *testing.T, bulk bool) {
+	pool := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer pool.AssertSize(t, 0)
+	record := buildArrowBindTestRecord(pool)
+	defer record.Release()
+
+	runDBTest(t, func(dbt *DBTest) {
+		dbt.mustExec("CREATE OR REPLACE TABLE test_arrow_binding (i INTEGER, d NUMBER(10, 2), f DOUBLE, b BOOLEAN, s VARCHAR, bin BINARY, date DATE, time TIME, ntz TIMESTAMP_NTZ, tz TIMESTAMP_TZ)")
+		defer dbt.mustExec("DROP TABLE IF EXISTS test_arrow_binding")
+		if bulk {
+			dbt.mustExec("ALTER SESSION SET CLIENT_STAGE_ARRAY_BINDING_THRESHOLD = 1")
+		}
+		dbt.mustExecContext(context.Background(), "INSERT INTO test_arrow_binding VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)", record)
+
+		rows := dbt.mustQuery("SELECT i, s, ntz FROM test_arrow_binding ORDER BY i NULLS LAST")
+		defer rows.Close()
+		var i *int64
+		var s *string
+		var ntz time.Time
+		assertTrueF(t, rows.Next())
+		assertNilF(t, rows.Scan(&i, &s, &ntz))
+		assertEqualE(t, *i, int64(1))
+		assertEqualE(t, *s, `a "quoted", text`)
+		assertTrueE(t, ntz.Equal(time.Date(2024, time.January, 19, 3, 42, 33, 123456789, time.UTC)))
+		assertTrueF(t, rows.Next())
+		assertNilF(t, rows.Scan(&i, &s, &ntz))
+		assertNilE(t, i)
+		assertNilE(t, s)
+		assertFalseE(t, rows.Next())
+
+		_, err := dbt.exec("INSERT INTO test_arrow_binding (i, bin) VALUES (?, ?)", record)
+		assertNotNilE(t, err, "column count mismatch should fail")
+	})
+}
@@ -7,6 +7,8 @@ import (
 	"net/url"
 	"strconv"
 	"time"
+
+	"go.opentelemetry.io/otel/trace"
 )
 
 func (sr *snowflakeRestful) processAsync(
@@ -40,7 +42,7 @@ func (sr *snowflakeRestful) processAsync(
 		func() {
 			err := sr.getAsync(ctx, headers, sr.getFullURL(respd.Data.GetResultURL, nil), timeout, res, rows, cfg)
 			if err != nil {
-				logger.Errorf("error while calling getAsync. %v", err)
+				sr.getLogger().Errorf("error while calling getAsync. %v", err)
 			}
 		},
 	)
@@ -54,7 +56,7 @@ func (sr *snowflakeRestful) getAsync(
 	timeout time.Duration,
 	res *snowflakeResult,
 	rows *snowflakeRows,
-	cfg *Config) error {
+	cfg *Config) (err error) {
 	resType := getResultType(ctx)
 	var errChannel chan error
 	sfError := &SnowflakeError{
@@ -68,12 +70,16 @@ func (sr *snowflakeRestful) getAsync(
 		sfError.QueryID = rows.queryID
 	}
 	defer close(errChannel)
+	ctx, span := startSpan(ctx, cfg, spanAsyncQuery, trace.SpanKindClient, attrQueryID.String(sfError.QueryID))
+	defer func() {
+		endSpan(span, err)
+	}()
 	token, _, _ := sr.TokenAccessor.GetTokens()
 	headers[headerAuthorizationKey] = fmt.Sprintf(headerSnowflakeToken, token)
 
 	respd, err := getQueryResultWithRetriesForAsyncMode(ctx, sr, URL, headers, timeout)
 	if err != nil {
-		logger.WithContext(ctx).Errorf("error: %v", err)
+		sr.getLogger().WithContext(ctx).Errorf("error: %v", err)
 		sfError.Message = err.Error()
 		errChannel <- sfError
 		return err
@@ -153,11 +159,11 @@ func getQueryResultWithRetriesForAsyncMode(
 	retryCountForSessionRenewal := 0
 
 	for {
-		logger.WithContext(ctx).Debugf("Retry count for get query result request in async mode: %v", retry)
+		sr.getLogger().WithContext(ctx).Debugf("Retry count for get query result request in async mode: %v", retry)
 
 		resp, err := sr.FuncGet(ctx, sr, URL, headers, timeout)
 		if err != nil {
-			logger.WithContext(ctx).Errorf("failed to get response. err: %v", err)
+			sr.getLogger().WithContext(ctx).Errorf("failed to get response. err: %v", err)
 			return respd, err
 		}
 		defer resp.Body.Close()
@@ -165,7 +171,7 @@ func getQueryResultWithRetriesForAsyncMode(
 		respd = &execResponse{} // reset the response
 		err = json.NewDecoder(resp.Body).Decode(&respd)
 		if err != nil {
-			logger.WithContext(ctx).Errorf("failed to decode JSON. err: %v", err)
+			sr.getLogger().WithContext(ctx).Errorf("failed to decode JSON. err: %v", err)
 			return respd, err
 		}
 		if respd.Code == sessionExpiredCode {
@@ -173,26 +179,26 @@ func getQueryResultWithRetriesForAsyncMode(
 			token, _, _ := sr.TokenAccessor.GetTokens()
 			if token != "" && headers[headerAuthorizationKey] != fmt.Sprintf(headerSnowflakeToken, token) {
 				headers[headerAuthorizationKey] = fmt.Sprintf(headerSnowflakeToken, token)
-				logger.WithContext(ctx).Info("Session token has been updated.")
+				sr.getLogger().WithContext(ctx).Info("Session token has been updated.")
 				retry++
 				continue
 			}
 
 			// Renew the session token
 			if err = sr.renewExpiredSessionToken(ctx, timeout, token); err != nil {
-				logger.WithContext(ctx).Errorf("failed to renew session token. err: %v", err)
+				sr.getLogger().WithContext(ctx).Errorf("failed to renew session token. err: %v", err)
 				return respd, err
 			}
 			retryCountForSessionRenewal++
 
 			// If this is the first response, go back to retry the query
 			// since it failed due to session expiration
-			logger.WithContext(ctx).Infof("retry count for session renewal: %v", retryCountForSessionRenewal)
+			sr.getLogger().WithContext(ctx).Infof("retry count for session renewal: %v", retryCountForSessionRenewal)
 			if retryCountForSessionRenewal < 2 {
 				retry++
 				continue
 			} else {
-				logger.WithContext(ctx).Errorf("failed to get query result with the renewed session token. err: %v", err)
+				sr.getLogger().WithContext(ctx).Errorf("failed to get query result with the renewed session token. err: %v", err)
 				return respd, err
 			}
 		} else if respd.Code != queryInProgressAsyncCode {
@@ -204,7 +210,7 @@ func getQueryResultWithRetriesForAsyncMode(
 			// Sleep before retrying get result request. Exponential backoff up to 5 seconds.
 			// Once 5 second backoff is reached it will keep retrying with this sleeptime.
 			sleepTime := time.Millisecond * time.Duration(500*retryPattern[retryPatternIndex])
-			logger.WithContext(ctx).Infof("Query execution still in progress. Response code: %v, message: %v Sleep for %v ms", respd.Code, respd.Message, sleepTime)
+			sr.getLogger().WithContext(ctx).Infof("Query execution still in progress. Response code: %v, message: %v Sleep for %v ms", respd.Code, respd.Message, sleepTime)
 			time.Sleep(sleepTime)
 			retry++
 
@@ -18,6 +18,7 @@ import (
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
+	"go.opentelemetry.io/otel/trace"
 )
 
 const (
@@ -232,7 +233,7 @@ func postAuth(
 	params.Set(requestGUIDKey, NewUUID().String())
 
 	fullURL := sr.getFullURL(loginRequestPath, params)
-	logger.WithContext(ctx).Infof("full URL: %v", fullURL)
+	sr.getLogger().WithContext(ctx).Infof("full URL: %v", fullURL)
 	resp, err := sr.FuncAuthPost(ctx, client, fullURL, headers, bodyCreator, timeout, sr.MaxRetryCount)
 	if err != nil {
 		return nil, err
@@ -242,7 +243,7 @@ func postAuth(
 		var respd authResponse
 		err = json.NewDecoder(resp.Body).Decode(&respd)
 		if err != nil {
-			logger.WithContext(ctx).Errorf("failed to decode JSON. err: %v", err)
+			sr.getLogger().WithContext(ctx).Errorf("failed to decode JSON. err: %v", err)
 			return nil, err
 		}
 		return &respd, nil
@@ -267,11 +268,11 @@ func postAuth(
 	}
 	b, err := io.ReadAll(resp.Body)
 	if err != nil {
-		logger.WithContext(ctx).Errorf("failed to extract HTTP response body. err: %v", err)
+		sr.getLogger().WithContext(ctx).Errorf("failed to extract HTTP response body. err: %v", err)
 		return nil, err
 	}
-	logger.WithContext(ctx).Infof("HTTP: %v, URL: %v, Body: %v", resp.StatusCode, fullURL, b)
-	logger.WithContext(ctx).Infof("Header: %v", resp.Header)
+	sr.getLogger().WithContext(ctx).Infof("HTTP: %v, URL: %v, Body: %v", resp.StatusCode, fullURL, b)
+	sr.getLogger().WithContext(ctx).Infof("Header: %v", resp.Header)
 	return nil, &SnowflakeError{
 		Number:      ErrFailedToAuth,
 		SQLState:    SQLStateConnectionRejected,
@@ -300,7 +301,7 @@ func authenticate(
 	proofKey []byte,
 ) (resp *authResponseMain, err error) {
 	if sc.cfg.Authenticator == AuthTypeTokenAccessor {
-		logger.WithContext(ctx).Info("Bypass authentication using existing token from token accessor")
+		sc.getLogger().WithContext(ctx).Info("Bypass authentication using existing token from token accessor")
 		sessionInfo := authResponseSessionInfo{
 			DatabaseName:  sc.cfg.Database,
 			SchemaName:    sc.cfg.Schema,
@@ -341,7 +342,7 @@ func authenticate(
 		sessionParameters[clientStoreTemporaryCredential] = true
 	}
 	bodyCreator := func() ([]byte, error) {
-		return createRequestBody(sc, sessionParameters, clientEnvironment, proofKey, samlResponse)
+		return createRequestBody(ctx, sc, sessionParameters, clientEnvironment, proofKey, samlResponse)
 	}
 
 	params := &url.Values{}
@@ -358,7 +359,7 @@ func authenticate(
 		params.Add("roleName", sc.cfg.Role)
 	}
 
-	logger.WithContext(ctx).WithContext(sc.ctx).Infof("PARAMS for Auth: %v, %v, %v, %v, %v, %v",
+	sc.getLogger().WithContext(ctx).WithContext(sc.ctx).Infof("PARAMS for Auth: %v, %v, %v, %v, %v, %v",
 		params, sc.rest.Protocol, sc.rest.Host, sc.rest.Port, sc.rest.LoginTimeout, sc.cfg.Authenticator.String())
 
 	respd, err := sc.rest.FuncPostAuth(ctx, sc.rest, sc.rest.getClientFor(sc.cfg.Authenticator), params, headers, bodyCreator, sc.rest.LoginTimeout)
@@ -366,7 +367,7 @@ func authenticate(
 		return nil, err
 	}
 	if !respd.Success {
-		logger.WithContext(ctx).Errorln("Authentication FAILED")
+		sc.getLogger().WithContext(ctx).Errorln("Authentication FAILED")
 		sc.rest.TokenAccessor.SetTokens("", "", -1)
 		if sessionParameters[clientRequestMfaToken] == true {
 			credentialsStorage.deleteCredential(newMfaTokenSpec(sc.cfg.Host, sc.cfg.User))
@@ -384,7 +385,7 @@ func authenticate(
 			Message:  respd.Message,
 		}).exceptionTelemetry(sc)
 	}
-	logger.WithContext(ctx).Info("Authentication SUCCESS")
+	sc.getLogger().WithContext(ctx).Info("Authentication SUCCESS")
 	sc.rest.TokenAccessor.SetTokens(respd.Data.Token, respd.Data.MasterToken, respd.Data.SessionID)
 	if sessionParameters[clientRequestMfaToken] == true {
 		token := respd.Data.MfaToken
@@ -397,7 +398,7 @@ func authenticate(
 	return &respd.Data, nil
 }
 
-func createRequestBody(sc *snowflakeConn, sessionParameters map[string]interface{},
+func createRequestBody(ctx context.Context, sc *snowflakeConn, sessionParameters map[string]interface{},
 	clientEnvironment authRequestClientEnvironment, proofKey []byte, samlResponse []byte,
 ) ([]byte, error) {
 	requestMain := authRequestData{
@@ -426,7 +427,7 @@ func createRequestBody(sc *snowflakeConn, sessionParameters map[string]interface
 		requestMain.Token = sc.cfg.Token
 	case AuthTypeOkta:
 		samlResponse, err := authenticateBySAML(
-			sc.ctx,
+			ctx,
 			sc.rest,
 			sc.cfg.OktaURL,
 			sc.cfg.Application,
@@ -450,12 +451,12 @@ func createRequestBody(sc *snowflakeConn, sessionParameters map[string]interface
 		if !experimentalAuthEnabled() {
 			return nil, errors.New("programmatic access tokens are not ready to use")
 		}
-		logger.WithContext(sc.ctx).Info("Programmatic access token")
+		sc.getLogger().WithContext(sc.ctx).Info("Programmatic access token")
 		requestMain.Authenticator = AuthTypePat.String()
 		requestMain.LoginName = sc.cfg.User
 		requestMain.Token = sc.cfg.Token
 	case AuthTypeSnowflake:
-		logger.WithContext(sc.ctx).Info("Username and password")
+		sc.getLogger().WithContext(sc.ctx).Info("Username and password")
 		requestMain.LoginName = sc.cfg.User
 		requestMain.Password = sc.cfg.Password
 		switch {
@@ -466,7 +467,7 @@ func createRequestBody(sc *snowflakeConn, sessionParameters map[string]interface
 			requestMain.ExtAuthnDuoMethod = "passcode"
 		}
 	case AuthTypeUsernamePasswordMFA:
-		logger.WithContext(sc.ctx).Info("Username and password MFA")
+		sc.getLogger().WithContext(sc.ctx).Info("Username and password MFA")
 		requestMain.LoginName = sc.cfg.User
 		requestMain.Password = sc.cfg.Password
 		switch {
@@ -495,7 +496,7 @@ func prepareJWTToken(config *Config) (string, error) {
 	if config.PrivateKey == nil {
 		return "", errors.New("trying to use keypair authentication, but PrivateKey was not provided in the driver config")
 	}
-	logger.Debug("preparing JWT for keypair authentication")
+	getConnectionLogger(config).Debug("preparing JWT for keypair authentication")
 	pubBytes, err := x509.MarshalPKIXPublicKey(config.PrivateKey.Public())
 	if err != nil {
 		return "", err
@@ -521,16 +522,23 @@ func prepareJWTToken(config *Config) (string, error) {
 		return "", err
 	}
 
-	logger.Debugf("successfully generated JWT with following claims: %v", jwtClaims)
+	getConnectionLogger(config).Debugf("successfully generated JWT with following claims: %v", jwtClaims)
 	return tokenString, err
 }
 
 // Authenticate with sc.cfg
-func authenticateWithConfig(sc *snowflakeConn) error {
+func authenticateWithConfig(sc *snowflakeConn) (err error) {
 	var authData *authResponseMain
 	var samlResponse []byte
 	var proofKey []byte
-	var err error
+	// the requests of the login are traced as children of the login span
+	ctx, span := startSpan(sc.ctx, sc.cfg, spanLogin, trace.SpanKindInternal,
+		attrAuthenticator.String(sc.cfg.Authenticator.String()))
+	start := time.Now()
+	defer func() {
+		getMetricsRecorder(sc.cfg).RecordLogin(ctx, time.Since(start), metricsStatus(err))
+		endSpan(span, err)
+	}()
 	//var consentCacheIdToken = true
 
 	if sc.cfg.Authenticator == AuthTypeExternalBrowser {
@@ -555,12 +563,12 @@ func authenticateWithConfig(sc *snowflakeConn) error {
 		}
 	}
 
-	logger.WithContext(sc.ctx).Infof("Authenticating via %v", sc.cfg.Authenticator.String())
+	sc.getLogger().WithContext(ctx).Infof("Authenticating via %v", sc.cfg.Authenticator.String())
 	switch sc.cfg.Authenticator {
 	case AuthTypeExternalBrowser:
 		if sc.cfg.IDToken == "" {
 			samlResponse, proofKey, err = authenticateByExternalBrowser(
-				sc.ctx,
+				ctx,
 				sc.rest,
 				sc.cfg.Authenticator.String(),
 				sc.cfg.Application,
@@ -576,7 +584,7 @@ func authenticateWithConfig(sc *snowflakeConn) error {
 		}
 	}
 	authData, err = authenticate(
-		sc.ctx,
+		ctx,
 		sc,
 		samlResponse,
 		proofKey)
@@ -1,12 +1,15 @@
 package gosnowflake
 
 import (
+	"bytes"
 	"cmp"
 	"context"
+	"encoding/base64"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"math"
 	"net/http"
 	"net/url"
 	"os"
@@ -15,9 +18,11 @@ import (
 
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/streaming"
 	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob"
 	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/blob"
 	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/bloberror"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/blockblob"
 	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/container"
 )
 
@@ -133,10 +138,16 @@ func (util *snowflakeAzureClient) getFileHeader(meta *fileMetadata, filename str
 		// sfcdigest is not in response, use empty string
 		digest = new(string)
 	}
+	var storageChecksums *contentChecksums
+	if len(resp.ContentMD5) > 0 {
+		// Azure computes the MD5 of blobs uploaded in a single request
+		storageChecksums = &contentChecksums{md5: base64.StdEncoding.EncodeToString(resp.ContentMD5)}
+	}
 	return &fileHeader{
 		*digest,
 		int64(len(metadata)),
 		&encryptionMetadata,
+		storageChecksums,
 	}, nil
 }
 
@@ -146,67 +157,24 @@ func (util *snowflakeAzureClient) uploadFile(
 	meta *fileMetadata,
 	maxConcurrency int,
 	multiPartThreshold int64) error {
-	azureMeta := map[string]*string{
-		"sfcdigest": &meta.sha256Digest,
-	}
-	if meta.encryptMeta != nil {
-		ed := &encryptionData{
-			EncryptionMode: "FullBlob",
-			WrappedContentKey: contentKey{
-				"symmKey1",
-				meta.encryptMeta.key,
-				"AES_CBC_256",
-			},
-			EncryptionAgent: encryptionAgent{
-				"1.0",
-				"AES_CBC_128",
-			},
-			ContentEncryptionIV: meta.encryptMeta.iv,
-			KeyWrappingMetadata: keyMetadata{
-				"Java 5.3.0",
-			},
-		}
-		metadata, err := json.Marshal(ed)
-		if err != nil {
-			return err
-		}
-		encryptionMetadata := string(metadata)
-		azureMeta["encryptiondata"] = &encryptionMetadata
-		azureMeta["matdesc"] = &meta.encryptMeta.matdesc
-	}
-
-	azureLoc, err := util.extractContainerNameAndPath(meta.stageInfo.Location)
+	azureMeta, err := util.getAzureMetadata(meta)
 	if err != nil {
 		return err
 	}
-	path := azureLoc.path + strings.TrimLeft(meta.dstFileName, "/")
-	client, ok := meta.client.(*azblob.Client)
-	if !ok {
-		return &SnowflakeError{
-			Message: "failed to cast to azure client",
-		}
-	}
-	containerClient, err := createContainerClient(client.URL(), util.cfg)
-
+	blobClient, err := util.getUploadBlobClient(meta)
 	if err != nil {
-		return &SnowflakeError{
-			Message: "failed to create container client",
-		}
-	}
-	var blobClient azureAPI
-	blobClient = containerClient.NewBlockBlobClient(path)
-	// for testing only
-	if meta.mockAzureClient != nil {
-		blobClient = meta.mockAzureClient
+		return err
 	}
 	if meta.srcStream != nil {
 		uploadSrc := cmp.Or(meta.realSrcStream, meta.srcStream)
 		_, err = withCloudStorageTimeout(util.cfg, func(ctx context.Context) (azblob.UploadStreamResponse, error) {
-			return blobClient.UploadStream(ctx, uploadSrc, &azblob.UploadStreamOptions{
+			return blobClient.UploadStream(ctx, meta.progress.reader(uploadSrc), &azblob.UploadStreamOptions{
 				BlockSize: int64(uploadSrc.Len()),
 				Metadata:  azureMeta,
 			})
 		})
+	} else if blockClient, ok := blobClient.(azureBlockAPI); ok && meta.uploadJournal != nil {
+		err = util.uploadFileResumable(dataFile, meta, blockClient, azureMeta, maxConcurrency, multiPartThreshold)
 	} else {
 		var f *os.File
 		f, err = os.Open(dataFile)
@@ -225,52 +193,275 @@ func (util *snowflakeAzureClient) uploadFile(
 			Metadata:    azureMeta,
 			Concurrency: uint16(maxConcurrency),
 		}
+		if meta.verifyChecksums() {
+			// Azure validates the CRC64 of every staged block
+			blobOptions.TransactionalValidation = blob.TransferValidationTypeComputeCRC64()
+		}
 		if meta.options.putAzureCallback != nil {
 			blobOptions.Progress = meta.options.putAzureCallback.call
+		} else if meta.progress != nil {
+			blobOptions.Progress = meta.progress.set
 		}
 		_, err = withCloudStorageTimeout(util.cfg, func(ctx context.Context) (azblob.UploadFileResponse, error) {
 			return blobClient.UploadFile(ctx, f, blobOptions)
 		})
 	}
 	if err != nil {
-		var se *azcore.ResponseError
-		if errors.As(err, &se) {
-			if se.StatusCode == 403 && util.detectAzureTokenExpireError(se.RawResponse) {
-				meta.resStatus = renewToken
-			} else {
-				meta.resStatus = needRetry
-				meta.lastError = err
-			}
+		util.setUploadErrorStatus(meta, err)
+		return err
+	}
+
+	meta.dstFileSize = meta.uploadSize
+	meta.resStatus = uploaded
+	return nil
+}
+
+type azureBlockAPI interface {
+	StageBlock(ctx context.Context, base64BlockID string, body io.ReadSeekCloser, options *blockblob.StageBlockOptions) (blockblob.StageBlockResponse, error)
+	CommitBlockList(ctx context.Context, base64BlockIDs []string, options *blockblob.CommitBlockListOptions) (blockblob.CommitBlockListResponse, error)
+	Delete(ctx context.Context, options *blob.DeleteOptions) (blob.DeleteResponse, error)
+}
+
+// abortBlockUpload discards the uncommitted blocks of a file in the stage of meta. If the blob does not exist,
+// an empty block list is committed, which discards the uncommitted blocks, and the empty blob is deleted.
+// Committing would replace an existing blob, so its uncommitted blocks are left to the garbage collection
+// of the storage, which discards them after a week.
+func (util *snowflakeAzureClient) abortBlockUpload(meta *fileMetadata, fileName string) error {
+	blobClient, err := util.getBlobClient(meta, fileName)
+	if err != nil {
+		return err
+	}
+	blockClient, ok := blobClient.(azureBlockAPI)
+	if !ok {
+		return nil
+	}
+	etagAny := azcore.ETagAny
+	resp, err := withCloudStorageTimeout(util.cfg, func(ctx context.Context) (blockblob.CommitBlockListResponse, error) {
+		return blockClient.CommitBlockList(ctx, nil, &blockblob.CommitBlockListOptions{
+			AccessConditions: &blob.AccessConditions{
+				ModifiedAccessConditions: &blob.ModifiedAccessConditions{IfNoneMatch: &etagAny},
+			},
+		})
+	})
+	if bloberror.HasCode(err, bloberror.BlobAlreadyExists, bloberror.ConditionNotMet) {
+		getConnectionLogger(util.cfg).Debugf("%v exists, its uncommitted blocks are discarded by the storage", fileName)
+		return nil
+	} else if err != nil {
+		return err
+	}
+	_, err = withCloudStorageTimeout(util.cfg, func(ctx context.Context) (blob.DeleteResponse, error) {
+		return blockClient.Delete(ctx, &blob.DeleteOptions{
+			AccessConditions: &blob.AccessConditions{
+				ModifiedAccessConditions: &blob.ModifiedAccessConditions{IfMatch: resp.ETag},
+			},
+		})
+	})
+	return err
+}
+
+// uploadFileResumable stages the blocks of the file recorded in the upload journal,
+// so that a failed upload continues with the blocks which were not staged yet.
+func (util *snowflakeAzureClient) uploadFileResumable(
+	dataFile string,
+	meta *fileMetadata,
+	blockClient azureBlockAPI,
+	azureMeta map[string]*string,
+	maxConcurrency int,
+	partSize int64) error {
+	journal := meta.uploadJournal
+	if journal.UploadID == "" {
+		stat, err := os.Stat(dataFile)
+		if err != nil {
 			return err
 		}
-		meta.resStatus = errStatus
+		// block IDs are prefixed with the upload ID, so that blocks left by other uploads are never committed
+		if err = journal.start(strings.ReplaceAll(NewUUID().String(), "-", ""), getMultipartPartSize(stat.Size(), partSize, blockblob.MaxBlocks)); err != nil {
+			return err
+		}
+	}
+	uploadID := journal.UploadID
+	meta.progress.set(journal.uploadedBytes())
+	err := uploadMissingParts(journal, dataFile, maxConcurrency, func(number int32, body *io.SectionReader) (uploadJournalPart, error) {
+		blockID := base64.StdEncoding.EncodeToString([]byte(fmt.Sprintf("%s%06d", uploadID, number)))
+		_, err := withCloudStorageTimeout(util.cfg, func(ctx context.Context) (blockblob.StageBlockResponse, error) {
+			return blockClient.StageBlock(ctx, blockID, streaming.NopCloser(body), nil)
+		})
+		if err != nil {
+			return uploadJournalPart{}, err
+		}
+		if meta.options.putAzureCallback != nil {
+			meta.options.putAzureCallback.call(body.Size())
+		}
+		meta.progress.add(body.Size())
+		return uploadJournalPart{Number: number, Size: body.Size(), BlockID: blockID}, nil
+	})
+	if err == nil {
+		parts := journal.sortedParts()
+		blockIDs := make([]string, len(parts))
+		for i, part := range parts {
+			blockIDs[i] = part.BlockID
+		}
+		err = util.commitBlocks(blockClient, blockIDs, azureMeta)
+	}
+	if err != nil {
+		if bloberror.HasCode(err, bloberror.InvalidBlockList, bloberror.InvalidBlockID) {
+			// uncommitted blocks were garbage collected by the storage, the next attempt starts from the beginning
+			journal.reset()
+		}
 		return err
 	}
+	journal.remove()
+	return nil
+}
 
+func (util *snowflakeAzureClient) commitBlocks(blockClient azureBlockAPI, blockIDs []string, azureMeta map[string]*string) error {
+	contentType := "application/octet-stream"
+	contentEncoding := "utf-8"
+	_, err := withCloudStorageTimeout(util.cfg, func(ctx context.Context) (blockblob.CommitBlockListResponse, error) {
+		return blockClient.CommitBlockList(ctx, blockIDs, &blockblob.CommitBlockListOptions{
+			HTTPHeaders: &blob.HTTPHeaders{
+				BlobContentType:     &contentType,
+				BlobContentEncoding: &contentEncoding,
+			},
+			Metadata: azureMeta,
+		})
+	})
+	return err
+}
+
+// cloudStreamUtil implementation
+func (util *snowflakeAzureClient) uploadStream(
+	meta *fileMetadata,
+	src io.Reader,
+	maxConcurrency int,
+	partSize int64) error {
+	blobClient, err := util.getUploadBlobClient(meta)
+	if err != nil {
+		return err
+	}
+	blockClient, ok := blobClient.(azureBlockAPI)
+	if !ok {
+		return &SnowflakeError{
+			Message: "failed to cast to azure block blob client",
+		}
+	}
+	blockIDs, err := util.stageStreamBlocks(meta, meta.progress.reader(src), blockClient, maxConcurrency, partSize)
+	if err == nil {
+		// the digest is known once the whole stream was read, so the metadata is built just before the commit
+		var azureMeta map[string]*string
+		if azureMeta, err = util.getAzureMetadata(meta); err == nil {
+			err = util.commitBlocks(blockClient, blockIDs, azureMeta)
+		}
+	}
+	if err != nil {
+		util.setUploadErrorStatus(meta, err)
+		return err
+	}
 	meta.dstFileSize = meta.uploadSize
 	meta.resStatus = uploaded
 	return nil
 }
 
-// cloudUtil implementation
-func (util *snowflakeAzureClient) nativeDownloadFile(
+// stageStreamBlocks stages the blocks of the stream as they are read, see uploadStreamParts.
+// It returns the IDs of the staged blocks in the order of the stream.
+func (util *snowflakeAzureClient) stageStreamBlocks(
 	meta *fileMetadata,
-	fullDstFileName string,
-	maxConcurrency int64) error {
+	src io.Reader,
+	blockClient azureBlockAPI,
+	maxConcurrency int,
+	partSize int64) ([]string, error) {
+	// block IDs are prefixed with the upload ID, so that blocks left by other uploads are never committed
+	uploadID := strings.ReplaceAll(NewUUID().String(), "-", "")
+	blockID := func(number int) string {
+		return base64.StdEncoding.EncodeToString([]byte(fmt.Sprintf("%s%06d", uploadID, number)))
+	}
+	blocks, err := uploadStreamParts(src, partSize, maxConcurrency, blockblob.MaxBlocks, func(number int, block []byte) error {
+		return util.stageBlockWithRetry(meta, blockClient, blockID(number), block)
+	})
+	blockIDs := make([]string, blocks)
+	for i := range blockIDs {
+		blockIDs[i] = blockID(i + 1)
+	}
+	return blockIDs, err
+}
+
+// stageBlockWithRetry stages a block of a stream, retrying it as uploadOneFile retries files.
+// Expired tokens are not retried, as the stream cannot be uploaded again with new credentials.
+func (util *snowflakeAzureClient) stageBlockWithRetry(meta *fileMetadata, blockClient azureBlockAPI, blockID string, block []byte) error {
+	var err error
+	for retry := 0; retry < defaultMaxRetry; retry++ {
+		if retry > 0 && !meta.noSleepingTime {
+			sleepingTime := intMin(int(math.Exp2(float64(retry))), 16)
+			time.Sleep(time.Second * time.Duration(sleepingTime))
+		}
+		_, err = withCloudStorageTimeout(util.cfg, func(ctx context.Context) (blockblob.StageBlockResponse, error) {
+			return blockClient.StageBlock(ctx, blockID, streaming.NopCloser(bytes.NewReader(block)), nil)
+		})
+		var se *azcore.ResponseError
+		if err == nil || !errors.As(err, &se) || (se.StatusCode == 403 && util.detectAzureTokenExpireError(se.RawResponse)) {
+			return err
+		}
+		getConnectionLogger(util.cfg).Debugf("error staging block %v of %v, retrying. err: %v", retry+1, meta.dstFileName, err)
+	}
+	return err
+}
+
+// getAzureMetadata returns the metadata of the uploaded blob with the current digest and encryption metadata of meta.
+func (util *snowflakeAzureClient) getAzureMetadata(meta *fileMetadata) (map[string]*string, error) {
+	digest := meta.sha256Digest
+	azureMeta := map[string]*string{
+		"sfcdigest": &digest,
+	}
+	if meta.encryptMeta != nil {
+		ed := &encryptionData{
+			EncryptionMode: "FullBlob",
+			WrappedContentKey: contentKey{
+				"symmKey1",
+				meta.encryptMeta.key,
+				"AES_CBC_256",
+			},
+			EncryptionAgent: encryptionAgent{
+				"1.0",
+				"AES_CBC_128",
+			},
+			ContentEncryptionIV: meta.encryptMeta.iv,
+			KeyWrappingMetadata: keyMetadata{
+				"Java 5.3.0",
+			},
+		}
+		metadata, err := json.Marshal(ed)
+		if err != nil {
+			return nil, err
+		}
+		encryptionMetadata := string(metadata)
+		azureMeta["encryptiondata"] = &encryptionMetadata
+		matdesc := meta.encryptMeta.matdesc
+		azureMeta["matdesc"] = &matdesc
+	}
+	return azureMeta, nil
+}
+
+func (util *snowflakeAzureClient) getUploadBlobClient(meta *fileMetadata) (azureAPI, error) {
+	return util.getBlobClient(meta, meta.dstFileName)
+}
+
+// getBlobClient returns the client of a file in the stage of meta.
+func (util *snowflakeAzureClient) getBlobClient(meta *fileMetadata, fileName string) (azureAPI, error) {
 	azureLoc, err := util.extractContainerNameAndPath(meta.stageInfo.Location)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	path := azureLoc.path + strings.TrimLeft(meta.srcFileName, "/")
+	path := azureLoc.path + strings.TrimLeft(fileName, "/")
 	client, ok := meta.client.(*azblob.Client)
 	if !ok {
-		return &SnowflakeError{
+		return nil, &SnowflakeError{
 			Message: "failed to cast to azure client",
 		}
 	}
 	containerClient, err := createContainerClient(client.URL(), util.cfg)
+
 	if err != nil {
-		return &SnowflakeError{
+		return nil, &SnowflakeError{
 			Message: "failed to create container client",
 		}
 	}
@@ -280,6 +471,32 @@ func (util *snowflakeAzureClient) nativeDownloadFile(
 	if meta.mockAzureClient != nil {
 		blobClient = meta.mockAzureClient
 	}
+	return blobClient, nil
+}
+
+func (util *snowflakeAzureClient) setUploadErrorStatus(meta *fileMetadata, err error) {
+	var se *azcore.ResponseError
+	if errors.As(err, &se) {
+		if se.StatusCode == 403 && util.detectAzureTokenExpireError(se.RawResponse) {
+			meta.resStatus = renewToken
+		} else {
+			meta.resStatus = needRetry
+			meta.lastError = err
+		}
+		return
+	}
+	meta.resStatus = errStatus
+}
+
+// cloudUtil implementation
+func (util *snowflakeAzureClient) nativeDownloadFile(
+	meta *fileMetadata,
+	fullDstFileName string,
+	maxConcurrency int64) error {
+	blobClient, err := util.downloadBlobClient(meta)
+	if err != nil {
+		return err
+	}
 	if meta.options.GetFileToStream {
 		blobDownloadResponse, err := withCloudStorageTimeout(util.cfg, func(ctx context.Context) (azblob.DownloadStreamResponse, error) {
 			return blobClient.DownloadStream(ctx, &azblob.DownloadStreamOptions{})
@@ -289,7 +506,7 @@ func (util *snowflakeAzureClient) nativeDownloadFile(
 		}
 		retryReader := blobDownloadResponse.NewRetryReader(context.Background(), &azblob.RetryReaderOptions{})
 		defer retryReader.Close()
-		_, err = meta.dstStream.ReadFrom(retryReader)
+		_, err = meta.dstStream.ReadFrom(meta.progress.reader(retryReader))
 		if err != nil {
 			return err
 		}
@@ -302,7 +519,8 @@ func (util *snowflakeAzureClient) nativeDownloadFile(
 		_, err = withCloudStorageTimeout(util.cfg, func(ctx context.Context) (any, error) {
 			return blobClient.DownloadFile(
 				ctx, f, &azblob.DownloadFileOptions{
-					Concurrency: uint16(maxConcurrency)})
+					Concurrency: uint16(maxConcurrency),
+					Progress:    meta.progress.set})
 		})
 		if err != nil {
 			return err
@@ -312,6 +530,49 @@ func (util *snowflakeAzureClient) nativeDownloadFile(
 	return nil
 }
 
+// downloadStream implements cloudDownloadStreamUtil.
+func (util *snowflakeAzureClient) downloadStream(meta *fileMetadata) (io.ReadCloser, error) {
+	blobClient, err := util.downloadBlobClient(meta)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := cloudStorageContext(util.cfg)
+	resp, err := blobClient.DownloadStream(ctx, &azblob.DownloadStreamOptions{})
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	return &readCloserWithCancel{resp.NewRetryReader(ctx, &azblob.RetryReaderOptions{}), cancel}, nil
+}
+
+// downloadBlobClient returns the client of the blob downloaded to meta.
+func (util *snowflakeAzureClient) downloadBlobClient(meta *fileMetadata) (azureAPI, error) {
+	azureLoc, err := util.extractContainerNameAndPath(meta.stageInfo.Location)
+	if err != nil {
+		return nil, err
+	}
+	path := azureLoc.path + strings.TrimLeft(meta.srcFileName, "/")
+	client, ok := meta.client.(*azblob.Client)
+	if !ok {
+		return nil, &SnowflakeError{
+			Message: "failed to cast to azure client",
+		}
+	}
+	containerClient, err := createContainerClient(client.URL(), util.cfg)
+	if err != nil {
+		return nil, &SnowflakeError{
+			Message: "failed to create container client",
+		}
+	}
+	var blobClient azureAPI
+	blobClient = containerClient.NewBlockBlobClient(path)
+	// for testing only
+	if meta.mockAzureClient != nil {
+		blobClient = meta.mockAzureClient
+	}
+	return blobClient, nil
+}
+
 func (util *snowflakeAzureClient) extractContainerNameAndPath(location string) (*azureLocation, error) {
 	stageLocation, err := expandUser(location)
 	if err != nil {
@@ -9,11 +9,15 @@ import (
 	"net/http"
 	"os"
 	"path"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
 	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob"
 	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/blob"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/bloberror"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/blockblob"
 )
 
 func TestExtractContainerNameAndPath(t *testing.T) {
@@ -250,6 +254,45 @@ func TestUploadStreamWithAzureUploadFailedError(t *testing.T) {
 	}
 }
 
+func TestAzureUploadStreamCommitsDigest(t *testing.T) {
+	info := execResponseStageInfo{
+		Location:     "azblob/storage/users/456/",
+		LocationType: "AZURE",
+	}
+	azureCli, err := new(snowflakeAzureClient).createClient(&info, false)
+	assertNilF(t, err)
+	data := bytes.Repeat([]byte("some streamed data "), 100)
+	blockClient := &azureBlockAPIMock{stagedBlocks: make(map[string][]byte), failures: 2}
+	uploadMeta := fileMetadata{
+		name:              "data1.txt",
+		stageLocationType: "AZURE",
+		client:            azureCli,
+		stageInfo:         &info,
+		dstFileName:       "data1.txt",
+		overwrite:         true,
+		noSleepingTime:    true,
+		options: &SnowflakeFileTransferOptions{
+			MultiPartThreshold: dataSizeThreshold,
+		},
+		mockAzureClient: blockClient,
+	}
+	stream, err := newUploadStream(&uploadMeta, bytes.NewReader(data), azureClient)
+	assertNilF(t, err)
+	defer stream.Close()
+
+	err = (&snowflakeAzureClient{cfg: &Config{}}).uploadStream(&uploadMeta, stream, 4, 300)
+	assertNilF(t, err)
+	assertEqualE(t, uploadMeta.resStatus, uploaded)
+	assertEqualF(t, len(blockClient.committed), 7)
+	var committed []byte
+	for _, blockID := range blockClient.committed {
+		committed = append(committed, blockClient.stagedBlocks[blockID]...)
+	}
+	assertBytesEqualE(t, committed, data, "failed blocks should be staged again")
+	assertNotEqualE(t, uploadMeta.sha256Digest, "")
+	assertEqualE(t, *blockClient.metadata["sfcdigest"], uploadMeta.sha256Digest)
+}
+
 func TestUploadFileWithAzureUploadTokenExpired(t *testing.T) {
 	info := execResponseStageInfo{
 		Location:     "azblob/storage/users/456/",
@@ -628,3 +671,109 @@ func TestAzureGetHeaderClientCastFail(t *testing.T) {
 		t.Fatal("should have failed")
 	}
 }
+
+type azureBlockAPIMock struct {
+	azureObjectAPIMock
+	mu           sync.Mutex
+	stagedBlocks map[string][]byte
+	failBlocks   int
+	failures     int
+	committed    []string
+	metadata     map[string]*string
+	exists       bool
+	deleted      bool
+}
+
+func (c *azureBlockAPIMock) StageBlock(_ context.Context, base64BlockID string, body io.ReadSeekCloser, _ *blockblob.StageBlockOptions) (blockblob.StageBlockResponse, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.failures > 0 {
+		c.failures--
+		return blockblob.StageBlockResponse{}, &azcore.ResponseError{
+			ErrorCode:  "ServerBusy",
+			StatusCode: http.StatusServiceUnavailable,
+		}
+	}
+	if c.failBlocks > 0 && len(c.stagedBlocks) >= c.failBlocks {
+		return blockblob.StageBlockResponse{}, &azcore.ResponseError{
+			ErrorCode:  "ServerBusy",
+			StatusCode: http.StatusServiceUnavailable,
+		}
+	}
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return blockblob.StageBlockResponse{}, err
+	}
+	c.stagedBlocks[base64BlockID] = data
+	return blockblob.StageBlockResponse{}, nil
+}
+
+func (c *azureBlockAPIMock) CommitBlockList(_ context.Context, base64BlockIDs []string, o *blockblob.CommitBlockListOptions) (blockblob.CommitBlockListResponse, error) {
+	if c.exists && o.AccessConditions != nil {
+		return blockblob.CommitBlockListResponse{}, &azcore.ResponseError{
+			ErrorCode:  string(bloberror.BlobAlreadyExists),
+			StatusCode: http.StatusConflict,
+		}
+	}
+	c.committed = base64BlockIDs
+	c.metadata = o.Metadata
+	return blockblob.CommitBlockListResponse{}, nil
+}
+
+func (c *azureBlockAPIMock) Delete(_ context.Context, _ *blob.DeleteOptions) (blob.DeleteResponse, error) {
+	c.deleted = true
+	return blob.DeleteResponse{}, nil
+}
+
+func TestAzureResumableUpload(t *testing.T) {
+	info := execResponseStageInfo{
+		Location:     "azblob/storage/users/456/",
+		LocationType: "AZURE",
+	}
+	azureCli, err := new(snowflakeAzureClient).createClient(&info, false)
+	assertNilF(t, err)
+	tmpDir := t.TempDir()
+	cfg := &Config{TmpDirPath: tmpDir, UploadJournalRetention: time.Hour}
+	data := bytes.Repeat([]byte("0123456789"), 1000)
+	dataFile := path.Join(tmpDir, "data1.txt")
+	assertNilF(t, os.WriteFile(dataFile, data, readWriteFileMode))
+
+	blockClient := &azureBlockAPIMock{stagedBlocks: make(map[string][]byte), failBlocks: 2}
+	uploadMeta := fileMetadata{
+		name:              "data1.txt",
+		stageLocationType: "AZURE",
+		client:            azureCli,
+		stageInfo:         &info,
+		dstFileName:       "data1.txt",
+		sha256Digest:      "digest",
+		uploadSize:        int64(len(data)),
+		overwrite:         true,
+		options: &SnowflakeFileTransferOptions{
+			MultiPartThreshold: 3000,
+		},
+		mockAzureClient: blockClient,
+	}
+	azureUtil := &snowflakeAzureClient{cfg: cfg}
+	uploadMeta.uploadJournal = openUploadJournal(cfg, &uploadMeta)
+	assertNotNilF(t, uploadMeta.uploadJournal)
+	err = azureUtil.uploadFile(dataFile, &uploadMeta, 1, 3000)
+	assertNotNilF(t, err)
+	assertEqualE(t, uploadMeta.resStatus, needRetry)
+	assertEqualE(t, len(blockClient.stagedBlocks), 2)
+
+	uploadMeta.uploadJournal = openUploadJournal(cfg, &uploadMeta)
+	assertEqualE(t, len(uploadMeta.uploadJournal.Parts), 2)
+	blockClient.failBlocks = 0
+	err = azureUtil.uploadFile(dataFile, &uploadMeta, 4, 3000)
+	assertNilF(t, err)
+	assertEqualE(t, uploadMeta.resStatus, uploaded)
+	assertEqualF(t, len(blockClient.committed), 4)
+	var committed []byte
+	for _, blockID := range blockClient.committed {
+		committed = append(committed, blockClient.stagedBlocks[blockID]...)
+	}
+	assertBytesEqualE(t, committed, data)
+	assertEqualE(t, *blockClient.metadata["sfcdigest"], "digest")
+	_, err = os.Stat(uploadMeta.uploadJournal.path)
+	assertTrueE(t, os.IsNotExist(err), "journal should be removed after the upload")
+}
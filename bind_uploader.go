@@ -134,7 +134,7 @@ func (bu *bindUploader) buildRowsAsBytes(columns []driver.NamedValue) ([][]byte,
 		}).exceptionTelemetry(bu.sc)
 	}
 
-	_, column, err := snowflakeArrayToString(&columns[0], true)
+	_, column, err := bu.arrayToString(&columns[0])
 	if err != nil {
 		return nil, err
 	}
@@ -153,7 +153,7 @@ func (bu *bindUploader) buildRowsAsBytes(columns []driver.NamedValue) ([][]byte,
 		}
 	}
 	for colIdx := 1; colIdx < numColumns; colIdx++ {
-		_, column, err = snowflakeArrayToString(&columns[colIdx], true)
+		_, column, err = bu.arrayToString(&columns[colIdx])
 		if err != nil {
 			return nil, err
 		}
@@ -180,6 +180,16 @@ func (bu *bindUploader) buildRowsAsBytes(columns []driver.NamedValue) ([][]byte,
 	return csvRows, nil
 }
 
+// arrayToString converts the array binding column to the string values
+// written into the uploaded CSV stream.
+func (bu *bindUploader) arrayToString(nv *driver.NamedValue) (snowflakeType, []*string, error) {
+	if sab, ok := nv.Value.(*structuredArrayBinding); ok {
+		t, arr, _, err := structuredArrayBindingToString(sab, bu.sc.cfg.Params)
+		return t, arr, err
+	}
+	return snowflakeArrayToString(nv, true)
+}
+
 func (bu *bindUploader) createCSVRecord(data []interface{}) []byte {
 	var b strings.Builder
 	b.Grow(1024)
@@ -191,7 +201,7 @@ func (bu *bindUploader) createCSVRecord(data []interface{}) []byte {
 		if ok {
 			b.WriteString(escapeForCSV(value))
 		} else if !reflect.ValueOf(data[i]).IsNil() {
-			logger.WithContext(bu.ctx).Debugf("Cannot convert value to string in createCSVRecord. value: %v", data[i])
+			bu.sc.getLogger().WithContext(bu.ctx).Debugf("Cannot convert value of type %T to string in createCSVRecord", data[i])
 		}
 	}
 	b.WriteString("\n")
@@ -204,6 +214,12 @@ func (sc *snowflakeConn) processBindings(
 	describeOnly bool,
 	requestID UUID,
 	req *execRequest) error {
+	if err := checkArrowBindings(bindings); err != nil {
+		return err
+	}
+	if isArrowBind(bindings) {
+		return sc.processArrowBindings(ctx, bindings[0], describeOnly, requestID, req)
+	}
 	arrayBindThreshold := sc.getArrayBindStageThreshold()
 	numBinds, err := arrayBindValueCount(bindings)
 	if err != nil {
@@ -250,7 +266,15 @@ func getBindValues(bindings []driver.NamedValue, params map[string]*string) (map
 		} else {
 			var val interface{}
 			var bv bindingValue
-			if t == sliceType {
+			if sab, ok := binding.Value.(*structuredArrayBinding); ok {
+				// retrieve structured array binding data
+				var schema *bindingSchema
+				t, val, schema, err = structuredArrayBindingToString(sab, params)
+				if err != nil {
+					return nil, err
+				}
+				bv = bindingValue{format: jsonFormatStr, schema: schema}
+			} else if t == sliceType {
 				// retrieve array binding data
 				t, val, err = snowflakeArrayToString(&binding, false)
 				if err != nil {
@@ -293,6 +317,9 @@ func arrayBindValueCount(bindValues []driver.NamedValue) (int, error) {
 	if !isArrayBind(bindValues) {
 		return 0, nil
 	}
+	if sab, ok := bindValues[0].Value.(*structuredArrayBinding); ok {
+		return len(bindValues) * sab.len(), nil
+	}
 	_, arr, err := snowflakeArrayToString(&bindValues[0], false)
 	if err != nil {
 		return 0, err
@@ -321,7 +348,7 @@ func supportedArrayBind(nv *driver.NamedValue) bool {
 		reflect.TypeOf(&stringArray{}), reflect.TypeOf(&byteArray{}),
 		reflect.TypeOf(&timestampNtzArray{}), reflect.TypeOf(&timestampLtzArray{}),
 		reflect.TypeOf(&timestampTzArray{}), reflect.TypeOf(&dateArray{}),
-		reflect.TypeOf(&timeArray{}):
+		reflect.TypeOf(&timeArray{}), reflect.TypeOf(&structuredArrayBinding{}):
 		return true
 	case reflect.TypeOf([]uint8{}):
 		// internal binding ts mode
@@ -337,8 +364,6 @@ func supportedArrayBind(nv *driver.NamedValue) bool {
 		}
 		return false
 	default:
-		// TODO SNOW-176486 variant, object, array
-
 		// Support for bulk array binding insertion using []interface{}
 		if isInterfaceArrayBinding(nv.Value) {
 			return true
@@ -19,6 +19,7 @@ import (
 	"github.com/apache/arrow-go/v18/arrow"
 	"github.com/apache/arrow-go/v18/arrow/ipc"
 	"github.com/apache/arrow-go/v18/arrow/memory"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type chunkDownloader interface {
@@ -67,6 +68,10 @@ type snowflakeChunkDownloader struct {
 	FuncGet            func(context.Context, *snowflakeConn, string, map[string]string, time.Duration) (*http.Response, error)
 }
 
+func (scd *snowflakeChunkDownloader) getLogger() SFLogger {
+	return scd.sc.getModuleLogger(logModuleChunkDownloader)
+}
+
 func (scd *snowflakeChunkDownloader) totalUncompressedSize() (acc int64) {
 	for _, c := range scd.ChunkMetas {
 		acc += c.UncompressedSize
@@ -123,8 +128,8 @@ func (scd *snowflakeChunkDownloader) start() error {
 	// start downloading chunks if exists
 	chunkMetaLen := len(scd.ChunkMetas)
 	if chunkMetaLen > 0 {
-		logger.WithContext(scd.ctx).Debugf("MaxChunkDownloadWorkers: %v", MaxChunkDownloadWorkers)
-		logger.WithContext(scd.ctx).Debugf("chunks: %v, total bytes: %d", chunkMetaLen, scd.totalUncompressedSize())
+		scd.getLogger().WithContext(scd.ctx).Debugf("MaxChunkDownloadWorkers: %v", MaxChunkDownloadWorkers)
+		scd.getLogger().WithContext(scd.ctx).Debugf("chunks: %v, total bytes: %d", chunkMetaLen, scd.totalUncompressedSize())
 		scd.ChunksMutex = &sync.Mutex{}
 		scd.DoneDownloadCond = sync.NewCond(scd.ChunksMutex)
 		scd.Chunks = make(map[int][]chunkRowType)
@@ -132,7 +137,7 @@ func (scd *snowflakeChunkDownloader) start() error {
 		scd.ChunksError = make(chan *chunkError, MaxChunkDownloadWorkers)
 		for i := 0; i < chunkMetaLen; i++ {
 			chunk := scd.ChunkMetas[i]
-			logger.WithContext(scd.ctx).Debugf("add chunk to channel ChunksChan: %v, URL: %v, RowCount: %v, UncompressedSize: %v, ChunkResultFormat: %v",
+			scd.getLogger().WithContext(scd.ctx).Debugf("add chunk to channel ChunksChan: %v, URL: %v, RowCount: %v, UncompressedSize: %v, ChunkResultFormat: %v",
 				i+1, chunk.URL, chunk.RowCount, chunk.UncompressedSize, scd.QueryResultFormat)
 			scd.ChunksChan <- i
 		}
@@ -146,7 +151,7 @@ func (scd *snowflakeChunkDownloader) start() error {
 func (scd *snowflakeChunkDownloader) schedule() {
 	select {
 	case nextIdx := <-scd.ChunksChan:
-		logger.WithContext(scd.ctx).Infof("schedule chunk: %v", nextIdx+1)
+		scd.getLogger().WithContext(scd.ctx).Infof("schedule chunk: %v", nextIdx+1)
 		go GoroutineWrapper(
 			scd.ctx,
 			func() {
@@ -155,7 +160,7 @@ func (scd *snowflakeChunkDownloader) schedule() {
 		)
 	default:
 		// no more download
-		logger.WithContext(scd.ctx).Info("no more download")
+		scd.getLogger().WithContext(scd.ctx).Info("no more download")
 	}
 }
 
@@ -173,15 +178,15 @@ func (scd *snowflakeChunkDownloader) checkErrorRetry() (err error) {
 				},
 			)
 			scd.ChunksErrorCounter++
-			logger.WithContext(scd.ctx).Warningf("chunk idx: %v, err: %v. retrying (%v/%v)...",
+			scd.getLogger().WithContext(scd.ctx).Warningf("chunk idx: %v, err: %v. retrying (%v/%v)...",
 				errc.Index, errc.Error, scd.ChunksErrorCounter, maxChunkDownloaderErrorCounter)
 		} else {
 			scd.ChunksFinalErrors = append(scd.ChunksFinalErrors, errc)
-			logger.WithContext(scd.ctx).Warningf("chunk idx: %v, err: %v. no further retry", errc.Index, errc.Error)
+			scd.getLogger().WithContext(scd.ctx).Warningf("chunk idx: %v, err: %v. no further retry", errc.Index, errc.Error)
 			return errc.Error
 		}
 	default:
-		logger.WithContext(scd.ctx).Info("no error is detected.")
+		scd.getLogger().WithContext(scd.ctx).Info("no error is detected.")
 	}
 	return nil
 }
@@ -204,7 +209,7 @@ func (scd *snowflakeChunkDownloader) next() (chunkRowType, error) {
 		}
 
 		for scd.Chunks[scd.CurrentChunkIndex] == nil {
-			logger.WithContext(scd.ctx).Debugf("waiting for chunk idx: %v/%v",
+			scd.getLogger().WithContext(scd.ctx).Debugf("waiting for chunk idx: %v/%v",
 				scd.CurrentChunkIndex+1, len(scd.ChunkMetas))
 
 			if err := scd.checkErrorRetry(); err != nil {
@@ -216,7 +221,7 @@ func (scd *snowflakeChunkDownloader) next() (chunkRowType, error) {
 			// 1) one chunk download finishes or 2) an error occurs.
 			scd.DoneDownloadCond.Wait()
 		}
-		logger.WithContext(scd.ctx).Debugf("ready: chunk %v", scd.CurrentChunkIndex+1)
+		scd.getLogger().WithContext(scd.ctx).Debugf("ready: chunk %v", scd.CurrentChunkIndex+1)
 		scd.CurrentChunk = scd.Chunks[scd.CurrentChunkIndex]
 		scd.ChunksMutex.Unlock()
 		scd.CurrentChunkSize = len(scd.CurrentChunk)
@@ -225,7 +230,7 @@ func (scd *snowflakeChunkDownloader) next() (chunkRowType, error) {
 		scd.schedule()
 	}
 
-	logger.WithContext(scd.ctx).Debugf("no more data")
+	scd.getLogger().WithContext(scd.ctx).Debugf("no more data")
 	if len(scd.ChunkMetas) > 0 {
 		close(scd.ChunksError)
 		close(scd.ChunksChan)
@@ -360,11 +365,11 @@ func (r *largeResultSetReader) Read(p []byte) (n int, err error) {
 }
 
 func downloadChunk(ctx context.Context, scd *snowflakeChunkDownloader, idx int) {
-	logger.WithContext(ctx).Infof("download start chunk: %v", idx+1)
+	scd.getLogger().WithContext(ctx).Infof("download start chunk: %v", idx+1)
 	defer scd.DoneDownloadCond.Broadcast()
 
 	if err := scd.FuncDownloadHelper(ctx, scd, idx); err != nil {
-		logger.WithContext(ctx).Errorf(
+		scd.getLogger().WithContext(ctx).Errorf(
 			"failed to extract HTTP response body. URL: %v, err: %v", scd.ChunkMetas[idx].URL, err)
 		scd.ChunksError <- &chunkError{Index: idx, Error: err}
 	} else if scd.ctx.Err() == context.Canceled || scd.ctx.Err() == context.DeadlineExceeded {
@@ -372,12 +377,25 @@ func downloadChunk(ctx context.Context, scd *snowflakeChunkDownloader, idx int)
 	}
 }
 
-func downloadChunkHelper(ctx context.Context, scd *snowflakeChunkDownloader, idx int) error {
+func downloadChunkHelper(ctx context.Context, scd *snowflakeChunkDownloader, idx int) (err error) {
+	ctx, span := startSpan(ctx, scd.sc.cfg, spanChunk, trace.SpanKindClient, attrChunkIndex.Int(idx),
+		attrChunkRows.Int(scd.ChunkMetas[idx].RowCount))
+	var body *countingReader
+	start := time.Now()
+	defer func() {
+		var n int64
+		if body != nil {
+			n = body.n
+			span.SetAttributes(attrBytes.Int64(n))
+		}
+		getMetricsRecorder(scd.sc.cfg).RecordChunkDownload(ctx, n, time.Since(start), metricsStatus(err))
+		endSpan(span, err)
+	}()
 	headers := make(map[string]string)
 	if len(scd.ChunkHeader) > 0 {
-		logger.WithContext(ctx).Debug("chunk header is provided.")
+		scd.getLogger().WithContext(ctx).Debug("chunk header is provided.")
 		for k, v := range scd.ChunkHeader {
-			logger.WithContext(ctx).Debugf("adding header: %v, value: %v", k, v)
+			scd.getLogger().WithContext(ctx).Debugf("adding header: %v, value: %v", k, v)
 
 			headers[k] = v
 		}
@@ -390,16 +408,17 @@ func downloadChunkHelper(ctx context.Context, scd *snowflakeChunkDownloader, idx
 	if err != nil {
 		return err
 	}
-	bufStream := bufio.NewReader(resp.Body)
+	body = &countingReader{r: resp.Body}
+	bufStream := bufio.NewReader(body)
 	defer resp.Body.Close()
-	logger.WithContext(ctx).Debugf("response returned chunk: %v for URL: %v", idx+1, scd.ChunkMetas[idx].URL)
+	scd.getLogger().WithContext(ctx).Debugf("response returned chunk: %v for URL: %v", idx+1, scd.ChunkMetas[idx].URL)
 	if resp.StatusCode != http.StatusOK {
 		b, err := io.ReadAll(bufStream)
 		if err != nil {
 			return err
 		}
-		logger.WithContext(ctx).Infof("HTTP: %v, URL: %v, Body: %v", resp.StatusCode, scd.ChunkMetas[idx].URL, b)
-		logger.WithContext(ctx).Infof("Header: %v", resp.Header)
+		scd.getLogger().WithContext(ctx).Infof("HTTP: %v, URL: %v, Body: %v", resp.StatusCode, scd.ChunkMetas[idx].URL, b)
+		scd.getLogger().WithContext(ctx).Infof("Header: %v", resp.Header)
 		return &SnowflakeError{
 			Number:      ErrFailedToGetChunk,
 			SQLState:    SQLStateConnectionFailure,
@@ -483,7 +502,7 @@ func decodeChunk(ctx context.Context, scd *snowflakeChunkDownloader, idx int, bu
 			return err
 		}
 	}
-	logger.WithContext(scd.ctx).Debugf(
+	scd.getLogger().WithContext(scd.ctx).Debugf(
 		"decoded %d rows w/ %d bytes in %s (chunk %v)",
 		scd.ChunkMetas[idx].RowCount,
 		scd.ChunkMetas[idx].UncompressedSize,
@@ -504,6 +523,7 @@ func populateJSONRowSet(dst []chunkRowType, src [][]*string) {
 }
 
 type streamChunkDownloader struct {
+	sc             *snowflakeConn
 	ctx            context.Context
 	id             int64
 	fetcher        streamChunkFetcher
@@ -515,6 +535,10 @@ type streamChunkDownloader struct {
 	RowSet         rowSetType
 }
 
+func (scd *streamChunkDownloader) getLogger() SFLogger {
+	return scd.sc.getModuleLogger(logModuleChunkDownloader)
+}
+
 func (scd *streamChunkDownloader) totalUncompressedSize() (acc int64) {
 	return -1
 }
@@ -533,16 +557,16 @@ func (scd *streamChunkDownloader) start() error {
 		func() {
 			readErr := io.EOF
 
-			logger.WithContext(scd.ctx).Infof(
+			scd.getLogger().WithContext(scd.ctx).Infof(
 				"start downloading. downloader id: %v, %v/%v rows, %v chunks",
 				scd.id, len(scd.RowSet.RowType), scd.Total, len(scd.ChunkMetas))
 			t := time.Now()
 
 			defer func() {
 				if readErr == io.EOF {
-					logger.WithContext(scd.ctx).Infof("downloading done. downloader id: %v", scd.id)
+					scd.getLogger().WithContext(scd.ctx).Infof("downloading done. downloader id: %v", scd.id)
 				} else {
-					logger.WithContext(scd.ctx).Debugf("downloading error. downloader id: %v", scd.id)
+					scd.getLogger().WithContext(scd.ctx).Debugf("downloading error. downloader id: %v", scd.id)
 				}
 				scd.readErr = readErr
 				close(scd.rowStream)
@@ -556,7 +580,7 @@ func (scd *streamChunkDownloader) start() error {
 				}
 			}()
 
-			logger.WithContext(scd.ctx).Infof("sending initial set of rows in %vms", time.Since(t).Microseconds())
+			scd.getLogger().WithContext(scd.ctx).Infof("sending initial set of rows in %vms", time.Since(t).Microseconds())
 			t = time.Now()
 			for _, row := range scd.RowSet.JSON {
 				scd.rowStream <- row
@@ -567,15 +591,15 @@ func (scd *streamChunkDownloader) start() error {
 			// parsed row to the row stream. When an error occurs, the fetcher will
 			// stop writing to the row stream so we can stop processing immediately
 			for i, chunk := range scd.ChunkMetas {
-				logger.WithContext(scd.ctx).Infof("starting chunk fetch %d (%d rows)", i, chunk.RowCount)
+				scd.getLogger().WithContext(scd.ctx).Infof("starting chunk fetch %d (%d rows)", i, chunk.RowCount)
 				if err := scd.fetcher.fetch(chunk.URL, scd.rowStream); err != nil {
-					logger.WithContext(scd.ctx).Debugf(
+					scd.getLogger().WithContext(scd.ctx).Debugf(
 						"failed chunk fetch %d: %#v, downloader id: %v, %v/%v rows, %v chunks",
 						i, err, scd.id, len(scd.RowSet.RowType), scd.Total, len(scd.ChunkMetas))
 					readErr = fmt.Errorf("chunk fetch: %w", err)
 					break
 				}
-				logger.WithContext(scd.ctx).Infof("fetched chunk %d (%d rows) in %vms", i, chunk.RowCount, time.Since(t).Microseconds())
+				scd.getLogger().WithContext(scd.ctx).Infof("fetched chunk %d (%d rows) in %vms", i, chunk.RowCount, time.Since(t).Microseconds())
 				t = time.Now()
 			}
 		},
@@ -639,6 +663,7 @@ type httpStreamChunkFetcher struct {
 
 func newStreamChunkDownloader(
 	ctx context.Context,
+	sc *snowflakeConn,
 	fetcher streamChunkFetcher,
 	total int64,
 	rowType []execResponseRowType,
@@ -646,6 +671,7 @@ func newStreamChunkDownloader(
 	chunks []execResponseChunk,
 ) *streamChunkDownloader {
 	return &streamChunkDownloader{
+		sc:         sc,
 		ctx:        ctx,
 		id:         rand.Int63(),
 		fetcher:    fetcher,
@@ -191,6 +191,7 @@ func TestStreamChunkDownloaderFirstRows(t *testing.T) {
 	firstRows := generateStreamChunkRows(10, 4)
 	downloader := newStreamChunkDownloader(
 		context.Background(),
+		nil,
 		fetcher,
 		int64(len(firstRows)),
 		[]execResponseRowType{},
@@ -233,6 +234,7 @@ func TestStreamChunkDownloaderChunks(t *testing.T) {
 	firstRows := generateStreamChunkRows(2, 4)
 	downloader := newStreamChunkDownloader(
 		context.Background(),
+		nil,
 		fetcher,
 		int64(len(firstRows)),
 		[]execResponseRowType{},
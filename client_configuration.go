@@ -7,6 +7,7 @@ import (
 	"os"
 	"path"
 	"path/filepath"
+	"regexp"
 	"runtime"
 	"strings"
 )
@@ -105,10 +106,27 @@ type ClientConfig struct {
 	Common *ClientConfigCommonProps `json:"common"`
 }
 
+// log formats for easy logging
+const (
+	logFormatText string = "text" // logfmt-like text format, the default
+	logFormatJSON string = "json" // one JSON object per line
+)
+
 // ClientConfigCommonProps properties from "common" section
 type ClientConfigCommonProps struct {
-	LogLevel string `json:"log_level,omitempty"`
-	LogPath  string `json:"log_path,omitempty"`
+	LogLevel      string                    `json:"log_level,omitempty"`
+	LogPath       string                    `json:"log_path,omitempty"`
+	LogMaxSizeMB  int                       `json:"log_max_size_mb,omitempty"` // size after which the log file is rotated, 0 disables rotation
+	LogMaxBackups int                       `json:"log_max_backups,omitempty"` // number of rotated log files kept, 1 by default
+	LogFormat     string                    `json:"log_format,omitempty"`      // "text" (default) or "json"
+	LogLevels     map[string]string         `json:"log_levels,omitempty"`      // log levels of modules: retry, ocsp or chunk_downloader
+	MaskPatterns  []ClientConfigMaskPattern `json:"mask_patterns,omitempty"`   // secrets masked in the logs in addition to the builtin ones
+}
+
+// ClientConfigMaskPattern a regular expression masking secrets in the logs, see RegexpMaskingRule
+type ClientConfigMaskPattern struct {
+	Pattern     string `json:"pattern"`
+	Replacement string `json:"replacement,omitempty"` // "****" by default
 }
 
 func parseClientConfiguration(filePath string) (*ClientConfig, error) {
@@ -155,8 +173,9 @@ func getUnknownValues(fileContents []byte) map[string]interface{} {
 	for k, v := range commonValues {
 		lowercaseCommonValues[strings.ToLower(k)] = v
 	}
-	delete(lowercaseCommonValues, "log_level")
-	delete(lowercaseCommonValues, "log_path")
+	for _, key := range []string{"log_level", "log_path", "log_max_size_mb", "log_max_backups", "log_format", "log_levels", "mask_patterns"} {
+		delete(lowercaseCommonValues, key)
+	}
 	return lowercaseCommonValues
 }
 
@@ -171,7 +190,10 @@ func validateClientConfiguration(clientConfig *ClientConfig) error {
 	if clientConfig.Common == nil {
 		return errors.New("common section in client config not found")
 	}
-	return validateLogLevel(*clientConfig)
+	if err := validateLogLevel(*clientConfig); err != nil {
+		return err
+	}
+	return validateLogOptions(*clientConfig)
 }
 
 func validateLogLevel(clientConfig ClientConfig) error {
@@ -185,6 +207,30 @@ func validateLogLevel(clientConfig ClientConfig) error {
 	return nil
 }
 
+func validateLogOptions(clientConfig ClientConfig) error {
+	common := clientConfig.Common
+	if common.LogMaxSizeMB < 0 {
+		return fmt.Errorf("log_max_size_mb must not be negative: %d", common.LogMaxSizeMB)
+	}
+	if common.LogMaxBackups < 0 {
+		return fmt.Errorf("log_max_backups must not be negative: %d", common.LogMaxBackups)
+	}
+	if _, err := toLogFormat(common.LogFormat); err != nil {
+		return err
+	}
+	for module, logLevel := range common.LogLevels {
+		if _, err := toLogLevel(logLevel); err != nil {
+			return fmt.Errorf("invalid log level of module %s: %w", module, err)
+		}
+	}
+	for _, maskPattern := range common.MaskPatterns {
+		if _, err := regexp.Compile(maskPattern.Pattern); err != nil {
+			return fmt.Errorf("invalid mask pattern %s: %w", maskPattern.Pattern, err)
+		}
+	}
+	return nil
+}
+
 func validateCfgPerm(filePath string) error {
 	if runtime.GOOS == "windows" {
 		return nil
@@ -210,3 +256,14 @@ func toLogLevel(logLevelString string) (string, error) {
 		return "", errors.New("unknown log level: " + logLevelString)
 	}
 }
+
+func toLogFormat(logFormatString string) (string, error) {
+	switch logFormat := strings.ToLower(logFormatString); logFormat {
+	case "":
+		return logFormatText, nil
+	case logFormatText, logFormatJSON:
+		return logFormat, nil
+	default:
+		return "", errors.New("unknown log format: " + logFormatString)
+	}
+}
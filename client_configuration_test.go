@@ -150,6 +150,30 @@ func TestParseConfiguration(t *testing.T) {
 	}
 }
 
+func TestParseConfigurationWithLogOptions(t *testing.T) {
+	fileContents := `{
+		"common": {
+			"log_level": "INFO",
+			"log_path": "/some-path/some-directory",
+			"log_max_size_mb": 10,
+			"log_max_backups": 3,
+			"log_format": "JSON",
+			"log_levels": {"retry": "debug", "ocsp": "OFF"},
+			"mask_patterns": [{"pattern": "employee-[0-9]+", "replacement": "employee-x"}]
+		}
+	}`
+	fileName := createFile(t, "config.json", fileContents, t.TempDir())
+
+	config, err := parseClientConfiguration(fileName)
+
+	assertNilF(t, err, "parse client configuration error")
+	assertEqualE(t, config.Common.LogMaxSizeMB, 10)
+	assertEqualE(t, config.Common.LogMaxBackups, 3)
+	assertEqualE(t, config.Common.LogFormat, "JSON")
+	assertDeepEqualE(t, config.Common.LogLevels, map[string]string{"retry": "debug", "ocsp": "OFF"})
+	assertDeepEqualE(t, config.Common.MaskPatterns, []ClientConfigMaskPattern{{Pattern: "employee-[0-9]+", Replacement: "employee-x"}})
+}
+
 func TestParseAllLogLevels(t *testing.T) {
 	dir := t.TempDir()
 	for _, logLevel := range []string{"OFF", "ERROR", "WARN", "INFO", "DEBUG", "TRACE"} {
@@ -206,6 +230,30 @@ func TestParseConfigurationFails(t *testing.T) {
 			}`,
 			expectedErrorMessageToContain: "ClientConfigCommonProps.common.log_path",
 		},
+		{
+			testName:                      "TestWithWrongLogFormat",
+			fileName:                      "config_5.json",
+			FileContents:                  `{"common": {"log_format": "xml"}}`,
+			expectedErrorMessageToContain: "unknown log format: xml",
+		},
+		{
+			testName:                      "TestWithWrongModuleLogLevel",
+			fileName:                      "config_6.json",
+			FileContents:                  `{"common": {"log_levels": {"retry": "verbose"}}}`,
+			expectedErrorMessageToContain: "invalid log level of module retry",
+		},
+		{
+			testName:                      "TestWithWrongMaskPattern",
+			fileName:                      "config_7.json",
+			FileContents:                  `{"common": {"mask_patterns": [{"pattern": "("}]}}`,
+			expectedErrorMessageToContain: "invalid mask pattern",
+		},
+		{
+			testName:                      "TestWithNegativeLogMaxSize",
+			fileName:                      "config_8.json",
+			FileContents:                  `{"common": {"log_max_size_mb": -1}}`,
+			expectedErrorMessageToContain: "log_max_size_mb must not be negative",
+		},
 		{
 			testName:                      "TestWithoutCommon",
 			fileName:                      "config_4.json",
@@ -276,7 +324,12 @@ func TestUnknownValues(t *testing.T) {
 			inputString: `{
 				"common": {
 					"log_level": "level",
-					"log_path": "path"
+					"log_path": "path",
+					"log_max_size_mb": 10,
+					"log_max_backups": 3,
+					"log_format": "json",
+					"log_levels": {},
+					"mask_patterns": []
 				}
 			}`,
 			expectedOutput: map[string]string{},
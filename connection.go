@@ -75,6 +75,11 @@ type snowflakeConn struct {
 	internal            InternalClient
 	queryContextCache   *queryContextCache
 	currentTimeProvider currentTimeProvider
+
+	transferBudgetOnce sync.Once
+	transferBudget     *transferBudget
+
+	queryHistory *queryHistory
 }
 
 var (
@@ -82,6 +87,22 @@ var (
 	queryIDRegexp  = regexp.MustCompile(queryIDPattern)
 )
 
+// getLogger returns the logger of the connection or the global one.
+func (sc *snowflakeConn) getLogger() SFLogger {
+	if sc == nil {
+		return logger
+	}
+	return getConnectionLogger(sc.cfg)
+}
+
+// getModuleLogger returns the logger of a module of the driver used by the connection.
+func (sc *snowflakeConn) getModuleLogger(module string) SFLogger {
+	if sc == nil {
+		return getModuleLogger(nil, module)
+	}
+	return getModuleLogger(sc.cfg, module)
+}
+
 func (sc *snowflakeConn) exec(
 	ctx context.Context,
 	query string,
@@ -89,13 +110,12 @@ func (sc *snowflakeConn) exec(
 	isInternal bool,
 	describeOnly bool,
 	bindings []driver.NamedValue) (
-	*execResponse, error) {
-	var err error
+	data *execResponse, err error) {
 	counter := atomic.AddUint64(&sc.SequenceCounter, 1) // query sequence counter
 
 	queryContext, err := buildQueryContext(sc.queryContextCache)
 	if err != nil {
-		logger.WithContext(ctx).Errorf("error while building query context: %v", err)
+		sc.getLogger().WithContext(ctx).Errorf("error while building query context: %v", err)
 	}
 	req := execRequest{
 		SQLText:      query,
@@ -112,16 +132,28 @@ func (sc *snowflakeConn) exec(
 	if tag := ctx.Value(queryTag); tag != nil {
 		req.Parameters[string(queryTag)] = tag
 	}
-	logger.WithContext(ctx).Infof("parameters: %v", req.Parameters)
+	sc.getLogger().WithContext(ctx).Infof("parameters: %v", req.Parameters)
 
 	// handle bindings, if required
 	requestID := getOrGenerateRequestIDFromContext(ctx)
+	if !isInternal && !describeOnly && (sc.queryHistoryEnabled() || sc.cfg.EventHooks != nil) {
+		var retries *int32
+		ctx, retries = withQueryRetryCounter(ctx)
+		startTime := time.Now()
+		eventHooks := getEventHooks(sc.cfg)
+		eventHooks.OnQueryStart(ctx, QueryStartEvent{SQLText: maskSecrets(query), RequestID: requestID.String(), StartTime: startTime})
+		defer func() {
+			entry := newQueryHistoryEntry(query, requestID, startTime, retries, data, err)
+			sc.recordQueryHistory(entry)
+			eventHooks.OnQueryFinish(ctx, newQueryFinishEvent(entry, data, err))
+		}()
+	}
 	if len(bindings) > 0 {
 		if err = sc.processBindings(ctx, bindings, describeOnly, requestID, &req); err != nil {
 			return nil, err
 		}
 	}
-	logger.WithContext(ctx).Infof("bindings: %v", req.Bindings)
+	sc.getLogger().WithContext(ctx).Debugf("bindings: %v", bindParametersForLog(req.Bindings, sc.cfg.LogBindValues))
 
 	// populate headers
 	headers := getHeaders()
@@ -139,7 +171,7 @@ func (sc *snowflakeConn) exec(
 		return nil, err
 	}
 
-	data, err := sc.rest.FuncPostQuery(ctx, sc.rest, &url.Values{}, headers,
+	data, err = sc.rest.FuncPostQuery(ctx, sc.rest, &url.Values{}, headers,
 		jsonBody, sc.rest.RequestTimeout, requestID, sc.cfg)
 	if err != nil {
 		return data, err
@@ -151,7 +183,7 @@ func (sc *snowflakeConn) exec(
 			return data, err
 		}
 	}
-	logger.WithContext(ctx).Infof("Success: %v, Code: %v", data.Success, code)
+	sc.getLogger().WithContext(ctx).Infof("Success: %v, Code: %v", data.Success, code)
 	if !data.Success {
 		err = (populateErrorFields(code, data)).exceptionTelemetry(sc)
 		return nil, err
@@ -160,7 +192,7 @@ func (sc *snowflakeConn) exec(
 	if !sc.cfg.DisableQueryContextCache && data.Data.QueryContext != nil {
 		queryContext, err := extractQueryContext(data)
 		if err != nil {
-			logger.WithContext(ctx).Errorf("error while decoding query context: %v", err)
+			sc.getLogger().WithContext(ctx).Errorf("error while decoding query context: %v", err)
 		} else {
 			sc.queryContextCache.add(sc, queryContext.Entries...)
 		}
@@ -169,23 +201,26 @@ func (sc *snowflakeConn) exec(
 	// handle PUT/GET commands
 	fileTransferChan := make(chan error, 1)
 	if isFileTransfer(query) {
+		var transferData *execResponse
 		go func() {
-			data, err = sc.processFileTransfer(ctx, data, query, isInternal)
+			var err error
+			transferData, err = sc.processFileTransfer(ctx, data, query, isInternal)
 			fileTransferChan <- err
 		}()
 
 		select {
 		case <-ctx.Done():
-			logger.WithContext(ctx).Info("File transfer has been cancelled")
+			sc.getLogger().WithContext(ctx).Info("File transfer has been cancelled")
 			return nil, ctx.Err()
 		case err := <-fileTransferChan:
 			if err != nil {
 				return nil, err
 			}
+			data = transferData
 		}
 	}
 
-	logger.WithContext(ctx).Infof("Exec/Query SUCCESS with total=%v, returned=%v", data.Data.Total, data.Data.Returned)
+	sc.getLogger().WithContext(ctx).Infof("Exec/Query SUCCESS with total=%v, returned=%v", data.Data.Total, data.Data.Returned)
 	if data.Data.FinalDatabaseName != "" {
 		sc.cfg.Database = data.Data.FinalDatabaseName
 	}
@@ -237,7 +272,7 @@ func (sc *snowflakeConn) BeginTx(
 	ctx context.Context,
 	opts driver.TxOptions) (
 	driver.Tx, error) {
-	logger.WithContext(ctx).Info("BeginTx")
+	sc.getLogger().WithContext(ctx).Info("BeginTx")
 	if opts.ReadOnly {
 		return nil, (&SnowflakeError{
 			Number:   ErrNoReadOnlyTransaction,
@@ -266,7 +301,7 @@ func (sc *snowflakeConn) BeginTx(
 
 func (sc *snowflakeConn) cleanup() {
 	// must flush log buffer while the process is running.
-	logger.WithContext(sc.ctx).Debugln("Snowflake connection closing.")
+	sc.getLogger().WithContext(sc.ctx).Debugln("Snowflake connection closing.")
 	if sc.rest != nil && sc.rest.Client != nil {
 		sc.rest.Client.CloseIdleConnections()
 	}
@@ -275,9 +310,9 @@ func (sc *snowflakeConn) cleanup() {
 }
 
 func (sc *snowflakeConn) Close() (err error) {
-	logger.WithContext(sc.ctx).Infoln("Close")
-	if err := sc.telemetry.sendBatch(); err != nil {
-		logger.WithContext(sc.ctx).Warnf("error while sending telemetry. %v", err)
+	sc.getLogger().WithContext(sc.ctx).Infoln("Close")
+	if err := sc.telemetry.close(); err != nil {
+		sc.getLogger().WithContext(sc.ctx).Warnf("error while sending telemetry. %v", err)
 	}
 	sc.stopHeartBeat()
 	defer sc.cleanup()
@@ -285,7 +320,7 @@ func (sc *snowflakeConn) Close() (err error) {
 	if sc.cfg != nil && !sc.cfg.KeepSessionAlive {
 		// we have to replace context with background, otherwise we can use a one that is cancelled or timed out
 		if err = sc.rest.FuncCloseSession(context.Background(), sc.rest, sc.rest.RequestTimeout); err != nil {
-			logger.WithContext(sc.ctx).Error(err)
+			sc.getLogger().WithContext(sc.ctx).Error(err)
 		}
 	}
 	return nil
@@ -295,7 +330,7 @@ func (sc *snowflakeConn) PrepareContext(
 	ctx context.Context,
 	query string) (
 	driver.Stmt, error) {
-	logger.WithContext(sc.ctx).Infoln("Prepare")
+	sc.getLogger().WithContext(sc.ctx).Infoln("Prepare")
 	if sc.rest == nil {
 		return nil, driver.ErrBadConn
 	}
@@ -311,7 +346,8 @@ func (sc *snowflakeConn) ExecContext(
 	query string,
 	args []driver.NamedValue) (
 	driver.Result, error) {
-	logger.WithContext(ctx).Infof("Exec: %#v, %v", query, args)
+	sc.getLogger().WithContext(ctx).Infof("Exec: %#v", query)
+	sc.getLogger().WithContext(ctx).Debugf("Exec bindings: %v", namedValuesForLog(args, sc.cfg.LogBindValues))
 	if sc.rest == nil {
 		return nil, driver.ErrBadConn
 	}
@@ -321,7 +357,7 @@ func (sc *snowflakeConn) ExecContext(
 	ctx = setResultType(ctx, execResultType)
 	data, err := sc.exec(ctx, query, noResult, isInternal, isDesc, args)
 	if err != nil {
-		logger.WithContext(ctx).Infof("error: %v", err)
+		sc.getLogger().WithContext(ctx).Infof("error: %v", err)
 		if data != nil {
 			code, e := strconv.Atoi(data.Code)
 			if e != nil {
@@ -348,7 +384,7 @@ func (sc *snowflakeConn) ExecContext(
 		if err != nil {
 			return nil, err
 		}
-		logger.WithContext(ctx).Debugf("number of updated rows: %#v", updatedRows)
+		sc.getLogger().WithContext(ctx).Debugf("number of updated rows: %#v", updatedRows)
 		return &snowflakeResult{
 			affectedRows: updatedRows,
 			insertID:     -1,
@@ -357,13 +393,13 @@ func (sc *snowflakeConn) ExecContext(
 	} else if isMultiStmt(&data.Data) {
 		return sc.handleMultiExec(ctx, data.Data)
 	} else if isDql(&data.Data) {
-		logger.WithContext(ctx).Debugf("DQL")
+		sc.getLogger().WithContext(ctx).Debugf("DQL")
 		if isStatementContext(ctx) {
 			return &snowflakeResultNoRows{queryID: data.Data.QueryID}, nil
 		}
 		return driver.ResultNoRows, nil
 	}
-	logger.WithContext(ctx).Debug("DDL")
+	sc.getLogger().WithContext(ctx).Debug("DDL")
 	if isStatementContext(ctx) {
 		return &snowflakeResultNoRows{queryID: data.Data.QueryID}, nil
 	}
@@ -398,7 +434,8 @@ func (sc *snowflakeConn) queryContextInternal(
 	query string,
 	args []driver.NamedValue) (
 	driver.Rows, error) {
-	logger.WithContext(ctx).Infof("Query: %#v, %v", query, args)
+	sc.getLogger().WithContext(ctx).Infof("Query: %#v", query)
+	sc.getLogger().WithContext(ctx).Debugf("Query bindings: %v", namedValuesForLog(args, sc.cfg.LogBindValues))
 	if sc.rest == nil {
 		return nil, driver.ErrBadConn
 	}
@@ -409,7 +446,7 @@ func (sc *snowflakeConn) queryContextInternal(
 	isInternal := isInternal(ctx)
 	data, err := sc.exec(ctx, query, noResult, isInternal, isDesc, args)
 	if err != nil {
-		logger.WithContext(ctx).Errorf("error: %v", err)
+		sc.getLogger().WithContext(ctx).Errorf("error: %v", err)
 		if data != nil {
 			code, e := strconv.Atoi(data.Code)
 			if e != nil {
@@ -468,7 +505,7 @@ func (sc *snowflakeConn) Query(
 }
 
 func (sc *snowflakeConn) Ping(ctx context.Context) error {
-	logger.WithContext(ctx).Infoln("Ping")
+	sc.getLogger().WithContext(ctx).Infoln("Ping")
 	if sc.rest == nil {
 		return driver.ErrBadConn
 	}
@@ -484,7 +521,7 @@ func (sc *snowflakeConn) Ping(ctx context.Context) error {
 // CheckNamedValue determines which types are handled by this driver aside from
 // the instances captured by driver.Value
 func (sc *snowflakeConn) CheckNamedValue(nv *driver.NamedValue) error {
-	if supportedNullBind(nv) || supportedArrayBind(nv) || supportedStructuredObjectWriterBind(nv) || supportedStructuredArrayBind(nv) || supportedStructuredMapBind(nv) {
+	if supportedNullBind(nv) || supportedArrayBind(nv) || supportedArrowBind(nv) || supportedStructuredObjectWriterBind(nv) || supportedStructuredArrayBind(nv) || supportedStructuredMapBind(nv) {
 		return nil
 	}
 	return driver.ErrSkip
@@ -520,7 +557,7 @@ func (sc *snowflakeConn) QueryArrowStream(ctx context.Context, query string, bin
 	isInternal := isInternal(ctx)
 	data, err := sc.exec(ctx, query, false, isInternal, isDesc, bindings)
 	if err != nil {
-		logger.WithContext(ctx).Errorf("error: %v", err)
+		sc.getLogger().WithContext(ctx).Errorf("error: %v", err)
 		if data != nil {
 			code, e := strconv.Atoi(data.Code)
 			if e != nil {
@@ -586,9 +623,9 @@ func (w *wrapReader) Close() error {
 func (asb *ArrowStreamBatch) downloadChunkStreamHelper(ctx context.Context) error {
 	headers := make(map[string]string)
 	if len(asb.scd.ChunkHeader) > 0 {
-		logger.WithContext(ctx).Debug("chunk header is provided")
+		asb.scd.sc.getLogger().WithContext(ctx).Debug("chunk header is provided")
 		for k, v := range asb.scd.ChunkHeader {
-			logger.Debugf("adding header: %v, value: %v", k, v)
+			asb.scd.sc.getLogger().Debugf("adding header: %v, value: %v", k, v)
 
 			headers[k] = v
 		}
@@ -601,7 +638,7 @@ func (asb *ArrowStreamBatch) downloadChunkStreamHelper(ctx context.Context) erro
 	if err != nil {
 		return err
 	}
-	logger.WithContext(ctx).Debugf("response returned chunk: %v for URL: %v", asb.idx+1, asb.scd.ChunkMetas[asb.idx].URL)
+	asb.scd.sc.getLogger().WithContext(ctx).Debugf("response returned chunk: %v for URL: %v", asb.idx+1, asb.scd.ChunkMetas[asb.idx].URL)
 	if resp.StatusCode != http.StatusOK {
 		defer resp.Body.Close()
 		b, err := io.ReadAll(resp.Body)
@@ -609,8 +646,8 @@ func (asb *ArrowStreamBatch) downloadChunkStreamHelper(ctx context.Context) erro
 			return err
 		}
 
-		logger.WithContext(ctx).Infof("HTTP: %v, URL: %v, Body: %v", resp.StatusCode, asb.scd.ChunkMetas[asb.idx].URL, b)
-		logger.WithContext(ctx).Infof("Header: %v", resp.Header)
+		asb.scd.sc.getLogger().WithContext(ctx).Infof("HTTP: %v, URL: %v, Body: %v", resp.StatusCode, asb.scd.ChunkMetas[asb.idx].URL, b)
+		asb.scd.sc.getLogger().WithContext(ctx).Infof("Header: %v", resp.Header)
 		return &SnowflakeError{
 			Number:      ErrFailedToGetChunk,
 			SQLState:    SQLStateConnectionFailure,
@@ -712,14 +749,14 @@ func (scd *snowflakeArrowStreamChunkDownloader) maybeFirstBatch() ([]byte, error
 	if err != nil {
 		// match logic in buildFirstArrowChunk
 		// assume there's no first chunk if we can't decode the base64 string
-		logger.Warnf("skipping first batch as it is not a valid base64 response. %v", err)
+		scd.sc.getLogger().Warnf("skipping first batch as it is not a valid base64 response. %v", err)
 		return nil, err
 	}
 
 	// verify it's a valid ipc stream, otherwise skip it
 	rr, err := ipc.NewReader(bytes.NewReader(rowSetBytes))
 	if err != nil {
-		logger.Warnf("skipping first batch as it is not a valid IPC stream. %v", err)
+		scd.sc.getLogger().Warnf("skipping first batch as it is not a valid IPC stream. %v", err)
 		return nil, err
 	}
 	rr.Release()
@@ -760,14 +797,14 @@ func (scd *snowflakeArrowStreamChunkDownloader) GetBatches() (out []ArrowStreamB
 			Loc:     loc,
 			scd:     scd,
 		}
-		logger.Debugf("batch %v, numrows: %v", i, toFill[i].numrows)
+		scd.sc.getLogger().Debugf("batch %v, numrows: %v", i, toFill[i].numrows)
 		totalCounted += int64(scd.ChunkMetas[i].RowCount)
 	}
 
 	if len(rowSetBytes) > 0 {
 		// if we had a first batch, fill in the numrows
 		out[0].numrows = scd.Total - totalCounted
-		logger.Debugf("first batch, numrows: %v", out[0].numrows)
+		scd.sc.getLogger().Debugf("first batch, numrows: %v", out[0].numrows)
 	}
 	return
 }
@@ -781,6 +818,7 @@ func buildSnowflakeConn(ctx context.Context, config Config) (*snowflakeConn, err
 		cfg:                 &config,
 		queryContextCache:   (&queryContextCache{}).init(),
 		currentTimeProvider: defaultTimeProvider,
+		queryHistory:        newQueryHistory(config.QueryHistorySize),
 	}
 	err := initEasyLogging(config.ClientConfigFile)
 	if err != nil {
@@ -826,6 +864,8 @@ func buildSnowflakeConn(ctx context.Context, config Config) (*snowflakeConn, err
 			Transport: st,
 		},
 		TokenAccessor:       tokenAccessor,
+		Logger:              sc.getLogger(),
+		EventHooks:          getEventHooks(sc.cfg),
 		LoginTimeout:        sc.cfg.LoginTimeout,
 		RequestTimeout:      sc.cfg.RequestTimeout,
 		MaxRetryCount:       sc.cfg.MaxRetryCount,
@@ -846,11 +886,19 @@ func buildSnowflakeConn(ctx context.Context, config Config) (*snowflakeConn, err
 	if sc.cfg.DisableTelemetry {
 		sc.telemetry = &snowflakeTelemetry{enabled: false}
 	} else {
+		queueSize := sc.cfg.TelemetryQueueSize
+		if queueSize == 0 {
+			queueSize = defaultTelemetryQueueSize
+		}
 		sc.telemetry = &snowflakeTelemetry{
-			flushSize: defaultFlushSize,
-			sr:        sc.rest,
-			mutex:     &sync.Mutex{},
-			enabled:   true,
+			flushSize:     defaultFlushSize,
+			flushInterval: sc.cfg.TelemetryFlushInterval,
+			maxQueueSize:  queueSize,
+			sr:            sc.rest,
+			mutex:         &sync.Mutex{},
+			enabled:       true,
+			sink:          sc.cfg.TelemetrySink,
+			recorder:      getMetricsRecorder(sc.cfg),
 		}
 	}
 
@@ -864,13 +912,13 @@ func getTransport(cfg *Config) http.RoundTripper {
 	}
 	// if user configured a custom Transporter, prioritize that
 	if cfg.Transporter != nil {
-		logger.Debug("getTransport: using Transporter configured by the user")
+		getConnectionLogger(cfg).Debug("getTransport: using Transporter configured by the user")
 		return cfg.Transporter
 	}
 	if cfg.DisableOCSPChecks || cfg.InsecureMode {
-		logger.Debug("getTransport: skipping OCSP validation for cloud storage")
+		getConnectionLogger(cfg).Debug("getTransport: skipping OCSP validation for cloud storage")
 		return snowflakeNoOcspTransport
 	}
-	logger.Debug("getTransport: will perform OCSP validation for cloud storage")
+	getConnectionLogger(cfg).Debug("getTransport: will perform OCSP validation for cloud storage")
 	return SnowflakeTransport
 }
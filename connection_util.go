@@ -75,10 +75,13 @@ func (sc *snowflakeConn) connectionTelemetry(cfg *Config) {
 	}
 	paramsMutex.Unlock()
 	if err := sc.telemetry.addLog(data); err != nil {
-		logger.WithContext(sc.ctx).Warn(err)
+		sc.getLogger().WithContext(sc.ctx).Warn(err)
+	}
+	if sc.telemetry.requestFlush() {
+		return
 	}
 	if err := sc.telemetry.sendBatch(); err != nil {
-		logger.WithContext(sc.ctx).Warn(err)
+		sc.getLogger().WithContext(sc.ctx).Warn(err)
 	}
 }
 
@@ -101,51 +104,46 @@ func (sc *snowflakeConn) processFileTransfer(
 		options:      options,
 		streamBuffer: new(bytes.Buffer),
 	}
-	fs, err := getFileStream(ctx)
+	if op := getFileTransferOptions(ctx); op != nil {
+		sfa.options = op
+	}
+	if sfa.options.MultiPartThreshold == 0 {
+		sfa.options.MultiPartThreshold = dataSizeThreshold
+	}
+	if sfa.options.GetFileWriter != nil && sfa.options.GetFileToStream {
+		options := *sfa.options
+		options.GetFileToStream = false
+		sfa.options = &options
+	}
+	fs, fsRest, err := getFileStream(ctx, sfa.options.MultiPartThreshold)
 	if err != nil {
 		return nil, err
 	}
 	if fs != nil {
 		sfa.sourceStream = fs
+		sfa.sourceStreamRest = fsRest
 		if isInternal {
 			sfa.data.AutoCompress = false
 		}
 	}
-	if op := getFileTransferOptions(ctx); op != nil {
-		sfa.options = op
-	}
-	if sfa.options.MultiPartThreshold == 0 {
-		sfa.options.MultiPartThreshold = dataSizeThreshold
-	}
 	if err := sfa.execute(); err != nil {
 		return nil, err
 	}
+	if collector := getFileTransferResultCollector(ctx); collector != nil {
+		collector.collect(&sfa)
+	}
 	data, err = sfa.result()
 	if err != nil {
 		return nil, err
 	}
 	if sfa.options.GetFileToStream {
-		if err := writeFileStream(ctx, sfa.streamBuffer); err != nil {
+		if err := writeFileStream(ctx, &sfa); err != nil {
 			return nil, err
 		}
 	}
 	return data, nil
 }
 
-func getFileStream(ctx context.Context) (*bytes.Buffer, error) {
-	s := ctx.Value(fileStreamFile)
-	if s == nil {
-		return nil, nil
-	}
-	r, ok := s.(io.Reader)
-	if !ok {
-		return nil, errors.New("incorrect io.Reader")
-	}
-	buf := new(bytes.Buffer)
-	_, err := buf.ReadFrom(r)
-	return buf, err
-}
-
 func getFileTransferOptions(ctx context.Context) *SnowflakeFileTransferOptions {
 	v := ctx.Value(fileTransferOptions)
 	if v == nil {
@@ -158,22 +156,30 @@ func getFileTransferOptions(ctx context.Context) *SnowflakeFileTransferOptions {
 	return o
 }
 
-func writeFileStream(ctx context.Context, streamBuf *bytes.Buffer) error {
+func writeFileStream(ctx context.Context, sfa *snowflakeFileTransferAgent) error {
 	s := ctx.Value(fileGetStream)
 	w, ok := s.(io.Writer)
 	if !ok {
 		return errors.New("expected an io.Writer")
 	}
-	_, err := streamBuf.WriteTo(w)
+	var fileName string
+	if len(sfa.fileMetadata) > 0 {
+		fileName = sfa.fileMetadata[0].dstFileName
+	}
+	stream, err := decompressDownloadedFile(sfa.options, fileName, sfa.streamBuffer)
 	if err != nil {
 		return err
 	}
+	defer stream.Close()
+	if _, err = io.Copy(w, stream); err != nil {
+		return err
+	}
 	return nil
 }
 
 func (sc *snowflakeConn) populateSessionParameters(parameters []nameValueParameter) {
 	// other session parameters (not all)
-	logger.WithContext(sc.ctx).Tracef("params: %#v", parameters)
+	sc.getLogger().WithContext(sc.ctx).Tracef("params: %#v", parameters)
 	for _, param := range parameters {
 		v := ""
 		switch param.Value.(type) {
@@ -194,7 +200,7 @@ func (sc *snowflakeConn) populateSessionParameters(parameters []nameValueParamet
 				v = vv
 			}
 		}
-		logger.WithContext(sc.ctx).Debugf("parameter. name: %v, value: %v", param.Name, v)
+		sc.getLogger().WithContext(sc.ctx).Debugf("parameter. name: %v, value: %v", param.Name, v)
 		paramsMutex.Lock()
 		sc.cfg.Params[strings.ToLower(param.Name)] = &v
 		paramsMutex.Unlock()
@@ -303,7 +309,7 @@ func populateChunkDownloader(
 			headers:  data.ChunkHeaders,
 			qrmk:     data.Qrmk,
 		}
-		return newStreamChunkDownloader(ctx, fetcher, data.Total, data.RowType,
+		return newStreamChunkDownloader(ctx, sc, fetcher, data.Total, data.RowType,
 			data.RowSet, data.Chunks)
 	}
 
@@ -74,7 +74,7 @@ func TestConnectorCancelContext(t *testing.T) {
 
 	// restore the logger output after the test is complete.
 	logger := GetLogger().(*defaultLogger)
-	initialOutput := logger.inner.Out
+	initialOutput := logger.inner.Out.(*syncWriter).w
 	defer logger.SetOutput(initialOutput)
 
 	// write logs to temp buffer so we can assert log output.
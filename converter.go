@@ -67,6 +67,49 @@ type interfaceArrayBinding struct {
 	timezoneTypeArray interface{}
 }
 
+// structuredArrayBinding wraps a slice of structured values (objects, arrays
+// or maps) bound as a column via array binding.
+type structuredArrayBinding struct {
+	tsmode snowflakeType
+	values interface{}
+}
+
+func (sab *structuredArrayBinding) len() int {
+	return reflect.Indirect(reflect.ValueOf(sab.values)).Len()
+}
+
+// isStructuredArrayBindingCandidate checks if a slice holds elements that are
+// bound as structured OBJECT, ARRAY or MAP values.
+func isStructuredArrayBindingCandidate(a interface{}) bool {
+	typ := reflect.TypeOf(a)
+	if typ == nil {
+		return false
+	}
+	if typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+	if typ.Kind() != reflect.Slice {
+		return false
+	}
+	return structuredArrayBindingElementType(typ.Elem()) != unSupportedType
+}
+
+func structuredArrayBindingElementType(elemType reflect.Type) snowflakeType {
+	if elemType.Implements(reflect.TypeOf((*StructuredObjectWriter)(nil)).Elem()) {
+		return objectType
+	}
+	switch elemType.Kind() {
+	case reflect.Slice:
+		if elemType.Elem().Kind() == reflect.Uint8 {
+			return unSupportedType // []byte is bound as binary
+		}
+		return arrayType
+	case reflect.Map:
+		return mapType
+	}
+	return unSupportedType
+}
+
 func isInterfaceArrayBinding(t interface{}) bool {
 	switch t.(type) {
 	case interfaceArrayBinding:
@@ -240,7 +283,6 @@ func snowflakeTypeToGoForMaps[K comparable](ctx context.Context, valueMetadata f
 // valueToString converts arbitrary golang type to a string. This is mainly used in binding data with placeholders
 // in queries.
 func valueToString(v driver.Value, tsmode snowflakeType, params map[string]*string) (bindingValue, error) {
-	logger.Debugf("TYPE: %v, %v", reflect.TypeOf(v), reflect.ValueOf(v))
 	isJSONFormat := isJSONFormatType(tsmode)
 	if v == nil {
 		if isJSONFormat {
@@ -344,17 +386,13 @@ func arrayToString(v driver.Value, tsmode snowflakeType, params map[string]*stri
 		return bindingValue{&s, jsonFormatStr, &schema}, nil
 	} else if times, ok := v.([]time.Time); ok {
 		typ := driverTypeToSnowflake[tsmode]
-		sfFormat, err := dateTimeInputFormatByType(typ, params)
-		if err != nil {
-			return bindingValue{nil, "", nil}, err
-		}
-		goFormat, err := snowflakeFormatToGoFormat(sfFormat)
-		if err != nil {
-			return bindingValue{nil, "", nil}, err
-		}
 		arr := make([]string, len(times))
 		for idx, t := range times {
-			arr[idx] = t.Format(goFormat)
+			s, err := timeToString(t, typ, params)
+			if err != nil {
+				return bindingValue{nil, "", nil}, err
+			}
+			arr[idx] = s
 		}
 		res, err := json.Marshal(v)
 		if err != nil {
@@ -1026,9 +1064,12 @@ func stringToValue(ctx context.Context, dest *driver.Value, srcColumnMeta execRe
 				Message:  fmt.Sprintf("invalid TIMESTAMP_TZ data. The offset value is not integer: %v", tm[1]),
 			}
 		}
-		loc := Location(int(offset) - 1440)
+		tzLoc := getTimestampTzLocation(ctx, loc)
+		if tzLoc == nil {
+			tzLoc = Location(int(offset) - 1440)
+		}
 		tt := time.Unix(sec, nsec)
-		*dest = tt.In(loc)
+		*dest = tt.In(tzLoc)
 		return nil
 	case "binary":
 		b, err := hex.DecodeString(*srcValue)
@@ -1184,28 +1225,12 @@ func jsonToMapWithKeyType[K comparable](ctx context.Context, valueMetadata field
 		}, true)
 	case "date", "time", "timestamp_tz", "timestamp_ltz", "timestamp_ntz":
 		return buildMapValues[K, sql.NullTime, time.Time](mapValuesNullableEnabled, m, func(v any) (time.Time, error) {
-			sfFormat, err := dateTimeOutputFormatByType(valueMetadata.Type, params)
-			if err != nil {
-				return time.Time{}, err
-			}
-			goFormat, err := snowflakeFormatToGoFormat(sfFormat)
-			if err != nil {
-				return time.Time{}, err
-			}
-			return time.Parse(goFormat, v.(string))
+			return stringToTime(v.(string), valueMetadata.Type, params)
 		}, func(v any) (sql.NullTime, error) {
 			if v == nil {
 				return sql.NullTime{Valid: false}, nil
 			}
-			sfFormat, err := dateTimeOutputFormatByType(valueMetadata.Type, params)
-			if err != nil {
-				return sql.NullTime{}, err
-			}
-			goFormat, err := snowflakeFormatToGoFormat(sfFormat)
-			if err != nil {
-				return sql.NullTime{}, err
-			}
-			time, err := time.Parse(goFormat, v.(string))
+			time, err := stringToTime(v.(string), valueMetadata.Type, params)
 			if err != nil {
 				return sql.NullTime{}, err
 			}
@@ -1309,15 +1334,7 @@ func buildStructuredArray(ctx context.Context, fieldMetadata fieldMetadata, srcV
 		})
 	case "time", "date", "timestamp_ltz", "timestamp_ntz", "timestamp_tz":
 		return copyArrayAndConvert[time.Time](srcValue, func(input any) (time.Time, error) {
-			sfFormat, err := dateTimeOutputFormatByType(fieldMetadata.Type, params)
-			if err != nil {
-				return time.Time{}, err
-			}
-			goFormat, err := snowflakeFormatToGoFormat(sfFormat)
-			if err != nil {
-				return time.Time{}, err
-			}
-			return time.Parse(goFormat, input.(string))
+			return stringToTime(input.(string), fieldMetadata.Type, params)
 		})
 	case "boolean":
 		return copyArrayAndConvert[bool](srcValue, func(input any) (bool, error) {
@@ -1634,10 +1651,13 @@ func arrowToValue(ctx context.Context, rowIdx int, srcColumnMeta fieldMetadata,
 		return arrowTimeToValue(srcValue, rowIdx, int(srcColumnMeta.Scale)), nil
 	case timestampNtzType, timestampLtzType, timestampTzType:
 		v := arrowSnowflakeTimestampToTime(srcValue, snowflakeType, int(srcColumnMeta.Scale), rowIdx, loc)
-		if v != nil {
-			return *v, nil
+		if v == nil {
+			return nil, nil
 		}
-		return nil, nil
+		if tzLoc := getTimestampTzLocation(ctx, loc); snowflakeType == timestampTzType && tzLoc != nil {
+			return v.In(tzLoc), nil
+		}
+		return *v, nil
 	}
 
 	return nil, fmt.Errorf("unsupported data type")
@@ -2552,10 +2572,52 @@ func Array(a interface{}, typ ...timezoneType) interface{} {
 			timezoneTypeArray: a,
 		}
 	default:
+		// Support for bulk array binding insertion of structured objects, arrays and maps
+		if isStructuredArrayBindingCandidate(a) {
+			tsmode := timestampNtzType
+			if len(typ) > 0 {
+				tsmode = convertTzTypeToSnowflakeType(typ[0])
+			}
+			return &structuredArrayBinding{
+				tsmode: tsmode,
+				values: a,
+			}
+		}
 		return a
 	}
 }
 
+// structuredArrayBindingToString converts each element of the structured
+// array binding to its JSON representation. The returned schema describes
+// the first non-null element and is used for the inline binding.
+func structuredArrayBindingToString(sab *structuredArrayBinding, params map[string]*string) (snowflakeType, []*string, *bindingSchema, error) {
+	values := reflect.Indirect(reflect.ValueOf(sab.values))
+	t := structuredArrayBindingElementType(values.Type().Elem())
+	arr := make([]*string, values.Len())
+	var schema *bindingSchema
+	for i := 0; i < values.Len(); i++ {
+		elem := values.Index(i)
+		switch elem.Kind() {
+		case reflect.Pointer, reflect.Interface, reflect.Slice, reflect.Map:
+			if elem.IsNil() {
+				continue
+			}
+		}
+		bv, err := valueToString(elem.Interface(), sab.tsmode, params)
+		if err != nil {
+			return unSupportedType, nil, nil, err
+		}
+		arr[i] = bv.value
+		if schema == nil {
+			schema = bv.schema
+		}
+	}
+	if t == mapType {
+		t = objectType
+	}
+	return t, arr, schema, nil
+}
+
 // snowflakeArrayToString converts the array binding to snowflake's native
 // string type. The string value differs whether it's directly bound or
 // uploaded via stream.
@@ -2826,6 +2888,25 @@ func higherPrecisionEnabled(ctx context.Context) bool {
 	return ok && d
 }
 
+// getTimestampTzLocation returns the location TIMESTAMP_TZ values are converted to
+// or nil if they should keep their original offset.
+func getTimestampTzLocation(ctx context.Context, sessionLoc *time.Location) *time.Location {
+	if ctx == nil {
+		return nil
+	}
+	loc, ok := ctx.Value(timestampTzLocation).(*time.Location)
+	if !ok {
+		return nil
+	}
+	if loc == nil {
+		if sessionLoc == nil {
+			return time.Now().Location()
+		}
+		return sessionLoc
+	}
+	return loc
+}
+
 func arrowBatchesUtf8ValidationEnabled(ctx context.Context) bool {
 	v := ctx.Value(enableArrowBatchesUtf8Validation)
 	if v == nil {
@@ -449,6 +449,63 @@ func TestArrayToString(t *testing.T) {
 	}
 }
 
+func TestStructuredArrayBindingToString(t *testing.T) {
+	objects := []*simpleObject{{s: "some string", i: 123}, nil}
+	t.Run("objects", func(t *testing.T) {
+		sab, ok := Array(&objects).(*structuredArrayBinding)
+		assertTrueF(t, ok)
+		typ, arr, schema, err := structuredArrayBindingToString(sab, nil)
+		assertNilF(t, err)
+		assertEqualE(t, typ, objectType)
+		assertEqualE(t, len(arr), 2)
+		assertEqualIgnoringWhitespaceE(t, *arr[0], `{"i": 123, "s": "some string"}`)
+		assertNilE(t, arr[1])
+		assertNotNilF(t, schema)
+		assertEqualE(t, schema.Typ, "object")
+		assertEqualE(t, len(schema.Fields), 2)
+	})
+	t.Run("arrays", func(t *testing.T) {
+		arrays := [][]int64{{1, 2}, nil, {3}}
+		sab, ok := Array(arrays).(*structuredArrayBinding)
+		assertTrueF(t, ok)
+		typ, arr, _, err := structuredArrayBindingToString(sab, nil)
+		assertNilF(t, err)
+		assertEqualE(t, typ, arrayType)
+		assertEqualE(t, *arr[0], "[1,2]")
+		assertNilE(t, arr[1])
+		assertEqualE(t, *arr[2], "[3]")
+	})
+	t.Run("maps", func(t *testing.T) {
+		maps := []map[string]bool{{"a": true}, nil}
+		sab, ok := Array(&maps).(*structuredArrayBinding)
+		assertTrueF(t, ok)
+		typ, arr, schema, err := structuredArrayBindingToString(sab, nil)
+		assertNilF(t, err)
+		assertEqualE(t, typ, objectType)
+		assertEqualIgnoringWhitespaceE(t, *arr[0], `{"a": true}`)
+		assertNilE(t, arr[1])
+		assertNotNilF(t, schema)
+		assertEqualE(t, schema.Typ, "MAP")
+	})
+	t.Run("bind values", func(t *testing.T) {
+		bindings := []driver.NamedValue{{Ordinal: 1, Value: Array(&objects)}}
+		assertTrueF(t, isArrayBind(bindings))
+		numBinds, err := arrayBindValueCount(bindings)
+		assertNilF(t, err)
+		assertEqualE(t, numBinds, 2)
+		bindValues, err := getBindValues(bindings, nil)
+		assertNilF(t, err)
+		assertEqualE(t, bindValues["1"].Type, "OBJECT")
+		assertEqualE(t, bindValues["1"].Format, jsonFormatStr)
+		assertNotNilE(t, bindValues["1"].Schema)
+	})
+	t.Run("unsupported elements", func(t *testing.T) {
+		assertFalseE(t, isStructuredArrayBindingCandidate([]int{1}))
+		assertFalseE(t, isStructuredArrayBindingCandidate([][]byte{{1}}))
+		assertFalseE(t, isStructuredArrayBindingCandidate(simpleObject{}))
+	})
+}
+
 func TestArrowToValues(t *testing.T) {
 	dest := make([]snowflakeValue, 2)
 
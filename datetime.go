@@ -2,35 +2,156 @@ package gosnowflake
 
 import (
 	"errors"
-	"regexp"
+	"fmt"
 	"strconv"
 	"strings"
 	"time"
 )
 
-var incorrectSecondsFractionRegex = regexp.MustCompile(`[^.,]FF`)
-var correctSecondsFractionRegex = regexp.MustCompile(`FF(?P<fraction>\d?)`)
+const autoFormat = "AUTO"
+
+type dateTimeFormatElement int
+
+const (
+	literalElement dateTimeFormatElement = iota
+	fourDigitYearElement
+	twoDigitYearElement
+	fullMonthNameElement
+	shortMonthNameElement
+	monthElement
+	dayElement
+	shortDayNameElement
+	hour24Element
+	hour12Element
+	meridiemElement
+	minuteElement
+	secondElement
+	fractionElement
+	timezoneHourElement
+	timezoneMinuteElement
+)
+
+type dateTimeFormatToken struct {
+	element dateTimeFormatElement
+	// literal holds the text of literalElement tokens
+	literal string
+	// digits holds the number of fraction digits of fractionElement tokens
+	digits int
+}
 
 type formatReplacement struct {
-	input  string
-	output string
+	input   string
+	element dateTimeFormatElement
+	output  string
 }
 
+// formatReplacements lists Snowflake format elements with their Go layout counterparts.
+// Longer elements sharing a prefix with shorter ones have to be listed first.
 var formatReplacements = []formatReplacement{
-	{input: "YYYY", output: "2006"},
-	{input: "YY", output: "06"},
-	{input: "MMMM", output: "January"},
-	{input: "MM", output: "01"},
-	{input: "MON", output: "Jan"},
-	{input: "DD", output: "02"},
-	{input: "DY", output: "Mon"},
-	{input: "HH24", output: "15"},
-	{input: "HH12", output: "03"},
-	{input: "AM", output: "PM"},
-	{input: "MI", output: "04"},
-	{input: "SS", output: "05"},
-	{input: "TZH", output: "Z07"},
-	{input: "TZM", output: "00"},
+	{input: "YYYY", element: fourDigitYearElement, output: "2006"},
+	{input: "YY", element: twoDigitYearElement, output: "06"},
+	{input: "MMMM", element: fullMonthNameElement, output: "January"},
+	{input: "MON", element: shortMonthNameElement, output: "Jan"},
+	{input: "MM", element: monthElement, output: "01"},
+	{input: "DD", element: dayElement, output: "02"},
+	{input: "DY", element: shortDayNameElement, output: "Mon"},
+	{input: "HH24", element: hour24Element, output: "15"},
+	{input: "HH12", element: hour12Element, output: "03"},
+	{input: "HH", element: hour24Element, output: "15"},
+	{input: "AM", element: meridiemElement, output: "PM"},
+	{input: "PM", element: meridiemElement, output: "PM"},
+	{input: "MI", element: minuteElement, output: "04"},
+	{input: "SS", element: secondElement, output: "05"},
+	{input: "TZH", element: timezoneHourElement, output: "Z07"},
+	{input: "TZM", element: timezoneMinuteElement, output: "00"},
+}
+
+// autoFormats are used for formatting values when the format parameter is AUTO.
+var autoFormats = map[string]string{
+	"date":          "YYYY-MM-DD",
+	"time":          "HH24:MI:SS.FF9",
+	"timestamp_ntz": "YYYY-MM-DD HH24:MI:SS.FF9",
+	"timestamp_ltz": "YYYY-MM-DD HH24:MI:SS.FF9 TZH:TZM",
+	"timestamp_tz":  "YYYY-MM-DD HH24:MI:SS.FF9 TZH:TZM",
+}
+
+// autoParseLayouts are tried in order when parsing values with the AUTO format.
+var autoParseLayouts = []string{
+	"2006-01-02 15:04:05.999999999 Z07:00",
+	"2006-01-02 15:04:05.999999999 Z0700",
+	"2006-01-02 15:04:05.999999999",
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02",
+	"15:04:05.999999999",
+	time.RFC1123Z,
+	time.RFC1123,
+}
+
+// parseDateTimeFormat splits a Snowflake date and time format into elements.
+// Text enclosed in double quotes and characters which are not format elements are kept as literals.
+func parseDateTimeFormat(sfFormat string) ([]dateTimeFormatToken, error) {
+	var tokens []dateTimeFormatToken
+	appendLiteral := func(literal string) {
+		if len(tokens) > 0 && tokens[len(tokens)-1].element == literalElement {
+			tokens[len(tokens)-1].literal += literal
+			return
+		}
+		tokens = append(tokens, dateTimeFormatToken{element: literalElement, literal: literal})
+	}
+	upper := strings.ToUpper(sfFormat)
+	for i := 0; i < len(sfFormat); {
+		if sfFormat[i] == '"' {
+			end := strings.IndexByte(sfFormat[i+1:], '"')
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated quoted literal in format %v", sfFormat)
+			}
+			appendLiteral(sfFormat[i+1 : i+1+end])
+			i += end + 2
+			continue
+		}
+		if strings.HasPrefix(upper[i:], "FF") {
+			digits := 9
+			i += 2
+			if i < len(sfFormat) && sfFormat[i] >= '0' && sfFormat[i] <= '9' {
+				digits = int(sfFormat[i] - '0')
+				i++
+			}
+			if digits == 0 {
+				// no fraction digits, so the decimal separator preceding them is dropped as well
+				if last := len(tokens) - 1; last >= 0 && tokens[last].element == literalElement {
+					literal := tokens[last].literal
+					if strings.HasSuffix(literal, ".") || strings.HasSuffix(literal, ",") {
+						tokens[last].literal = literal[:len(literal)-1]
+					}
+					if tokens[last].literal == "" {
+						tokens = tokens[:last]
+					}
+				}
+				continue
+			}
+			tokens = append(tokens, dateTimeFormatToken{element: fractionElement, digits: digits})
+			continue
+		}
+		matched := false
+		for _, replacement := range formatReplacements {
+			if strings.HasPrefix(upper[i:], replacement.input) {
+				tokens = append(tokens, dateTimeFormatToken{element: replacement.element})
+				i += len(replacement.input)
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			appendLiteral(sfFormat[i : i+1])
+			i++
+		}
+	}
+	return tokens, nil
+}
+
+func isAutoFormat(sfFormat string) bool {
+	return strings.EqualFold(strings.TrimSpace(sfFormat), autoFormat)
 }
 
 func timeToString(t time.Time, dateTimeType string, params map[string]*string) (string, error) {
@@ -38,38 +159,250 @@ func timeToString(t time.Time, dateTimeType string, params map[string]*string) (
 	if err != nil {
 		return "", err
 	}
-	goFormat, err := snowflakeFormatToGoFormat(sfFormat)
+	return formatDateTime(t, sfFormat, dateTimeType)
+}
+
+// stringToTime parses a date and time value returned by Snowflake as a string, e.g. in structured types.
+func stringToTime(s string, dateTimeType string, params map[string]*string) (time.Time, error) {
+	sfFormat, err := dateTimeOutputFormatByType(dateTimeType, params)
 	if err != nil {
-		return "", err
+		return time.Time{}, err
 	}
-	return t.Format(goFormat), nil
+	return parseDateTime(s, sfFormat)
 }
 
-func snowflakeFormatToGoFormat(sfFormat string) (string, error) {
-	res := sfFormat
-	for _, replacement := range formatReplacements {
-		res = strings.Replace(res, replacement.input, replacement.output, -1)
+// formatDateTime formats the time using a Snowflake date and time format.
+// The AUTO format is replaced by the default format of the given Snowflake type.
+func formatDateTime(t time.Time, sfFormat string, dateTimeType string) (string, error) {
+	if isAutoFormat(sfFormat) {
+		var ok bool
+		if sfFormat, ok = autoFormats[strings.ToLower(dateTimeType)]; !ok {
+			return "", errors.New("not known AUTO format for " + dateTimeType)
+		}
+	}
+	tokens, err := parseDateTimeFormat(sfFormat)
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	for _, token := range tokens {
+		switch token.element {
+		case literalElement:
+			b.WriteString(token.literal)
+		case fourDigitYearElement:
+			b.WriteString(fmt.Sprintf("%04d", t.Year()))
+		case twoDigitYearElement:
+			b.WriteString(fmt.Sprintf("%02d", t.Year()%100))
+		case fullMonthNameElement:
+			b.WriteString(t.Month().String())
+		case shortMonthNameElement:
+			b.WriteString(t.Month().String()[:3])
+		case monthElement:
+			b.WriteString(fmt.Sprintf("%02d", int(t.Month())))
+		case dayElement:
+			b.WriteString(fmt.Sprintf("%02d", t.Day()))
+		case shortDayNameElement:
+			b.WriteString(t.Weekday().String()[:3])
+		case hour24Element:
+			b.WriteString(fmt.Sprintf("%02d", t.Hour()))
+		case hour12Element:
+			hour := t.Hour() % 12
+			if hour == 0 {
+				hour = 12
+			}
+			b.WriteString(fmt.Sprintf("%02d", hour))
+		case meridiemElement:
+			if t.Hour() < 12 {
+				b.WriteString("AM")
+			} else {
+				b.WriteString("PM")
+			}
+		case minuteElement:
+			b.WriteString(fmt.Sprintf("%02d", t.Minute()))
+		case secondElement:
+			b.WriteString(fmt.Sprintf("%02d", t.Second()))
+		case fractionElement:
+			b.WriteString(fmt.Sprintf("%09d", t.Nanosecond())[:token.digits])
+		case timezoneHourElement:
+			_, offset := t.Zone()
+			sign := '+'
+			if offset < 0 {
+				sign = '-'
+				offset = -offset
+			}
+			b.WriteString(fmt.Sprintf("%c%02d", sign, offset/3600))
+		case timezoneMinuteElement:
+			_, offset := t.Zone()
+			if offset < 0 {
+				offset = -offset
+			}
+			b.WriteString(fmt.Sprintf("%02d", offset%3600/60))
+		}
 	}
+	return b.String(), nil
+}
 
-	if incorrectSecondsFractionRegex.MatchString(res) {
-		return "", errors.New("incorrect second fraction - golang requires fraction to be preceded by comma or decimal point")
+// parseDateTime parses the value using a Snowflake date and time format.
+// Values without time zone elements are returned in UTC.
+func parseDateTime(value string, sfFormat string) (time.Time, error) {
+	if isAutoFormat(sfFormat) {
+		for _, layout := range autoParseLayouts {
+			if t, err := time.Parse(layout, value); err == nil {
+				return t, nil
+			}
+		}
+		return time.Time{}, fmt.Errorf("cannot parse %v with AUTO format", value)
+	}
+	tokens, err := parseDateTimeFormat(sfFormat)
+	if err != nil {
+		return time.Time{}, err
+	}
+	year, month, day := 1, 1, 1
+	hour, minute, second, nanosecond := 0, 0, 0, 0
+	pm, hasMeridiem := false, false
+	hasTimezone, tzSign, tzHour, tzMinute := false, 1, 0, 0
+	rest := value
+	readNumber := func(maxDigits int) (int, error) {
+		n := 0
+		for n < maxDigits && n < len(rest) && rest[n] >= '0' && rest[n] <= '9' {
+			n++
+		}
+		if n == 0 {
+			return 0, fmt.Errorf("cannot parse %v as %v: expected number at %q", value, sfFormat, rest)
+		}
+		number, err := strconv.Atoi(rest[:n])
+		rest = rest[n:]
+		return number, err
+	}
+	readName := func(names func(int) string, count int, length int) (int, error) {
+		for i := 0; i < count; i++ {
+			name := names(i)
+			if length > 0 {
+				name = name[:length]
+			}
+			if len(rest) >= len(name) && strings.EqualFold(rest[:len(name)], name) {
+				rest = rest[len(name):]
+				return i, nil
+			}
+		}
+		return 0, fmt.Errorf("cannot parse %v as %v: unexpected name at %q", value, sfFormat, rest)
+	}
+	monthName := func(i int) string { return time.Month(i + 1).String() }
+	dayName := func(i int) string { return time.Weekday(i).String() }
+	for _, token := range tokens {
+		switch token.element {
+		case literalElement:
+			if !strings.HasPrefix(rest, token.literal) {
+				return time.Time{}, fmt.Errorf("cannot parse %v as %v: expected %q at %q", value, sfFormat, token.literal, rest)
+			}
+			rest = rest[len(token.literal):]
+		case fourDigitYearElement:
+			year, err = readNumber(4)
+		case twoDigitYearElement:
+			year, err = readNumber(2)
+			// the same pivot as in time.Parse
+			if year >= 69 {
+				year += 1900
+			} else {
+				year += 2000
+			}
+		case fullMonthNameElement:
+			month, err = readName(monthName, 12, 0)
+			month++
+		case shortMonthNameElement:
+			month, err = readName(monthName, 12, 3)
+			month++
+		case monthElement:
+			month, err = readNumber(2)
+		case dayElement:
+			day, err = readNumber(2)
+		case shortDayNameElement:
+			_, err = readName(dayName, 7, 3)
+		case hour24Element, hour12Element:
+			hour, err = readNumber(2)
+		case meridiemElement:
+			var meridiem int
+			meridiem, err = readName(func(i int) string { return []string{"AM", "PM"}[i] }, 2, 0)
+			hasMeridiem, pm = true, meridiem == 1
+		case minuteElement:
+			minute, err = readNumber(2)
+		case secondElement:
+			second, err = readNumber(2)
+		case fractionElement:
+			digits := len(rest)
+			nanosecond, err = readNumber(token.digits)
+			digits -= len(rest)
+			for ; err == nil && digits < 9; digits++ {
+				nanosecond *= 10
+			}
+		case timezoneHourElement:
+			hasTimezone = true
+			if strings.HasPrefix(rest, "Z") {
+				rest = rest[1:]
+				continue
+			}
+			if strings.HasPrefix(rest, "-") {
+				tzSign = -1
+				rest = rest[1:]
+			} else {
+				rest = strings.TrimPrefix(rest, "+")
+			}
+			tzHour, err = readNumber(2)
+		case timezoneMinuteElement:
+			tzMinute, err = readNumber(2)
+		}
+		if err != nil {
+			return time.Time{}, err
+		}
 	}
-	for {
-		submatch := correctSecondsFractionRegex.FindStringSubmatch(res)
-		if submatch == nil {
-			break
+	if rest != "" {
+		return time.Time{}, fmt.Errorf("cannot parse %v as %v: extra text %q", value, sfFormat, rest)
+	}
+	if hasMeridiem {
+		if hour == 12 {
+			hour = 0
+		}
+		if pm {
+			hour += 12
 		}
-		fractionNumbers := 9
-		if submatch[1] != "" {
-			var err error
-			fractionNumbers, err = strconv.Atoi(submatch[1])
-			if err != nil {
-				return "", err
+	}
+	loc := time.UTC
+	if hasTimezone {
+		loc = Location(tzSign * (tzHour*60 + tzMinute))
+	}
+	return time.Date(year, time.Month(month), day, hour, minute, second, nanosecond, loc), nil
+}
+
+// snowflakeFormatToGoFormat converts a Snowflake date and time format to a Go layout.
+// Not every format can be expressed as a Go layout, use formatDateTime and parseDateTime instead where possible.
+func snowflakeFormatToGoFormat(sfFormat string) (string, error) {
+	if isAutoFormat(sfFormat) {
+		return "", errors.New("AUTO format cannot be converted to golang layout")
+	}
+	tokens, err := parseDateTimeFormat(sfFormat)
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	for _, token := range tokens {
+		switch token.element {
+		case literalElement:
+			b.WriteString(token.literal)
+		case fractionElement:
+			if !strings.HasSuffix(b.String(), ".") && !strings.HasSuffix(b.String(), ",") {
+				return "", errors.New("incorrect second fraction - golang requires fraction to be preceded by comma or decimal point")
+			}
+			b.WriteString(strings.Repeat("0", token.digits))
+		default:
+			for _, replacement := range formatReplacements {
+				if replacement.element == token.element {
+					b.WriteString(replacement.output)
+					break
+				}
 			}
 		}
-		res = strings.Replace(res, submatch[0], strings.Repeat("0", fractionNumbers), -1)
 	}
-	return res, nil
+	return b.String(), nil
 }
 
 func dateTimeOutputFormatByType(dateTimeType string, params map[string]*string) (string, error) {
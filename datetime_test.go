@@ -1,6 +1,8 @@
 package gosnowflake
 
 import (
+	"fmt"
+	"strings"
 	"testing"
 	"time"
 )
@@ -46,6 +48,12 @@ func TestSnowflakeFormatToGoFormatUnitTest(t *testing.T) {
 			formatted1:  "03:42:33.123 03:42:33,123456789",
 			formatted2:  "13:05:03.987 01:05:03,987000000",
 		},
+		{
+			inputFormat: "HH24:MI:SS.FF0",
+			output:      "15:04:05",
+			formatted1:  "03:42:33",
+			formatted2:  "13:05:03",
+		},
 	}
 	for _, tc := range testcases {
 		t.Run(tc.inputFormat, func(t *testing.T) {
@@ -63,6 +71,124 @@ func TestIncorrectSecondsFraction(t *testing.T) {
 	assertHasPrefixE(t, err.Error(), "incorrect second fraction")
 }
 
+func TestFormatAndParseDateTime(t *testing.T) {
+	location, err := time.LoadLocation("Europe/Warsaw")
+	assertNilF(t, err)
+	someTime1 := time.Date(2024, time.January, 19, 3, 42, 33, 123456789, location)
+	someTime2 := time.Date(1973, time.December, 5, 13, 5, 3, 987000000, location)
+	testcases := []struct {
+		sfFormat   string
+		formatted1 string
+		formatted2 string
+		precision  time.Duration
+	}{
+		{
+			sfFormat:   "YYYY-MM-DD HH24:MI:SS.FF TZH:TZM",
+			formatted1: "2024-01-19 03:42:33.123456789 +01:00",
+			formatted2: "1973-12-05 13:05:03.987000000 +01:00",
+			precision:  time.Nanosecond,
+		},
+		{
+			sfFormat:   "YY-MM-DD HH12:MI:SS,FF5AM TZHTZM",
+			formatted1: "24-01-19 03:42:33,12345AM +0100",
+			formatted2: "73-12-05 01:05:03,98700PM +0100",
+			precision:  10 * time.Microsecond,
+		},
+		{
+			sfFormat:   "DY, DD MON YYYY HH24:MI:SSFF3 TZHTZM",
+			formatted1: "Fri, 19 Jan 2024 03:42:33123 +0100",
+			formatted2: "Wed, 05 Dec 1973 13:05:03987 +0100",
+			precision:  time.Millisecond,
+		},
+		{
+			sfFormat:   `MMMM DD, YYYY "at" HH12 PM TZH:TZM`,
+			formatted1: "January 19, 2024 at 03 AM +01:00",
+			formatted2: "December 05, 1973 at 01 PM +01:00",
+			precision:  time.Hour,
+		},
+		{
+			sfFormat:   `yyyy-mm-dd"T"hh24:mi:ss.ff0`,
+			formatted1: "2024-01-19T03:42:33",
+			formatted2: "1973-12-05T13:05:03",
+			precision:  time.Second,
+		},
+		{
+			sfFormat:   "HH24:MI:SS,FF0 DD.MM.YYYY TZHTZM",
+			formatted1: "03:42:33 19.01.2024 +0100",
+			formatted2: "13:05:03 05.12.1973 +0100",
+			precision:  time.Second,
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.sfFormat, func(t *testing.T) {
+			formatted1, err := formatDateTime(someTime1, tc.sfFormat, "timestamp_tz")
+			assertNilF(t, err)
+			assertEqualE(t, formatted1, tc.formatted1)
+			formatted2, err := formatDateTime(someTime2, tc.sfFormat, "timestamp_tz")
+			assertNilF(t, err)
+			assertEqualE(t, formatted2, tc.formatted2)
+
+			parsed, err := parseDateTime(formatted1, tc.sfFormat)
+			assertNilF(t, err)
+			if strings.Contains(tc.sfFormat, "TZH") {
+				assertTrueE(t, parsed.Equal(someTime1.Truncate(tc.precision)), fmt.Sprintf("expected %v, got %v", someTime1, parsed))
+			} else {
+				assertEqualE(t, parsed.Format(time.DateTime), someTime1.Format(time.DateTime))
+			}
+		})
+	}
+}
+
+func TestFormatAndParseDateTimeWithAutoFormat(t *testing.T) {
+	someTime := time.Date(2024, time.January, 19, 3, 42, 33, 123456789, time.FixedZone("", -5*3600))
+	formatted, err := formatDateTime(someTime, "AUTO", "timestamp_tz")
+	assertNilF(t, err)
+	assertEqualE(t, formatted, "2024-01-19 03:42:33.123456789 -05:00")
+	formatted, err = formatDateTime(someTime, "auto", "date")
+	assertNilF(t, err)
+	assertEqualE(t, formatted, "2024-01-19")
+
+	parsed, err := parseDateTime("2024-01-19 03:42:33.123456789 -05:00", "AUTO")
+	assertNilF(t, err)
+	assertTrueE(t, parsed.Equal(someTime))
+	parsed, err = parseDateTime("2024-01-19T03:42:33.123456789-05:00", "AUTO")
+	assertNilF(t, err)
+	assertTrueE(t, parsed.Equal(someTime))
+	_, err = parseDateTime("19/01/2024", "AUTO")
+	assertNotNilE(t, err)
+}
+
+func TestParseDateTimeErrors(t *testing.T) {
+	for _, tc := range []struct {
+		value    string
+		sfFormat string
+	}{
+		{value: "2024-01-19", sfFormat: "YYYY/MM/DD"},
+		{value: "2024-01-19 extra", sfFormat: "YYYY-MM-DD"},
+		{value: "2024-Foo-19", sfFormat: "YYYY-MON-DD"},
+		{value: "2024-01-19", sfFormat: `YYYY-MM-DD "unterminated`},
+	} {
+		t.Run(tc.value+" "+tc.sfFormat, func(t *testing.T) {
+			_, err := parseDateTime(tc.value, tc.sfFormat)
+			assertNotNilE(t, err)
+		})
+	}
+}
+
+func TestTimeToStringAndStringToTime(t *testing.T) {
+	tsFormat := "DD.MM.YYYY HH24:MI:SS.FF3 TZHTZM"
+	params := map[string]*string{
+		"timestamp_output_format": &tsFormat,
+	}
+	someTime := time.Date(2024, time.January, 19, 3, 42, 33, 123000000, time.FixedZone("", 3600))
+	s, err := timeToString(someTime, "timestamp_tz", params)
+	assertNilF(t, err)
+	assertEqualE(t, s, "19.01.2024 03:42:33.123 +0100")
+	parsed, err := stringToTime(s, "timestamp_tz", params)
+	assertNilF(t, err)
+	assertTrueE(t, parsed.Equal(someTime))
+}
+
 func TestSnowflakeFormatToGoFormatIntegrationTest(t *testing.T) {
 	runDBTest(t, func(dbt *DBTest) {
 		dbt.mustExec("ALTER SESSION SET TIME_OUTPUT_FORMAT = 'HH24:MI:SS.FF'")
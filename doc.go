@@ -201,6 +201,58 @@ Users can use SetLogger in driver.go to set a customized logger for gosnowflake
 In order to enable debug logging for the driver, user could use SetLogLevel("debug") in SFLogger interface
 as shown in demo code at cmd/logger.go. To redirect the logs SFlogger.SetOutput method could do the work.
 
+Applications using log/slog can pass the records of the driver to their slog.Handler with CreateSlogLogger.
+The fields of the context, including the ones registered with RegisterLogContextHook, become attributes
+of the records and secrets are masked in the messages and attributes:
+
+	logger := sf.CreateSlogLogger(slog.Default().Handler())
+	sf.SetLogger(&logger)
+
+A connection can log with its own logger and level instead of the global one, e.g. to route the logs of
+a tenant separately. Set Config.Logger to the logger of the connection and Config.LogLevel to its level.
+Config.LogLevel does not change the level of Config.Logger, which may be shared by other connections.
+If only Config.LogLevel is set, the connection logs at that level to the output of the global logger:
+
+	cfg.Logger = sf.CreateSlogLogger(tenantHandler)
+	cfg.LogLevel = "debug"
+	db := sql.OpenDB(sf.NewConnector(sf.SnowflakeDriver{}, *cfg))
+
+The driver masks known secrets, like passwords, tokens and keys, in log messages, log fields and the
+messages of SnowflakeError. Additional secrets, e.g. internal tokens or personal data in SQL text,
+can be masked by registering rules on startup:
+
+	sf.RegisterSecretMaskingRule(sf.RegexpMaskingRule(regexp.MustCompile(`(ssn\s*=\s*)'\d+'`), "$1'****'"))
+	sf.RegisterSecretMaskingRule(func(text string) string {
+		return emailRegexp.ReplaceAllString(text, "<email>")
+	})
+
+The values of query bindings are logged only at debug level and they are masked unless
+Config.LogBindValues is set.
+
+Easy Logging configures the global logger from a client configuration file, given by the clientConfigFile
+parameter or the SF_CLIENT_CONFIG_FILE environment variable, or found as sf_client_config.json in the application
+or the home directory. Besides log_level and log_path, the "common" section can set the size in megabytes after which
+the log file is rotated, the number of rotated files kept, the JSON output format, the log levels of the retry,
+ocsp and chunk_downloader modules, and patterns of secrets masked in the logs:
+
+	{
+	  "common": {
+	    "log_level": "INFO",
+	    "log_path": "/var/log/myapp",
+	    "log_max_size_mb": 100,
+	    "log_max_backups": 5,
+	    "log_format": "json",
+	    "log_levels": {"retry": "DEBUG", "ocsp": "WARN"},
+	    "mask_patterns": [{"pattern": "employee-[0-9]+", "replacement": "employee-****"}]
+	  }
+	}
+
+The log levels of modules apply to the global logger, the connections with their own Config.Logger or
+Config.LogLevel log all modules with the level of the connection. Unknown modules are ignored.
+The file is checked for changes every 10 seconds and the logger is reconfigured with the new content.
+An invalid or removed file keeps the current configuration, and a logger installed with SetLogger after
+the initialization is not replaced.
+
 If you want to define S3 client logging, override S3LoggingMode variable using configuration: https://pkg.go.dev/github.com/aws/aws-sdk-go-v2/aws#ClientLogMode
 Example:
 
@@ -213,6 +265,114 @@ Example:
 
 		sf.S3LoggingMode = aws.LogRequest | aws.LogResponseWithBody | aws.LogRetries
 
+# Tracing
+
+The driver creates OpenTelemetry spans when Config.TracerProvider is set. The spans are children of the span
+in the context passed to the driver, so they nest under the spans of the application:
+
+	cfg.TracerProvider = otel.GetTracerProvider()
+	db := sql.OpenDB(sf.NewConnector(sf.SnowflakeDriver{}, *cfg))
+
+The following spans are created:
+  - snowflake.login: the authentication of a new connection.
+  - snowflake.query: a query request including polling for its result, with the request ID and query ID.
+  - snowflake.async_query: retrieving the result of an asynchronous query.
+  - snowflake.chunk_download: downloading a chunk of a result set, with its index, rows and bytes.
+  - snowflake.http_request: an HTTP request to Snowflake, with its path, status code and number of retries.
+  - snowflake.file_upload and snowflake.file_download: the transfer of a file by PUT or GET, with its status and bytes.
+
+Without a TracerProvider, no spans are created.
+
+# Metrics
+
+The driver reports the latency of logins and queries, HTTP retries by their endpoint and reason, the bytes and
+duration of result chunk downloads, OCSP cache lookups and the bytes transferred by PUT and GET to a MetricsRecorder.
+Set it with SetMetricsRecorder for all connections or in Config.MetricsRecorder for a single connection.
+OCSP cache lookups are shared by all connections and are reported only to the recorder set by SetMetricsRecorder.
+
+Recorders for Prometheus and OpenTelemetry are provided by the packages github.com/snowflakedb/gosnowflake/metrics/prometheus
+and github.com/snowflakedb/gosnowflake/metrics/otel, so the driver itself does not depend on their client libraries:
+
+	recorder := sfprometheus.NewRecorder()
+	prometheus.MustRegister(recorder)
+	sf.SetMetricsRecorder(recorder)
+
+	recorder, err := sfotel.NewRecorder(otel.GetMeterProvider())
+	if err != nil {
+		log.Fatal(err)
+	}
+	sf.SetMetricsRecorder(recorder)
+
+No metrics are recorded by default.
+
+# Telemetry
+
+The driver reports connection parameters and errors to Snowflake telemetry, unless Config.DisableTelemetry is set.
+The events are queued and sent by a background goroutine every Config.TelemetryFlushInterval, when enough
+events are queued and when the connection is closed. At most Config.TelemetryQueueSize events are queued,
+further events are dropped and counted by the MetricsRecorders of the driver.
+To see what the driver reports, set Config.TelemetrySink, e.g. to write the events to a logger:
+
+	cfg.TelemetrySink = sf.NewLoggerTelemetrySink(sf.GetLogger())
+
+# Query history
+
+The driver can keep the recent statements in memory to find their query IDs while debugging. Each entry contains the
+SQL text with the secrets masked, the query ID, the request ID, the start and end time, the number of rows and bytes
+of the result, the number of retried requests, the result format and the error of the statement.
+Set Config.QueryHistorySize to keep the recent statements of a connection, available through
+SnowflakeConnection.QueryHistory, and SetQueryHistorySize to keep the recent statements of all connections,
+available through GetQueryHistory. Both can be exposed on a debug endpoint with NewQueryHistoryHandler:
+
+	sf.SetQueryHistorySize(100)
+	http.Handle("/debug/snowflake/queries", sf.NewQueryHistoryHandler(sf.GetQueryHistory))
+
+Internal requests of the driver and the describe requests of Prepare are not recorded.
+No statements are kept by default.
+
+# Event hooks
+
+To alert on slow queries or on HTTP retries piling up without parsing the logs, set Config.EventHooks to an
+EventHooks implementation. It is called when a statement starts and finishes, with the summary of the response
+also kept in the query history, before each retry of an HTTP request, with the URL path, the status code, the
+reason, the attempt and the time to wait, and when the session token is renewed. Like the query history, the
+statement events skip the internal requests of the driver and the describe requests of Prepare. Embed
+NoopEventHooks to handle only some of the events:
+
+	type slowQueryAlerts struct {
+		sf.NoopEventHooks
+	}
+
+	func (slowQueryAlerts) OnQueryFinish(ctx context.Context, event sf.QueryFinishEvent) {
+		if event.Duration > time.Minute {
+			alert("slow query %v: %v", event.QueryID, event.Duration)
+		}
+	}
+
+	cfg.EventHooks = slowQueryAlerts{}
+
+The hooks are called synchronously, so they should return quickly.
+
+# Recording and replaying HTTP requests
+
+To capture the REST exchanges of a failing session, set Config.Transporter to a RecordingTransport. It writes the
+login, query, chunk download and stage requests and their responses to a file, one JSON object per line, with
+tokens, passwords, credentials and signatures masked:
+
+	f, err := os.Create("session.jsonl")
+	...
+	cfg.Transporter = sf.NewRecordingTransport(sf.SnowflakeTransport, f)
+
+The recorded session can be replayed offline with a ReplayTransport, which answers each request with the next
+recorded response of the same method and URL path:
+
+	f, err := os.Open("session.jsonl")
+	...
+	cfg.Transporter, err = sf.NewReplayTransport(f)
+
+As the tokens are masked in the recording, only the responses of the replay are real, so it exercises the driver
+without a Snowflake account but cannot be used to access one.
+
 # Query tag
 
 A custom query tag can be set in the context. Each query run with this context
@@ -651,6 +811,40 @@ of the returned value:
 	    }
 	}
 
+# Custom type mappings
+
+The Go types returned for each Snowflake type can be changed with a TypeMappingRegistry.
+A TypeConverter receives the value the driver would return otherwise (it is not called for NULL values)
+and declares the type reported by ColumnTypeScanType. Converters can be registered for all columns of
+a type, or for an exact precision and scale, which takes priority:
+
+	registry := sf.NewTypeMappingRegistry()
+	err := registry.RegisterWithPrecisionScale("FIXED", 38, 0, sf.TypeConverter{
+	    ScanType: reflect.TypeOf(&big.Int{}),
+	    Convert: func(value driver.Value, column sf.TypeMappingColumn) (driver.Value, error) {
+	        i, ok := new(big.Int).SetString(fmt.Sprint(value), 10)
+	        if !ok {
+	            return nil, fmt.Errorf("invalid integer in column %v", column.Name)
+	        }
+	        return i, nil
+	    },
+	})
+	err = registry.Register("BINARY", sf.TypeConverter{
+	    ScanType: reflect.TypeOf(""),
+	    Convert: func(value driver.Value, _ sf.TypeMappingColumn) (driver.Value, error) {
+	        return hex.EncodeToString(value.([]byte)), nil
+	    },
+	})
+
+Types are named as returned by ColumnTypeDatabaseTypeName, e.g. FIXED, TEXT, TIMESTAMP_NTZ or BINARY.
+Note that the input value depends on the result format and context, e.g. FIXED columns
+are returned as int64, string or *big.Int.
+
+A registry is set for all queries of a connection with Config.TypeMappings,
+or for a single query with WithTypeMappings, which takes priority over the connection registry:
+
+	rows, err := db.QueryContext(sf.WithTypeMappings(ctx, registry), "SELECT ...")
+
 # Arrow batches
 
 You can retrieve data in a columnar format similar to the format a server returns, without transposing them to rows.
@@ -834,6 +1028,29 @@ This feature is available in version 1.6.13 (and later) of the driver. For examp
 	_, err = db.Exec("create or replace table my_table(c1 timestamp_ntz, c2 timestamp_ltz)")
 	_, err = db.Exec("insert into my_table values (?,?)", Array(&ntzArray, sf.TimestampNTZType), Array(&ltzArray, sf.TimestampLTZType))
 
+Structured OBJECT, ARRAY and MAP columns can be array bound as well. Wrap a slice of StructuredObjectWriter
+implementations, a slice of slices or a slice of maps in the Array() function. Nil elements are inserted as SQL NULL.
+Time values nested in arrays and maps use the binding parameter flag passed to the Array() function. For example,
+
+	_, err = db.Exec("create or replace table my_table(o OBJECT(s VARCHAR, i INTEGER), a ARRAY(INTEGER), m MAP(VARCHAR, BOOLEAN))")
+	objects := []*simpleObject{{s: "a", i: 1}, nil}
+	arrays := [][]int64{{1, 2}, {3}}
+	maps := []map[string]bool{{"a": true}, nil}
+	_, err = db.Exec("insert into my_table select ?, ?, ?", Array(&objects), Array(&arrays), Array(&maps))
+
+Data already held in Arrow format can be bound without converting it to Go values. Pass an arrow.Record
+or an array.RecordReader as the only argument, its columns are bound to the parameters in order.
+Mixing it with other arguments fails with ErrBindSerialization.
+Before the insert, the driver describes the statement with an internal request, which is not recorded in
+the query history nor passed to EventHooks, checks that the Arrow column types are compatible with the described
+binds and returns ErrBindTypeMismatch otherwise. Records read from an array.RecordReader are always streamed
+to a temporary stage, unless the CLIENT_STAGE_ARRAY_BINDING_THRESHOLD parameter is 0. The records are staged
+as CSV, like other array binds, as the bind stage does not accept Parquet files. For example,
+
+	var record arrow.Record // with int64 and string columns
+	...
+	_, err = db.ExecContext(ctx, "insert into my_table values (?, ?)", record)
+
 Note: For alternative ways to load data into the Snowflake database (including bulk loading using the COPY command), see
 Loading Data into Snowflake (https://docs.snowflake.com/en/user-guide-data-load.html).
 
@@ -897,7 +1114,21 @@ use in a geographical area, such as CET (Central European Time) or UTC
 cached when a Go Snowflake Driver application starts, and if the given offset
 is not in the cache, it is generated dynamically.
 
-Currently, Snowflake does not support the name-based Location types (e.g. "America/Los_Angeles").
+TIMESTAMP_TZ values do not store a name-based Location (e.g. "America/Los_Angeles"), so by default
+they keep the fixed offset returned by Snowflake. To convert them to a name-based Location, for example
+to get daylight saving time aware arithmetic, use WithTimestampTZLocation per query:
+
+	loc, err := sf.LocationWithName("America/Los_Angeles")
+	...
+	rows, err := db.QueryContext(sf.WithTimestampTZLocation(ctx, loc), "SELECT tz_column FROM my_table")
+
+Passing a nil location converts TIMESTAMP_TZ values to the location of the session TIMEZONE parameter.
+
+TIMESTAMP_LTZ values are returned in the name-based Location of the session TIMEZONE parameter.
+If the time zone database is not available on the host (e.g. in scratch containers), the driver falls back
+to the local time zone. Build your application with the sftzdata tag to embed the time zone database in the binary:
+
+	go build -tags sftzdata
 
 For more information about Location types, see the Go documentation for https://golang.org/pkg/time/#Location.
 
@@ -1274,6 +1505,43 @@ To send information from a stream (rather than a file) use code similar to the c
 	dbt.mustExecContext(WithFileStream(context.Background(), fileStream),
 		sqlText)
 
+Streams up to SnowflakeFileTransferOptions.MultiPartThreshold (64 MB by default) are buffered in memory.
+Larger streams are compressed, encrypted and uploaded in parts as they are read, so the memory used is bounded
+by the part size and the PUT parallelism. Each part is kept in memory until it is uploaded, so that a failed part
+is uploaded again. On Azure the digest is set when the block list is committed. On S3 the parts are uploaded
+in a multipart upload, and the digest and the encryption metadata are set by copying the completed object onto
+itself, as they are only known once the whole stream was read. Until the copy completes the object is visible without
+them, so a concurrent reader of the stage may find it without the sfc-digest metadata. The copy is done by S3, but it
+reads and writes the whole object again, and objects larger than 5 GiB are copied part by part in a multipart copy,
+which doubles the server-side work of the upload and makes that window longer. Upload such large data from files
+instead, which are uploaded with their metadata. On GCS the stream is written to a temporary file in Config.TmpDirPath first, because
+the digest has to be sent before the content, and the file is then uploaded and retried like any other file.
+
+A source location ending with the ** wildcard (e.g. file:///tmp/data/**) uploads the files of the directory and all
+its subdirectories, and each file is uploaded to the stage path mirroring its directory relative to the source
+directory. A pattern of the relative paths may follow the wildcard to filter the files. Setting Recursive
+in SnowflakeFileTransferOptions or PutRequest walks the directories matched by a source location the same way. IncludePatterns and ExcludePatterns
+select the files by their relative paths with the syntax of path.Match, where ** matches any number of directories
+and a pattern without a slash matches the file name. Symbolic links to files are uploaded with the content of their
+targets, symbolic links to directories are walked only if FollowSymlinks is set:
+
+	ctx := WithFileTransferOptions(context.Background(), &SnowflakeFileTransferOptions{
+		Recursive:       true,
+		ExcludePatterns: []string{"*.tmp"},
+	})
+	db.ExecContext(ctx, "PUT file:///tmp/data @~/data")
+
+Files which are not compressed yet are compressed with gzip when AUTO_COMPRESS is true (the default).
+Set AutoCompression in SnowflakeFileTransferOptions or PutRequest to ZSTD or BROTLI to compress them
+with zstd or brotli instead, and AutoCompressionLevel to use another than the default level of the compression
+(1-22 for zstd, 0-11 for brotli and -2-9 for gzip, where zero is the default level):
+
+	ctx := WithFileTransferOptions(context.Background(), &SnowflakeFileTransferOptions{
+		AutoCompression:      "ZSTD",
+		AutoCompressionLevel: 6,
+	})
+	db.ExecContext(ctx, "PUT file:///tmp/data.csv @~/data")
+
 Note: PUT statements are not supported for multi-statement queries.
 
 Using GET:
@@ -1299,6 +1567,27 @@ To download a file into an in-memory stream (rather than a file) use code simila
 	// streamBuf is now filled with the stream. Use bytes.NewReader(streamBuf.Bytes()) to read uncompressed stream or
 	// use gzip.NewReader(&streamBuf) for to read compressed stream.
 
+GetFileToStream downloads a single file. To stream many files, set GetFileWriter, which returns an io.WriteCloser
+for every downloaded file, e.g. an upload to another object store. The files are downloaded in parallel,
+so GetFileWriter is called concurrently, and every writer is closed once the whole file is written to it.
+Every file is decrypted and written to its writer as it is downloaded, without storing it on disk.
+A file is downloaded with a single request then, instead of the concurrent ranged requests of S3 downloads.
+The name passed to GetFileWriter is the path of the file relative to the stage location:
+
+	ctx := WithFileTransferOptions(context.Background(), &SnowflakeFileTransferOptions{
+		GetFileWriter: func(name string) (io.WriteCloser, error) {
+			return bucket.NewWriter(ctx, "copy/"+name)
+		},
+	})
+	db.ExecContext(ctx, "GET @my_stage/data file:///tmp PATTERN='.*[.]csv'")
+
+Files streamed by GET are compressed as they are stored on the stage. Set DecompressGetStream to decompress
+them according to their extensions (.gz, .deflate, .raw_deflate, .bz2, .zst and .br) before they are written
+to the stream or to the writers of GetFileWriter.
+
+By default, GET writes all files to the local directory itself. Set PreserveStagePaths to write them to
+subdirectories of the local directory mirroring their paths on the stage instead.
+
 Note: GET statements are not supported for multi-statement queries.
 
 Specifying temporary directory for encryption and compression:
@@ -1310,11 +1599,114 @@ Example:
 
 	u:p@a.r.c.snowflakecomputing.com/db/s?account=a.r.c&tmpDirPath=%2Fother%2Ftmp
 
+Resuming large uploads:
+
+Resuming uploads is disabled by default. It is enabled by setting the "uploadJournalRetention" DSN parameter
+(in seconds) or Config.UploadJournalRetention to how long an unfinished upload can be resumed:
+
+	u:p@a.r.c.snowflakecomputing.com/db/s?account=a.r.c&uploadJournalRetention=86400
+
+Files larger than MultiPartThreshold uploaded to S3 or Azure stages are then uploaded in parts, and the progress
+of the upload is recorded in a journal in the "snowflake_upload_journal" subdirectory of the temporary directory.
+If the upload fails, the retry, or a later PUT of the same file to the same location, uploads only the parts
+which were not uploaded yet. When a journal expires, or the file changed, the unfinished upload is aborted
+and its parts are deleted from the stage. Expired journals are discarded by the next PUT to the same stage.
+
+Limiting the bandwidth and concurrency of PUT and GET:
+
+The "fileTransferBandwidthLimit" DSN parameter (Config.FileTransferBandwidthLimit) limits the number of bytes
+per second transferred to and from cloud storage, and the "fileTransferMaxConcurrency" DSN parameter
+(Config.FileTransferMaxConcurrency) limits the number of concurrent cloud storage requests. Both limits
+are shared by all PUT and GET commands of the connection, by the files transferred in parallel
+and by the parts of multipart uploads:
+
+	u:p@a.r.c.snowflakecomputing.com/db/s?account=a.r.c&fileTransferBandwidthLimit=10485760&fileTransferMaxConcurrency=4
+
+A single command can use its own limits with BandwidthLimit and MaxConcurrency of SnowflakeFileTransferOptions,
+PutRequest or GetRequest. A negative value removes the limit of the connection for the command.
+
+Reporting the progress of PUT and GET:
+
+Set a ProgressListener in SnowflakeFileTransferOptions, PutRequest or GetRequest to receive the progress
+of every transferred file. The listener is notified when a file starts, when bytes are transferred,
+when a failed attempt is retried and when the file completes. Every event also carries the bytes transferred
+by the whole command and its throughput. Files are transferred in parallel, so the listener must be safe
+for concurrent use:
+
+	ctx := sf.WithFileTransferOptions(context.Background(), &sf.SnowflakeFileTransferOptions{
+		ProgressListener: myListener,
+	})
+	db.ExecContext(ctx, "PUT file:///tmp/data.csv @~")
+
+Verifying the integrity of PUT and GET:
+
+Set VerifyChecksums in SnowflakeFileTransferOptions, PutRequest or GetRequest to verify the transferred files.
+Uploads to S3 and GCS then carry a CRC32C checksum, and uploads to Azure a CRC64 checksum of every block, which
+the storage validates when it receives the content. After the upload, the SHA-256 digest of the file is compared
+with the digest stored with the file on the stage, and after a download, the digest of the downloaded content is
+compared with the stored digest. Files whose digests differ fail with ErrChecksumMismatch:
+
+	ctx := WithFileTransferOptions(context.Background(), &SnowflakeFileTransferOptions{VerifyChecksums: true})
+	db.ExecContext(ctx, "PUT file:///tmp/data.csv @~/data")
+
+GCS uploads with presigned URLs cannot carry additional headers, so only their digests are compared.
+
+Syncing files to a stage with PUT:
+
+With OVERWRITE=FALSE, PUT skips every file which already exists on the stage, even if it was modified locally.
+Set Sync in SnowflakeFileTransferOptions or PutRequest to upload only new files and files whose SHA-256 digest
+differs from the digest stored with the file on the stage, regardless of OVERWRITE. Set SyncDelete as well to
+remove the files of the stage location which match the source pattern but were not uploaded by the command,
+e.g. files deleted locally. Other files of the stage location are kept:
+
+	results, err := x.(SnowflakeConnection).Put(ctx, PutRequest{
+		Sources:    []string{"/tmp/data/**"},
+		Stage:      "@~/data",
+		Sync:       true,
+		SyncDelete: true,
+	})
+	summary := SummarizeFileTransferResults(results)
+	fmt.Printf("%v uploaded, %v skipped, %v deleted\n", summary.Uploaded, summary.Skipped, summary.Deleted)
+
+Removed files are reported with the DELETED status. Nothing is removed if any file fails to upload.
+Many removed files are split into several REMOVE commands.
+The digest covers the compressed content, so files uploaded by other clients or with another compression
+may be uploaded once again. Streams larger than MultiPartThreshold are always uploaded.
+
 Using custom configuration for PUT/GET:
 
 If you want to override some default configuration options, you can use `WithFileTransferOptions` context.
 There are multiple config parameters including progress bars or compression.
 
+Using the typed file transfer API:
+
+Instead of building PUT and GET statements, files can be transferred with the Put and Get methods
+of SnowflakeConnection, available through sql.Conn.Raw. Paths and stage locations are quoted by the driver,
+and the result of every file is returned as a FileTransferResult with its status, sizes, compression,
+digest and error:
+
+	err = conn.Raw(func(x any) error {
+		results, err := x.(SnowflakeConnection).Put(ctx, PutRequest{
+			Sources:   []string{"/tmp/data/*.csv"},
+			Stage:     "@~/data",
+			Overwrite: true,
+			Parallel:  4,
+		})
+		if err != nil {
+			return err
+		}
+		for _, res := range results {
+			if res.Status == FileTransferError {
+				log.Printf("failed to upload %v: %v", res.Source, res.Err)
+			}
+		}
+		return nil
+	})
+
+A stream is uploaded by setting Stream and StreamName instead of Sources. Get downloads the files of a stage,
+optionally filtered by Pattern, to LocalDirectory or to the writers returned by Writer. Errors of single files do not fail the call,
+regardless of RaisePutGetError, only errors of a whole command are returned.
+
 # Surfacing errors originating from PUT and GET commands
 
 Default behaviour is to propagate the potential underlying errors encountered during executing calls associated with the PUT or GET commands to the caller, for increased awareness and easier handling or troubleshooting them.
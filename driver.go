@@ -53,21 +53,26 @@ func (d SnowflakeDriver) OpenWithConfig(ctx context.Context, config Config) (dri
 			return nil, err
 		}
 	}
-	logger.WithContext(ctx).Info("OpenWithConfig")
+	if err := setupConnectionLogger(&config); err != nil {
+		return nil, err
+	}
+	connLogger := getConnectionLogger(&config)
+	connLogger.WithContext(ctx).Info("OpenWithConfig")
 	sc, err := buildSnowflakeConn(ctx, config)
 	if err != nil {
 		return nil, err
 	}
 
 	if strings.HasSuffix(strings.ToLower(config.Host), cnDomain) {
-		logger.WithContext(ctx).Info("Connecting to CHINA Snowflake domain")
+		connLogger.WithContext(ctx).Info("Connecting to CHINA Snowflake domain")
 	} else {
-		logger.WithContext(ctx).Info("Connecting to GLOBAL Snowflake domain")
+		connLogger.WithContext(ctx).Info("Connecting to GLOBAL Snowflake domain")
 	}
 
 	if err = authenticateWithConfig(sc); err != nil {
 		return nil, err
 	}
+	sc.telemetry.start()
 	sc.connectionTelemetry(&config)
 
 	sc.startHeartBeat()
@@ -14,6 +14,8 @@ import (
 	"strconv"
 	"strings"
 	"time"
+
+	"go.opentelemetry.io/otel/trace"
 )
 
 const (
@@ -93,12 +95,32 @@ type Config struct {
 
 	Transporter http.RoundTripper // RoundTripper to intercept HTTP requests and responses
 
+	TracerProvider  trace.TracerProvider // Optional OpenTelemetry provider of the spans of logins, queries, chunk downloads and file transfers
+	MetricsRecorder MetricsRecorder      // Optional recorder of the metrics of the connection, the recorder set by SetMetricsRecorder is used by default
+	EventHooks      EventHooks           // Optional hooks notified of the queries, HTTP retries and session renewals of the connection
+
 	DisableTelemetry bool // indicates whether to disable telemetry
 
+	TelemetryFlushInterval time.Duration // how often queued telemetry events are sent to Snowflake, 10 seconds by default
+	TelemetryQueueSize     int           // maximum number of queued telemetry events, further events are dropped, 1000 by default
+	TelemetrySink          TelemetrySink // Optional sink receiving the telemetry events sent to Snowflake
+
 	Tracing string // sets logging level
 
+	Logger   SFLogger // Optional logger of the connection, the logger set by SetLogger is used by default
+	LogLevel string   // sets logging level of the connection without changing the level of Logger or of the global logger
+
+	LogBindValues bool // logs the values of bindings at debug level, they are masked by default
+
+	QueryHistorySize int // number of recent statements kept in the query history of the connection, 0 disables it
+
 	TmpDirPath string // sets temporary directory used by a driver for operations like encrypting, compressing etc
 
+	UploadJournalRetention time.Duration // how long unfinished multipart uploads can be resumed, 0 (the default) or a negative value disables resuming
+
+	FileTransferBandwidthLimit int64 // maximum number of bytes per second transferred by PUT and GET commands of the connection, 0 means no limit
+	FileTransferMaxConcurrency int   // maximum number of concurrent cloud storage requests of PUT and GET commands of the connection, 0 means no limit
+
 	MfaToken                       string     // Internally used to cache the MFA token
 	IDToken                        string     // Internally used to cache the Id Token for external browser
 	ClientRequestMfaToken          ConfigBool // When true the MFA token is cached in the credential manager. True by default in Windows/OSX. False for Linux.
@@ -113,6 +135,8 @@ type Config struct {
 	DisableConsoleLogin ConfigBool // Indicates whether console login should be disabled
 
 	DisableSamlURLCheck ConfigBool // Indicates whether the SAML URL check should be disabled
+
+	TypeMappings *TypeMappingRegistry // Optional custom conversions of query results to Go types
 }
 
 // Validate enables testing if config is correct.
@@ -260,6 +284,17 @@ func DSN(cfg *Config) (dsn string, err error) {
 	if cfg.TmpDirPath != "" {
 		params.Add("tmpDirPath", cfg.TmpDirPath)
 	}
+	if cfg.UploadJournalRetention < 0 {
+		params.Add("uploadJournalRetention", "-1")
+	} else if cfg.UploadJournalRetention != 0 {
+		params.Add("uploadJournalRetention", strconv.FormatInt(int64(cfg.UploadJournalRetention/time.Second), 10))
+	}
+	if cfg.FileTransferBandwidthLimit > 0 {
+		params.Add("fileTransferBandwidthLimit", strconv.FormatInt(cfg.FileTransferBandwidthLimit, 10))
+	}
+	if cfg.FileTransferMaxConcurrency > 0 {
+		params.Add("fileTransferMaxConcurrency", strconv.Itoa(cfg.FileTransferMaxConcurrency))
+	}
 	if cfg.DisableQueryContextCache {
 		params.Add("disableQueryContextCache", "true")
 	}
@@ -838,6 +873,21 @@ func parseDSNParams(cfg *Config, params string) (err error) {
 			cfg.Tracing = value
 		case "tmpDirPath":
 			cfg.TmpDirPath = value
+		case "uploadJournalRetention":
+			cfg.UploadJournalRetention, err = parseTimeout(value)
+			if err != nil {
+				return err
+			}
+		case "fileTransferBandwidthLimit":
+			cfg.FileTransferBandwidthLimit, err = strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return err
+			}
+		case "fileTransferMaxConcurrency":
+			cfg.FileTransferMaxConcurrency, err = strconv.Atoi(value)
+			if err != nil {
+				return err
+			}
 		case "disableQueryContextCache":
 			var b bool
 			b, err = strconv.ParseBool(value)
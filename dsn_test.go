@@ -918,6 +918,39 @@ func TestParseDSN(t *testing.T) {
 			ocspMode: ocspModeFailOpen,
 			err:      nil,
 		},
+		{
+			dsn: "u:p@a.r.c.snowflakecomputing.com/db/s?account=a.r.c&uploadJournalRetention=3600",
+			config: &Config{
+				Account: "a", User: "u", Password: "p",
+				Protocol: "https", Host: "a.r.c.snowflakecomputing.com", Port: 443,
+				Database: "db", Schema: "s", ValidateDefaultParameters: ConfigBoolTrue, OCSPFailOpen: OCSPFailOpenTrue,
+				ClientTimeout:          defaultClientTimeout,
+				JWTClientTimeout:       defaultJWTClientTimeout,
+				ExternalBrowserTimeout: defaultExternalBrowserTimeout,
+				CloudStorageTimeout:    defaultCloudStorageTimeout,
+				UploadJournalRetention: time.Hour,
+				IncludeRetryReason:     ConfigBoolTrue,
+			},
+			ocspMode: ocspModeFailOpen,
+			err:      nil,
+		},
+		{
+			dsn: "u:p@a.r.c.snowflakecomputing.com/db/s?account=a.r.c&fileTransferBandwidthLimit=1048576&fileTransferMaxConcurrency=4",
+			config: &Config{
+				Account: "a", User: "u", Password: "p",
+				Protocol: "https", Host: "a.r.c.snowflakecomputing.com", Port: 443,
+				Database: "db", Schema: "s", ValidateDefaultParameters: ConfigBoolTrue, OCSPFailOpen: OCSPFailOpenTrue,
+				ClientTimeout:              defaultClientTimeout,
+				JWTClientTimeout:           defaultJWTClientTimeout,
+				ExternalBrowserTimeout:     defaultExternalBrowserTimeout,
+				CloudStorageTimeout:        defaultCloudStorageTimeout,
+				FileTransferBandwidthLimit: 1048576,
+				FileTransferMaxConcurrency: 4,
+				IncludeRetryReason:         ConfigBoolTrue,
+			},
+			ocspMode: ocspModeFailOpen,
+			err:      nil,
+		},
 		{
 			dsn: "u:p@a.r.c.snowflakecomputing.com/db/s?account=a.r.c&disableQueryContextCache=true",
 			config: &Config{
@@ -1236,6 +1269,15 @@ func TestParseDSN(t *testing.T) {
 				if test.config.TmpDirPath != cfg.TmpDirPath {
 					t.Fatalf("%v: Failed to match TmpDirPatch. expected: %v, got: %v", i, test.config.TmpDirPath, cfg.TmpDirPath)
 				}
+				if test.config.UploadJournalRetention != cfg.UploadJournalRetention {
+					t.Fatalf("%v: Failed to match UploadJournalRetention. expected: %v, got: %v", i, test.config.UploadJournalRetention, cfg.UploadJournalRetention)
+				}
+				if test.config.FileTransferBandwidthLimit != cfg.FileTransferBandwidthLimit {
+					t.Fatalf("%v: Failed to match FileTransferBandwidthLimit. expected: %v, got: %v", i, test.config.FileTransferBandwidthLimit, cfg.FileTransferBandwidthLimit)
+				}
+				if test.config.FileTransferMaxConcurrency != cfg.FileTransferMaxConcurrency {
+					t.Fatalf("%v: Failed to match FileTransferMaxConcurrency. expected: %v, got: %v", i, test.config.FileTransferMaxConcurrency, cfg.FileTransferMaxConcurrency)
+				}
 				if test.config.DisableQueryContextCache != cfg.DisableQueryContextCache {
 					t.Fatalf("%v: Failed to match DisableQueryContextCache. expected: %v, got: %v", i, test.config.DisableQueryContextCache, cfg.DisableQueryContextCache)
 				}
@@ -1750,6 +1792,25 @@ func TestDSN(t *testing.T) {
 			},
 			dsn: "u:p@a.b.c.snowflakecomputing.com:443?ocspFailOpen=true&region=b.c&tmpDirPath=%2Ftmp&validateDefaultParameters=true",
 		},
+		{
+			cfg: &Config{
+				User:                   "u",
+				Password:               "p",
+				Account:                "a.b.c",
+				UploadJournalRetention: -1,
+			},
+			dsn: "u:p@a.b.c.snowflakecomputing.com:443?ocspFailOpen=true&region=b.c&uploadJournalRetention=-1&validateDefaultParameters=true",
+		},
+		{
+			cfg: &Config{
+				User:                       "u",
+				Password:                   "p",
+				Account:                    "a.b.c",
+				FileTransferBandwidthLimit: 1048576,
+				FileTransferMaxConcurrency: 4,
+			},
+			dsn: "u:p@a.b.c.snowflakecomputing.com:443?fileTransferBandwidthLimit=1048576&fileTransferMaxConcurrency=4&ocspFailOpen=true&region=b.c&validateDefaultParameters=true",
+		},
 		{
 			cfg: &Config{
 				User:               "u",
@@ -6,22 +6,31 @@ import (
 	"io"
 	"os"
 	"path"
+	"regexp"
 	"runtime"
+	"slices"
 	"strings"
 	"sync"
+	"time"
+
+	rlog "github.com/sirupsen/logrus"
 )
 
 type initTrials struct {
 	everTriedToInitialize bool
 	clientConfigFileInput string
 	configureCounter      int
-	mu                    sync.Mutex
+	watcher               *clientConfigWatcher
+	// installedLogger is the global logger installed by Easy Logging, to tell if the application replaced it
+	installedLogger SFLogger
+	mu              sync.Mutex
 }
 
 var easyLoggingInitTrials = initTrials{
 	everTriedToInitialize: false,
 	clientConfigFileInput: "",
 	configureCounter:      0,
+	watcher:               nil,
 	mu:                    sync.Mutex{},
 }
 
@@ -34,6 +43,19 @@ func (i *initTrials) increaseReconfigureCounter() {
 	i.configureCounter++
 }
 
+// watch starts watching the client config file for changes, replacing the previous watcher.
+func (i *initTrials) watch(configPath string) {
+	i.stopWatching()
+	i.watcher = newClientConfigWatcher(configPath)
+}
+
+func (i *initTrials) stopWatching() {
+	if i.watcher != nil {
+		i.watcher.stop()
+		i.watcher = nil
+	}
+}
+
 func initEasyLogging(clientConfigFileInput string) error {
 	easyLoggingInitTrials.mu.Lock()
 	defer easyLoggingInitTrials.mu.Unlock()
@@ -53,25 +75,20 @@ func initEasyLogging(clientConfigFileInput string) error {
 		easyLoggingInitTrials.setInitTrial(clientConfigFileInput)
 		return nil
 	}
-	var logLevel string
-	logLevel, err = getLogLevel(config.Common.LogLevel)
+	var settings *easyLoggingSettings
+	settings, err = getEasyLoggingSettings(config.Common)
 	if err != nil {
 		logger.Errorf("Failed to initialize Easy Logging, err: %s", err)
 		return easyLoggingInitError(err)
 	}
-	var logPath string
-	logPath, err = getLogPath(config.Common.LogPath)
-	if err != nil {
-		logger.Errorf("Failed to initialize Easy Logging, err: %s", err)
-		return easyLoggingInitError(err)
-	}
-	logger.Infof("Initializing Easy Logging with logPath=%s and logLevel=%s from file: %s", logPath, logLevel, configPath)
-	err = reconfigureEasyLogging(logLevel, logPath)
+	logger.Infof("Initializing Easy Logging with logPath=%s and logLevel=%s from file: %s", settings.logPath, settings.logLevel, configPath)
+	err = reconfigureEasyLogging(settings)
 	if err != nil {
 		logger.Errorf("Failed to initialize Easy Logging, err: %s", err)
 	}
 	easyLoggingInitTrials.setInitTrial(clientConfigFileInput)
 	easyLoggingInitTrials.increaseReconfigureCounter()
+	easyLoggingInitTrials.watch(configPath)
 	return err
 }
 
@@ -83,32 +100,121 @@ func easyLoggingInitError(err error) error {
 	}
 }
 
-func reconfigureEasyLogging(logLevel string, logPath string) error {
-	newLogger := CreateDefaultLogger()
-	err := newLogger.SetLogLevel(logLevel)
+// easyLoggingSettings are the validated logging properties of the client config file.
+type easyLoggingSettings struct {
+	logLevel     string
+	logPath      string
+	logFormat    string
+	maxSize      int64 // in bytes, 0 disables the rotation
+	maxBackups   int
+	moduleLevels map[string]string
+	maskingRules []SecretMaskingRule
+}
+
+// defaultMaskReplacement replaces the matches of the mask patterns without a replacement.
+const defaultMaskReplacement = "****"
+
+func getEasyLoggingSettings(common *ClientConfigCommonProps) (*easyLoggingSettings, error) {
+	logLevel, err := getLogLevel(common.LogLevel)
+	if err != nil {
+		return nil, err
+	}
+	logPath, err := getLogPath(common.LogPath)
+	if err != nil {
+		return nil, err
+	}
+	logFormat, err := toLogFormat(common.LogFormat)
+	if err != nil {
+		return nil, err
+	}
+	moduleLevels := make(map[string]string, len(common.LogLevels))
+	for module, moduleLevel := range common.LogLevels {
+		if !slices.Contains(logModules, module) {
+			logger.Warnf("Ignoring the log level of unknown module %s, known modules: %v", module, logModules)
+			continue
+		}
+		moduleLevels[module], err = toLogLevel(moduleLevel)
+		if err != nil {
+			return nil, err
+		}
+	}
+	maskingRules := make([]SecretMaskingRule, 0, len(common.MaskPatterns))
+	for _, maskPattern := range common.MaskPatterns {
+		re, err := regexp.Compile(maskPattern.Pattern)
+		if err != nil {
+			return nil, err
+		}
+		replacement := maskPattern.Replacement
+		if replacement == "" {
+			replacement = defaultMaskReplacement
+		}
+		maskingRules = append(maskingRules, RegexpMaskingRule(re, replacement))
+	}
+	maxBackups := common.LogMaxBackups
+	if maxBackups == 0 {
+		maxBackups = 1
+	}
+	return &easyLoggingSettings{
+		logLevel:     logLevel,
+		logPath:      logPath,
+		logFormat:    logFormat,
+		maxSize:      int64(common.LogMaxSizeMB) * 1024 * 1024,
+		maxBackups:   maxBackups,
+		moduleLevels: moduleLevels,
+		maskingRules: maskingRules,
+	}, nil
+}
+
+func reconfigureEasyLogging(settings *easyLoggingSettings) error {
+	newLogger := CreateDefaultLogger().(*defaultLogger)
+	err := newLogger.SetLogLevel(settings.logLevel)
 	if err != nil {
 		return err
 	}
+	loggers := make(map[string]SFLogger, len(settings.moduleLevels))
+	for module, moduleLevel := range settings.moduleLevels {
+		if loggers[module], err = newModuleLogger(moduleLevel); err != nil {
+			return err
+		}
+	}
+	newLogger.SetFormatter(createLogFormatter(settings.logFormat))
 	var output io.Writer
-	var file *os.File
-	output, file, err = createLogWriter(logPath)
+	output, newLogger.closer, err = createLogWriter(settings.logPath, settings.maxSize, settings.maxBackups)
 	if err != nil {
 		return err
 	}
 	newLogger.SetOutput(output)
-	err = newLogger.CloseFileOnLoggerReplace(file)
-	if err != nil {
-		logger.Errorf("%s", err)
-	}
-	logger.Replace(&newLogger)
+	setClientConfigMaskingRules(settings.maskingRules)
+	var sfLogger SFLogger = newLogger
+	logger.Replace(&sfLogger)
+	easyLoggingInitTrials.installedLogger = sfLogger
+	setModuleLoggers(loggers)
 	return nil
 }
 
-func createLogWriter(logPath string) (io.Writer, *os.File, error) {
+func createLogFormatter(logFormat string) rlog.Formatter {
+	if logFormat == logFormatJSON {
+		formatter := new(sfJSONFormatter)
+		formatter.CallerPrettyfier = SFCallerPrettyfier
+		return formatter
+	}
+	formatter := new(sfTextFormatter)
+	formatter.CallerPrettyfier = SFCallerPrettyfier
+	return formatter
+}
+
+func createLogWriter(logPath string, maxSize int64, maxBackups int) (io.Writer, io.Closer, error) {
 	if strings.EqualFold(logPath, "STDOUT") {
 		return os.Stdout, nil, nil
 	}
 	logFileName := path.Join(logPath, "snowflake.log")
+	if maxSize > 0 {
+		writer, err := newRotatingLogWriter(logFileName, maxSize, maxBackups)
+		if err != nil {
+			return nil, nil, err
+		}
+		return writer, writer, nil
+	}
 	file, err := os.OpenFile(logFileName, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0640)
 	if err != nil {
 		return nil, nil, err
@@ -116,6 +222,170 @@ func createLogWriter(logPath string) (io.Writer, *os.File, error) {
 	return file, file, nil
 }
 
+// rotatingLogWriter writes to a log file, renaming it to <file>.1 when it would exceed maxSize.
+// The previously rotated files are shifted to <file>.2 and so on, keeping at most maxBackups of them.
+type rotatingLogWriter struct {
+	mu         sync.Mutex
+	fileName   string
+	maxSize    int64
+	maxBackups int
+	file       *os.File
+	size       int64
+}
+
+func newRotatingLogWriter(fileName string, maxSize int64, maxBackups int) (*rotatingLogWriter, error) {
+	w := &rotatingLogWriter{fileName: fileName, maxSize: maxSize, maxBackups: maxBackups}
+	if err := w.open(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+func (w *rotatingLogWriter) open() error {
+	file, err := os.OpenFile(w.fileName, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0640)
+	if err != nil {
+		return err
+	}
+	stat, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+	w.file = file
+	w.size = stat.Size()
+	return nil
+}
+
+func (w *rotatingLogWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.file == nil {
+		return 0, os.ErrClosed
+	}
+	if w.size > 0 && w.size+int64(len(p)) > w.maxSize {
+		if err := w.rotate(); err != nil && w.file == nil {
+			return 0, err
+		}
+	}
+	n, err := w.file.Write(p)
+	w.size += int64(n)
+	return n, err
+}
+
+func (w *rotatingLogWriter) rotate() error {
+	w.file.Close()
+	w.file = nil
+	renameErr := w.renameBackups()
+	if err := w.open(); err != nil {
+		return err
+	}
+	return renameErr
+}
+
+func (w *rotatingLogWriter) renameBackups() error {
+	for i := w.maxBackups - 1; i > 0; i-- {
+		err := os.Rename(w.backupFileName(i), w.backupFileName(i+1))
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+	return os.Rename(w.fileName, w.backupFileName(1))
+}
+
+func (w *rotatingLogWriter) backupFileName(i int) string {
+	return fmt.Sprintf("%s.%d", w.fileName, i)
+}
+
+// Close closes the current log file.
+func (w *rotatingLogWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.file == nil {
+		return nil
+	}
+	err := w.file.Close()
+	w.file = nil
+	return err
+}
+
+// clientConfigWatchInterval is how often the client config file is checked for changes.
+var clientConfigWatchInterval = 10 * time.Second
+
+// clientConfigWatcher reconfigures Easy Logging when the modification time or the size
+// of the client config file changes. A removed file keeps the current configuration.
+type clientConfigWatcher struct {
+	filePath string
+	modTime  time.Time
+	size     int64
+	stopChan chan struct{}
+}
+
+func newClientConfigWatcher(filePath string) *clientConfigWatcher {
+	w := &clientConfigWatcher{filePath: filePath, stopChan: make(chan struct{})}
+	w.modTime, w.size = w.stat()
+	go w.run(clientConfigWatchInterval)
+	return w
+}
+
+func (w *clientConfigWatcher) stat() (time.Time, int64) {
+	stat, err := os.Stat(w.filePath)
+	if err != nil {
+		return time.Time{}, -1
+	}
+	return stat.ModTime(), stat.Size()
+}
+
+func (w *clientConfigWatcher) run(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-w.stopChan:
+			return
+		case <-ticker.C:
+			modTime, size := w.stat()
+			if size < 0 || (modTime.Equal(w.modTime) && size == w.size) {
+				continue
+			}
+			w.modTime, w.size = modTime, size
+			w.reload()
+		}
+	}
+}
+
+// stop does not wait for a reload in progress, which gives up as the watcher is not the current one anymore.
+func (w *clientConfigWatcher) stop() {
+	close(w.stopChan)
+}
+
+func (w *clientConfigWatcher) reload() {
+	easyLoggingInitTrials.mu.Lock()
+	defer easyLoggingInitTrials.mu.Unlock()
+	if easyLoggingInitTrials.watcher != w {
+		return
+	}
+	if logger != easyLoggingInitTrials.installedLogger {
+		logger.Infof("Client config file %s changed, but the logger was replaced by SetLogger, so Easy Logging is not reconfigured", w.filePath)
+		return
+	}
+	logger.Infof("Client config file %s changed, reconfiguring Easy Logging", w.filePath)
+	config, err := parseClientConfiguration(w.filePath)
+	if err != nil {
+		logger.Errorf("Failed to reconfigure Easy Logging, keeping the current configuration, err: %s", err)
+		return
+	}
+	settings, err := getEasyLoggingSettings(config.Common)
+	if err != nil {
+		logger.Errorf("Failed to reconfigure Easy Logging, keeping the current configuration, err: %s", err)
+		return
+	}
+	if err = reconfigureEasyLogging(settings); err != nil {
+		logger.Errorf("Failed to reconfigure Easy Logging, err: %s", err)
+		return
+	}
+	easyLoggingInitTrials.increaseReconfigureCounter()
+}
+
 func allowedToInitialize(clientConfigFileInput string) bool {
 	triedToInitializeWithoutConfigFile := easyLoggingInitTrials.everTriedToInitialize && easyLoggingInitTrials.clientConfigFileInput == ""
 	isAllowedToInitialize := !easyLoggingInitTrials.everTriedToInitialize || (triedToInitializeWithoutConfigFile && clientConfigFileInput != "")
@@ -1,7 +1,9 @@
 package gosnowflake
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"os"
 	"path"
@@ -9,6 +11,9 @@ import (
 	"strings"
 	"sync"
 	"testing"
+	"time"
+
+	rlog "github.com/sirupsen/logrus"
 )
 
 func TestInitializeEasyLoggingOnlyOnceWhenConfigGivenAsAParameter(t *testing.T) {
@@ -185,6 +190,149 @@ func TestDataRace(t *testing.T) {
 	wg.Wait()
 }
 
+func TestLogToConfiguredFileWithOptions(t *testing.T) {
+	defer cleanUp()
+	dir := t.TempDir()
+	easyLoggingInitTrials.reset()
+	configContent := fmt.Sprintf(`{
+		"common": {
+			"log_level": "ERROR",
+			"log_path": "%s",
+			"log_format": "json",
+			"log_levels": {"ocsp": "DEBUG", "retry": "OFF", "unknown": "TRACE"},
+			"mask_patterns": [{"pattern": "employee-[0-9]+"}, {"pattern": "(ssn=)[0-9-]+", "replacement": "${1}xxx"}]
+		}
+	}`, strings.ReplaceAll(dir, "\\", "\\\\"))
+	configFilePath := createFile(t, "config.json", configContent, dir)
+	err := initEasyLogging(configFilePath)
+	assertNilF(t, err, "init easy logging error")
+
+	ocspLogger().Debug("Debug message of employee-123 with ssn=123-45-6789")
+	ocspLogger().Trace("Trace message")
+	getModuleLogger(nil, logModuleRetry).WithContext(context.Background()).Error("Error message of a module with logging switched off")
+	logger.Debug("Debug message of another module")
+	_, err = findClientConfigFilePath(configFilePath, nil) // logs an info message in another module
+	assertNilF(t, err)
+
+	logContents, err := os.ReadFile(path.Join(dir, "go", "snowflake.log"))
+	assertNilF(t, err, "read file error")
+	logs := notEmptyLines(string(logContents))
+	assertEqualF(t, len(logs), 1, "number of logs")
+	var entry map[string]interface{}
+	assertNilF(t, json.Unmarshal([]byte(logs[0]), &entry))
+	assertEqualE(t, entry["level"], "debug")
+	assertEqualE(t, entry["msg"], "Debug message of **** with ssn=xxx")
+}
+
+func TestReconfigureEasyLoggingWhenConfigFileChanges(t *testing.T) {
+	defer cleanUp()
+	defer func(interval time.Duration) { clientConfigWatchInterval = interval }(clientConfigWatchInterval)
+	clientConfigWatchInterval = 10 * time.Millisecond
+	dir := t.TempDir()
+	easyLoggingInitTrials.reset()
+	configFilePath := createFile(t, "config.json", createClientConfigContent(levelError, dir), dir)
+	err := initEasyLogging(configFilePath)
+	assertNilF(t, err, "init easy logging error")
+	assertEqualE(t, toClientConfigLevel(logger.GetLogLevel()), levelError)
+
+	assertNilF(t, os.WriteFile(configFilePath, []byte(createClientConfigContent("something weird", dir)), 0600))
+	time.Sleep(100 * time.Millisecond)
+	assertEqualE(t, toClientConfigLevel(logger.GetLogLevel()), levelError, "invalid config should be ignored")
+
+	assertNilF(t, os.WriteFile(configFilePath, []byte(createClientConfigContent(levelDebug, dir)), 0600))
+	assertTrueF(t, waitForCondition(func() bool {
+		easyLoggingInitTrials.mu.Lock()
+		defer easyLoggingInitTrials.mu.Unlock()
+		return easyLoggingInitTrials.configureCounter == 2
+	}, time.Second), "config should be reloaded")
+	assertEqualE(t, toClientConfigLevel(logger.GetLogLevel()), levelDebug)
+}
+
+func TestDoNotReconfigureEasyLoggingWhenLoggerWasReplaced(t *testing.T) {
+	defer cleanUp()
+	defer func(interval time.Duration) { clientConfigWatchInterval = interval }(clientConfigWatchInterval)
+	clientConfigWatchInterval = 10 * time.Millisecond
+	dir := t.TempDir()
+	easyLoggingInitTrials.reset()
+	configFilePath := createFile(t, "config.json", createClientConfigContent(levelError, dir), dir)
+	err := initEasyLogging(configFilePath)
+	assertNilF(t, err, "init easy logging error")
+	appLogger := CreateDefaultLogger()
+	// the watcher reads the logger with the lock held
+	easyLoggingInitTrials.mu.Lock()
+	SetLogger(&appLogger)
+	easyLoggingInitTrials.mu.Unlock()
+
+	assertNilF(t, os.WriteFile(configFilePath, []byte(createClientConfigContent(levelDebug, dir)), 0600))
+	time.Sleep(100 * time.Millisecond)
+	easyLoggingInitTrials.mu.Lock()
+	defer easyLoggingInitTrials.mu.Unlock()
+	assertEqualE(t, logger, appLogger, "the logger of the application should be kept")
+	assertEqualE(t, easyLoggingInitTrials.configureCounter, 1)
+}
+
+func TestRotatingLogWriter(t *testing.T) {
+	fileName := path.Join(t.TempDir(), "snowflake.log")
+	writer, err := newRotatingLogWriter(fileName, 10, 2)
+	assertNilF(t, err)
+	for _, line := range []string{"first\n", "second\n", "third\n", "fourth\n"} {
+		_, err = writer.Write([]byte(line))
+		assertNilF(t, err)
+	}
+	assertNilF(t, writer.Close())
+
+	for name, expected := range map[string]string{
+		fileName:        "fourth\n",
+		fileName + ".1": "third\n",
+		fileName + ".2": "second\n",
+	} {
+		contents, err := os.ReadFile(name)
+		assertNilF(t, err)
+		assertEqualE(t, string(contents), expected, name)
+	}
+	_, err = os.Stat(fileName + ".3")
+	assertTrueE(t, os.IsNotExist(err), "only two backups should be kept")
+}
+
+func TestModuleLoggers(t *testing.T) {
+	defer cleanUp()
+	var buf bytes.Buffer
+	hook := &countingHook{}
+	err := reconfigureEasyLogging(&easyLoggingSettings{logLevel: levelWarn, logPath: "STDOUT", logFormat: logFormatText,
+		moduleLevels: map[string]string{logModuleOCSP: levelDebug, logModuleRetry: levelError}})
+	assertNilF(t, err)
+	logger.SetOutput(&buf)
+	logger.(*defaultLogger).AddHook(hook)
+
+	ocspLogger().Debug("debug of ocsp")
+	getModuleLogger(nil, logModuleRetry).Warn("warning of retry")
+	getModuleLogger(nil, logModuleChunkDownloader).Warn("warning of chunk downloader")
+	getModuleLogger(nil, logModuleChunkDownloader).Info("info of chunk downloader")
+
+	output := buf.String()
+	assertStringContainsE(t, output, "debug of ocsp")
+	assertFalseE(t, strings.Contains(output, "warning of retry"), "retry logs only errors")
+	assertStringContainsE(t, output, "warning of chunk downloader")
+	assertFalseE(t, strings.Contains(output, "info of chunk downloader"), "modules without a level follow the global one")
+	assertEqualE(t, hook.count, 2, "hooks should not fire for filtered entries")
+
+	cfg := &Config{Logger: CreateDefaultLogger()}
+	assertEqualE(t, getModuleLogger(cfg, logModuleOCSP), cfg.Logger, "the connection logger takes precedence")
+}
+
+type countingHook struct {
+	count int
+}
+
+func (h *countingHook) Levels() []rlog.Level {
+	return rlog.AllLevels
+}
+
+func (h *countingHook) Fire(*rlog.Entry) error {
+	h.count++
+	return nil
+}
+
 func notEmptyLines(lines string) []string {
 	notEmptyFunc := func(val string) bool {
 		return val != ""
@@ -195,7 +343,9 @@ func notEmptyLines(lines string) []string {
 func cleanUp() {
 	newLogger := CreateDefaultLogger()
 	logger.Replace(&newLogger)
+	setModuleLoggers(nil)
 	easyLoggingInitTrials.reset()
+	setClientConfigMaskingRules(nil)
 }
 
 func toClientConfigLevel(logLevel string) string {
@@ -241,4 +391,6 @@ func (i *initTrials) reset() {
 	i.everTriedToInitialize = false
 	i.clientConfigFileInput = ""
 	i.configureCounter = 0
+	i.installedLogger = nil
+	i.stopWatching()
 }
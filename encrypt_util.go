@@ -7,6 +7,7 @@ import (
 	"crypto/rand"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"os"
@@ -37,61 +38,39 @@ func encryptStreamCBC(
 	src io.Reader,
 	out io.Writer,
 	chunkSize int) (*encryptMetadata, error) {
-	if chunkSize == 0 {
-		chunkSize = aes.BlockSize * 4 * 1024
+	mode, meta, err := initEncryptionCBC(sfe)
+	if err != nil {
+		return nil, err
 	}
+	if err = encryptCBC(mode, src, out, chunkSize); err != nil {
+		return nil, err
+	}
+	return meta, nil
+}
+
+// initEncryptionCBC generates a random file key and IV and returns the CBC encrypter
+// together with the metadata describing the wrapped key
+func initEncryptionCBC(sfe *snowflakeFileEncryption) (cipher.BlockMode, *encryptMetadata, error) {
 	kek, err := base64.StdEncoding.DecodeString(sfe.QueryStageMasterKey)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	keySize := len(kek)
 
 	fileKey := getSecureRandom(keySize)
 	block, err := aes.NewCipher(fileKey)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	dataIv := getSecureRandom(block.BlockSize())
 
 	mode := cipher.NewCBCEncrypter(block, dataIv)
-	cipherText := make([]byte, chunkSize)
-	chunk := make([]byte, chunkSize)
-
-	// encrypt file with CBC
-	padded := false
-	for {
-		// read the stream buffer up to len(chunk) bytes into chunk
-		// note that all spaces in chunk may be used even if Read() returns n < len(chunk)
-		n, err := src.Read(chunk)
-		if n == 0 || err != nil {
-			break
-		} else if n%aes.BlockSize != 0 {
-			// add padding to the end of the chunk and update the length n
-			chunk = padBytesLength(chunk[:n], aes.BlockSize)
-			n = len(chunk)
-			padded = true
-		}
-		// make sure only n bytes of chunk is used
-		mode.CryptBlocks(cipherText, chunk[:n])
-		if _, err := out.Write(cipherText[:n]); err != nil {
-			return nil, err
-		}
-	}
-
-	// add padding if not yet added
-	if !padded {
-		padding := bytes.Repeat([]byte(string(rune(aes.BlockSize))), aes.BlockSize)
-		mode.CryptBlocks(cipherText, padding)
-		if _, err := out.Write(cipherText[:len(padding)]); err != nil {
-			return nil, err
-		}
-	}
 
 	// encrypt key with ECB
 	fileKey = padBytesLength(fileKey, block.BlockSize())
 	encryptedFileKey := make([]byte, len(fileKey))
 	if err = encryptECB(encryptedFileKey, fileKey, kek); err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	matDesc := materialDescriptor{
@@ -102,15 +81,60 @@ func encryptStreamCBC(
 
 	matDescUnicode, err := matdescToUnicode(matDesc)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
-	return &encryptMetadata{
+	return mode, &encryptMetadata{
 		base64.StdEncoding.EncodeToString(encryptedFileKey),
 		base64.StdEncoding.EncodeToString(dataIv),
 		matDescUnicode,
 	}, nil
 }
 
+// encryptCBC encrypts src into out chunk by chunk, padding the last block
+func encryptCBC(mode cipher.BlockMode, src io.Reader, out io.Writer, chunkSize int) error {
+	if chunkSize == 0 {
+		chunkSize = aes.BlockSize * 4 * 1024
+	}
+	cipherText := make([]byte, chunkSize+aes.BlockSize)
+	chunk := make([]byte, chunkSize)
+
+	// encrypt file with CBC
+	padded := false
+	for {
+		// fill the whole chunk so that padding is only ever added at the end of the stream
+		n, err := io.ReadFull(src, chunk)
+		if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+			return err
+		}
+		if n == 0 {
+			break
+		}
+		data := chunk[:n]
+		if n%aes.BlockSize != 0 {
+			// add padding to the end of the chunk
+			data = padBytesLength(data, aes.BlockSize)
+			padded = true
+		}
+		mode.CryptBlocks(cipherText, data)
+		if _, err := out.Write(cipherText[:len(data)]); err != nil {
+			return err
+		}
+		if err != nil {
+			break
+		}
+	}
+
+	// add padding if not yet added
+	if !padded {
+		padding := bytes.Repeat([]byte(string(rune(aes.BlockSize))), aes.BlockSize)
+		mode.CryptBlocks(cipherText, padding)
+		if _, err := out.Write(cipherText[:len(padding)]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func encryptECB(encrypted []byte, fileKey []byte, decodedKey []byte) error {
 	block, err := aes.NewCipher(decodedKey)
 	if err != nil {
@@ -154,13 +178,52 @@ func encryptFileCBC(
 	filename string,
 	chunkSize int,
 	tmpDir string) (
-	meta *encryptMetadata, fileName string, err error) {
+	*encryptMetadata, string, error) {
+	mode, meta, err := initEncryptionCBC(sfe)
+	if err != nil {
+		return nil, "", err
+	}
+	fileName, err := encryptFileWithModeCBC(mode, filename, chunkSize, tmpDir)
+	if err != nil {
+		return nil, "", err
+	}
+	return meta, fileName, nil
+}
+
+// reencryptFileCBC encrypts the file with the file key and IV of existing encryption metadata,
+// which produces the same encrypted content as the original encryption of the same file
+func reencryptFileCBC(
+	sfe *snowflakeFileEncryption,
+	meta *encryptMetadata,
+	filename string,
+	chunkSize int,
+	tmpDir string) (string, error) {
+	fileKey, iv, err := decryptFileKeyECB(meta, sfe)
+	if err != nil {
+		return "", err
+	}
+	block, err := aes.NewCipher(fileKey)
+	if err != nil {
+		return "", err
+	}
+	if len(iv) != block.BlockSize() {
+		return "", errors.New("invalid IV length in encryption metadata")
+	}
+	return encryptFileWithModeCBC(cipher.NewCBCEncrypter(block, iv), filename, chunkSize, tmpDir)
+}
+
+func encryptFileWithModeCBC(
+	mode cipher.BlockMode,
+	filename string,
+	chunkSize int,
+	tmpDir string) (
+	fileName string, err error) {
 	if chunkSize == 0 {
 		chunkSize = aes.BlockSize * 4 * 1024
 	}
 	tmpOutputFile, err := os.CreateTemp(tmpDir, baseName(filename)+"#")
 	if err != nil {
-		return nil, "", err
+		return "", err
 	}
 	defer func() {
 		if tmpErr := tmpOutputFile.Close(); tmpErr != nil && err == nil {
@@ -169,7 +232,7 @@ func encryptFileCBC(
 	}()
 	infile, err := os.OpenFile(filename, os.O_CREATE|os.O_RDONLY, readWriteFileMode)
 	if err != nil {
-		return nil, "", err
+		return "", err
 	}
 	defer func() {
 		if tmpErr := infile.Close(); tmpErr != nil && err == nil {
@@ -177,11 +240,10 @@ func encryptFileCBC(
 		}
 	}()
 
-	meta, err = encryptStreamCBC(sfe, infile, tmpOutputFile, chunkSize)
-	if err != nil {
-		return nil, "", err
+	if err = encryptCBC(mode, infile, tmpOutputFile, chunkSize); err != nil {
+		return "", err
 	}
-	return meta, tmpOutputFile.Name(), err
+	return tmpOutputFile.Name(), err
 }
 
 func decryptFileKeyECB(
@@ -303,6 +365,80 @@ func decryptStreamCBC(
 	return totalFileSize, err
 }
 
+// cbcDecryptingReader decrypts a stream encrypted with AES CBC as it is read. The last decrypted block
+// is held back until the end of the stream, when its padding is removed.
+type cbcDecryptingReader struct {
+	src       io.Reader
+	mode      cipher.BlockMode
+	chunkSize int
+	buf       []byte
+	last      []byte
+	eof       bool
+}
+
+func newDecryptingReaderCBC(
+	metadata *encryptMetadata,
+	sfe *snowflakeFileEncryption,
+	chunkSize int,
+	src io.Reader) (io.Reader, error) {
+	if chunkSize == 0 {
+		chunkSize = aes.BlockSize * 4 * 1024
+	}
+	decryptedKey, ivBytes, err := decryptFileKeyECB(metadata, sfe)
+	if err != nil {
+		return nil, err
+	}
+	mode, err := initCBC(decryptedKey, ivBytes)
+	if err != nil {
+		return nil, err
+	}
+	return &cbcDecryptingReader{src: src, mode: mode, chunkSize: chunkSize}, nil
+}
+
+func (r *cbcDecryptingReader) Read(p []byte) (int, error) {
+	for len(r.buf) == 0 {
+		if r.eof {
+			return 0, io.EOF
+		}
+		if err := r.decryptChunk(); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, r.buf)
+	r.buf = r.buf[n:]
+	return n, nil
+}
+
+func (r *cbcDecryptingReader) decryptChunk() error {
+	chunk := make([]byte, len(r.last)+r.chunkSize)
+	copy(chunk, r.last)
+	n, err := io.ReadFull(r.src, chunk[len(r.last):])
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		r.eof = true
+	} else if err != nil {
+		return err
+	}
+	if n%aes.BlockSize != 0 {
+		return &SnowflakeError{
+			Number:  ErrInvalidPadding,
+			Message: errMsgInvalidPadding,
+		}
+	}
+	encrypted := chunk[len(r.last) : len(r.last)+n]
+	r.mode.CryptBlocks(encrypted, encrypted)
+	chunk = chunk[:len(r.last)+n]
+	if !r.eof {
+		r.buf, r.last = chunk[:len(chunk)-aes.BlockSize], chunk[len(chunk)-aes.BlockSize:]
+		return nil
+	}
+	r.last = nil
+	if len(chunk) == 0 {
+		return nil
+	}
+	r.buf, err = paddingTrim(chunk)
+	return err
+}
+
 func encryptGCM(iv []byte, plaintext []byte, encryptionKey []byte, aad []byte) ([]byte, error) {
 	aead, err := initGcm(encryptionKey)
 	if err != nil {
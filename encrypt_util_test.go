@@ -2,6 +2,7 @@ package gosnowflake
 
 import (
 	"bufio"
+	"bytes"
 	"compress/gzip"
 	"encoding/base64"
 	"fmt"
@@ -13,6 +14,7 @@ import (
 	"path/filepath"
 	"strconv"
 	"testing"
+	"testing/iotest"
 	"time"
 )
 
@@ -67,6 +69,28 @@ func TestEncryptDecryptFileCBC(t *testing.T) {
 	}
 }
 
+func TestReencryptFileCBC(t *testing.T) {
+	encMat := snowflakeFileEncryption{
+		"ztke8tIdVt1zmlQIZm0BMA==",
+		"123873c7-3a66-40c4-ab89-e3722fbccce1",
+		3112,
+	}
+	tmpDir := t.TempDir()
+	inputFile := filepath.Join(tmpDir, "test_reencrypt_file")
+	assertNilF(t, os.WriteFile(inputFile, bytes.Repeat([]byte("test data"), 10000), readWriteFileMode))
+
+	metadata, encryptedFile, err := encryptFileCBC(&encMat, inputFile, 0, tmpDir)
+	assertNilF(t, err)
+	reencryptedFile, err := reencryptFileCBC(&encMat, metadata, inputFile, 0, tmpDir)
+	assertNilF(t, err)
+
+	encrypted, err := os.ReadFile(encryptedFile)
+	assertNilF(t, err)
+	reencrypted, err := os.ReadFile(reencryptedFile)
+	assertNilF(t, err)
+	assertBytesEqualE(t, reencrypted, encrypted, "content encrypted with the same key and IV should not change")
+}
+
 func TestEncryptDecryptFilePadding(t *testing.T) {
 	encMat := snowflakeFileEncryption{
 		"ztke8tIdVt1zmlQIZm0BMA==",
@@ -286,3 +310,27 @@ func TestEncryptDecryptFileGCM(t *testing.T) {
 
 	assertEqualE(t, string(fileContent), "abc")
 }
+
+func TestDecryptingReaderCBC(t *testing.T) {
+	encMat := snowflakeFileEncryption{
+		"ztke8tIdVt1zmlQIZm0BMA==",
+		"123873c7-3a66-40c4-ab89-e3722fbccce1",
+		3112,
+	}
+	for _, size := range []int{0, 1, 15, 16, 17, 64, 1000, 65536 + 7} {
+		t.Run(strconv.Itoa(size), func(t *testing.T) {
+			data := make([]byte, size)
+			for i := range data {
+				data[i] = byte(i % 251)
+			}
+			var encrypted bytes.Buffer
+			metadata, err := encryptStreamCBC(&encMat, bytes.NewReader(data), &encrypted, 0)
+			assertNilF(t, err)
+			r, err := newDecryptingReaderCBC(metadata, &encMat, 64, iotest.OneByteReader(&encrypted))
+			assertNilF(t, err)
+			decrypted, err := io.ReadAll(r)
+			assertNilF(t, err)
+			assertBytesEqualE(t, decrypted, data)
+		})
+	}
+}
@@ -22,6 +22,7 @@ func (se *SnowflakeError) Error() string {
 	if len(se.MessageArgs) > 0 {
 		message = fmt.Sprintf(se.Message, se.MessageArgs...)
 	}
+	message = maskSecrets(message)
 	if se.SQLState != "" {
 		if se.IncludeQueryID {
 			return fmt.Sprintf("%06d (%s): %s: %s", se.Number, se.SQLState, se.QueryID, message)
@@ -199,6 +200,14 @@ const (
 	ErrNotImplemented = 264011
 	// ErrInvalidPadding is an error code denoting the invalid padding of decryption key
 	ErrInvalidPadding = 264012
+	// ErrInvalidFileTransferPattern is an error code denoting a malformed pattern of the files to be uploaded
+	ErrInvalidFileTransferPattern = 264013
+	// ErrDownloadPathOutsideDirectory is an error code denoting a stage file path which leaves the local directory of GET
+	ErrDownloadPathOutsideDirectory = 264014
+	// ErrChecksumMismatch is an error code denoting a transferred file whose checksum differs from the checksum on the stage
+	ErrChecksumMismatch = 264015
+	// ErrSyncDeleteMultipleSources is an error code denoting a sync of several sources which would remove each other's files
+	ErrSyncDeleteMultipleSources = 264016
 
 	/* binding */
 
@@ -206,6 +215,8 @@ const (
 	ErrBindSerialization = 265001
 	// ErrBindUpload is an error code for the uploading process of bind elements to the stage
 	ErrBindUpload = 265002
+	// ErrBindTypeMismatch is an error code for bind variables which are not compatible with the target columns
+	ErrBindTypeMismatch = 265003
 
 	/* async */
 
@@ -232,6 +243,8 @@ const (
 	ErrNullValueInArray = 268004
 	// ErrNullValueInMap is an error code for the case where there are null values in a map without mapValuesNullable set to true
 	ErrNullValueInMap = 268005
+	// ErrInvalidTypeMapping is an error code for the case where a type converter cannot be registered
+	ErrInvalidTypeMapping = 268006
 
 	/* OCSP */
 
@@ -311,6 +324,20 @@ const (
 	errMsgFailedToFindDSNInTomlFile          = "failed to find DSN in toml file."
 	errMsgInvalidPermissionToTomlFile        = "file permissions different than read/write for user. Your Permission: %v"
 	errMsgNonArrowResponseInArrowBatches     = "arrow batches enabled, but the response is not Arrow based"
+	errMsgArrowBindUnsupportedType           = "arrow column %v has a type not supported for binding: %v"
+	errMsgArrowBindColumnCountMismatch       = "number of arrow columns (%v) doesn't match the number of binds (%v)"
+	errMsgArrowBindTypeMismatch              = "arrow column %v of type %v cannot be bound to a %v column"
+	errMsgArrowBindNotSingle                 = "an arrow record or record reader must be the only bind, got %v binds"
+	errMsgUnsupportedTypeMapping             = "type mapping is not supported for type: %v"
+	errMsgTypeConverterIncomplete            = "type converter must have both ScanType and Convert set"
+	errMsgFileTransferSourceMissing          = "no file to upload, set Sources or Stream with StreamName"
+	errMsgInvalidFileTransferPattern         = "invalid file pattern: %v"
+	errMsgDownloadPathOutsideDirectory       = "stage file %v cannot be written inside the local directory"
+	errMsgAutoCompressionNotSupported        = "auto compression with %v is not supported, use GZIP, ZSTD or BROTLI"
+	errMsgInvalidCompressionLevel            = "invalid %v compression level: %v"
+	errMsgInvalidSourceCompression           = "invalid source compression: %v"
+	errMsgChecksumMismatch                   = "checksum mismatch of file %v: expected %v, got %v"
+	errMsgSyncDeleteMultipleSources          = "SyncDelete requires a single source, got %v"
 )
 
 // Returned if a DNS doesn't include account parameter.
@@ -391,3 +418,89 @@ func errNonArrowResponseForArrowBatches(queryID string) *SnowflakeError {
 		Message: errMsgNonArrowResponseInArrowBatches,
 	}
 }
+
+func errArrowBindNotSingle(count int) *SnowflakeError {
+	return &SnowflakeError{
+		Number:      ErrBindSerialization,
+		Message:     errMsgArrowBindNotSingle,
+		MessageArgs: []interface{}{count},
+	}
+}
+
+func errUnsupportedTypeMapping(sfType string) *SnowflakeError {
+	return &SnowflakeError{
+		Number:      ErrInvalidTypeMapping,
+		Message:     errMsgUnsupportedTypeMapping,
+		MessageArgs: []interface{}{sfType},
+	}
+}
+
+func errTypeConverterIncomplete() *SnowflakeError {
+	return &SnowflakeError{
+		Number:  ErrInvalidTypeMapping,
+		Message: errMsgTypeConverterIncomplete,
+	}
+}
+
+func errFileTransferSourceMissing() *SnowflakeError {
+	return &SnowflakeError{
+		Number:  ErrFileNotExists,
+		Message: errMsgFileTransferSourceMissing,
+	}
+}
+
+func errInvalidFileTransferPattern(pattern string) *SnowflakeError {
+	return &SnowflakeError{
+		Number:      ErrInvalidFileTransferPattern,
+		Message:     errMsgInvalidFileTransferPattern,
+		MessageArgs: []interface{}{pattern},
+	}
+}
+
+func errDownloadPathOutsideDirectory(fileName string) *SnowflakeError {
+	return &SnowflakeError{
+		Number:      ErrDownloadPathOutsideDirectory,
+		Message:     errMsgDownloadPathOutsideDirectory,
+		MessageArgs: []interface{}{fileName},
+	}
+}
+
+func errAutoCompressionNotSupported(compression string) *SnowflakeError {
+	return &SnowflakeError{
+		Number:      ErrCompressionNotSupported,
+		Message:     errMsgAutoCompressionNotSupported,
+		MessageArgs: []interface{}{compression},
+	}
+}
+
+func errInvalidCompressionLevel(compression string, level int) *SnowflakeError {
+	return &SnowflakeError{
+		Number:      ErrCompressionNotSupported,
+		Message:     errMsgInvalidCompressionLevel,
+		MessageArgs: []interface{}{compression, level},
+	}
+}
+
+func errInvalidSourceCompression(compression string) *SnowflakeError {
+	return &SnowflakeError{
+		Number:      ErrCompressionNotSupported,
+		Message:     errMsgInvalidSourceCompression,
+		MessageArgs: []interface{}{compression},
+	}
+}
+
+func errChecksumMismatch(fileName string, expected string, actual string) *SnowflakeError {
+	return &SnowflakeError{
+		Number:      ErrChecksumMismatch,
+		Message:     errMsgChecksumMismatch,
+		MessageArgs: []interface{}{fileName, expected, actual},
+	}
+}
+
+func errSyncDeleteMultipleSources(sources int) *SnowflakeError {
+	return &SnowflakeError{
+		Number:      ErrSyncDeleteMultipleSources,
+		Message:     errMsgSyncDeleteMultipleSources,
+		MessageArgs: []interface{}{sources},
+	}
+}
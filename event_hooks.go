@@ -0,0 +1,99 @@
+package gosnowflake
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// EventHooks receives structured events of a connection, e.g. to alert on slow queries or on
+// HTTP retries piling up without parsing the logs. Its methods are called concurrently and
+// synchronously with the driver, so they should return quickly. Set it in Config.EventHooks.
+// Embed NoopEventHooks to implement only some of the methods.
+type EventHooks interface {
+	// OnQueryStart is called when a statement of the application is sent to Snowflake. It is not called for
+	// the internal requests of the driver nor for the describe requests of Prepare.
+	OnQueryStart(ctx context.Context, event QueryStartEvent)
+	// OnQueryFinish is called when the statement finishes, including polling for its result.
+	OnQueryFinish(ctx context.Context, event QueryFinishEvent)
+	// OnRetry is called before an HTTP request is retried.
+	OnRetry(ctx context.Context, event RetryEvent)
+	// OnSessionRenewal is called when the expired session token has been renewed or failed to be renewed.
+	OnSessionRenewal(ctx context.Context, event SessionRenewalEvent)
+}
+
+// QueryStartEvent is the event of EventHooks.OnQueryStart.
+type QueryStartEvent struct {
+	SQLText   string // SQL text with the secrets masked
+	RequestID string
+	StartTime time.Time
+}
+
+// QueryFinishEvent is the event of EventHooks.OnQueryFinish with the summary of the response.
+type QueryFinishEvent struct {
+	QueryHistoryEntry
+	Duration time.Duration
+	Chunks   int   // number of result chunks to download
+	Err      error // error of the statement, if any
+}
+
+// RetryEvent is the event of EventHooks.OnRetry.
+type RetryEvent struct {
+	URLPath    string
+	StatusCode int           // HTTP status code of the failed attempt, 0 for connection errors
+	Reason     string        // HTTP status text or the connection error with the secrets masked
+	Attempt    int           // number of the retry, starting with 1
+	Sleep      time.Duration // time to wait before the retry
+}
+
+// SessionRenewalEvent is the event of EventHooks.OnSessionRenewal.
+type SessionRenewalEvent struct {
+	StartTime time.Time
+	Duration  time.Duration
+	Err       error // error of the renewal, if any
+}
+
+// NoopEventHooks ignores all events. Embed it in an EventHooks implementation to handle only some of them.
+type NoopEventHooks struct{}
+
+// OnQueryStart implements EventHooks.
+func (NoopEventHooks) OnQueryStart(context.Context, QueryStartEvent) {}
+
+// OnQueryFinish implements EventHooks.
+func (NoopEventHooks) OnQueryFinish(context.Context, QueryFinishEvent) {}
+
+// OnRetry implements EventHooks.
+func (NoopEventHooks) OnRetry(context.Context, RetryEvent) {}
+
+// OnSessionRenewal implements EventHooks.
+func (NoopEventHooks) OnSessionRenewal(context.Context, SessionRenewalEvent) {}
+
+// getEventHooks returns the EventHooks of the connection or hooks ignoring the events.
+func getEventHooks(cfg *Config) EventHooks {
+	if cfg != nil && cfg.EventHooks != nil {
+		return cfg.EventHooks
+	}
+	return NoopEventHooks{}
+}
+
+// newQueryFinishEvent returns the event of a statement which finished with data or err.
+func newQueryFinishEvent(entry QueryHistoryEntry, data *execResponse, err error) QueryFinishEvent {
+	event := QueryFinishEvent{QueryHistoryEntry: entry, Duration: entry.EndTime.Sub(entry.StartTime), Err: err}
+	if data != nil {
+		event.Chunks = len(data.Data.Chunks)
+	}
+	return event
+}
+
+// newRetryEvent returns the event of a retry of an HTTP request which failed with res or err.
+func newRetryEvent(urlPath string, res *http.Response, err error, attempt int, sleep time.Duration) RetryEvent {
+	event := RetryEvent{URLPath: urlPath, Attempt: attempt, Sleep: sleep}
+	if res != nil {
+		event.StatusCode = res.StatusCode
+		event.Reason = http.StatusText(res.StatusCode)
+	}
+	if err != nil {
+		event.Reason = maskSecrets(err.Error())
+	}
+	return event
+}
@@ -0,0 +1,129 @@
+package gosnowflake
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+)
+
+type testEventHooks struct {
+	NoopEventHooks
+	mu              sync.Mutex
+	queryStarts     []QueryStartEvent
+	queryFinishes   []QueryFinishEvent
+	retries         []RetryEvent
+	sessionRenewals []SessionRenewalEvent
+}
+
+func (h *testEventHooks) OnQueryStart(_ context.Context, event QueryStartEvent) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.queryStarts = append(h.queryStarts, event)
+}
+
+func (h *testEventHooks) OnQueryFinish(_ context.Context, event QueryFinishEvent) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.queryFinishes = append(h.queryFinishes, event)
+}
+
+func (h *testEventHooks) OnRetry(_ context.Context, event RetryEvent) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.retries = append(h.retries, event)
+}
+
+func (h *testEventHooks) OnSessionRenewal(_ context.Context, event SessionRenewalEvent) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.sessionRenewals = append(h.sessionRenewals, event)
+}
+
+func TestQueryEventHooks(t *testing.T) {
+	hooks := &testEventHooks{}
+	postQueryMock := func(ctx context.Context, _ *snowflakeRestful, _ *url.Values, _ map[string]string,
+		_ []byte, _ time.Duration, _ UUID, _ *Config) (*execResponse, error) {
+		countQueryRetry(ctx)
+		return &execResponse{
+			Data: execResponseData{
+				QueryID:           "01b2c3",
+				Total:             5,
+				QueryResultFormat: "arrow",
+				Chunks:            []execResponseChunk{{UncompressedSize: 100}, {UncompressedSize: 200}},
+			},
+			Code:    "0",
+			Success: true,
+		}, nil
+	}
+	sc := &snowflakeConn{
+		cfg:               &Config{Params: map[string]*string{}, EventHooks: hooks},
+		rest:              &snowflakeRestful{FuncPostQuery: postQueryMock},
+		queryContextCache: (&queryContextCache{}).init(),
+	}
+	requestID := NewUUID()
+	_, err := sc.exec(WithRequestID(context.Background(), requestID),
+		"create user testuser password='testpassword'", false, false, false, nil)
+	assertNilF(t, err)
+	_, err = sc.exec(context.Background(), "select 1", false, true /* isInternal */, false, nil)
+	assertNilF(t, err)
+	_, err = sc.exec(context.Background(), "select ?", false, false, true /* describeOnly */, nil)
+	assertNilF(t, err)
+
+	assertEqualF(t, len(hooks.queryStarts), 1)
+	assertEqualE(t, hooks.queryStarts[0].SQLText, "create user testuser password='****")
+	assertEqualE(t, hooks.queryStarts[0].RequestID, requestID.String())
+	assertEqualF(t, len(hooks.queryFinishes), 1)
+	finish := hooks.queryFinishes[0]
+	assertEqualE(t, finish.QueryID, "01b2c3")
+	assertEqualE(t, finish.Rows, int64(5))
+	assertEqualE(t, finish.Bytes, int64(300))
+	assertEqualE(t, finish.Chunks, 2)
+	assertEqualE(t, finish.Retries, 1)
+	assertEqualE(t, finish.StartTime, hooks.queryStarts[0].StartTime)
+	assertEqualE(t, finish.Duration, finish.EndTime.Sub(finish.StartTime))
+	assertNilE(t, finish.Err)
+	assertEqualE(t, len(sc.QueryHistory()), 0, "query history is not enabled")
+}
+
+func TestRetryEventHooks(t *testing.T) {
+	hooks := &testEventHooks{}
+	client := &fakeHTTPClient{
+		cnt:        2,
+		success:    true,
+		statusCode: 503,
+		t:          t,
+	}
+	urlPtr, err := url.Parse("https://fakeaccountretrysuccess.snowflakecomputing.com:443/queries/v1/query-request?" + requestIDKey + "=testid")
+	assertNilF(t, err, "failed to parse the test URL")
+	_, err = newRetryHTTP(context.Background(),
+		client,
+		emptyRequest, urlPtr, make(map[string]string), 60*time.Second, 3, defaultTimeProvider, &Config{EventHooks: hooks}).doPost().setBody([]byte{0}).execute()
+	assertNilF(t, err, "failed to run retry")
+
+	assertEqualF(t, len(hooks.retries), 1)
+	retry := hooks.retries[0]
+	assertEqualE(t, retry.URLPath, queryRequestPath)
+	assertEqualE(t, retry.StatusCode, 503)
+	assertEqualE(t, retry.Reason, "Service Unavailable")
+	assertEqualE(t, retry.Attempt, 1)
+	assertTrueE(t, retry.Sleep > 0, "sleep time should be set")
+}
+
+func TestSessionRenewalEventHooks(t *testing.T) {
+	hooks := &testEventHooks{}
+	accessor := getSimpleTokenAccessor()
+	sr := &snowflakeRestful{
+		FuncRenewSession: renewSessionTestError,
+		TokenAccessor:    accessor,
+		EventHooks:       hooks,
+	}
+	err := sr.renewExpiredSessionToken(context.Background(), time.Hour, "")
+	assertNotNilF(t, err)
+
+	assertEqualF(t, len(hooks.sessionRenewals), 1)
+	assertTrueE(t, errors.Is(hooks.sessionRenewals[0].Err, err))
+	assertFalseE(t, hooks.sessionRenewals[0].StartTime.IsZero())
+}
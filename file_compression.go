@@ -0,0 +1,117 @@
+package gosnowflake
+
+import (
+	"compress/bzip2"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"path"
+	"strings"
+
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/zstd"
+)
+
+// autoCompressionLevels are the ranges of the levels of the compression types supported by auto compression.
+var autoCompressionLevels = map[string][2]int{
+	"GZIP":   {gzip.HuffmanOnly, gzip.BestCompression},
+	"ZSTD":   {1, 22},
+	"BROTLI": {brotli.BestSpeed, brotli.BestCompression},
+}
+
+// autoCompressionType returns the compression type of auto-compressed uploads, which is GZIP by default.
+func autoCompressionType(options *SnowflakeFileTransferOptions) (*compressionType, *SnowflakeError) {
+	if options == nil || options.AutoCompression == "" {
+		return compressionTypes["GZIP"], nil
+	}
+	name := strings.ToUpper(options.AutoCompression)
+	levels, ok := autoCompressionLevels[name]
+	if !ok {
+		return nil, errAutoCompressionNotSupported(options.AutoCompression)
+	}
+	if level := options.AutoCompressionLevel; level != 0 && (level < levels[0] || level > levels[1]) {
+		return nil, errInvalidCompressionLevel(name, level)
+	}
+	return compressionTypes[name], nil
+}
+
+// compressionLevel returns the level of auto compression, zero means the default level of the compression type.
+func (meta *fileMetadata) compressionLevel() int {
+	if meta.options == nil {
+		return 0
+	}
+	return meta.options.AutoCompressionLevel
+}
+
+// autoCompressionType returns the compression type the file is compressed with before the upload.
+func (meta *fileMetadata) autoCompressionType() *compressionType {
+	if meta.dstCompressionType == nil {
+		return compressionTypes["GZIP"]
+	}
+	return meta.dstCompressionType
+}
+
+// newCompressWriter returns a writer compressing the data written to w with the compression type.
+// A zero level uses the default level of the compression type.
+func newCompressWriter(w io.Writer, ct *compressionType, level int) (io.WriteCloser, error) {
+	switch ct.name {
+	case "GZIP":
+		if level == 0 {
+			level = gzip.DefaultCompression
+		}
+		zw, err := gzip.NewWriterLevel(w, level)
+		if err != nil {
+			return nil, errInvalidCompressionLevel(ct.name, level)
+		}
+		return zw, nil
+	case "ZSTD":
+		var opts []zstd.EOption
+		if level != 0 {
+			opts = append(opts, zstd.WithEncoderLevel(zstd.EncoderLevelFromZstd(level)))
+		}
+		return zstd.NewWriter(w, opts...)
+	case "BROTLI":
+		if level == 0 {
+			return brotli.NewWriter(w), nil
+		}
+		return brotli.NewWriterLevel(w, level), nil
+	}
+	return nil, errAutoCompressionNotSupported(ct.name)
+}
+
+// newDecompressReader returns a reader decompressing r with the compression type.
+// The data is returned as it is if the compression type is nil or cannot be decompressed by the driver.
+func newDecompressReader(r io.Reader, ct *compressionType) (io.ReadCloser, error) {
+	if ct == nil {
+		return io.NopCloser(r), nil
+	}
+	switch ct.name {
+	case "GZIP":
+		return gzip.NewReader(r)
+	case "DEFLATE":
+		return zlib.NewReader(r)
+	case "RAW_DEFLATE":
+		return flate.NewReader(r), nil
+	case "BZIP2":
+		return io.NopCloser(bzip2.NewReader(r)), nil
+	case "ZSTD":
+		zr, err := zstd.NewReader(r)
+		if err != nil {
+			return nil, err
+		}
+		return zr.IOReadCloser(), nil
+	case "BROTLI":
+		return io.NopCloser(brotli.NewReader(r)), nil
+	}
+	return io.NopCloser(r), nil
+}
+
+// decompressDownloadedFile returns a reader of the downloaded file decompressed according to its extension
+// if DecompressGetStream is set.
+func decompressDownloadedFile(options *SnowflakeFileTransferOptions, fileName string, r io.Reader) (io.ReadCloser, error) {
+	if options == nil || !options.DecompressGetStream {
+		return io.NopCloser(r), nil
+	}
+	return newDecompressReader(r, lookupByExtension(path.Ext(fileName)))
+}
@@ -0,0 +1,138 @@
+package gosnowflake
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCompressionRoundTrip(t *testing.T) {
+	data := bytes.Repeat([]byte("snowflake compression test data "), 1000)
+	testcases := []struct {
+		compression string
+		level       int
+	}{
+		{"GZIP", 0},
+		{"GZIP", 9},
+		{"ZSTD", 0},
+		{"ZSTD", 19},
+		{"BROTLI", 0},
+		{"BROTLI", 11},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.compression, func(t *testing.T) {
+			ct := compressionTypes[tc.compression]
+			var compressed bytes.Buffer
+			w, err := newCompressWriter(&compressed, ct, tc.level)
+			assertNilF(t, err)
+			_, err = w.Write(data)
+			assertNilF(t, err)
+			assertNilF(t, w.Close())
+			assertTrueE(t, compressed.Len() < len(data), "data should be compressed")
+
+			r, err := newDecompressReader(&compressed, ct)
+			assertNilF(t, err)
+			defer r.Close()
+			decompressed, err := io.ReadAll(r)
+			assertNilF(t, err)
+			assertDeepEqualE(t, decompressed, data)
+		})
+	}
+}
+
+func TestAutoCompressionType(t *testing.T) {
+	ct, err := autoCompressionType(nil)
+	assertNilF(t, err)
+	assertEqualE(t, ct.name, "GZIP")
+	ct, err = autoCompressionType(&SnowflakeFileTransferOptions{AutoCompression: "zstd", AutoCompressionLevel: 3})
+	assertNilF(t, err)
+	assertEqualE(t, ct.name, "ZSTD")
+
+	_, err = autoCompressionType(&SnowflakeFileTransferOptions{AutoCompression: "BZIP2"})
+	assertNotNilF(t, err)
+	assertEqualE(t, err.Number, ErrCompressionNotSupported)
+	_, err = autoCompressionType(&SnowflakeFileTransferOptions{AutoCompression: "BROTLI", AutoCompressionLevel: 12})
+	assertNotNilF(t, err)
+	assertEqualE(t, err.Number, ErrCompressionNotSupported)
+}
+
+func TestDecompressDownloadedFile(t *testing.T) {
+	var compressed bytes.Buffer
+	w, err := newCompressWriter(&compressed, compressionTypes["BROTLI"], 0)
+	assertNilF(t, err)
+	_, err = w.Write([]byte("data"))
+	assertNilF(t, err)
+	assertNilF(t, w.Close())
+
+	r, err := decompressDownloadedFile(&SnowflakeFileTransferOptions{}, "dir/a.csv.br", bytes.NewReader(compressed.Bytes()))
+	assertNilF(t, err)
+	content, err := io.ReadAll(r)
+	assertNilF(t, err)
+	assertDeepEqualE(t, content, compressed.Bytes(), "data should not be decompressed by default")
+
+	options := &SnowflakeFileTransferOptions{DecompressGetStream: true}
+	r, err = decompressDownloadedFile(options, "dir/a.csv.br", bytes.NewReader(compressed.Bytes()))
+	assertNilF(t, err)
+	content, err = io.ReadAll(r)
+	assertNilF(t, err)
+	assertEqualE(t, string(content), "data")
+
+	r, err = decompressDownloadedFile(options, "dir/a.csv", bytes.NewReader([]byte("data")))
+	assertNilF(t, err)
+	content, err = io.ReadAll(r)
+	assertNilF(t, err)
+	assertEqualE(t, string(content), "data", "files without a compression extension should be passed as they are")
+}
+
+func TestPutGetWithZstdAutoCompression(t *testing.T) {
+	tmpDir := t.TempDir()
+	stageDir := filepath.Join(tmpDir, "stage")
+	assertNilF(t, os.Mkdir(stageDir, 0755))
+	srcFile := filepath.Join(tmpDir, "data.csv")
+	data := bytes.Repeat([]byte("1,2,3\n"), 1000)
+	assertNilF(t, os.WriteFile(srcFile, data, readWriteFileMode))
+
+	sc := &snowflakeConn{cfg: &Config{TmpDirPath: tmpDir}}
+	collector := &fileTransferResultCollector{}
+	ctx := fileTransferContext(context.Background(), collector, fileTransferOverrides{autoCompression: "ZSTD", compressionLevel: 3})
+	_, err := sc.processFileTransfer(ctx, &execResponse{Data: execResponseData{
+		Command:           string(uploadCommand),
+		SrcLocations:      []string{srcFile},
+		AutoCompress:      true,
+		SourceCompression: "auto_detect",
+		Overwrite:         true,
+		StageInfo: execResponseStageInfo{
+			LocationType: string(local),
+			Location:     stageDir,
+		},
+	}}, "PUT 'file://data.csv' '@~'", false)
+	assertNilF(t, err)
+	assertEqualF(t, len(collector.results), 1)
+	assertEqualE(t, collector.results[0].Target, "data.csv.zst")
+	assertEqualE(t, collector.results[0].TargetCompression, "ZSTD")
+
+	var got testGetFileWriter
+	collector = &fileTransferResultCollector{}
+	ctx = fileTransferContext(context.Background(), collector, fileTransferOverrides{
+		decompressStream: true,
+		getFileWriter: func(name string) (io.WriteCloser, error) {
+			return &got, nil
+		},
+	})
+	_, err = sc.processFileTransfer(ctx, &execResponse{Data: execResponseData{
+		Command:       string(downloadCommand),
+		SrcLocations:  []string{"data.csv.zst"},
+		LocalLocation: tmpDir,
+		StageInfo: execResponseStageInfo{
+			LocationType: string(local),
+			Location:     stageDir,
+		},
+	}}, "GET @~/data.csv.zst file:///tmp", false)
+	assertNilF(t, err)
+	assertEqualF(t, len(collector.results), 1)
+	assertNilE(t, collector.results[0].Err)
+	assertDeepEqualE(t, got.Bytes(), data)
+}
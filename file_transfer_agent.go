@@ -24,6 +24,7 @@ import (
 
 	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/gabriel-vasile/mimetype"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type (
@@ -66,16 +67,17 @@ const (
 	notFoundFile
 	needRetry
 	needRetryWithLowerConcurrency
+	deleted
 )
 
 func (rs resultStatus) String() string {
 	return [...]string{"ERROR", "UPLOADED", "DOWNLOADED", "SKIPPED",
 		"RENEW_TOKEN", "RENEW_PRESIGNED_URL", "NOT_FOUND_FILE", "NEED_RETRY",
-		"NEED_RETRY_WITH_LOWER_CONCURRENCY"}[rs]
+		"NEED_RETRY_WITH_LOWER_CONCURRENCY", "DELETED"}[rs]
 }
 
 func (rs resultStatus) isSet() bool {
-	return uploaded <= rs && rs <= needRetryWithLowerConcurrency
+	return uploaded <= rs && rs <= deleted
 }
 
 // SnowflakeFileTransferOptions enables users to specify options regarding
@@ -88,9 +90,24 @@ type SnowflakeFileTransferOptions struct {
 	/* streaming PUT */
 	compressSourceFromStream bool
 
+	// AutoCompression is the compression of files auto-compressed by PUT: GZIP (default), ZSTD or BROTLI.
+	// AutoCompressionLevel is its level, zero uses the default level of the compression.
+	AutoCompression      string
+	AutoCompressionLevel int
+
 	/* streaming GET */
 	GetFileToStream bool
 
+	// GetFileWriter receives every file downloaded by GET instead of the local directory. It is called
+	// concurrently for the files downloaded in parallel, and GetFileToStream is ignored when it is set.
+	GetFileWriter GetFileWriterFunc
+	// DecompressGetStream decompresses the files streamed by GET to GetFileWriter or with GetFileToStream
+	// according to their extensions, e.g. .gz, .zst or .br.
+	DecompressGetStream bool
+	// PreserveStagePaths writes the files downloaded by GET to the subdirectories of the local directory
+	// mirroring their paths on the stage instead of writing all of them to the local directory itself.
+	PreserveStagePaths bool
+
 	/* PUT */
 	putCallback             *snowflakeProgressPercentage
 	putAzureCallback        *snowflakeProgressPercentage
@@ -100,6 +117,38 @@ type SnowflakeFileTransferOptions struct {
 	getCallback             *snowflakeProgressPercentage
 	getAzureCallback        *snowflakeProgressPercentage
 	getCallbackOutputStream *io.Writer
+
+	// ProgressListener receives the progress of the transferred files
+	ProgressListener ProgressListener
+
+	// Recursive uploads the files in the subdirectories of the PUT source directories to stage paths
+	// mirroring their relative directories, like a source location ending with /** does.
+	// IncludePatterns and ExcludePatterns select the files of recursive uploads by their relative paths
+	// and FollowSymlinks walks symbolic links to directories.
+	Recursive       bool
+	IncludePatterns []string
+	ExcludePatterns []string
+	FollowSymlinks  bool
+
+	// VerifyChecksums sends content checksums with uploads to cloud storages which validate them (CRC32C on S3 and GCS,
+	// CRC64 on Azure) and compares the SHA-256 digest of every uploaded or downloaded file with the digest stored
+	// on the stage. A file whose digest differs fails with ErrChecksumMismatch.
+	VerifyChecksums bool
+
+	// Sync uploads only the files which do not exist on the stage or whose SHA-256 digest differs from
+	// the digest stored on the stage, regardless of OVERWRITE. Other files are skipped. The digest is computed
+	// after compression, so files uploaded with another compression or by other clients are uploaded again.
+	// SyncDelete also removes the files of the stage location which match the PUT source pattern but were not
+	// uploaded by the PUT command, e.g. files deleted locally. It has no effect on streams or if any file fails to upload.
+	Sync       bool
+	SyncDelete bool
+
+	// BandwidthLimit is the maximum number of bytes per second transferred by the command and
+	// MaxConcurrency the maximum number of its concurrent cloud storage requests.
+	// When any of them is set, the command does not share the budget of the connection,
+	// and a negative value removes the limit of the connection for the command.
+	BandwidthLimit int64
+	MaxConcurrency int
 }
 
 type snowflakeFileTransferAgent struct {
@@ -114,18 +163,23 @@ type snowflakeFileTransferAgent struct {
 	stageInfo                   *execResponseStageInfo
 	results                     []*fileMetadata
 	sourceStream                *bytes.Buffer
+	sourceStreamRest            io.Reader
 	srcLocations                []string
 	autoCompress                bool
 	srcCompression              string
 	parallel                    int64
 	overwrite                   bool
 	srcFiles                    []string
+	srcFileNames                map[string]string
 	localLocation               string
 	srcFileToEncryptionMaterial map[string]*snowflakeFileEncryption
 	useAccelerateEndpoint       bool
 	presignedURLs               []string
 	options                     *SnowflakeFileTransferOptions
 	streamBuffer                *bytes.Buffer
+	progress                    *progressTracker
+	budget                      *transferBudget
+	storageCfg                  *Config
 }
 
 func (sfa *snowflakeFileTransferAgent) execute() error {
@@ -167,6 +221,13 @@ func (sfa *snowflakeFileTransferAgent) execute() error {
 		return err
 	}
 
+	sfa.progress = newProgressTracker(sfa.options.ProgressListener, sfa.commandType == uploadCommand)
+	sfa.budget = sfa.getTransferBudget()
+	if sfa.budget != nil && sfa.budget.bandwidth != nil {
+		cfg := *sfa.sc.cfg
+		cfg.Transporter = sfa.budget.transport(getTransport(sfa.sc.cfg))
+		sfa.storageCfg = &cfg
+	}
 	smallFileMetas := make([]*fileMetadata, 0)
 	largeFileMetas := make([]*fileMetadata, 0)
 
@@ -194,6 +255,9 @@ func (sfa *snowflakeFileTransferAgent) execute() error {
 		if err = sfa.upload(largeFileMetas, smallFileMetas); err != nil {
 			return err
 		}
+		if err = sfa.syncStageFiles(); err != nil {
+			return err
+		}
 	} else {
 		if err = sfa.download(smallFileMetas); err != nil {
 			return err
@@ -327,10 +391,23 @@ func (sfa *snowflakeFileTransferAgent) expandFilenames(locations []string) ([]st
 				// followed by a drive letter and colon.
 				fileName = fileName[1:]
 			}
+			if root, pattern, ok := splitRecursiveLocation(fileName); ok {
+				files, err := sfa.expandDirectory(root, pattern)
+				if err != nil {
+					return []string{}, err
+				}
+				canonicalLocations = append(canonicalLocations, files...)
+				continue
+			}
 			files, err := filepath.Glob(fileName)
 			if err != nil {
 				return []string{}, err
 			}
+			if sfa.options != nil && sfa.options.Recursive {
+				if files, err = sfa.expandDirectories(files); err != nil {
+					return []string{}, err
+				}
+			}
 			canonicalLocations = append(canonicalLocations, files...)
 		} else {
 			canonicalLocations = append(canonicalLocations, fileName)
@@ -339,6 +416,61 @@ func (sfa *snowflakeFileTransferAgent) expandFilenames(locations []string) ([]st
 	return canonicalLocations, nil
 }
 
+// expandDirectories replaces the directories among the files with the files found in them recursively.
+func (sfa *snowflakeFileTransferAgent) expandDirectories(files []string) ([]string, error) {
+	expanded := make([]string, 0, len(files))
+	for _, file := range files {
+		if fi, err := os.Stat(file); err != nil || !fi.IsDir() {
+			expanded = append(expanded, file)
+			continue
+		}
+		dirFiles, err := sfa.expandDirectory(file, recursiveWildcard)
+		if err != nil {
+			return nil, err
+		}
+		expanded = append(expanded, dirFiles...)
+	}
+	return expanded, nil
+}
+
+// expandDirectory returns the files under root matching the pattern and remembers their relative paths,
+// which are used as their names on the stage.
+func (sfa *snowflakeFileTransferAgent) expandDirectory(root string, pattern string) ([]string, error) {
+	if err := validateFileTransferPattern(pattern); err != nil {
+		return nil, err
+	}
+	options := sfa.options
+	if options == nil {
+		options = &SnowflakeFileTransferOptions{}
+	}
+	filter, err := newUploadFileFilter(options.IncludePatterns, options.ExcludePatterns)
+	if err != nil {
+		return nil, err
+	}
+	sources, err := walkUploadDirectory(sfa.sc.getLogger(), root, pattern, filter, options.FollowSymlinks)
+	if err != nil {
+		return nil, err
+	}
+	if sfa.srcFileNames == nil {
+		sfa.srcFileNames = make(map[string]string)
+	}
+	files := make([]string, 0, len(sources))
+	for _, source := range sources {
+		sfa.srcFileNames[source.path] = source.name
+		files = append(files, source.path)
+	}
+	return files, nil
+}
+
+// stageFileName returns the name of the uploaded file on the stage,
+// which is the relative path for files found in directories.
+func (sfa *snowflakeFileTransferAgent) stageFileName(fileName string) string {
+	if name, ok := sfa.srcFileNames[fileName]; ok {
+		return name
+	}
+	return baseName(fileName)
+}
+
 func (sfa *snowflakeFileTransferAgent) initFileMetadata() error {
 	sfa.fileMetadata = []*fileMetadata{}
 	if sfa.commandType == uploadCommand {
@@ -355,14 +487,20 @@ func (sfa *snowflakeFileTransferAgent) initFileMetadata() error {
 		if sfa.sourceStream != nil {
 			fileName := sfa.srcFiles[0]
 			srcFileSize := int64(sfa.sourceStream.Len())
-			sfa.fileMetadata = append(sfa.fileMetadata, &fileMetadata{
+			meta := &fileMetadata{
 				name:              baseName(fileName),
 				srcFileName:       fileName,
-				srcStream:         sfa.sourceStream,
 				srcFileSize:       srcFileSize,
 				stageLocationType: sfa.stageLocationType,
 				stageInfo:         sfa.stageInfo,
-			})
+			}
+			if sfa.sourceStreamRest != nil {
+				// the stream is larger than the multipart threshold, so it is uploaded without buffering
+				meta.srcReader = io.MultiReader(sfa.sourceStream, sfa.sourceStreamRest)
+			} else {
+				meta.srcStream = sfa.sourceStream
+			}
+			sfa.fileMetadata = append(sfa.fileMetadata, meta)
 		} else {
 			for i, fileName := range sfa.srcFiles {
 				fi, err := os.Stat(fileName)
@@ -384,7 +522,7 @@ func (sfa *snowflakeFileTransferAgent) initFileMetadata() error {
 					}).exceptionTelemetry(sfa.sc)
 				}
 				sfa.fileMetadata = append(sfa.fileMetadata, &fileMetadata{
-					name:              baseName(fileName),
+					name:              sfa.stageFileName(fileName),
 					srcFileName:       fileName,
 					srcFileSize:       fi.Size(),
 					stageLocationType: sfa.stageLocationType,
@@ -453,7 +591,12 @@ func (sfa *snowflakeFileTransferAgent) processFileCompressionType() error {
 		autoDetect = false
 	}
 
-	gzipCompression := compressionTypes["GZIP"]
+	autoCompression, sfErr := autoCompressionType(sfa.options)
+	if sfErr != nil {
+		sfErr.SQLState = sfa.data.SQLState
+		sfErr.QueryID = sfa.data.QueryID
+		return sfErr.exceptionTelemetry(sfa.sc)
+	}
 	for _, meta := range sfa.fileMetadata {
 		fileName := meta.srcFileName
 		var currentFileCompressionType *compressionType
@@ -471,6 +614,8 @@ func (sfa *snowflakeFileTransferAgent) processFileCompressionType() error {
 					if _, err = io.ReadAll(r); err != nil { // flush out tee buffer
 						return err
 					}
+				} else if meta.srcReader != nil {
+					mtype = mimetype.Detect(sfa.sourceStream.Bytes())
 				} else {
 					mtype, err = mimetype.DetectFile(fileName)
 					if err != nil {
@@ -512,9 +657,9 @@ func (sfa *snowflakeFileTransferAgent) processFileCompressionType() error {
 			meta.requireCompress = sfa.autoCompress
 			meta.srcCompressionType = nil
 			if sfa.autoCompress {
-				dstFileName := meta.name + compressionTypes["GZIP"].fileExtension
+				dstFileName := meta.name + autoCompression.fileExtension
 				meta.dstFileName = dstFileName
-				meta.dstCompressionType = gzipCompression
+				meta.dstCompressionType = autoCompression
 			} else {
 				meta.dstFileName = meta.name
 				meta.dstCompressionType = nil
@@ -624,7 +769,7 @@ func (sfa *snowflakeFileTransferAgent) transferAccelerateConfigWithUtil(s3Util s
 	})
 	sfa.useAccelerateEndpoint = ret != nil && ret.Status == "Enabled"
 	if err != nil {
-		logger.WithContext(sfa.sc.ctx).Warnln("An error occurred when getting accelerate config:", err)
+		sfa.sc.getLogger().WithContext(sfa.sc.ctx).Warnln("An error occurred when getting accelerate config:", err)
 	}
 	return nil
 }
@@ -638,6 +783,15 @@ func withCloudStorageTimeout[T any](cfg *Config, f func(ctx context.Context) (T,
 	return f(context.Background())
 }
 
+// cloudStorageContext returns the context of a cloud storage request whose response is read after the request
+// returns, with the timeout of withCloudStorageTimeout. It is canceled when the response body is closed.
+func cloudStorageContext(cfg *Config) (context.Context, context.CancelFunc) {
+	if cfg.CloudStorageTimeout > 0 {
+		return context.WithTimeout(context.Background(), cfg.CloudStorageTimeout)
+	}
+	return context.WithCancel(context.Background())
+}
+
 func (sfa *snowflakeFileTransferAgent) transferAccelerateConfig() error {
 	if sfa.stageLocationType == s3Client {
 		s3Util := new(snowflakeS3Client)
@@ -691,7 +845,7 @@ func (sfa *snowflakeFileTransferAgent) upload(
 	largeFileMetadata []*fileMetadata,
 	smallFileMetadata []*fileMetadata) error {
 	client, err := sfa.getStorageClient(sfa.stageLocationType).
-		createClient(sfa.stageInfo, sfa.useAccelerateEndpoint, sfa.sc.cfg)
+		createClient(sfa.stageInfo, sfa.useAccelerateEndpoint, sfa.storageConfig())
 	if err != nil {
 		return err
 	}
@@ -703,13 +857,13 @@ func (sfa *snowflakeFileTransferAgent) upload(
 	}
 
 	if len(smallFileMetadata) > 0 {
-		logger.WithContext(sfa.sc.ctx).Infof("uploading %v small files", len(smallFileMetadata))
+		sfa.sc.getLogger().WithContext(sfa.sc.ctx).Infof("uploading %v small files", len(smallFileMetadata))
 		if err = sfa.uploadFilesParallel(smallFileMetadata); err != nil {
 			return err
 		}
 	}
 	if len(largeFileMetadata) > 0 {
-		logger.WithContext(sfa.sc.ctx).Infof("uploading %v large files", len(largeFileMetadata))
+		sfa.sc.getLogger().WithContext(sfa.sc.ctx).Infof("uploading %v large files", len(largeFileMetadata))
 		if err = sfa.uploadFilesSequential(largeFileMetadata); err != nil {
 			return err
 		}
@@ -720,7 +874,7 @@ func (sfa *snowflakeFileTransferAgent) upload(
 func (sfa *snowflakeFileTransferAgent) download(
 	fileMetadata []*fileMetadata) error {
 	client, err := sfa.getStorageClient(sfa.stageLocationType).
-		createClient(sfa.stageInfo, sfa.useAccelerateEndpoint, sfa.sc.cfg)
+		createClient(sfa.stageInfo, sfa.useAccelerateEndpoint, sfa.storageConfig())
 	if err != nil {
 		return err
 	}
@@ -728,7 +882,7 @@ func (sfa *snowflakeFileTransferAgent) download(
 		meta.client = client
 	}
 
-	logger.WithContext(sfa.sc.ctx).Infof("downloading %v files", len(fileMetadata))
+	sfa.sc.getLogger().WithContext(sfa.sc.ctx).Infof("downloading %v files", len(fileMetadata))
 	if err = sfa.downloadFilesParallel(fileMetadata); err != nil {
 		return err
 	}
@@ -856,7 +1010,16 @@ func (sfa *snowflakeFileTransferAgent) uploadFilesSequential(fileMetas []*fileMe
 	return nil
 }
 
-func (sfa *snowflakeFileTransferAgent) uploadOneFile(meta *fileMetadata) (*fileMetadata, error) {
+func (sfa *snowflakeFileTransferAgent) uploadOneFile(meta *fileMetadata) (_ *fileMetadata, err error) {
+	meta.progress = sfa.progress.file(meta, meta.srcFileSize)
+	_, span := startSpan(sfa.ctx, sfa.sc.cfg, spanFileUpload, trace.SpanKindClient,
+		attrFileName.String(meta.srcFileName), attrStageLocation.String(string(sfa.stageLocationType)))
+	defer func() {
+		sfa.reportFileTransferred(meta, err)
+		span.SetAttributes(attrFileStatus.String(meta.resStatus.String()), attrBytes.Int64(meta.uploadSize))
+		endSpan(span, err)
+		sfa.recordFileTransfer(meta, meta.uploadSize, err)
+	}()
 	meta.realSrcFileName = meta.srcFileName
 	tmpDir, err := os.MkdirTemp(sfa.sc.cfg.TmpDirPath, "")
 	if err != nil {
@@ -865,6 +1028,10 @@ func (sfa *snowflakeFileTransferAgent) uploadOneFile(meta *fileMetadata) (*fileM
 	meta.tmpDir = tmpDir
 	defer os.RemoveAll(tmpDir) // cleanup
 
+	if meta.srcReader != nil {
+		return meta, sfa.uploadOneStream(meta)
+	}
+
 	fileUtil := new(snowflakeFileUtil)
 	err = compressDataIfRequired(meta, fileUtil, tmpDir)
 	if err != nil {
@@ -875,12 +1042,19 @@ func (sfa *snowflakeFileTransferAgent) uploadOneFile(meta *fileMetadata) (*fileM
 	if err != nil {
 		return meta, err
 	}
+	meta.progress.setSize(meta.uploadSize)
+	meta.uploadJournal = openUploadJournal(sfa.sc.cfg, meta)
 
 	err = encryptDataIfRequired(meta, sfa.stageLocationType)
 	if err != nil {
 		return meta, err
 	}
 
+	release, err := sfa.acquireTransferSlots(meta)
+	if err != nil {
+		return meta, err
+	}
+	defer release()
 	client := sfa.getStorageClient(sfa.stageLocationType)
 	if err = client.uploadOneFileWithRetry(meta); err != nil {
 		return meta, err
@@ -888,6 +1062,39 @@ func (sfa *snowflakeFileTransferAgent) uploadOneFile(meta *fileMetadata) (*fileM
 	return meta, nil
 }
 
+// uploadOneStream uploads a source stream which is too large to be buffered.
+// Since the stream can be read only once, it is retried in parts or written to a temporary file by the storage client.
+func (sfa *snowflakeFileTransferAgent) uploadOneStream(meta *fileMetadata) error {
+	// the size of the stream is not known until it is read
+	meta.progress.setSize(0)
+	release, err := sfa.acquireTransferSlots(meta)
+	if err != nil {
+		return err
+	}
+	defer release()
+	src := &countingReader{r: meta.srcReader}
+	stream, err := newUploadStream(meta, src, sfa.stageLocationType)
+	if err != nil {
+		return err
+	}
+	defer stream.Close()
+	if err = sfa.getStorageClient(sfa.stageLocationType).uploadOneStream(meta, stream); err != nil {
+		meta.resStatus = errStatus
+		return err
+	}
+	if meta.resStatus == uploaded {
+		meta.srcFileSize = src.n
+	} else if meta.resStatus != skipped {
+		err = meta.lastError
+		if err == nil {
+			err = fmt.Errorf("failed to upload stream with status %v", meta.resStatus)
+		}
+		meta.resStatus = errStatus
+		return err
+	}
+	return nil
+}
+
 func (sfa *snowflakeFileTransferAgent) downloadFilesParallel(fileMetas []*fileMetadata) error {
 	idx := 0
 	fileMetaLen := len(fileMetas)
@@ -920,14 +1127,14 @@ func (sfa *snowflakeFileTransferAgent) downloadFilesParallel(fileMetas []*fileMe
 			if len(retryMeta) == 0 {
 				break
 			}
-			logger.WithContext(sfa.sc.ctx).Infof("%v retries found", len(retryMeta))
+			sfa.sc.getLogger().WithContext(sfa.sc.ctx).Infof("%v retries found", len(retryMeta))
 
 			needRenewToken := false
 			for _, result := range retryMeta {
 				if result.resStatus == renewToken {
 					needRenewToken = true
 				}
-				logger.WithContext(sfa.sc.ctx).Infof(
+				sfa.sc.getLogger().WithContext(sfa.sc.ctx).Infof(
 					"retying download file %v with status %v",
 					result.name, result.resStatus)
 			}
@@ -965,17 +1172,31 @@ func (sfa *snowflakeFileTransferAgent) downloadFilesParallel(fileMetas []*fileMe
 	return err
 }
 
-func (sfa *snowflakeFileTransferAgent) downloadOneFile(meta *fileMetadata) (*fileMetadata, error) {
+func (sfa *snowflakeFileTransferAgent) downloadOneFile(meta *fileMetadata) (_ *fileMetadata, err error) {
+	meta.progress = sfa.progress.file(meta, 0)
+	_, span := startSpan(sfa.ctx, sfa.sc.cfg, spanFileDownload, trace.SpanKindClient,
+		attrFileName.String(meta.srcFileName), attrStageLocation.String(string(sfa.stageLocationType)))
+	defer func() {
+		sfa.reportFileTransferred(meta, err)
+		span.SetAttributes(attrFileStatus.String(meta.resStatus.String()), attrBytes.Int64(meta.dstFileSize))
+		endSpan(span, err)
+		sfa.recordFileTransfer(meta, meta.dstFileSize, err)
+	}()
 	tmpDir, err := os.MkdirTemp(sfa.sc.cfg.TmpDirPath, "")
 	if err != nil {
 		return nil, err
 	}
 	meta.tmpDir = tmpDir
 	defer os.RemoveAll(tmpDir) // cleanup
+	release, err := sfa.acquireTransferSlots(meta)
+	if err != nil {
+		return meta, err
+	}
+	defer release()
 	client := sfa.getStorageClient(sfa.stageLocationType)
 	if err = client.downloadOneFile(meta); err != nil {
 		meta.dstFileSize = -1
-		if !meta.resStatus.isSet() {
+		if !meta.resStatus.isSet() || meta.resStatus == downloaded {
 			meta.resStatus = errStatus
 		}
 		meta.errorDetails = errors.New(err.Error() + ", file=" + meta.dstFileName)
@@ -984,12 +1205,38 @@ func (sfa *snowflakeFileTransferAgent) downloadOneFile(meta *fileMetadata) (*fil
 	return meta, nil
 }
 
+// reportFileTransferred reports the completion of the file transfer,
+// or a retry if the file is transferred again after renewing the credentials.
+func (sfa *snowflakeFileTransferAgent) reportFileTransferred(meta *fileMetadata, err error) {
+	if meta.resStatus == renewToken || meta.resStatus == renewPresignedURL {
+		meta.progress.retry(cmp.Or(err, meta.lastError))
+		return
+	}
+	meta.progress.complete(meta.resStatus, err)
+}
+
+// recordFileTransfer reports the transferred file to the MetricsRecorder unless the transfer is retried.
+func (sfa *snowflakeFileTransferAgent) recordFileTransfer(meta *fileMetadata, bytes int64, err error) {
+	if meta.resStatus == renewToken || meta.resStatus == renewPresignedURL {
+		return
+	}
+	status := meta.resStatus.String()
+	if err != nil {
+		status = errStatus.String()
+	}
+	command := "PUT"
+	if sfa.commandType == downloadCommand {
+		command = "GET"
+	}
+	getMetricsRecorder(sfa.sc.cfg).RecordFileTransfer(sfa.ctx, command, bytes, status)
+}
+
 func (sfa *snowflakeFileTransferAgent) getStorageClient(stageLocationType cloudType) storageUtil {
 	if stageLocationType == local {
 		return &localUtil{}
 	} else if stageLocationType == s3Client || stageLocationType == azureClient || stageLocationType == gcsClient {
 		return &remoteStorageUtil{
-			cfg: sfa.sc.cfg,
+			cfg: sfa.storageConfig(),
 		}
 	}
 	return nil
@@ -1007,7 +1254,7 @@ func (sfa *snowflakeFileTransferAgent) renewExpiredClient() (cloudClient, error)
 		return nil, err
 	}
 	storageClient := sfa.getStorageClient(sfa.stageLocationType)
-	return storageClient.createClient(&data.Data.StageInfo, sfa.useAccelerateEndpoint, sfa.sc.cfg)
+	return storageClient.createClient(&data.Data.StageInfo, sfa.useAccelerateEndpoint, sfa.storageConfig())
 }
 
 func (sfa *snowflakeFileTransferAgent) result() (*execResponse, error) {
@@ -1161,6 +1408,7 @@ func isFileTransfer(query string) bool {
 }
 
 type snowflakeProgressPercentage struct {
+	cfg             *Config
 	filename        string
 	fileSize        float64
 	outputStream    *io.Writer
@@ -1213,7 +1461,7 @@ func (spp *snowflakeProgressPercentage) updateProgress(filename string, startTim
 		text := fmt.Sprintf("\r%v(%.2fMB): [%v] %.2f%% %v ", filename, totalSize, strings.Repeat("#", block)+strings.Repeat("-", barLength-block), progress*100, status)
 		_, err := (*outputStream).Write([]byte(text))
 		if err != nil {
-			logger.Warn("cannot write status of progress. %v", err)
+			getConnectionLogger(spp.cfg).Warnf("cannot write status of progress. %v", err)
 		}
 	}
 	return progress == 1.0
@@ -1223,9 +1471,9 @@ func compressDataIfRequired(meta *fileMetadata, fileUtil *snowflakeFileUtil, tmp
 	var err error
 	if meta.requireCompress {
 		if meta.srcStream != nil {
-			meta.realSrcStream, _, err = fileUtil.compressFileWithGzipFromStream(&meta.srcStream)
+			meta.realSrcStream, _, err = fileUtil.compressFileFromStream(&meta.srcStream, meta.autoCompressionType(), meta.compressionLevel())
 		} else {
-			meta.realSrcFileName, _, err = fileUtil.compressFileWithGzip(meta.srcFileName, tmpDir)
+			meta.realSrcFileName, _, err = fileUtil.compressFile(meta.srcFileName, tmpDir, meta.autoCompressionType(), meta.compressionLevel())
 		}
 	}
 	return err
@@ -1258,10 +1506,23 @@ func encryptDataIfRequired(meta *fileMetadata, ct cloudType) error {
 			meta.realSrcStream = &encryptedStream
 		} else {
 			var dataFile string
+			if encryptMeta := meta.uploadJournal.encryptMetadata(); encryptMeta != nil {
+				// the content must be encrypted to the same bytes as the parts uploaded before
+				dataFile, err = reencryptFileCBC(meta.encryptionMaterial, encryptMeta, meta.realSrcFileName, 0, meta.tmpDir)
+				if err == nil {
+					meta.encryptMeta = encryptMeta
+					meta.realSrcFileName = dataFile
+					return nil
+				}
+				getConnectionLogger(meta.uploadJournal.cfg).Warnf("cannot encrypt %v with the key of the unfinished upload, starting a new upload. err: %v", meta.srcFileName, err)
+				meta.uploadJournal.abortUpload(meta)
+				meta.uploadJournal.reset()
+			}
 			meta.encryptMeta, dataFile, err = encryptFileCBC(meta.encryptionMaterial, meta.realSrcFileName, 0, meta.tmpDir)
 			if err != nil {
 				return err
 			}
+			meta.uploadJournal.setEncryptMetadata(meta.encryptMeta)
 			meta.realSrcFileName = dataFile
 		}
 	}
@@ -675,9 +675,8 @@ func TestUploadWhenErrorWithResultIsReturned(t *testing.T) {
 		t.Run(strconv.FormatBool(tc.shouldRaiseError), func(t *testing.T) {
 			var err error
 
-			dir, err := os.Getwd()
-			assertNilF(t, err)
-			err = createWriteonlyFile(path.Join(dir, "test_data"), "writeonly.csv")
+			dir := t.TempDir()
+			err = createWriteonlyFile(dir, "writeonly.csv")
 			assertNilF(t, err)
 
 			uploadMeta := fileMetadata{
@@ -691,7 +690,7 @@ func TestUploadWhenErrorWithResultIsReturned(t *testing.T) {
 					LocationType: "local",
 				},
 				dstFileName: "data1.txt.gz",
-				srcFileName: path.Join(dir, "test_data/writeonly.csv"),
+				srcFileName: path.Join(dir, "writeonly.csv"),
 				overwrite:   true,
 			}
 
@@ -703,7 +702,7 @@ func TestUploadWhenErrorWithResultIsReturned(t *testing.T) {
 					},
 				},
 				data: &execResponseData{
-					SrcLocations:      []string{path.Join(dir, "/test_data/writeonly.csv")},
+					SrcLocations:      []string{path.Join(dir, "writeonly.csv")},
 					Command:           "UPLOAD",
 					SourceCompression: "none",
 					StageInfo: execResponseStageInfo{
@@ -981,7 +980,7 @@ func testUploadDownloadOneFile(t *testing.T, isStream bool) {
 	if isStream {
 		fileStream, _ := os.Open(uploadFile)
 		ctx := WithFileStream(context.Background(), fileStream)
-		uploadMeta.srcStream, err = getFileStream(ctx)
+		uploadMeta.srcStream, _, err = getFileStream(ctx, dataSizeThreshold)
 		assertNilF(t, err)
 	}
 
@@ -0,0 +1,355 @@
+package gosnowflake
+
+import (
+	"cmp"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// FileTransferStatus is the outcome of the transfer of a single file.
+type FileTransferStatus string
+
+const (
+	// FileTransferUploaded means the file was uploaded to the stage.
+	FileTransferUploaded FileTransferStatus = "UPLOADED"
+	// FileTransferDownloaded means the file was downloaded from the stage.
+	FileTransferDownloaded FileTransferStatus = "DOWNLOADED"
+	// FileTransferSkipped means the file already existed and was not transferred.
+	FileTransferSkipped FileTransferStatus = "SKIPPED"
+	// FileTransferDeleted means the file was removed from the stage by a sync because it does not exist locally.
+	FileTransferDeleted FileTransferStatus = "DELETED"
+	// FileTransferError means the transfer failed, see FileTransferResult.Err.
+	FileTransferError FileTransferStatus = "ERROR"
+)
+
+// PutRequest describes files uploaded to a stage with SnowflakeConnection.Put.
+type PutRequest struct {
+	// Sources are local file paths. Each may contain the wildcards supported by PUT (* and ?).
+	Sources []string
+	// Stream is uploaded instead of Sources when set. StreamName is the name of the file on the stage.
+	Stream     io.Reader
+	StreamName string
+	// Stage is the stage location, e.g. "@~/data" or "@my_stage/path".
+	Stage string
+	// Overwrite replaces files which already exist on the stage.
+	Overwrite bool
+	// Parallel is the number of threads used to upload the files. Zero uses the server default.
+	Parallel int
+	// DisableAutoCompress uploads the files without compression.
+	DisableAutoCompress bool
+	// SourceCompression is the compression of the source files, e.g. "GZIP". Empty means auto detection.
+	SourceCompression string
+	// AutoCompression and AutoCompressionLevel select the compression of auto-compressed files,
+	// see SnowflakeFileTransferOptions.
+	AutoCompression      string
+	AutoCompressionLevel int
+	// ProgressListener receives the progress of the uploaded files.
+	ProgressListener ProgressListener
+	// VerifyChecksums verifies the checksums of the transferred files, see SnowflakeFileTransferOptions.
+	VerifyChecksums bool
+	// BandwidthLimit and MaxConcurrency override the limits of the connection, see SnowflakeFileTransferOptions.
+	BandwidthLimit int64
+	MaxConcurrency int
+	// Recursive uploads the files in the subdirectories of the Sources directories, see SnowflakeFileTransferOptions.
+	// A source ending with /** is always uploaded recursively.
+	Recursive       bool
+	IncludePatterns []string
+	ExcludePatterns []string
+	FollowSymlinks  bool
+	// Sync uploads only new or changed files and SyncDelete removes the stage files which do not exist locally,
+	// see SnowflakeFileTransferOptions. SyncDelete requires a single source.
+	Sync       bool
+	SyncDelete bool
+}
+
+// GetRequest describes files downloaded from a stage with SnowflakeConnection.Get.
+type GetRequest struct {
+	// Stage is the stage location, e.g. "@~/data" or "@my_stage/path".
+	Stage string
+	// LocalDirectory is the directory the files are written to. It is created if it does not exist.
+	// It may be empty when Writer is set.
+	LocalDirectory string
+	// Writer receives the downloaded files instead of LocalDirectory, see SnowflakeFileTransferOptions.GetFileWriter.
+	Writer GetFileWriterFunc
+	// PreserveStagePaths writes the files to subdirectories of LocalDirectory mirroring their paths on the stage.
+	PreserveStagePaths bool
+	// DecompressStream decompresses the files passed to Writer according to their extensions.
+	DecompressStream bool
+	// Pattern is a regular expression filtering the files on the stage.
+	Pattern string
+	// Parallel is the number of threads used to download the files. Zero uses the server default.
+	Parallel int
+	// ProgressListener receives the progress of the downloaded files.
+	ProgressListener ProgressListener
+	// VerifyChecksums verifies the checksums of the transferred files, see SnowflakeFileTransferOptions.
+	VerifyChecksums bool
+	// BandwidthLimit and MaxConcurrency override the limits of the connection, see SnowflakeFileTransferOptions.
+	BandwidthLimit int64
+	MaxConcurrency int
+}
+
+// FileTransferResult is the result of the transfer of a single file.
+type FileTransferResult struct {
+	// Source is the local path for PUT or the stage file for GET.
+	Source string
+	// Target is the stage file for PUT or the local path for GET.
+	Target            string
+	SourceSize        int64
+	TargetSize        int64
+	SourceCompression string
+	TargetCompression string
+	Status            FileTransferStatus
+	// Digest is the base64 encoded SHA-256 digest of the uploaded content.
+	Digest string
+	// Err is set when the transfer of this file failed.
+	Err error
+}
+
+// fileTransferResultCollector receives the metadata of transferred files from processFileTransfer.
+type fileTransferResultCollector struct {
+	results []FileTransferResult
+}
+
+func (c *fileTransferResultCollector) collect(sfa *snowflakeFileTransferAgent) {
+	results := make([]FileTransferResult, 0, len(sfa.results))
+	for _, meta := range sfa.results {
+		results = append(results, newFileTransferResult(sfa.commandType, meta))
+	}
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Source < results[j].Source
+	})
+	c.results = append(c.results, results...)
+}
+
+func newFileTransferResult(command commandType, meta *fileMetadata) FileTransferResult {
+	res := FileTransferResult{
+		Source:     meta.srcFileName,
+		Target:     meta.dstFileName,
+		SourceSize: meta.srcFileSize,
+		TargetSize: meta.dstFileSize,
+		Status:     FileTransferStatus(meta.resStatus.String()),
+		Digest:     meta.sha256Digest,
+		Err:        meta.errorDetails,
+	}
+	if command == downloadCommand {
+		res.Target = downloadTarget(meta)
+	}
+	if meta.srcCompressionType != nil {
+		res.SourceCompression = meta.srcCompressionType.name
+	}
+	if meta.dstCompressionType != nil {
+		res.TargetCompression = meta.dstCompressionType.name
+	}
+	if res.Err != nil {
+		res.Status = FileTransferError
+	}
+	return res
+}
+
+func getFileTransferResultCollector(ctx context.Context) *fileTransferResultCollector {
+	c, _ := ctx.Value(fileTransferResults).(*fileTransferResultCollector)
+	return c
+}
+
+// quoteFileTransferLocation quotes a file or stage location so that it may contain spaces and quotes.
+func quoteFileTransferLocation(location string) string {
+	location = strings.ReplaceAll(location, `\`, `\\`)
+	location = strings.ReplaceAll(location, `'`, `\'`)
+	return "'" + location + "'"
+}
+
+// validSourceCompression reports whether compression may be used as SOURCE_COMPRESSION of a PUT command.
+func validSourceCompression(compression string) bool {
+	if strings.EqualFold(compression, "AUTO_DETECT") || strings.EqualFold(compression, "NONE") {
+		return true
+	}
+	_, ok := compressionTypes[strings.ToUpper(compression)]
+	return ok
+}
+
+func buildPutCommand(source string, req PutRequest) (string, error) {
+	var sb strings.Builder
+	sb.WriteString("PUT ")
+	sb.WriteString(quoteFileTransferLocation("file://" + filepath.ToSlash(source)))
+	sb.WriteString(" ")
+	sb.WriteString(quoteFileTransferLocation(req.Stage))
+	if req.Parallel > 0 {
+		sb.WriteString(" PARALLEL=" + strconv.Itoa(req.Parallel))
+	}
+	sb.WriteString(" AUTO_COMPRESS=" + strconv.FormatBool(!req.DisableAutoCompress))
+	if req.SourceCompression != "" {
+		if !validSourceCompression(req.SourceCompression) {
+			return "", errInvalidSourceCompression(req.SourceCompression)
+		}
+		sb.WriteString(" SOURCE_COMPRESSION=" + req.SourceCompression)
+	}
+	sb.WriteString(" OVERWRITE=" + strconv.FormatBool(req.Overwrite))
+	return sb.String(), nil
+}
+
+func buildGetCommand(req GetRequest) string {
+	var sb strings.Builder
+	sb.WriteString("GET ")
+	sb.WriteString(quoteFileTransferLocation(req.Stage))
+	sb.WriteString(" ")
+	sb.WriteString(quoteFileTransferLocation("file://" + filepath.ToSlash(req.LocalDirectory)))
+	if req.Parallel > 0 {
+		sb.WriteString(" PARALLEL=" + strconv.Itoa(req.Parallel))
+	}
+	if req.Pattern != "" {
+		sb.WriteString(" PATTERN=" + quoteFileTransferLocation(req.Pattern))
+	}
+	return sb.String()
+}
+
+// fileTransferOverrides are the options of PutRequest and GetRequest overriding the options of the context.
+type fileTransferOverrides struct {
+	progressListener ProgressListener
+	bandwidthLimit   int64
+	maxConcurrency   int
+	recursive        bool
+	includePatterns  []string
+	excludePatterns  []string
+	followSymlinks   bool
+	getFileWriter    GetFileWriterFunc
+	preserveStage    bool
+	decompressStream bool
+	autoCompression  string
+	compressionLevel int
+	verifyChecksums  bool
+	sync             bool
+	syncDelete       bool
+}
+
+// fileTransferContext returns a context which collects the transfer results
+// and reports errors per file instead of failing the whole command.
+func fileTransferContext(ctx context.Context, collector *fileTransferResultCollector, overrides fileTransferOverrides) context.Context {
+	options := &SnowflakeFileTransferOptions{}
+	if op := getFileTransferOptions(ctx); op != nil {
+		*options = *op
+	}
+	options.RaisePutGetError = false
+	if overrides.progressListener != nil {
+		options.ProgressListener = overrides.progressListener
+	}
+	if overrides.bandwidthLimit != 0 {
+		options.BandwidthLimit = overrides.bandwidthLimit
+	}
+	if overrides.maxConcurrency != 0 {
+		options.MaxConcurrency = overrides.maxConcurrency
+	}
+	if overrides.recursive {
+		options.Recursive = true
+	}
+	if overrides.includePatterns != nil {
+		options.IncludePatterns = overrides.includePatterns
+	}
+	if overrides.excludePatterns != nil {
+		options.ExcludePatterns = overrides.excludePatterns
+	}
+	if overrides.followSymlinks {
+		options.FollowSymlinks = true
+	}
+	if overrides.getFileWriter != nil {
+		options.GetFileWriter = overrides.getFileWriter
+	}
+	if overrides.preserveStage {
+		options.PreserveStagePaths = true
+	}
+	if overrides.decompressStream {
+		options.DecompressGetStream = true
+	}
+	if overrides.verifyChecksums {
+		options.VerifyChecksums = true
+	}
+	if overrides.sync {
+		options.Sync = true
+	}
+	if overrides.syncDelete {
+		options.SyncDelete = true
+	}
+	if overrides.autoCompression != "" {
+		options.AutoCompression = overrides.autoCompression
+	}
+	if overrides.compressionLevel != 0 {
+		options.AutoCompressionLevel = overrides.compressionLevel
+	}
+	ctx = WithFileTransferOptions(ctx, options)
+	return context.WithValue(ctx, fileTransferResults, collector)
+}
+
+// Put uploads local files or a stream to a stage and returns the result of each file.
+// An error is returned only if a command fails as a whole, errors of single files are reported in the results.
+func (sc *snowflakeConn) Put(ctx context.Context, req PutRequest) ([]FileTransferResult, error) {
+	collector := &fileTransferResultCollector{}
+	ctx = fileTransferContext(ctx, collector, fileTransferOverrides{
+		progressListener: req.ProgressListener,
+		bandwidthLimit:   req.BandwidthLimit,
+		maxConcurrency:   req.MaxConcurrency,
+		recursive:        req.Recursive,
+		includePatterns:  req.IncludePatterns,
+		excludePatterns:  req.ExcludePatterns,
+		followSymlinks:   req.FollowSymlinks,
+		autoCompression:  req.AutoCompression,
+		compressionLevel: req.AutoCompressionLevel,
+		verifyChecksums:  req.VerifyChecksums,
+		sync:             req.Sync || req.SyncDelete,
+		syncDelete:       req.SyncDelete,
+	})
+	if req.SyncDelete && len(req.Sources) > 1 {
+		return nil, errSyncDeleteMultipleSources(len(req.Sources))
+	}
+	if req.Stream != nil {
+		if req.StreamName == "" {
+			return nil, errFileTransferSourceMissing()
+		}
+		command, err := buildPutCommand(req.StreamName, req)
+		if err != nil {
+			return nil, err
+		}
+		if _, err = sc.ExecContext(WithFileStream(ctx, req.Stream), command, nil); err != nil {
+			return collector.results, err
+		}
+		return collector.results, nil
+	}
+	if len(req.Sources) == 0 {
+		return nil, errFileTransferSourceMissing()
+	}
+	for _, source := range req.Sources {
+		command, err := buildPutCommand(source, req)
+		if err != nil {
+			return collector.results, err
+		}
+		if _, err = sc.ExecContext(ctx, command, nil); err != nil {
+			return collector.results, err
+		}
+	}
+	return collector.results, nil
+}
+
+// Get downloads files from a stage to a local directory or to writers and returns the result of each file.
+// An error is returned only if the command fails as a whole, errors of single files are reported in the results.
+func (sc *snowflakeConn) Get(ctx context.Context, req GetRequest) ([]FileTransferResult, error) {
+	collector := &fileTransferResultCollector{}
+	ctx = fileTransferContext(ctx, collector, fileTransferOverrides{
+		progressListener: req.ProgressListener,
+		bandwidthLimit:   req.BandwidthLimit,
+		maxConcurrency:   req.MaxConcurrency,
+		getFileWriter:    req.Writer,
+		preserveStage:    req.PreserveStagePaths,
+		decompressStream: req.DecompressStream,
+		verifyChecksums:  req.VerifyChecksums,
+	})
+	if req.Writer != nil && req.LocalDirectory == "" {
+		req.LocalDirectory = cmp.Or(sc.cfg.TmpDirPath, os.TempDir())
+	}
+	if _, err := sc.ExecContext(ctx, buildGetCommand(req), nil); err != nil {
+		return collector.results, err
+	}
+	return collector.results, nil
+}
@@ -0,0 +1,104 @@
+package gosnowflake
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestBuildPutCommand(t *testing.T) {
+	command, err := buildPutCommand("/tmp/my data/file's.csv", PutRequest{Stage: "@~/dir"})
+	assertNilF(t, err)
+	assertEqualE(t, command, `PUT 'file:///tmp/my data/file\'s.csv' '@~/dir' AUTO_COMPRESS=true OVERWRITE=false`)
+	command, err = buildPutCommand("/tmp/*.csv.gz", PutRequest{
+		Stage:               `@"My Stage"/path`,
+		Overwrite:           true,
+		Parallel:            8,
+		DisableAutoCompress: true,
+		SourceCompression:   "GZIP",
+	})
+	assertNilF(t, err)
+	assertEqualE(t, command, `PUT 'file:///tmp/*.csv.gz' '@"My Stage"/path' PARALLEL=8 AUTO_COMPRESS=false SOURCE_COMPRESSION=GZIP OVERWRITE=true`)
+	command, err = buildPutCommand("/tmp/data.csv", PutRequest{Stage: "@~", SourceCompression: "auto_detect"})
+	assertNilF(t, err)
+	assertEqualE(t, command, `PUT 'file:///tmp/data.csv' '@~' AUTO_COMPRESS=true SOURCE_COMPRESSION=auto_detect OVERWRITE=false`)
+
+	_, err = buildPutCommand("/tmp/data.csv", PutRequest{Stage: "@~", SourceCompression: "GZIP OVERWRITE=TRUE; DROP TABLE t"})
+	assertNotNilF(t, err)
+	assertEqualE(t, err.(*SnowflakeError).Number, ErrCompressionNotSupported)
+}
+
+func TestBuildGetCommand(t *testing.T) {
+	assertEqualE(t, buildGetCommand(GetRequest{Stage: "@~/dir", LocalDirectory: "/tmp/out"}),
+		`GET '@~/dir' 'file:///tmp/out'`)
+	assertEqualE(t, buildGetCommand(GetRequest{Stage: "@~/dir", LocalDirectory: "/tmp/out", Parallel: 4, Pattern: `.*\.csv`}),
+		`GET '@~/dir' 'file:///tmp/out' PARALLEL=4 PATTERN='.*\\.csv'`)
+}
+
+func TestPutWithoutSource(t *testing.T) {
+	sc := &snowflakeConn{cfg: &Config{}}
+	_, err := sc.Put(context.Background(), PutRequest{Stage: "@~"})
+	assertNotNilF(t, err)
+	assertEqualE(t, err.(*SnowflakeError).Number, ErrFileNotExists)
+	_, err = sc.Put(context.Background(), PutRequest{Stage: "@~", Stream: bytes.NewReader(nil)})
+	assertNotNilF(t, err)
+	assertEqualE(t, err.(*SnowflakeError).Number, ErrFileNotExists)
+}
+
+func TestFileTransferResultsCollected(t *testing.T) {
+	tmpDir := t.TempDir()
+	stageDir := filepath.Join(tmpDir, "stage")
+	assertNilF(t, os.Mkdir(stageDir, 0755))
+	data := bytes.Repeat([]byte("0123456789"), 100)
+
+	sc := &snowflakeConn{cfg: &Config{TmpDirPath: tmpDir}}
+	collector := &fileTransferResultCollector{}
+	ctx := fileTransferContext(WithFileStream(context.Background(), bytes.NewReader(data)), collector, fileTransferOverrides{})
+	_, err := sc.processFileTransfer(ctx, &execResponse{Data: execResponseData{
+		Command:           string(uploadCommand),
+		SrcLocations:      []string{"data.csv"},
+		AutoCompress:      true,
+		SourceCompression: "auto_detect",
+		Overwrite:         true,
+		StageInfo: execResponseStageInfo{
+			LocationType: string(local),
+			Location:     stageDir,
+		},
+	}}, "PUT 'file://data.csv' '@~'", false)
+	assertNilF(t, err)
+	assertEqualF(t, len(collector.results), 1)
+	res := collector.results[0]
+	assertEqualE(t, res.Source, "data.csv")
+	assertEqualE(t, res.Target, "data.csv.gz")
+	assertEqualE(t, res.SourceSize, int64(len(data)))
+	assertEqualE(t, res.TargetCompression, "GZIP")
+	assertEqualE(t, res.Status, FileTransferUploaded)
+	assertNilE(t, res.Err)
+	assertTrueE(t, res.Digest != "", "digest should be reported")
+}
+
+func TestFileTransferResultWithError(t *testing.T) {
+	res := newFileTransferResult(uploadCommand, &fileMetadata{
+		srcFileName:  "/tmp/a.csv",
+		dstFileName:  "a.csv.gz",
+		resStatus:    needRetry,
+		errorDetails: errors.New("connection reset"),
+	})
+	assertEqualE(t, res.Status, FileTransferError)
+	assertEqualE(t, res.Err.Error(), "connection reset")
+	assertEqualE(t, res.SourceCompression, "")
+
+	res = newFileTransferResult(downloadCommand, &fileMetadata{
+		srcFileName:   "dir/a.csv.gz",
+		dstFileName:   "a.csv.gz",
+		localLocation: "/tmp/out",
+		resStatus:     downloaded,
+		dstFileSize:   10,
+	})
+	assertEqualE(t, res.Target, filepath.Join("/tmp/out", "a.csv.gz"))
+	assertEqualE(t, res.Status, FileTransferDownloaded)
+	assertEqualE(t, res.TargetSize, int64(10))
+}
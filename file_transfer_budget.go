@@ -0,0 +1,256 @@
+package gosnowflake
+
+import (
+	"cmp"
+	"context"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// bandwidthChunkSize is the maximum number of bytes read at once from a throttled body,
+// so that the bandwidth is shared evenly by concurrent requests.
+const bandwidthChunkSize = 32 * 1024
+
+// transferBudget limits the bandwidth and the number of concurrent cloud storage requests of file transfers.
+// The budget configured on the connection is shared by all PUT and GET commands of the connection,
+// including the files transferred in parallel and the parts of multipart uploads.
+type transferBudget struct {
+	bandwidth *bandwidthLimiter
+	slots     *transferSlots
+}
+
+// newTransferBudget returns nil if neither the bandwidth nor the concurrency is limited.
+func newTransferBudget(bandwidthLimit int64, maxConcurrency int) *transferBudget {
+	if bandwidthLimit <= 0 && maxConcurrency <= 0 {
+		return nil
+	}
+	budget := &transferBudget{}
+	if bandwidthLimit > 0 {
+		budget.bandwidth = &bandwidthLimiter{rate: bandwidthLimit}
+	}
+	if maxConcurrency > 0 {
+		budget.slots = &transferSlots{size: maxConcurrency}
+	}
+	return budget
+}
+
+// acquire waits until n concurrent requests fit in the budget. Fewer requests are granted
+// if n exceeds the whole budget. The returned function releases them.
+func (b *transferBudget) acquire(ctx context.Context, n int) (int, func(), error) {
+	if b == nil || b.slots == nil {
+		return n, func() {}, nil
+	}
+	return b.slots.acquire(ctx, n)
+}
+
+// transport returns a round tripper limiting the bandwidth of the request and response bodies.
+func (b *transferBudget) transport(rt http.RoundTripper) http.RoundTripper {
+	if b == nil || b.bandwidth == nil {
+		return rt
+	}
+	return &throttledTransport{rt: rt, limiter: b.bandwidth}
+}
+
+// transferSlots is a weighted semaphore granting the waiters in order.
+type transferSlots struct {
+	mu      sync.Mutex
+	size    int
+	used    int
+	waiters []*slotsWaiter
+}
+
+type slotsWaiter struct {
+	n     int
+	ready chan struct{}
+}
+
+func (s *transferSlots) acquire(ctx context.Context, n int) (int, func(), error) {
+	n = min(max(n, 1), s.size)
+	release := func() {
+		s.release(n)
+	}
+	s.mu.Lock()
+	if len(s.waiters) == 0 && s.used+n <= s.size {
+		s.used += n
+		s.mu.Unlock()
+		return n, release, nil
+	}
+	w := &slotsWaiter{n: n, ready: make(chan struct{})}
+	s.waiters = append(s.waiters, w)
+	s.mu.Unlock()
+
+	select {
+	case <-w.ready:
+		return n, release, nil
+	case <-ctx.Done():
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		select {
+		case <-w.ready:
+			// the slots were granted in the meantime
+			s.used -= n
+		default:
+			for i, waiter := range s.waiters {
+				if waiter == w {
+					s.waiters = append(s.waiters[:i], s.waiters[i+1:]...)
+					break
+				}
+			}
+		}
+		s.notify()
+		return 0, nil, ctx.Err()
+	}
+}
+
+func (s *transferSlots) release(n int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.used -= n
+	s.notify()
+}
+
+// notify must be called with s.mu held.
+func (s *transferSlots) notify() {
+	for len(s.waiters) > 0 {
+		w := s.waiters[0]
+		if s.used+w.n > s.size {
+			return
+		}
+		s.used += w.n
+		s.waiters = s.waiters[1:]
+		close(w.ready)
+	}
+}
+
+// bandwidthLimiter paces the bytes transferred by all requests to rate bytes per second.
+type bandwidthLimiter struct {
+	mu   sync.Mutex
+	rate int64
+	// next is the time when the bytes transferred so far are due at the rate
+	next time.Time
+}
+
+// wait blocks until the transfer of n more bytes is due.
+func (l *bandwidthLimiter) wait(ctx context.Context, n int) error {
+	if n <= 0 {
+		return nil
+	}
+	l.mu.Lock()
+	now := time.Now()
+	if l.next.Before(now) {
+		l.next = now
+	}
+	l.next = l.next.Add(time.Duration(float64(n) * float64(time.Second) / float64(l.rate)))
+	delay := l.next.Sub(now)
+	l.mu.Unlock()
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (l *bandwidthLimiter) chunkSize() int {
+	return int(min(bandwidthChunkSize, l.rate))
+}
+
+func (l *bandwidthLimiter) readCloser(ctx context.Context, rc io.ReadCloser) io.ReadCloser {
+	return &throttledReader{ctx: ctx, rc: rc, limiter: l}
+}
+
+type throttledReader struct {
+	ctx     context.Context
+	rc      io.ReadCloser
+	limiter *bandwidthLimiter
+}
+
+func (tr *throttledReader) Read(p []byte) (int, error) {
+	if chunk := tr.limiter.chunkSize(); len(p) > chunk {
+		p = p[:chunk]
+	}
+	n, err := tr.rc.Read(p)
+	if waitErr := tr.limiter.wait(tr.ctx, n); waitErr != nil {
+		return n, waitErr
+	}
+	return n, err
+}
+
+func (tr *throttledReader) Close() error {
+	return tr.rc.Close()
+}
+
+// throttledTransport limits the bandwidth of the cloud storage requests of file transfers.
+type throttledTransport struct {
+	rt      http.RoundTripper
+	limiter *bandwidthLimiter
+}
+
+func (t *throttledTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	if req.Body != nil && req.Body != http.NoBody {
+		throttled := *req
+		throttled.Body = t.limiter.readCloser(ctx, req.Body)
+		if getBody := req.GetBody; getBody != nil {
+			throttled.GetBody = func() (io.ReadCloser, error) {
+				body, err := getBody()
+				if err != nil {
+					return nil, err
+				}
+				return t.limiter.readCloser(ctx, body), nil
+			}
+		}
+		req = &throttled
+	}
+	resp, err := t.rt.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.Body != nil && resp.Body != http.NoBody {
+		resp.Body = t.limiter.readCloser(ctx, resp.Body)
+	}
+	return resp, nil
+}
+
+// getTransferBudget returns the budget shared by the file transfers of the connection.
+func (sc *snowflakeConn) getTransferBudget() *transferBudget {
+	sc.transferBudgetOnce.Do(func() {
+		sc.transferBudget = newTransferBudget(sc.cfg.FileTransferBandwidthLimit, sc.cfg.FileTransferMaxConcurrency)
+	})
+	return sc.transferBudget
+}
+
+// getTransferBudget returns the budget of the connection, unless the command sets its own limits.
+func (sfa *snowflakeFileTransferAgent) getTransferBudget() *transferBudget {
+	if sfa.options.BandwidthLimit == 0 && sfa.options.MaxConcurrency == 0 {
+		return sfa.sc.getTransferBudget()
+	}
+	return newTransferBudget(
+		cmp.Or(sfa.options.BandwidthLimit, sfa.sc.cfg.FileTransferBandwidthLimit),
+		cmp.Or(sfa.options.MaxConcurrency, sfa.sc.cfg.FileTransferMaxConcurrency))
+}
+
+// storageConfig returns the configuration of the cloud storage clients,
+// whose transport limits the bandwidth of the command.
+func (sfa *snowflakeFileTransferAgent) storageConfig() *Config {
+	if sfa.storageCfg != nil {
+		return sfa.storageCfg
+	}
+	return sfa.sc.cfg
+}
+
+// acquireTransferSlots waits until the requests of the file fit in the concurrency budget
+// and lowers the multipart concurrency of the file to the granted number of requests.
+func (sfa *snowflakeFileTransferAgent) acquireTransferSlots(meta *fileMetadata) (func(), error) {
+	granted, release, err := sfa.budget.acquire(sfa.ctx, int(meta.parallel))
+	if err != nil {
+		return nil, err
+	}
+	meta.parallel = int64(granted)
+	return release, nil
+}
@@ -0,0 +1,136 @@
+package gosnowflake
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestTransferSlots(t *testing.T) {
+	budget := newTransferBudget(0, 3)
+	ctx := context.Background()
+
+	granted, releaseLarge, err := budget.acquire(ctx, 8)
+	assertNilF(t, err)
+	assertEqualE(t, granted, 3, "the whole budget should be granted at most")
+
+	acquired := make(chan int)
+	go func() {
+		n, release, err := budget.acquire(ctx, 1)
+		if err != nil {
+			acquired <- 0
+			return
+		}
+		release()
+		acquired <- n
+	}()
+	select {
+	case <-acquired:
+		t.Fatal("slot should not be granted while the budget is used")
+	case <-time.After(50 * time.Millisecond):
+	}
+	releaseLarge()
+	select {
+	case n := <-acquired:
+		assertEqualE(t, n, 1)
+	case <-time.After(time.Second):
+		t.Fatal("slot should be granted after release")
+	}
+
+	_, release, err := budget.acquire(ctx, 3)
+	assertNilF(t, err)
+	cancelCtx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+	_, _, err = budget.acquire(cancelCtx, 1)
+	assertTrueE(t, errors.Is(err, context.DeadlineExceeded), "acquire should stop when the context is done")
+	release()
+	granted, release, err = budget.acquire(ctx, 3)
+	assertNilF(t, err)
+	assertEqualE(t, granted, 3, "cancelled waiter should not hold slots")
+	release()
+}
+
+func TestTransferBudgetUnlimited(t *testing.T) {
+	var budget *transferBudget
+	assertNilE(t, newTransferBudget(0, 0))
+	assertNilE(t, newTransferBudget(-1, -1))
+	granted, release, err := budget.acquire(context.Background(), 8)
+	assertNilF(t, err)
+	assertEqualE(t, granted, 8)
+	release()
+	assertEqualE(t, budget.transport(http.DefaultTransport), http.DefaultTransport)
+}
+
+type bodyEchoTransport struct{}
+
+func (bodyEchoTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	data, err := io.ReadAll(req.Body)
+	if err != nil {
+		return nil, err
+	}
+	return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(bytes.NewReader(data))}, nil
+}
+
+func TestThrottledTransport(t *testing.T) {
+	const rate = 100 * 1024
+	data := bytes.Repeat([]byte{'a'}, rate/5)
+	transport := newTransferBudget(rate, 0).transport(bodyEchoTransport{})
+
+	start := time.Now()
+	req, err := http.NewRequest(http.MethodPut, "https://example.com/file", bytes.NewReader(data))
+	assertNilF(t, err)
+	resp, err := transport.RoundTrip(req)
+	assertNilF(t, err)
+	body, err := io.ReadAll(resp.Body)
+	assertNilF(t, err)
+	assertNilF(t, resp.Body.Close())
+	elapsed := time.Since(start)
+
+	assertTrueE(t, bytes.Equal(body, data), "body should not be changed")
+	// the request and the response bodies are 200ms each at the rate
+	assertTrueE(t, elapsed >= 350*time.Millisecond, "transfer took "+elapsed.String())
+	assertTrueE(t, elapsed < 2*time.Second, "transfer took "+elapsed.String())
+}
+
+func TestFileTransferBudgetSelection(t *testing.T) {
+	sc := &snowflakeConn{cfg: &Config{FileTransferBandwidthLimit: 1024, FileTransferMaxConcurrency: 4}}
+	sfa := &snowflakeFileTransferAgent{sc: sc, options: &SnowflakeFileTransferOptions{}}
+	budget := sfa.getTransferBudget()
+	assertNotNilF(t, budget)
+	assertEqualE(t, budget, sc.getTransferBudget(), "commands should share the budget of the connection")
+	assertEqualE(t, budget.bandwidth.rate, int64(1024))
+	assertEqualE(t, budget.slots.size, 4)
+
+	sfa.options.MaxConcurrency = 2
+	budget = sfa.getTransferBudget()
+	assertTrueE(t, budget != sc.getTransferBudget(), "command limits should not change the connection budget")
+	assertEqualE(t, budget.bandwidth.rate, int64(1024))
+	assertEqualE(t, budget.slots.size, 2)
+
+	sfa.options.BandwidthLimit = -1
+	sfa.options.MaxConcurrency = -1
+	assertNilE(t, sfa.getTransferBudget(), "negative limits should remove the limits of the connection")
+}
+
+func TestAcquireTransferSlotsLimitsMultipartConcurrency(t *testing.T) {
+	sfa := &snowflakeFileTransferAgent{
+		ctx:    context.Background(),
+		budget: newTransferBudget(0, 2),
+	}
+	meta := &fileMetadata{parallel: 8}
+	release, err := sfa.acquireTransferSlots(meta)
+	assertNilF(t, err)
+	assertEqualE(t, meta.parallel, int64(2))
+	release()
+
+	sfa.budget = nil
+	meta.parallel = 8
+	release, err = sfa.acquireTransferSlots(meta)
+	assertNilF(t, err)
+	assertEqualE(t, meta.parallel, int64(8), "unlimited budget should not change the concurrency")
+	release()
+}
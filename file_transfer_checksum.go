@@ -0,0 +1,159 @@
+package gosnowflake
+
+import (
+	"bytes"
+	"cmp"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"hash/crc32"
+	"io"
+	"os"
+	"strings"
+)
+
+var crc32cTable = crc32.MakeTable(crc32.Castagnoli)
+
+// verifyChecksums reports whether the checksums of the transferred file are verified, see SnowflakeFileTransferOptions.
+func (meta *fileMetadata) verifyChecksums() bool {
+	return meta.options != nil && meta.options.VerifyChecksums
+}
+
+// contentChecksums are the base64 encoded checksums of the content sent to a cloud storage,
+// which validates them when the content is received.
+type contentChecksums struct {
+	md5    string
+	crc32c string
+}
+
+func computeContentChecksums(r io.Reader) (*contentChecksums, error) {
+	md5Hash := md5.New()
+	crc32cHash := crc32.New(crc32cTable)
+	if _, err := io.Copy(io.MultiWriter(md5Hash, crc32cHash), r); err != nil {
+		return nil, err
+	}
+	crc := make([]byte, 4)
+	binary.BigEndian.PutUint32(crc, crc32cHash.Sum32())
+	return &contentChecksums{
+		md5:    base64.StdEncoding.EncodeToString(md5Hash.Sum(nil)),
+		crc32c: base64.StdEncoding.EncodeToString(crc),
+	}, nil
+}
+
+// gcsHash returns the value of the x-goog-hash header.
+func (c *contentChecksums) gcsHash() string {
+	return "crc32c=" + c.crc32c + ",md5=" + c.md5
+}
+
+// parseGcsHash returns the checksums of an x-goog-hash header, nil if it has none.
+func parseGcsHash(values []string) *contentChecksums {
+	checksums := &contentChecksums{}
+	for _, value := range values {
+		for _, hash := range strings.Split(value, ",") {
+			name, checksum, _ := strings.Cut(strings.TrimSpace(hash), "=")
+			switch name {
+			case "crc32c":
+				checksums.crc32c = checksum
+			case "md5":
+				checksums.md5 = checksum
+			}
+		}
+	}
+	if checksums.crc32c == "" && checksums.md5 == "" {
+		return nil
+	}
+	return checksums
+}
+
+// openUploadedContent returns the content sent to the cloud storage, nil if it is not kept after the upload.
+func openUploadedContent(meta *fileMetadata) (*io.SectionReader, func(), error) {
+	if meta.srcStream != nil {
+		data := cmp.Or(meta.realSrcStream, meta.srcStream).Bytes()
+		return io.NewSectionReader(bytes.NewReader(data), 0, int64(len(data))), func() {}, nil
+	}
+	if meta.realSrcFileName == "" {
+		return nil, func() {}, nil
+	}
+	f, err := os.Open(meta.realSrcFileName)
+	if err != nil {
+		return nil, nil, err
+	}
+	stat, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, nil, err
+	}
+	return io.NewSectionReader(f, 0, stat.Size()), func() { f.Close() }, nil
+}
+
+// verifyUploadedChecksums compares the checksums computed by the storage with the checksums of the uploaded content.
+// Checksums which the storage does not report are not compared.
+func verifyUploadedChecksums(log SFLogger, meta *fileMetadata, header *fileHeader) error {
+	if header == nil || header.storageChecksums == nil {
+		log.Debugf("storage reported no checksums of %v", meta.dstFileName)
+		return nil
+	}
+	storage := header.storageChecksums
+	content, closeContent, err := openUploadedContent(meta)
+	if err != nil {
+		return err
+	}
+	defer closeContent()
+	if content == nil {
+		return nil
+	}
+	expected, err := computeContentChecksums(content)
+	if err != nil {
+		return err
+	}
+	if storage.crc32c != "" && storage.crc32c != expected.crc32c {
+		return errChecksumMismatch(meta.dstFileName, expected.crc32c, storage.crc32c)
+	}
+	if storage.md5 != "" && storage.md5 != expected.md5 {
+		return errChecksumMismatch(meta.dstFileName, expected.md5, storage.md5)
+	}
+	return nil
+}
+
+// verifyDownloadedDigest compares the SHA-256 digest of the downloaded content with the digest stored on the stage.
+// Files uploaded without a digest cannot be verified.
+func verifyDownloadedDigest(log SFLogger, meta *fileMetadata, header *fileHeader, fullDstFileName string) error {
+	if header == nil || header.digest == "" {
+		log.Debugf("file %v has no digest to verify", meta.srcFileName)
+		return nil
+	}
+	var r io.Reader
+	if meta.options.GetFileToStream {
+		if meta.encryptionMaterial != nil {
+			r = bytes.NewReader(meta.sfa.streamBuffer.Bytes())
+		} else {
+			r = bytes.NewReader(meta.dstStream.Bytes())
+		}
+	} else {
+		f, err := os.Open(fullDstFileName)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		r = f
+	}
+	h := sha256.New()
+	if _, err := io.Copy(h, r); err != nil {
+		return err
+	}
+	return verifyDownloadedStreamDigest(log, meta, header, base64.StdEncoding.EncodeToString(h.Sum(nil)))
+}
+
+// verifyDownloadedStreamDigest compares the SHA-256 digest computed while the content was streamed
+// with the digest stored on the stage.
+func verifyDownloadedStreamDigest(log SFLogger, meta *fileMetadata, header *fileHeader, actual string) error {
+	if header == nil || header.digest == "" {
+		log.Debugf("file %v has no digest to verify", meta.srcFileName)
+		return nil
+	}
+	if actual != header.digest {
+		return errChecksumMismatch(meta.srcFileName, header.digest, actual)
+	}
+	return nil
+}
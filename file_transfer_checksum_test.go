@@ -0,0 +1,114 @@
+package gosnowflake
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/feature/s3/manager"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+func TestComputeContentChecksums(t *testing.T) {
+	checksums, err := computeContentChecksums(strings.NewReader("hello"))
+	assertNilF(t, err)
+	assertEqualE(t, checksums.md5, "XUFAKrxLKna5cZ2REBfFkg==")
+	assertEqualE(t, checksums.crc32c, "mnG7TA==")
+	assertEqualE(t, checksums.gcsHash(), "crc32c=mnG7TA==,md5=XUFAKrxLKna5cZ2REBfFkg==")
+}
+
+func TestUploadToS3WithChecksumVerification(t *testing.T) {
+	info := execResponseStageInfo{
+		Location:     "sfc-customer-stage/rwyi-testacco/users/9220/",
+		LocationType: "S3",
+	}
+	dir, err := os.Getwd()
+	assertNilF(t, err)
+	storageCrc32c := "mnG7TA=="
+	uploadMeta := fileMetadata{
+		name:              "data1.txt.gz",
+		stageLocationType: "S3",
+		noSleepingTime:    true,
+		parallel:          1,
+		client:            s3.New(s3.Options{}),
+		sha256Digest:      "123456789abcdef",
+		stageInfo:         &info,
+		dstFileName:       "data1.txt.gz",
+		srcFileName:       path.Join(dir, "/test_data/put_get_1.txt"),
+		overwrite:         true,
+		options: &SnowflakeFileTransferOptions{
+			MultiPartThreshold: dataSizeThreshold,
+			VerifyChecksums:    true,
+		},
+		mockUploader: mockUploadObjectAPI(func(ctx context.Context, params *s3.PutObjectInput, optFns ...func(*manager.Uploader)) (*manager.UploadOutput, error) {
+			assertEqualE(t, params.ChecksumAlgorithm, types.ChecksumAlgorithmCrc32c)
+			return &manager.UploadOutput{}, nil
+		}),
+		mockHeader: mockHeaderAPI(func(ctx context.Context, params *s3.HeadObjectInput, optFns ...func(*s3.Options)) (*s3.HeadObjectOutput, error) {
+			assertEqualE(t, params.ChecksumMode, types.ChecksumModeEnabled)
+			return &s3.HeadObjectOutput{
+				Metadata:       map[string]string{sfcDigest: "123456789abcdef"},
+				ChecksumCRC32C: &storageCrc32c,
+			}, nil
+		}),
+		sfa: &snowflakeFileTransferAgent{
+			sc: &snowflakeConn{
+				cfg: &Config{},
+			},
+		},
+	}
+	uploadMeta.realSrcFileName = uploadMeta.srcFileName
+
+	err = (&remoteStorageUtil{cfg: &Config{}}).uploadOneFileWithRetry(&uploadMeta)
+	assertNotNilF(t, err)
+	assertEqualE(t, err.(*SnowflakeError).Number, ErrChecksumMismatch)
+	assertEqualE(t, uploadMeta.resStatus, errStatus)
+
+	content, err := os.ReadFile(uploadMeta.realSrcFileName)
+	assertNilF(t, err)
+	checksums, err := computeContentChecksums(bytes.NewReader(content))
+	assertNilF(t, err)
+	storageCrc32c = checksums.crc32c
+	err = (&remoteStorageUtil{cfg: &Config{}}).uploadOneFileWithRetry(&uploadMeta)
+	assertNilF(t, err)
+	assertEqualE(t, uploadMeta.resStatus, uploaded)
+
+	storageCrc32c = "AAAAAA==-2"
+	err = (&remoteStorageUtil{cfg: &Config{}}).uploadOneFileWithRetry(&uploadMeta)
+	assertNilF(t, err, "checksums of multipart uploads should not be compared")
+	assertEqualE(t, uploadMeta.resStatus, uploaded)
+}
+
+func TestParseGcsHash(t *testing.T) {
+	checksums := parseGcsHash([]string{"crc32c=mnG7TA==", "md5=XUFAKrxLKna5cZ2REBfFkg=="})
+	assertNotNilF(t, checksums)
+	assertEqualE(t, checksums.crc32c, "mnG7TA==")
+	assertEqualE(t, checksums.md5, "XUFAKrxLKna5cZ2REBfFkg==")
+	checksums = parseGcsHash([]string{"crc32c=mnG7TA==,md5=XUFAKrxLKna5cZ2REBfFkg=="})
+	assertNotNilF(t, checksums)
+	assertEqualE(t, checksums.gcsHash(), "crc32c=mnG7TA==,md5=XUFAKrxLKna5cZ2REBfFkg==")
+	assertNilE(t, parseGcsHash(nil))
+}
+
+func TestVerifyDownloadedDigest(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), "data.csv.gz")
+	assertNilF(t, os.WriteFile(fileName, []byte("hello"), readWriteFileMode))
+	meta := &fileMetadata{
+		srcFileName: "data.csv.gz",
+		options:     &SnowflakeFileTransferOptions{VerifyChecksums: true},
+	}
+	fileUtil := new(snowflakeFileUtil)
+	digest, _, err := fileUtil.getDigestAndSizeForFile(fileName)
+	assertNilF(t, err)
+
+	assertNilE(t, verifyDownloadedDigest(logger, meta, &fileHeader{digest: digest}, fileName))
+	assertNilE(t, verifyDownloadedDigest(logger, meta, &fileHeader{}, fileName), "files without digest should not be verified")
+	err = verifyDownloadedDigest(logger, meta, &fileHeader{digest: "other digest"}, fileName)
+	assertNotNilF(t, err)
+	assertEqualE(t, err.(*SnowflakeError).Number, ErrChecksumMismatch)
+}
@@ -0,0 +1,100 @@
+package gosnowflake
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// GetFileWriterFunc returns the writer of a file downloaded by GET. name is the path of the file
+// relative to the stage location. The writer is closed when the whole file is written to it.
+type GetFileWriterFunc func(name string) (io.WriteCloser, error)
+
+// stagePath returns the slash separated path of the downloaded file relative to the stage location.
+func (meta *fileMetadata) stagePath() string {
+	return strings.TrimLeft(meta.dstFileName, "/")
+}
+
+func (meta *fileMetadata) getFileWriter() GetFileWriterFunc {
+	if meta.options == nil {
+		return nil
+	}
+	return meta.options.GetFileWriter
+}
+
+func (meta *fileMetadata) preserveStagePaths() bool {
+	return meta.options != nil && meta.options.PreserveStagePaths
+}
+
+// downloadFilePath returns the local path the file is downloaded to and creates its directory if the stage
+// paths are preserved.
+func downloadFilePath(meta *fileMetadata, localLocation string) (string, error) {
+	if !meta.preserveStagePaths() {
+		return filepath.Join(localLocation, baseName(meta.dstFileName)), nil
+	}
+	name := filepath.FromSlash(meta.stagePath())
+	if !filepath.IsLocal(name) {
+		return "", errDownloadPathOutsideDirectory(meta.srcFileName)
+	}
+	fullName := filepath.Join(localLocation, name)
+	if err := os.MkdirAll(filepath.Dir(fullName), os.ModePerm); err != nil {
+		return "", err
+	}
+	return fullName, nil
+}
+
+// downloadTarget returns the target of the downloaded file reported in its FileTransferResult.
+func downloadTarget(meta *fileMetadata) string {
+	switch {
+	case meta.getFileWriter() != nil:
+		return meta.stagePath()
+	case meta.preserveStagePaths():
+		return filepath.Join(meta.localLocation, filepath.FromSlash(meta.stagePath()))
+	default:
+		return filepath.Join(meta.localLocation, baseName(meta.dstFileName))
+	}
+}
+
+// writeDownloadStream writes the downloaded content to the writer returned by GetFileWriter as it is read,
+// decompressing it if DecompressGetStream is set. It returns the SHA-256 digest of the content before decompression.
+func writeDownloadStream(meta *fileMetadata, src io.Reader) (string, error) {
+	h := sha256.New()
+	content := io.TeeReader(src, h)
+	r, err := decompressDownloadedFile(meta.options, meta.dstFileName, content)
+	if err != nil {
+		return "", err
+	}
+	defer r.Close()
+	dst, err := meta.getFileWriter()(meta.stagePath())
+	if err != nil {
+		return "", err
+	}
+	n, err := io.Copy(dst, r)
+	if closeErr := dst.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return "", err
+	}
+	// the decompression may stop before the end of the content, which is still part of the digest
+	if _, err = io.Copy(io.Discard, content); err != nil {
+		return "", err
+	}
+	meta.dstFileSize = n
+	return base64.StdEncoding.EncodeToString(h.Sum(nil)), nil
+}
+
+// readCloserWithCancel cancels the context of a download request when its response body is closed.
+type readCloserWithCancel struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (r *readCloserWithCancel) Close() error {
+	defer r.cancel()
+	return r.ReadCloser.Close()
+}
@@ -0,0 +1,131 @@
+package gosnowflake
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+type testGetFileWriter struct {
+	bytes.Buffer
+	closed bool
+}
+
+func (w *testGetFileWriter) Close() error {
+	w.closed = true
+	return nil
+}
+
+func setupLocalStageForGet(t *testing.T) (string, *execResponse) {
+	stageDir := t.TempDir()
+	for _, name := range []string{"data/sub/a.csv", "data/b.csv"} {
+		file := filepath.Join(stageDir, filepath.FromSlash(name))
+		assertNilF(t, os.MkdirAll(filepath.Dir(file), os.ModePerm))
+		assertNilF(t, os.WriteFile(file, []byte(name), readWriteFileMode))
+	}
+	return stageDir, &execResponse{Data: execResponseData{
+		Command:       string(downloadCommand),
+		SrcLocations:  []string{"data/sub/a.csv", "data/b.csv"},
+		LocalLocation: t.TempDir(),
+		Parallel:      2,
+		StageInfo: execResponseStageInfo{
+			LocationType: string(local),
+			Location:     stageDir,
+		},
+	}}
+}
+
+func TestGetPreservingStagePaths(t *testing.T) {
+	_, data := setupLocalStageForGet(t)
+	localDir := data.Data.LocalLocation
+	sc := &snowflakeConn{cfg: &Config{TmpDirPath: t.TempDir()}}
+	collector := &fileTransferResultCollector{}
+	ctx := fileTransferContext(context.Background(), collector, fileTransferOverrides{preserveStage: true})
+	_, err := sc.processFileTransfer(ctx, data, "GET @~/data file:///tmp", false)
+	assertNilF(t, err)
+
+	assertEqualF(t, len(collector.results), 2)
+	for _, res := range collector.results {
+		assertEqualE(t, res.Status, FileTransferDownloaded)
+		content, err := os.ReadFile(res.Target)
+		assertNilF(t, err)
+		assertEqualE(t, string(content), res.Source)
+	}
+	assertEqualE(t, collector.results[0].Target, filepath.Join(localDir, "b.csv"))
+	assertEqualE(t, collector.results[1].Target, filepath.Join(localDir, "sub", "a.csv"))
+}
+
+func TestGetToFileWriters(t *testing.T) {
+	_, data := setupLocalStageForGet(t)
+	var mu sync.Mutex
+	writers := make(map[string]*testGetFileWriter)
+	sc := &snowflakeConn{cfg: &Config{TmpDirPath: t.TempDir()}}
+	collector := &fileTransferResultCollector{}
+	ctx := fileTransferContext(context.Background(), collector, fileTransferOverrides{
+		getFileWriter: func(name string) (io.WriteCloser, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			w := &testGetFileWriter{}
+			writers[name] = w
+			return w, nil
+		},
+	})
+	_, err := sc.processFileTransfer(ctx, data, "GET @~/data file:///tmp", false)
+	assertNilF(t, err)
+
+	assertEqualF(t, len(writers), 2)
+	for name, w := range writers {
+		assertTrueE(t, w.closed, "writer should be closed")
+		assertEqualE(t, w.String(), "data/"+name)
+	}
+	assertEqualF(t, len(collector.results), 2)
+	assertEqualE(t, collector.results[0].Target, "b.csv")
+	assertEqualE(t, collector.results[1].Target, "sub/a.csv")
+	assertEqualE(t, collector.results[1].TargetSize, int64(len("data/sub/a.csv")))
+	entries, err := os.ReadDir(data.Data.LocalLocation)
+	assertNilF(t, err)
+	assertEqualE(t, len(entries), 0, "nothing should be written to the local directory")
+}
+
+func TestGetToFileWriterFailure(t *testing.T) {
+	_, data := setupLocalStageForGet(t)
+	sc := &snowflakeConn{cfg: &Config{TmpDirPath: t.TempDir()}}
+	collector := &fileTransferResultCollector{}
+	ctx := fileTransferContext(context.Background(), collector, fileTransferOverrides{
+		getFileWriter: func(name string) (io.WriteCloser, error) {
+			if name == "b.csv" {
+				return nil, errors.New("no space left")
+			}
+			return &testGetFileWriter{}, nil
+		},
+	})
+	_, err := sc.processFileTransfer(ctx, data, "GET @~/data file:///tmp", false)
+	assertNilF(t, err)
+
+	assertEqualF(t, len(collector.results), 2)
+	assertEqualE(t, collector.results[0].Status, FileTransferError)
+	assertNotNilE(t, collector.results[0].Err)
+	assertEqualE(t, collector.results[1].Status, FileTransferDownloaded)
+}
+
+func TestDownloadFilePathOutsideDirectory(t *testing.T) {
+	meta := &fileMetadata{
+		srcFileName: "data/../../etc/passwd",
+		dstFileName: "../../etc/passwd",
+		options:     &SnowflakeFileTransferOptions{PreserveStagePaths: true},
+	}
+	_, err := downloadFilePath(meta, t.TempDir())
+	assertNotNilF(t, err)
+	assertEqualE(t, err.(*SnowflakeError).Number, ErrDownloadPathOutsideDirectory)
+
+	meta.options.PreserveStagePaths = false
+	localDir := t.TempDir()
+	fileName, err := downloadFilePath(meta, localDir)
+	assertNilF(t, err)
+	assertEqualE(t, fileName, filepath.Join(localDir, "passwd"))
+}
@@ -0,0 +1,273 @@
+package gosnowflake
+
+import (
+	"io"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ProgressListener receives the progress of the files transferred by PUT and GET commands.
+// Files are transferred in parallel, so the methods may be called concurrently. They are called
+// without holding locks of the driver, but a slow listener still slows down the transfer.
+type ProgressListener interface {
+	// OnFileStart is called when the transfer of a file starts.
+	OnFileStart(progress FileTransferProgress)
+	// OnFileProgress is called when bytes of a file are transferred.
+	OnFileProgress(progress FileTransferProgress)
+	// OnFileRetry is called when the transfer of a file failed and is retried.
+	// The bytes transferred by the failed attempt are not counted anymore.
+	OnFileRetry(progress FileTransferProgress)
+	// OnFileComplete is called when the transfer of a file finished, successfully or not.
+	OnFileComplete(progress FileTransferProgress)
+}
+
+// FileTransferProgress describes the progress of a file transfer and of the whole command.
+type FileTransferProgress struct {
+	// File is the name of the transferred file.
+	File string
+	// Upload is true for PUT and false for GET.
+	Upload bool
+	// FileSize is the number of bytes to transfer, 0 if it is not known, e.g. for streams.
+	FileSize int64
+	// FileBytes is the number of bytes of the file transferred so far.
+	FileBytes int64
+	// TotalBytes is the number of bytes of all the files of the command transferred so far.
+	TotalBytes int64
+	// Throughput is the number of bytes per second transferred by the command since it started.
+	Throughput float64
+	// Attempt is the number of the transfer attempt of the file, starting with 1.
+	Attempt int
+	// Status is the result of the transfer. It is set on completion only.
+	Status FileTransferStatus
+	// Err is the error of the failed attempt on retry and the error of the transfer on completion.
+	Err error
+}
+
+// progressTracker reports the progress of the files of a command to the listener.
+type progressTracker struct {
+	listener   ProgressListener
+	upload     bool
+	startTime  time.Time
+	totalBytes atomic.Int64
+}
+
+func newProgressTracker(listener ProgressListener, upload bool) *progressTracker {
+	if listener == nil {
+		return nil
+	}
+	return &progressTracker{
+		listener:  listener,
+		upload:    upload,
+		startTime: time.Now(),
+	}
+}
+
+// fileProgress reports the progress of a single file. All methods are no-ops on a nil receiver,
+// so the storage clients report progress unconditionally.
+type fileProgress struct {
+	tracker *progressTracker
+	file    string
+
+	mu          sync.Mutex
+	size        int64
+	transferred int64
+	attempt     int
+}
+
+// file returns the progress of the file, starting it on the first call.
+func (pt *progressTracker) file(meta *fileMetadata, size int64) *fileProgress {
+	if pt == nil {
+		return nil
+	}
+	if meta.progress != nil {
+		return meta.progress
+	}
+	fp := &fileProgress{
+		tracker: pt,
+		file:    meta.name,
+		size:    size,
+		attempt: 1,
+	}
+	pt.listener.OnFileStart(fp.event(nil))
+	return fp
+}
+
+// event must be called with fp.mu held or before the progress is shared.
+func (fp *fileProgress) event(err error) FileTransferProgress {
+	total := fp.tracker.totalBytes.Load()
+	var throughput float64
+	if elapsed := time.Since(fp.tracker.startTime).Seconds(); elapsed > 0 {
+		throughput = float64(total) / elapsed
+	}
+	return FileTransferProgress{
+		File:       fp.file,
+		Upload:     fp.tracker.upload,
+		FileSize:   fp.size,
+		FileBytes:  fp.transferred,
+		TotalBytes: total,
+		Throughput: throughput,
+		Attempt:    fp.attempt,
+		Err:        err,
+	}
+}
+
+// setSize sets the number of bytes to transfer once it is known, e.g. after compression.
+func (fp *fileProgress) setSize(size int64) {
+	if fp == nil {
+		return
+	}
+	fp.mu.Lock()
+	defer fp.mu.Unlock()
+	fp.size = size
+}
+
+// add reports n more bytes transferred.
+func (fp *fileProgress) add(n int64) {
+	if fp == nil || n == 0 {
+		return
+	}
+	fp.mu.Lock()
+	event := fp.setTransferred(fp.transferred + n)
+	fp.mu.Unlock()
+	fp.tracker.listener.OnFileProgress(event)
+}
+
+// set reports the number of bytes transferred so far, for storages reporting cumulative progress.
+func (fp *fileProgress) set(n int64) {
+	if fp == nil {
+		return
+	}
+	fp.mu.Lock()
+	if n == fp.transferred {
+		fp.mu.Unlock()
+		return
+	}
+	event := fp.setTransferred(n)
+	fp.mu.Unlock()
+	fp.tracker.listener.OnFileProgress(event)
+}
+
+// setTransferred must be called with fp.mu held, it returns the event to pass to the listener after unlocking.
+func (fp *fileProgress) setTransferred(n int64) FileTransferProgress {
+	if fp.size > 0 {
+		// bodies may be read again by the SDKs, e.g. to retry a request
+		n = min(n, fp.size)
+	}
+	fp.tracker.totalBytes.Add(n - fp.transferred)
+	fp.transferred = n
+	return fp.event(nil)
+}
+
+// retry reports a failed attempt, which is retried from the beginning.
+func (fp *fileProgress) retry(err error) {
+	if fp == nil {
+		return
+	}
+	fp.mu.Lock()
+	fp.tracker.totalBytes.Add(-fp.transferred)
+	fp.transferred = 0
+	event := fp.event(err)
+	fp.attempt++
+	fp.mu.Unlock()
+	fp.tracker.listener.OnFileRetry(event)
+}
+
+func (fp *fileProgress) complete(status resultStatus, err error) {
+	if fp == nil {
+		return
+	}
+	fp.mu.Lock()
+	event := fp.event(err)
+	fp.mu.Unlock()
+	event.Status = FileTransferStatus(status.String())
+	if err != nil {
+		event.Status = FileTransferError
+	}
+	fp.tracker.listener.OnFileComplete(event)
+}
+
+// reader returns a reader reporting the bytes read from r as transferred.
+func (fp *fileProgress) reader(r io.Reader) io.Reader {
+	if fp == nil {
+		return r
+	}
+	return &progressReader{r: r, progress: fp}
+}
+
+// readCloser returns a request or response body reporting the bytes read as transferred.
+func (fp *fileProgress) readCloser(rc io.ReadCloser) io.ReadCloser {
+	if fp == nil {
+		return rc
+	}
+	return &progressReader{r: rc, progress: fp}
+}
+
+// progressReader closes the underlying reader if it is a closer, so that it can replace request bodies.
+type progressReader struct {
+	r        io.Reader
+	progress *fileProgress
+}
+
+func (pr *progressReader) Read(p []byte) (int, error) {
+	n, err := pr.r.Read(p)
+	pr.progress.add(int64(n))
+	return n, err
+}
+
+func (pr *progressReader) Close() error {
+	if closer, ok := pr.r.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+// readerAtSeeker returns a reader of the file reporting the bytes read as transferred,
+// keeping io.ReaderAt and io.Seeker so that the S3 uploader reads parts without buffering them.
+func (fp *fileProgress) readerAtSeeker(f *os.File) io.Reader {
+	if fp == nil {
+		return f
+	}
+	return &progressReaderAtSeeker{f: f, progress: fp}
+}
+
+type progressReaderAtSeeker struct {
+	f        *os.File
+	progress *fileProgress
+}
+
+func (pr *progressReaderAtSeeker) Read(p []byte) (int, error) {
+	n, err := pr.f.Read(p)
+	pr.progress.add(int64(n))
+	return n, err
+}
+
+func (pr *progressReaderAtSeeker) ReadAt(p []byte, off int64) (int, error) {
+	n, err := pr.f.ReadAt(p, off)
+	pr.progress.add(int64(n))
+	return n, err
+}
+
+func (pr *progressReaderAtSeeker) Seek(offset int64, whence int) (int64, error) {
+	return pr.f.Seek(offset, whence)
+}
+
+// writerAt returns a writer reporting the bytes written as transferred.
+func (fp *fileProgress) writerAt(w io.WriterAt) io.WriterAt {
+	if fp == nil {
+		return w
+	}
+	return &progressWriterAt{w: w, progress: fp}
+}
+
+type progressWriterAt struct {
+	w        io.WriterAt
+	progress *fileProgress
+}
+
+func (pw *progressWriterAt) WriteAt(p []byte, off int64) (int, error) {
+	n, err := pw.w.WriteAt(p, off)
+	pw.progress.add(int64(n))
+	return n, err
+}
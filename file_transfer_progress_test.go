@@ -0,0 +1,165 @@
+package gosnowflake
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+type recordingProgressListener struct {
+	mu       sync.Mutex
+	starts   []FileTransferProgress
+	progress []FileTransferProgress
+	retries  []FileTransferProgress
+	complete []FileTransferProgress
+}
+
+func (l *recordingProgressListener) OnFileStart(progress FileTransferProgress) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.starts = append(l.starts, progress)
+}
+
+func (l *recordingProgressListener) OnFileProgress(progress FileTransferProgress) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.progress = append(l.progress, progress)
+}
+
+func (l *recordingProgressListener) OnFileRetry(progress FileTransferProgress) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.retries = append(l.retries, progress)
+}
+
+func (l *recordingProgressListener) OnFileComplete(progress FileTransferProgress) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.complete = append(l.complete, progress)
+}
+
+func TestProgressTracker(t *testing.T) {
+	assertNilE(t, newProgressTracker(nil, true), "no tracker without listener")
+	// nil progress must be safe to use
+	var fp *fileProgress
+	fp.add(10)
+	fp.retry(nil)
+	fp.complete(uploaded, nil)
+
+	listener := &recordingProgressListener{}
+	tracker := newProgressTracker(listener, true)
+	meta := &fileMetadata{name: "data.csv"}
+	fp = tracker.file(meta, 100)
+	meta.progress = fp
+	assertEqualE(t, tracker.file(meta, 100), fp, "progress of the file should be reused")
+	assertEqualF(t, len(listener.starts), 1)
+	assertEqualE(t, listener.starts[0].File, "data.csv")
+	assertTrueE(t, listener.starts[0].Upload)
+	assertEqualE(t, listener.starts[0].Attempt, 1)
+
+	fp.add(60)
+	fp.add(60)
+	assertEqualF(t, len(listener.progress), 2)
+	assertEqualE(t, listener.progress[1].FileBytes, int64(100), "bytes should be capped at the file size")
+	assertEqualE(t, listener.progress[1].TotalBytes, int64(100))
+
+	fp.retry(errors.New("connection reset"))
+	assertEqualF(t, len(listener.retries), 1)
+	assertEqualE(t, listener.retries[0].Attempt, 1)
+	assertEqualE(t, listener.retries[0].FileBytes, int64(0))
+	assertEqualE(t, listener.retries[0].TotalBytes, int64(0))
+	assertNotNilE(t, listener.retries[0].Err)
+
+	fp.set(40)
+	fp.set(40)
+	assertEqualF(t, len(listener.progress), 3, "unchanged progress should not be reported")
+	fp.complete(uploaded, nil)
+	assertEqualF(t, len(listener.complete), 1)
+	assertEqualE(t, listener.complete[0].Status, FileTransferUploaded)
+	assertEqualE(t, listener.complete[0].Attempt, 2)
+	assertEqualE(t, listener.complete[0].FileBytes, int64(40))
+	assertTrueE(t, listener.complete[0].Throughput > 0)
+}
+
+// reentrantProgressListener calls back into the progress of the file from the listener methods.
+type reentrantProgressListener struct {
+	recordingProgressListener
+	fp *fileProgress
+}
+
+func (l *reentrantProgressListener) OnFileProgress(progress FileTransferProgress) {
+	l.fp.setSize(progress.FileSize)
+	l.recordingProgressListener.OnFileProgress(progress)
+}
+
+func (l *reentrantProgressListener) OnFileRetry(progress FileTransferProgress) {
+	l.fp.setSize(progress.FileSize)
+	l.recordingProgressListener.OnFileRetry(progress)
+}
+
+func (l *reentrantProgressListener) OnFileComplete(progress FileTransferProgress) {
+	l.fp.setSize(progress.FileSize)
+	l.recordingProgressListener.OnFileComplete(progress)
+}
+
+func TestProgressListenerCalledWithoutLock(t *testing.T) {
+	listener := &reentrantProgressListener{}
+	listener.fp = newProgressTracker(listener, false).file(&fileMetadata{name: "data.csv"}, 100)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		listener.fp.add(10)
+		listener.fp.set(20)
+		listener.fp.retry(errors.New("connection reset"))
+		listener.fp.complete(downloaded, nil)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the listener should not be called with the lock of the file progress held")
+	}
+	assertEqualE(t, len(listener.progress), 2)
+	assertEqualE(t, len(listener.retries), 1)
+	assertEqualE(t, len(listener.complete), 1)
+}
+
+func TestProgressReportedForLocalStage(t *testing.T) {
+	tmpDir := t.TempDir()
+	stageDir := filepath.Join(tmpDir, "stage")
+	assertNilF(t, os.Mkdir(stageDir, 0755))
+	data := bytes.Repeat([]byte("0123456789"), 1000)
+
+	sc := &snowflakeConn{cfg: &Config{TmpDirPath: tmpDir}}
+	listener := &recordingProgressListener{}
+	collector := &fileTransferResultCollector{}
+	ctx := fileTransferContext(WithFileStream(context.Background(), bytes.NewReader(data)), collector, fileTransferOverrides{progressListener: listener})
+	_, err := sc.processFileTransfer(ctx, &execResponse{Data: execResponseData{
+		Command:           string(uploadCommand),
+		SrcLocations:      []string{"data.csv"},
+		AutoCompress:      true,
+		SourceCompression: "auto_detect",
+		Overwrite:         true,
+		StageInfo: execResponseStageInfo{
+			LocationType: string(local),
+			Location:     stageDir,
+		},
+	}}, "PUT 'file://data.csv' '@~'", false)
+	assertNilF(t, err)
+	assertEqualF(t, len(collector.results), 1)
+
+	assertEqualF(t, len(listener.starts), 1)
+	assertEqualE(t, listener.starts[0].File, "data.csv")
+	assertTrueF(t, len(listener.progress) > 0, "progress should be reported")
+	last := listener.progress[len(listener.progress)-1]
+	assertEqualE(t, last.FileBytes, collector.results[0].TargetSize)
+	assertEqualE(t, last.TotalBytes, collector.results[0].TargetSize)
+	assertEqualF(t, len(listener.complete), 1)
+	assertEqualE(t, listener.complete[0].Status, FileTransferUploaded)
+	assertEqualE(t, listener.complete[0].FileSize, collector.results[0].TargetSize)
+	assertEqualE(t, len(listener.retries), 0)
+}
@@ -0,0 +1,213 @@
+package gosnowflake
+
+import (
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// recursiveWildcard in a PUT source location uploads the files of all the subdirectories, e.g. file:///data/**
+const recursiveWildcard = "**"
+
+// uploadSource is a local file found by a recursive PUT.
+// name is the path relative to the walked directory, which is also the path on the stage.
+type uploadSource struct {
+	path string
+	name string
+}
+
+// splitRecursiveLocation splits a location containing the recursive wildcard into the directory
+// which is walked and the pattern of the relative paths, e.g. /data/**/*.csv into /data and **/*.csv.
+func splitRecursiveLocation(location string) (string, string, bool) {
+	slashed := filepath.ToSlash(location)
+	elements := strings.Split(slashed, "/")
+	for i, element := range elements {
+		if element == recursiveWildcard {
+			root := strings.Join(elements[:i], "/")
+			if root == "" {
+				root = "/"
+			}
+			return filepath.FromSlash(root), strings.Join(elements[i:], "/"), true
+		}
+	}
+	return "", "", false
+}
+
+// uploadFileFilter selects the files of a recursive PUT by their relative paths.
+type uploadFileFilter struct {
+	include []string
+	exclude []string
+}
+
+func newUploadFileFilter(include []string, exclude []string) (*uploadFileFilter, error) {
+	for _, pattern := range append(append([]string(nil), include...), exclude...) {
+		if err := validateFileTransferPattern(pattern); err != nil {
+			return nil, err
+		}
+	}
+	return &uploadFileFilter{include: include, exclude: exclude}, nil
+}
+
+func validateFileTransferPattern(pattern string) error {
+	for _, element := range strings.Split(pattern, "/") {
+		if _, err := path.Match(element, ""); err != nil {
+			return errInvalidFileTransferPattern(pattern)
+		}
+	}
+	return nil
+}
+
+// matches reports whether the file is included and not excluded.
+// Without include patterns, all files are included.
+func (f *uploadFileFilter) matches(name string) bool {
+	included := len(f.include) == 0
+	for _, pattern := range f.include {
+		if ok, _ := matchFileTransferPattern(pattern, name); ok {
+			included = true
+			break
+		}
+	}
+	if !included {
+		return false
+	}
+	for _, pattern := range f.exclude {
+		if ok, _ := matchFileTransferPattern(pattern, name); ok {
+			return false
+		}
+	}
+	return true
+}
+
+// matchFileTransferPattern matches a slash separated relative path against a pattern with the syntax of path.Match,
+// where ** matches any number of directories. A pattern without a slash matches the base name of the path.
+func matchFileTransferPattern(pattern string, name string) (bool, error) {
+	if !strings.Contains(pattern, "/") && pattern != recursiveWildcard {
+		return path.Match(pattern, path.Base(name))
+	}
+	return matchPatternElements(strings.Split(pattern, "/"), strings.Split(name, "/"))
+}
+
+func matchPatternElements(pattern []string, name []string) (bool, error) {
+	for len(pattern) > 0 {
+		if pattern[0] == recursiveWildcard {
+			for i := 0; i <= len(name); i++ {
+				if ok, err := matchPatternElements(pattern[1:], name[i:]); ok || err != nil {
+					return ok, err
+				}
+			}
+			return false, nil
+		}
+		if len(name) == 0 {
+			return false, nil
+		}
+		ok, err := path.Match(pattern[0], name[0])
+		if !ok || err != nil {
+			return false, err
+		}
+		pattern, name = pattern[1:], name[1:]
+	}
+	return len(name) == 0, nil
+}
+
+// walkUploadDirectory returns the files under root whose relative paths match the pattern and the filter.
+// Symbolic links to files are uploaded with the content of their targets. Symbolic links to directories
+// are walked only if followSymlinks is set, and then every directory is walked at most once to avoid cycles.
+func walkUploadDirectory(log SFLogger, root string, pattern string, filter *uploadFileFilter, followSymlinks bool) ([]uploadSource, error) {
+	realRoot, err := filepath.EvalSymlinks(root)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	w := &uploadDirectoryWalker{
+		log:            log,
+		pattern:        pattern,
+		filter:         filter,
+		followSymlinks: followSymlinks,
+		visited:        make(map[string]bool),
+	}
+	if err = w.walk(realRoot, ""); err != nil {
+		return nil, err
+	}
+	return w.sources, nil
+}
+
+type uploadDirectoryWalker struct {
+	log            SFLogger
+	pattern        string
+	filter         *uploadFileFilter
+	followSymlinks bool
+	visited        map[string]bool
+	sources        []uploadSource
+}
+
+func (w *uploadDirectoryWalker) walk(dir string, prefix string) error {
+	return filepath.WalkDir(dir, func(file string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, file)
+		if err != nil {
+			return err
+		}
+		name := path.Join(prefix, filepath.ToSlash(rel))
+		switch {
+		case d.IsDir():
+			return w.visit(file)
+		case d.Type()&fs.ModeSymlink != 0:
+			return w.walkSymlink(file, name)
+		case d.Type().IsRegular():
+			w.add(file, name)
+		}
+		return nil
+	})
+}
+
+// visit returns fs.SkipDir if the directory was already walked through a symbolic link.
+func (w *uploadDirectoryWalker) visit(dir string) error {
+	if !w.followSymlinks {
+		return nil
+	}
+	realDir, err := filepath.EvalSymlinks(dir)
+	if err != nil {
+		return err
+	}
+	if w.visited[realDir] {
+		w.log.Debugf("skipping already uploaded directory %v", dir)
+		return fs.SkipDir
+	}
+	w.visited[realDir] = true
+	return nil
+}
+
+func (w *uploadDirectoryWalker) walkSymlink(file string, name string) error {
+	fi, err := os.Stat(file)
+	if err != nil {
+		w.log.Warnf("skipping broken symbolic link %v. err: %v", file, err)
+		return nil
+	}
+	if !fi.IsDir() {
+		if fi.Mode().IsRegular() {
+			w.add(file, name)
+		}
+		return nil
+	}
+	if !w.followSymlinks {
+		w.log.Debugf("skipping symbolic link to directory %v", file)
+		return nil
+	}
+	target, err := filepath.EvalSymlinks(file)
+	if err != nil {
+		return err
+	}
+	return w.walk(target, name)
+}
+
+func (w *uploadDirectoryWalker) add(file string, name string) {
+	if ok, _ := matchFileTransferPattern(w.pattern, name); !ok || !w.filter.matches(name) {
+		return
+	}
+	w.sources = append(w.sources, uploadSource{path: file, name: name})
+}
@@ -0,0 +1,156 @@
+package gosnowflake
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"testing"
+)
+
+func TestSplitRecursiveLocation(t *testing.T) {
+	testcases := []struct {
+		location string
+		root     string
+		pattern  string
+		ok       bool
+	}{
+		{"/data/**", "/data", "**", true},
+		{"/data/**/*.csv", "/data", "**/*.csv", true},
+		{"/**", "/", "**", true},
+		{"/data/*.csv", "", "", false},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.location, func(t *testing.T) {
+			root, pattern, ok := splitRecursiveLocation(filepath.FromSlash(tc.location))
+			assertEqualE(t, ok, tc.ok)
+			assertEqualE(t, filepath.ToSlash(root), tc.root)
+			assertEqualE(t, pattern, tc.pattern)
+		})
+	}
+}
+
+func TestMatchFileTransferPattern(t *testing.T) {
+	testcases := []struct {
+		pattern string
+		name    string
+		matches bool
+	}{
+		{"**", "a/b/c.csv", true},
+		{"**/*.csv", "c.csv", true},
+		{"**/*.csv", "a/b/c.csv", true},
+		{"**/*.csv", "a/b/c.json", false},
+		{"a/**/c.csv", "a/c.csv", true},
+		{"a/**/c.csv", "a/b/d/c.csv", true},
+		{"a/*/c.csv", "a/b/d/c.csv", false},
+		{"*.csv", "a/b/c.csv", true},
+		{"tmp/**", "a/tmp/c.csv", false},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.pattern+" "+tc.name, func(t *testing.T) {
+			ok, err := matchFileTransferPattern(tc.pattern, tc.name)
+			assertNilF(t, err)
+			assertEqualE(t, ok, tc.matches)
+		})
+	}
+}
+
+func TestUploadFileFilterInvalidPattern(t *testing.T) {
+	_, err := newUploadFileFilter([]string{"[a"}, nil)
+	assertNotNilF(t, err)
+	driverErr, ok := err.(*SnowflakeError)
+	assertTrueF(t, ok, "should be a SnowflakeError")
+	assertEqualE(t, driverErr.Number, ErrInvalidFileTransferPattern)
+}
+
+func writeRecursiveTestFiles(t *testing.T, root string, names ...string) {
+	for _, name := range names {
+		file := filepath.Join(root, filepath.FromSlash(name))
+		assertNilF(t, os.MkdirAll(filepath.Dir(file), os.ModePerm))
+		assertNilF(t, os.WriteFile(file, []byte(name), readWriteFileMode))
+	}
+}
+
+func uploadSourceNames(sources []uploadSource) []string {
+	names := make([]string, 0, len(sources))
+	for _, source := range sources {
+		names = append(names, source.name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func TestWalkUploadDirectory(t *testing.T) {
+	root := t.TempDir()
+	writeRecursiveTestFiles(t, root, "a.csv", "b.json", "sub/c.csv", "sub/tmp/d.csv", "sub/deep/e.csv")
+
+	filter, err := newUploadFileFilter(nil, []string{"**/tmp/**"})
+	assertNilF(t, err)
+	sources, err := walkUploadDirectory(logger, root, "**/*.csv", filter, false)
+	assertNilF(t, err)
+	assertDeepEqualE(t, uploadSourceNames(sources), []string{"a.csv", "sub/c.csv", "sub/deep/e.csv"})
+	for _, source := range sources {
+		content, err := os.ReadFile(source.path)
+		assertNilF(t, err)
+		assertEqualE(t, string(content), source.name)
+	}
+
+	filter, err = newUploadFileFilter([]string{"sub/**"}, nil)
+	assertNilF(t, err)
+	sources, err = walkUploadDirectory(logger, root, recursiveWildcard, filter, false)
+	assertNilF(t, err)
+	assertDeepEqualE(t, uploadSourceNames(sources), []string{"sub/c.csv", "sub/deep/e.csv", "sub/tmp/d.csv"})
+
+	sources, err = walkUploadDirectory(logger, filepath.Join(root, "missing"), recursiveWildcard, filter, false)
+	assertNilF(t, err)
+	assertEqualE(t, len(sources), 0)
+}
+
+func TestWalkUploadDirectorySymlinks(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("creating symbolic links requires privileges on Windows")
+	}
+	root := t.TempDir()
+	target := t.TempDir()
+	writeRecursiveTestFiles(t, root, "a.csv")
+	writeRecursiveTestFiles(t, target, "linked/b.csv")
+	assertNilF(t, os.Symlink(filepath.Join(target, "linked"), filepath.Join(root, "dir")))
+	assertNilF(t, os.Symlink(filepath.Join(root, "a.csv"), filepath.Join(root, "file.csv")))
+	assertNilF(t, os.Symlink(filepath.Join(root, "missing.csv"), filepath.Join(root, "broken.csv")))
+	// a cycle back to the walked directory
+	assertNilF(t, os.Symlink(root, filepath.Join(target, "linked", "loop")))
+
+	filter, err := newUploadFileFilter(nil, nil)
+	assertNilF(t, err)
+	sources, err := walkUploadDirectory(logger, root, recursiveWildcard, filter, false)
+	assertNilF(t, err)
+	assertDeepEqualE(t, uploadSourceNames(sources), []string{"a.csv", "file.csv"})
+
+	sources, err = walkUploadDirectory(logger, root, recursiveWildcard, filter, true)
+	assertNilF(t, err)
+	assertDeepEqualE(t, uploadSourceNames(sources), []string{"a.csv", "dir/b.csv", "file.csv"})
+}
+
+func TestExpandFilenamesRecursive(t *testing.T) {
+	root, err := filepath.EvalSymlinks(t.TempDir())
+	assertNilF(t, err)
+	writeRecursiveTestFiles(t, root, "a.csv", "sub/b.csv")
+
+	sfa := &snowflakeFileTransferAgent{commandType: uploadCommand}
+	files, err := sfa.expandFilenames([]string{filepath.Join(root, "**")})
+	assertNilF(t, err)
+	assertEqualE(t, len(files), 2)
+	assertEqualE(t, sfa.stageFileName(filepath.Join(root, "sub", "b.csv")), "sub/b.csv")
+
+	sfa = &snowflakeFileTransferAgent{commandType: uploadCommand, options: &SnowflakeFileTransferOptions{Recursive: true}}
+	files, err = sfa.expandFilenames([]string{root})
+	assertNilF(t, err)
+	assertEqualE(t, len(files), 2)
+	assertEqualE(t, sfa.stageFileName(filepath.Join(root, "a.csv")), "a.csv")
+
+	sfa = &snowflakeFileTransferAgent{commandType: uploadCommand}
+	files, err = sfa.expandFilenames([]string{filepath.Join(root, "*")})
+	assertNilF(t, err)
+	assertEqualE(t, len(files), 2, "without recursion the directory is not expanded")
+	assertEqualE(t, sfa.stageFileName(filepath.Join(root, "a.csv")), "a.csv")
+}
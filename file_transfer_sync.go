@@ -0,0 +1,349 @@
+package gosnowflake
+
+import (
+	"crypto/sha256"
+	"database/sql/driver"
+	"encoding/base64"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// syncUpload reports whether only new or changed files are uploaded, see SnowflakeFileTransferOptions.
+func (meta *fileMetadata) syncUpload() bool {
+	return meta.options != nil && meta.options.Sync
+}
+
+// isStageFileUnchanged reports whether the stage file exists and has the SHA-256 digest of the uploaded content.
+// The digest is computed after compression, so a file uploaded with another compression or by a client
+// which compresses differently does not match and is uploaded again.
+func isStageFileUnchanged(utilClass cloudUtil, meta *fileMetadata) (bool, error) {
+	header, err := utilClass.getFileHeader(meta, meta.dstFileName)
+	if meta.resStatus == notFoundFile {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	return header != nil && header.digest != "" && header.digest == meta.sha256Digest, nil
+}
+
+// isLocalStageFileUnchanged is isStageFileUnchanged for local stages, which do not store digests.
+func isLocalStageFileUnchanged(fileName string, meta *fileMetadata) (bool, error) {
+	f, err := os.Open(fileName)
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err = io.Copy(h, f); err != nil {
+		return false, err
+	}
+	return base64.StdEncoding.EncodeToString(h.Sum(nil)) == meta.sha256Digest, nil
+}
+
+// syncStageFiles removes the stage files which do not exist locally anymore if SyncDelete is set
+// and logs the numbers of uploaded, skipped and deleted files of a sync.
+func (sfa *snowflakeFileTransferAgent) syncStageFiles() error {
+	if !sfa.options.Sync {
+		return nil
+	}
+	if sfa.options.SyncDelete && sfa.sourceStream == nil {
+		if err := sfa.removeDeletedStageFiles(); err != nil {
+			return err
+		}
+	}
+	counts := make(map[resultStatus]int)
+	for _, meta := range sfa.results {
+		counts[meta.resStatus]++
+	}
+	sfa.sc.getLogger().WithContext(sfa.ctx).Infof("synced files to the stage: %v uploaded, %v skipped, %v deleted, %v failed",
+		counts[uploaded], counts[skipped], counts[deleted], counts[errStatus])
+	return nil
+}
+
+// removeDeletedStageFiles removes the files of the stage location which were not uploaded by the PUT command
+// and adds them to the results with the deleted status.
+func (sfa *snowflakeFileTransferAgent) removeDeletedStageFiles() error {
+	sources, err := newSyncSourceFilter(sfa.srcLocations, sfa.options)
+	if err != nil {
+		return err
+	}
+	names := make([]string, 0, len(sfa.results))
+	for _, meta := range sfa.results {
+		if meta.resStatus != uploaded && meta.resStatus != skipped {
+			// a file which failed to upload may still exist on the stage, so nothing is removed
+			sfa.sc.getLogger().WithContext(sfa.ctx).Warnf("file %v was not uploaded, no stage file is removed", meta.srcFileName)
+			return nil
+		}
+		names = append(names, strings.TrimLeft(meta.dstFileName, "/"))
+	}
+	var removed []string
+	if sfa.stageLocationType == local {
+		removed, err = sfa.removeDeletedLocalStageFiles(names, sources)
+	} else {
+		removed, err = sfa.removeDeletedRemoteStageFiles(names, sources)
+	}
+	if err != nil {
+		return err
+	}
+	for _, name := range removed {
+		sfa.results = append(sfa.results, &fileMetadata{
+			name:        name,
+			dstFileName: name,
+			resStatus:   deleted,
+		})
+	}
+	return nil
+}
+
+func (sfa *snowflakeFileTransferAgent) removeDeletedLocalStageFiles(uploaded []string, sources *syncSourceFilter) ([]string, error) {
+	location, err := expandUser(sfa.stageInfo.Location)
+	if err != nil {
+		return nil, err
+	}
+	keep := make(map[string]bool, len(uploaded))
+	for _, name := range uploaded {
+		keep[name] = true
+	}
+	var removed []string
+	err = filepath.WalkDir(location, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(location, path)
+		if err != nil {
+			return err
+		}
+		if rel = filepath.ToSlash(rel); keep[rel] || !sources.matches(rel) {
+			return nil
+		}
+		if err = os.Remove(path); err != nil {
+			return err
+		}
+		removed = append(removed, rel)
+		return nil
+	})
+	return removed, err
+}
+
+func (sfa *snowflakeFileTransferAgent) removeDeletedRemoteStageFiles(uploaded []string, sources *syncSourceFilter) ([]string, error) {
+	location := getStageLocationFromCommand(sfa.command)
+	if location == "" {
+		sfa.sc.getLogger().WithContext(sfa.ctx).Warnf("no stage location found in the PUT command, no stage file is removed")
+		return nil, nil
+	}
+	rows, err := sfa.sc.QueryContext(sfa.ctx, "LIST "+quoteFileTransferLocation(location), nil)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var listed []string
+	dest := make([]driver.Value, len(rows.Columns()))
+	for {
+		if err = rows.Next(dest); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		if name, ok := dest[0].(string); ok {
+			listed = append(listed, name)
+		}
+	}
+	prefix, toRemove := stageFilesToRemove(listed, uploaded, sources)
+	for _, pattern := range stageRemovePatterns(prefix, toRemove) {
+		command := "REMOVE " + quoteFileTransferLocation(location) + " PATTERN=" + quoteFileTransferLocation(pattern)
+		if _, err = sfa.sc.ExecContext(sfa.ctx, command, nil); err != nil {
+			return nil, err
+		}
+	}
+	return toRemove, nil
+}
+
+// maxStageRemovePatternLength is the length above which the files removed by a sync are split into several REMOVE commands.
+const maxStageRemovePatternLength = 8192
+
+// stageRemovePatterns returns the PATTERN values of the REMOVE commands removing the files.
+// Every pattern is an alternation of the escaped file names which is at most maxStageRemovePatternLength long,
+// unless a single file name is longer.
+func stageRemovePatterns(prefix string, names []string) []string {
+	var patterns []string
+	var sb strings.Builder
+	for _, name := range names {
+		escaped := regexp.QuoteMeta(prefix + name)
+		if sb.Len() > 0 && sb.Len()+1+len(escaped) > maxStageRemovePatternLength {
+			patterns = append(patterns, sb.String())
+			sb.Reset()
+		}
+		if sb.Len() > 0 {
+			sb.WriteByte('|')
+		}
+		sb.WriteString(escaped)
+	}
+	if sb.Len() > 0 {
+		patterns = append(patterns, sb.String())
+	}
+	return patterns
+}
+
+// syncSourceFilter selects the stage files which can be uploaded from the source locations of a PUT command,
+// so that SyncDelete does not remove the files of other sources in the same stage location.
+type syncSourceFilter struct {
+	locations []string
+	recursive bool
+	filter    *uploadFileFilter
+}
+
+func newSyncSourceFilter(locations []string, options *SnowflakeFileTransferOptions) (*syncSourceFilter, error) {
+	if options == nil {
+		options = &SnowflakeFileTransferOptions{}
+	}
+	filter, err := newUploadFileFilter(options.IncludePatterns, options.ExcludePatterns)
+	if err != nil {
+		return nil, err
+	}
+	return &syncSourceFilter{locations: locations, recursive: options.Recursive, filter: filter}, nil
+}
+
+// matches reports whether the stage file name, with or without the extension of its compression,
+// matches the file pattern of a source location.
+func (f *syncSourceFilter) matches(name string) bool {
+	names := []string{name}
+	if ct, ok := extensionToCompression[path.Ext(name)]; ok {
+		names = append(names, strings.TrimSuffix(name, ct.fileExtension))
+	}
+	for _, location := range f.locations {
+		for _, n := range names {
+			if f.matchesLocation(location, n) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (f *syncSourceFilter) matchesLocation(location string, name string) bool {
+	if _, pattern, ok := splitRecursiveLocation(location); ok {
+		matched, _ := matchFileTransferPattern(pattern, name)
+		return matched && f.filter.matches(name)
+	}
+	if f.recursive {
+		return f.filter.matches(name)
+	}
+	if strings.Contains(name, "/") {
+		return false
+	}
+	matched, _ := path.Match(path.Base(filepath.ToSlash(location)), name)
+	return matched
+}
+
+// stageFilesToRemove returns the files listed on the stage which were not uploaded and match the sources,
+// relative to the stage location. LIST returns the names including the stage path, whose prefix is derived
+// from the uploaded files. Nothing is removed when no uploaded file is listed, because the prefix cannot be derived then.
+func stageFilesToRemove(listed []string, uploaded []string, sources *syncSourceFilter) (prefix string, toRemove []string) {
+	found := false
+	for _, name := range listed {
+		for _, dst := range uploaded {
+			if name != dst && !strings.HasSuffix(name, "/"+dst) {
+				continue
+			}
+			if p := strings.TrimSuffix(name, dst); !found || len(p) < len(prefix) {
+				prefix = p
+				found = true
+			}
+		}
+	}
+	if !found {
+		return "", nil
+	}
+	keep := make(map[string]bool, len(uploaded))
+	for _, name := range uploaded {
+		keep[name] = true
+	}
+	for _, name := range listed {
+		if rel, ok := strings.CutPrefix(name, prefix); ok && !keep[rel] && sources.matches(rel) {
+			toRemove = append(toRemove, rel)
+		}
+	}
+	sort.Strings(toRemove)
+	return prefix, toRemove
+}
+
+// getStageLocationFromCommand returns the stage location of a PUT command, which follows its local file path.
+func getStageLocationFromCommand(command string) string {
+	idx := strings.Index(command, fileProtocol)
+	if idx < 0 {
+		return ""
+	}
+	if idx > 0 && command[idx-1] == '\'' {
+		idx--
+	}
+	_, rest := nextCommandToken(command[idx:])
+	location, _ := nextCommandToken(rest)
+	return location
+}
+
+// nextCommandToken returns the first token of the command, which is either a quoted string with escaped
+// quotes and backslashes or ends at a whitespace or semicolon, and the rest of the command.
+func nextCommandToken(command string) (token string, rest string) {
+	command = strings.TrimLeftFunc(command, unicode.IsSpace)
+	if !strings.HasPrefix(command, "'") {
+		end := strings.IndexFunc(command, func(r rune) bool {
+			return unicode.IsSpace(r) || r == ';'
+		})
+		if end < 0 {
+			return command, ""
+		}
+		return command[:end], command[end:]
+	}
+	var sb strings.Builder
+	for i := 1; i < len(command); i++ {
+		switch c := command[i]; {
+		case c == '\\' && i+1 < len(command):
+			i++
+			sb.WriteByte(command[i])
+		case c == '\'':
+			return sb.String(), command[i+1:]
+		default:
+			sb.WriteByte(c)
+		}
+	}
+	return sb.String(), ""
+}
+
+// FileTransferSummary counts the results of a file transfer by their status.
+type FileTransferSummary struct {
+	Uploaded   int
+	Downloaded int
+	Skipped    int
+	Deleted    int
+	Failed     int
+}
+
+// SummarizeFileTransferResults counts the results returned by SnowflakeConnection.Put or SnowflakeConnection.Get.
+func SummarizeFileTransferResults(results []FileTransferResult) FileTransferSummary {
+	var summary FileTransferSummary
+	for _, res := range results {
+		switch res.Status {
+		case FileTransferUploaded:
+			summary.Uploaded++
+		case FileTransferDownloaded:
+			summary.Downloaded++
+		case FileTransferSkipped:
+			summary.Skipped++
+		case FileTransferDeleted:
+			summary.Deleted++
+		case FileTransferError:
+			summary.Failed++
+		}
+	}
+	return summary
+}
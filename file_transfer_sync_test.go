@@ -0,0 +1,151 @@
+package gosnowflake
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGetStageLocationFromCommand(t *testing.T) {
+	testcases := []struct {
+		command  string
+		location string
+	}{
+		{"PUT file:///tmp/data.csv @~/data", "@~/data"},
+		{"PUT file:///tmp/data.csv @~/data;", "@~/data"},
+		{"put 'file:///tmp/my data.csv' '@my_stage/it\\'s' OVERWRITE=TRUE", "@my_stage/it's"},
+		{`PUT 'file:///tmp/a b/*.csv' '@%t/c\\d'`, `@%t/c\d`},
+		{"LIST @~", ""},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.command, func(t *testing.T) {
+			assertEqualE(t, getStageLocationFromCommand(tc.command), tc.location)
+		})
+	}
+}
+
+func TestStageFilesToRemove(t *testing.T) {
+	sources, err := newSyncSourceFilter([]string{"/tmp/data/**"}, nil)
+	assertNilF(t, err)
+	listed := []string{"data/a.csv.gz", "data/sub/a.csv.gz", "data/old.csv.gz", "data/sub/old.csv.gz"}
+	prefix, toRemove := stageFilesToRemove(listed, []string{"a.csv.gz", "sub/a.csv.gz"}, sources)
+	assertEqualE(t, prefix, "data/")
+	assertDeepEqualE(t, toRemove, []string{"old.csv.gz", "sub/old.csv.gz"})
+
+	prefix, toRemove = stageFilesToRemove([]string{"a.csv.gz", "b.csv.gz"}, []string{"a.csv.gz"}, sources)
+	assertEqualE(t, prefix, "")
+	assertDeepEqualE(t, toRemove, []string{"b.csv.gz"})
+
+	_, toRemove = stageFilesToRemove(listed, []string{"other.csv.gz"}, sources)
+	assertEqualE(t, len(toRemove), 0, "nothing should be removed if the prefix is unknown")
+
+	sources, err = newSyncSourceFilter([]string{"/tmp/data/*.csv"}, nil)
+	assertNilF(t, err)
+	_, toRemove = stageFilesToRemove([]string{"a.csv.gz", "b.csv.gz", "c.json.gz", "sub/d.csv.gz"}, []string{"a.csv.gz"}, sources)
+	assertDeepEqualE(t, toRemove, []string{"b.csv.gz"}, "files not matching the source pattern should be kept")
+}
+
+func TestSyncSourceFilter(t *testing.T) {
+	testcases := []struct {
+		location  string
+		recursive bool
+		name      string
+		matches   bool
+	}{
+		{"/tmp/data/*.csv", false, "a.csv", true},
+		{"/tmp/data/*.csv", false, "a.csv.gz", true},
+		{"/tmp/data/*.csv", false, "a.csv.zst", true},
+		{"/tmp/data/*.csv", false, "a.json", false},
+		{"/tmp/data/*.csv", false, "sub/a.csv", false},
+		{"/tmp/data/a.csv", false, "a.csv", true},
+		{"/tmp/data/**/*.csv", false, "sub/a.csv.gz", true},
+		{"/tmp/data/**/*.csv", false, "sub/a.json", false},
+		{"/tmp/data", true, "sub/a.json", true},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.location+" "+tc.name, func(t *testing.T) {
+			sources, err := newSyncSourceFilter([]string{tc.location}, &SnowflakeFileTransferOptions{Recursive: tc.recursive})
+			assertNilF(t, err)
+			assertEqualE(t, sources.matches(tc.name), tc.matches)
+		})
+	}
+
+	sources, err := newSyncSourceFilter([]string{"/tmp/data/**"}, &SnowflakeFileTransferOptions{ExcludePatterns: []string{"*.tmp"}})
+	assertNilF(t, err)
+	assertFalseE(t, sources.matches("sub/a.tmp"), "excluded files should be kept")
+}
+
+func TestStageRemovePatterns(t *testing.T) {
+	patterns := stageRemovePatterns("data/", []string{"a.csv", "b(1).csv"})
+	assertDeepEqualE(t, patterns, []string{`data/a\.csv|data/b\(1\)\.csv`})
+
+	names := make([]string, 1000)
+	for i := range names {
+		names[i] = fmt.Sprintf("file_%04d.csv.gz", i)
+	}
+	patterns = stageRemovePatterns("data/", names)
+	assertTrueE(t, len(patterns) > 1, "many files should be removed by several commands")
+	count := 0
+	for _, pattern := range patterns {
+		assertTrueE(t, len(pattern) <= maxStageRemovePatternLength)
+		count += len(strings.Split(pattern, "|"))
+	}
+	assertEqualE(t, count, len(names))
+}
+
+func TestSyncToLocalStage(t *testing.T) {
+	tmpDir := t.TempDir()
+	srcDir := filepath.Join(tmpDir, "src")
+	stageDir := filepath.Join(tmpDir, "stage")
+	assertNilF(t, os.Mkdir(srcDir, 0755))
+	for _, name := range []string{"a.csv", "b.csv", "c.csv"} {
+		assertNilF(t, os.WriteFile(filepath.Join(srcDir, name), []byte(name), readWriteFileMode))
+	}
+	assertNilF(t, os.Mkdir(stageDir, 0755))
+	assertNilF(t, os.WriteFile(filepath.Join(stageDir, "notes.txt"), []byte("notes"), readWriteFileMode))
+	sc := &snowflakeConn{cfg: &Config{TmpDirPath: tmpDir}}
+	put := func(srcLocations ...string) FileTransferSummary {
+		collector := &fileTransferResultCollector{}
+		ctx := fileTransferContext(context.Background(), collector, fileTransferOverrides{sync: true, syncDelete: true})
+		_, err := sc.processFileTransfer(ctx, &execResponse{Data: execResponseData{
+			Command:           string(uploadCommand),
+			SrcLocations:      srcLocations,
+			SourceCompression: "none",
+			StageInfo: execResponseStageInfo{
+				LocationType: string(local),
+				Location:     stageDir,
+			},
+		}}, "PUT 'file://src/*.csv' '@~'", false)
+		assertNilF(t, err)
+		return SummarizeFileTransferResults(collector.results)
+	}
+
+	summary := put(filepath.Join(srcDir, "*.csv"))
+	assertEqualE(t, summary, FileTransferSummary{Uploaded: 3})
+
+	summary = put(filepath.Join(srcDir, "*.csv"))
+	assertEqualE(t, summary, FileTransferSummary{Skipped: 3}, "unchanged files should be skipped")
+
+	assertNilF(t, os.WriteFile(filepath.Join(srcDir, "b.csv"), []byte("changed"), readWriteFileMode))
+	assertNilF(t, os.Remove(filepath.Join(srcDir, "c.csv")))
+	summary = put(filepath.Join(srcDir, "*.csv"))
+	assertEqualE(t, summary, FileTransferSummary{Uploaded: 1, Skipped: 1, Deleted: 1})
+
+	content, err := os.ReadFile(filepath.Join(stageDir, "b.csv"))
+	assertNilF(t, err)
+	assertEqualE(t, string(content), "changed")
+	_, err = os.Stat(filepath.Join(stageDir, "c.csv"))
+	assertTrueE(t, os.IsNotExist(err), "deleted file should be removed from the stage")
+	_, err = os.Stat(filepath.Join(stageDir, "notes.txt"))
+	assertNilE(t, err, "file not matching the source pattern should be kept")
+}
+
+func TestPutSyncDeleteMultipleSources(t *testing.T) {
+	sc := &snowflakeConn{cfg: &Config{}}
+	_, err := sc.Put(context.Background(), PutRequest{Sources: []string{"a", "b"}, Stage: "@~", SyncDelete: true})
+	assertNotNilF(t, err)
+	assertEqualE(t, err.(*SnowflakeError).Number, ErrSyncDeleteMultipleSources)
+}
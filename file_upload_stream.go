@@ -0,0 +1,212 @@
+package gosnowflake
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+var errUploadStreamClosed = errors.New("upload stream closed")
+
+// getFileStream reads at most maxBufferSize+1 bytes of the stream set with WithFileStream.
+// If the whole stream fits in the buffer, the returned reader is nil,
+// otherwise it returns the rest of the stream, which is uploaded without buffering.
+func getFileStream(ctx context.Context, maxBufferSize int64) (*bytes.Buffer, io.Reader, error) {
+	s := ctx.Value(fileStreamFile)
+	if s == nil {
+		return nil, nil, nil
+	}
+	r, ok := s.(io.Reader)
+	if !ok {
+		return nil, nil, errors.New("incorrect io.Reader")
+	}
+	buf := new(bytes.Buffer)
+	n, err := io.CopyN(buf, r, maxBufferSize+1)
+	if err == io.EOF {
+		return buf, nil, nil
+	} else if err != nil {
+		return nil, nil, err
+	}
+	if n <= maxBufferSize {
+		return buf, nil, nil
+	}
+	return buf, r, nil
+}
+
+// countingReader counts the bytes read from the underlying reader.
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (cr *countingReader) Read(p []byte) (int, error) {
+	n, err := cr.r.Read(p)
+	cr.n += int64(n)
+	return n, err
+}
+
+// digestReader computes the SHA-256 digest of the data read and reports it once the reader reaches EOF.
+type digestReader struct {
+	r     io.Reader
+	hash  hash.Hash
+	size  int64
+	onEOF func(digest string, size int64)
+}
+
+func (dr *digestReader) Read(p []byte) (int, error) {
+	n, err := dr.r.Read(p)
+	dr.hash.Write(p[:n])
+	dr.size += int64(n)
+	if err == io.EOF && dr.onEOF != nil {
+		dr.onEOF(base64.StdEncoding.EncodeToString(dr.hash.Sum(nil)), dr.size)
+		dr.onEOF = nil
+	}
+	return n, err
+}
+
+// uploadStream is the source of a streaming PUT after compression and encryption.
+// Each stage runs in its own goroutine connected with pipes, so only bounded chunks of the source are kept in memory.
+// Closing the stream stops the stages that are still running.
+type uploadStream struct {
+	io.Reader
+	pipes []*io.PipeReader
+}
+
+func (us *uploadStream) Close() error {
+	for _, pipe := range us.pipes {
+		pipe.CloseWithError(errUploadStreamClosed)
+	}
+	return nil
+}
+
+func (us *uploadStream) pipe(f func(w io.Writer) error) {
+	pr, pw := io.Pipe()
+	go func() {
+		pw.CloseWithError(f(pw))
+	}()
+	us.pipes = append(us.pipes, pr)
+	us.Reader = pr
+}
+
+// newUploadStream builds the pipeline src -> compression -> SHA-256 digest -> CBC encryption.
+// The digest and upload size are set on the metadata when the stream is fully read,
+// the encryption metadata is set immediately so that it can be sent before the content.
+func newUploadStream(meta *fileMetadata, src io.Reader, ct cloudType) (*uploadStream, error) {
+	us := &uploadStream{Reader: src}
+	if meta.requireCompress {
+		compressed := us.Reader
+		us.pipe(func(w io.Writer) error {
+			zw, err := newCompressWriter(w, meta.autoCompressionType(), meta.compressionLevel())
+			if err != nil {
+				return err
+			}
+			if _, err = io.Copy(zw, compressed); err != nil {
+				return err
+			}
+			return zw.Close()
+		})
+	}
+	us.Reader = &digestReader{
+		r:    us.Reader,
+		hash: sha256.New(),
+		onEOF: func(digest string, size int64) {
+			meta.sha256Digest = digest
+			meta.uploadSize = size
+		},
+	}
+	if ct != local && meta.encryptionMaterial != nil {
+		mode, encryptMeta, err := initEncryptionCBC(meta.encryptionMaterial)
+		if err != nil {
+			return nil, err
+		}
+		meta.encryptMeta = encryptMeta
+		plain := us.Reader
+		us.pipe(func(w io.Writer) error {
+			return encryptCBC(mode, plain, w, 0)
+		})
+	}
+	return us, nil
+}
+
+// uploadStreamParts reads the stream in parts of partSize and uploads each part with upload as soon as it is filled,
+// at most maxConcurrency at a time. It returns the number of parts, which are numbered from 1 in the order
+// of the stream. A part is kept in memory until it is uploaded, so that upload can retry it,
+// as the stream cannot be read again.
+func uploadStreamParts(
+	src io.Reader,
+	partSize int64,
+	maxConcurrency int,
+	maxParts int,
+	upload func(number int, part []byte) error) (int, error) {
+	var mu sync.Mutex
+	var firstErr error
+	setErr := func(err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+	failed := func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return firstErr != nil
+	}
+	var wg sync.WaitGroup
+	slots := make(chan struct{}, max(maxConcurrency, 1))
+	parts := 0
+	for !failed() {
+		part := make([]byte, partSize)
+		n, err := io.ReadFull(src, part)
+		if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+			setErr(err)
+			break
+		}
+		if n == 0 {
+			break
+		}
+		if parts == maxParts {
+			setErr(fmt.Errorf("the stream has more than %v parts of %v bytes", maxParts, partSize))
+			break
+		}
+		parts++
+		slots <- struct{}{}
+		wg.Add(1)
+		go func(number int, part []byte) {
+			defer func() {
+				<-slots
+				wg.Done()
+			}()
+			if err := upload(number, part); err != nil {
+				setErr(err)
+			}
+		}(parts, part[:n])
+		if err != nil {
+			// the last part was shorter than partSize
+			break
+		}
+	}
+	wg.Wait()
+	return parts, firstErr
+}
+
+// spoolUploadStream writes the stream to a temporary file, for storages which need the digest before the content.
+func spoolUploadStream(meta *fileMetadata, src io.Reader) (string, error) {
+	f, err := os.CreateTemp(meta.tmpDir, baseName(meta.dstFileName)+"#")
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	if _, err = io.Copy(f, src); err != nil {
+		return "", err
+	}
+	return filepath.Clean(f.Name()), nil
+}
@@ -0,0 +1,117 @@
+package gosnowflake
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGetFileStream(t *testing.T) {
+	buf, rest, err := getFileStream(context.Background(), 10)
+	assertNilF(t, err)
+	assertNilE(t, buf)
+	assertNilE(t, rest)
+
+	buf, rest, err = getFileStream(WithFileStream(context.Background(), strings.NewReader("0123456789")), 10)
+	assertNilF(t, err)
+	assertEqualE(t, buf.String(), "0123456789")
+	assertNilE(t, rest, "stream fitting in the buffer should not be streamed")
+
+	buf, rest, err = getFileStream(WithFileStream(context.Background(), strings.NewReader("0123456789abc")), 10)
+	assertNilF(t, err)
+	assertEqualE(t, buf.String(), "0123456789a")
+	assertNotNilF(t, rest)
+	remaining, err := io.ReadAll(rest)
+	assertNilF(t, err)
+	assertEqualE(t, string(remaining), "bc")
+}
+
+func TestUploadStreamPipeline(t *testing.T) {
+	data := bytes.Repeat([]byte("some streamed data "), 100000)
+	encMat := snowflakeFileEncryption{
+		QueryStageMasterKey: "ztke8tIdVt1zmlQIZm0BMA==",
+		QueryID:             "01abc874-0406-1bf0-0000-53b10668e056",
+		SMKID:               92019681909886,
+	}
+	meta := &fileMetadata{
+		requireCompress:    true,
+		encryptionMaterial: &encMat,
+	}
+	stream, err := newUploadStream(meta, bytes.NewReader(data), s3Client)
+	assertNilF(t, err)
+	defer stream.Close()
+	assertNotNilF(t, meta.encryptMeta, "encryption metadata should be known before the upload")
+
+	encrypted, err := io.ReadAll(stream)
+	assertNilF(t, err)
+
+	var decrypted bytes.Buffer
+	size, err := decryptStreamCBC(meta.encryptMeta, &encMat, 0, bytes.NewBuffer(encrypted), &decrypted)
+	assertNilF(t, err)
+	compressed := decrypted.Bytes()[:size]
+	digest := sha256.Sum256(compressed)
+	assertEqualE(t, meta.sha256Digest, base64.StdEncoding.EncodeToString(digest[:]))
+	assertEqualE(t, meta.uploadSize, int64(len(compressed)))
+
+	gzr, err := gzip.NewReader(bytes.NewReader(compressed))
+	assertNilF(t, err)
+	uncompressed, err := io.ReadAll(gzr)
+	assertNilF(t, err)
+	assertBytesEqualE(t, uncompressed, data)
+}
+
+func TestUploadStreamPipelineClose(t *testing.T) {
+	meta := &fileMetadata{requireCompress: true}
+	stream, err := newUploadStream(meta, bytes.NewReader(make([]byte, 10*1024*1024)), local)
+	assertNilF(t, err)
+	buf := make([]byte, 10)
+	_, err = stream.Read(buf)
+	assertNilF(t, err)
+	assertNilE(t, stream.Close())
+	_, err = stream.Read(buf)
+	assertEqualE(t, err, io.ErrClosedPipe)
+}
+
+func TestStreamingPutToLocalStage(t *testing.T) {
+	tmpDir := t.TempDir()
+	stageDir := filepath.Join(tmpDir, "stage")
+	assertNilF(t, os.Mkdir(stageDir, 0755))
+	data := bytes.Repeat([]byte("0123456789"), 1000)
+
+	sc := &snowflakeConn{cfg: &Config{TmpDirPath: tmpDir}}
+	ctx := WithFileTransferOptions(WithFileStream(context.Background(), bytes.NewReader(data)), &SnowflakeFileTransferOptions{
+		MultiPartThreshold: 100,
+	})
+	resp, err := sc.processFileTransfer(ctx, &execResponse{Data: execResponseData{
+		Command:           string(uploadCommand),
+		SrcLocations:      []string{"data.csv"},
+		AutoCompress:      true,
+		SourceCompression: "auto_detect",
+		Overwrite:         true,
+		StageInfo: execResponseStageInfo{
+			LocationType: string(local),
+			Location:     stageDir,
+		},
+	}}, "PUT file://data.csv @~", false)
+	assertNilF(t, err)
+	assertEqualF(t, len(resp.Data.RowSet), 1)
+	assertEqualE(t, *resp.Data.RowSet[0][1], "data.csv.gz")
+	assertEqualE(t, *resp.Data.RowSet[0][2], "10000", "source size should be counted while streaming")
+	assertEqualE(t, *resp.Data.RowSet[0][6], uploaded.String())
+
+	f, err := os.Open(filepath.Join(stageDir, "data.csv.gz"))
+	assertNilF(t, err)
+	defer f.Close()
+	gzr, err := gzip.NewReader(f)
+	assertNilF(t, err)
+	uploadedData, err := io.ReadAll(gzr)
+	assertNilF(t, err)
+	assertBytesEqualE(t, uploadedData, data)
+}
@@ -2,7 +2,6 @@ package gosnowflake
 
 import (
 	"bytes"
-	"compress/gzip"
 	"crypto/sha256"
 	"encoding/base64"
 	"io"
@@ -21,15 +20,18 @@ const (
 	readWriteFileMode os.FileMode = 0666
 )
 
-func (util *snowflakeFileUtil) compressFileWithGzipFromStream(srcStream **bytes.Buffer) (*bytes.Buffer, int, error) {
+func (util *snowflakeFileUtil) compressFileFromStream(srcStream **bytes.Buffer, ct *compressionType, level int) (*bytes.Buffer, int, error) {
 	r := getReaderFromBuffer(srcStream)
 	buf, err := io.ReadAll(r)
 	if err != nil {
 		return nil, -1, err
 	}
 	var c bytes.Buffer
-	w := gzip.NewWriter(&c)
-	if _, err := w.Write(buf); err != nil { // write buf to gzip writer
+	w, err := newCompressWriter(&c, ct, level)
+	if err != nil {
+		return nil, -1, err
+	}
+	if _, err := w.Write(buf); err != nil { // write buf to compression writer
 		return nil, -1, err
 	}
 	if err := w.Close(); err != nil {
@@ -38,9 +40,9 @@ func (util *snowflakeFileUtil) compressFileWithGzipFromStream(srcStream **bytes.
 	return &c, c.Len(), nil
 }
 
-func (util *snowflakeFileUtil) compressFileWithGzip(fileName string, tmpDir string) (gzipFileName string, size int64, err error) {
+func (util *snowflakeFileUtil) compressFile(fileName string, tmpDir string, ct *compressionType, level int) (compressedFileName string, size int64, err error) {
 	basename := baseName(fileName)
-	gzipFileName = filepath.Join(tmpDir, basename+"_c.gz")
+	compressedFileName = filepath.Join(tmpDir, basename+"_c"+ct.fileExtension)
 
 	fr, err := os.Open(fileName)
 	if err != nil {
@@ -51,26 +53,27 @@ func (util *snowflakeFileUtil) compressFileWithGzip(fileName string, tmpDir stri
 			err = tmpErr
 		}
 	}()
-	fw, err := os.OpenFile(gzipFileName, os.O_WRONLY|os.O_CREATE, readWriteFileMode)
+	fw, err := os.OpenFile(compressedFileName, os.O_WRONLY|os.O_CREATE, readWriteFileMode)
 	if err != nil {
 		return "", -1, err
 	}
-	gzw := gzip.NewWriter(fw)
-	defer func() {
-		if tmpErr := gzw.Close(); tmpErr != nil {
-			err = tmpErr
-		}
-	}()
-	_, err = io.Copy(gzw, fr)
+	defer fw.Close()
+	zw, err := newCompressWriter(fw, ct, level)
 	if err != nil {
 		return "", -1, err
 	}
+	if _, err = io.Copy(zw, fr); err != nil {
+		return "", -1, err
+	}
+	if err = zw.Close(); err != nil {
+		return "", -1, err
+	}
 
-	stat, err := os.Stat(gzipFileName)
+	stat, err := os.Stat(compressedFileName)
 	if err != nil {
 		return "", -1, err
 	}
-	return gzipFileName, stat.Size(), err
+	return compressedFileName, stat.Size(), err
 }
 
 func (util *snowflakeFileUtil) getDigestAndSizeForStream(stream **bytes.Buffer) (string, int64, error) {
@@ -156,6 +159,12 @@ type fileMetadata struct {
 	/* streaming PUT */
 	srcStream     *bytes.Buffer
 	realSrcStream *bytes.Buffer
+	srcReader     io.Reader // source stream larger than the multipart threshold
+
+	/* resumable multipart upload */
+	uploadJournal *uploadJournal
+
+	progress *fileProgress
 
 	/* streaming GET */
 	dstStream *bytes.Buffer
@@ -165,10 +174,14 @@ type fileMetadata struct {
 	gcsFileHeaderDigest         string
 	gcsFileHeaderContentLength  int64
 	gcsFileHeaderEncryptionMeta *encryptMetadata
+	gcsFileHeaderChecksums      *contentChecksums
 
 	/* mock */
 	mockUploader    s3UploadAPI
+	mockMultipart   s3MultipartAPI
+	mockCopier      s3CopyAPI
 	mockDownloader  s3DownloadAPI
+	mockGetter      s3GetObjectAPI
 	mockHeader      s3HeaderAPI
 	mockGcsClient   gcsAPI
 	mockAzureClient azureAPI
@@ -190,6 +203,7 @@ type fileHeader struct {
 	digest             string
 	contentLength      int64
 	encryptionMetadata *encryptMetadata
+	storageChecksums   *contentChecksums // checksums computed by the storage, nil if it reports none
 }
 
 func getReaderFromBuffer(src **bytes.Buffer) io.Reader {
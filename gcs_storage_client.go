@@ -1,6 +1,8 @@
 package gosnowflake
 
 import (
+	"bytes"
+	"cmp"
 	"context"
 	"encoding/json"
 	"fmt"
@@ -18,6 +20,7 @@ const (
 	gcsMetadataMatdescKey         = gcsMetadataPrefix + "matdesc"
 	gcsMetadataEncryptionDataProp = gcsMetadataPrefix + "encryptiondata"
 	gcsFileHeaderDigest           = "gcs-file-header-digest"
+	gcsHash                       = "x-goog-hash"
 	gcsRegionMeCentral2           = "me-central2"
 )
 
@@ -32,10 +35,10 @@ type gcsLocation struct {
 
 func (util *snowflakeGcsClient) createClient(info *execResponseStageInfo, _ bool) (cloudClient, error) {
 	if info.Creds.GcsAccessToken != "" {
-		logger.Debug("Using GCS downscoped token")
+		getConnectionLogger(util.cfg).Debug("Using GCS downscoped token")
 		return info.Creds.GcsAccessToken, nil
 	}
-	logger.Debugf("No access token received from GS, using presigned url: %s", info.PresignedURL)
+	getConnectionLogger(util.cfg).Debugf("No access token received from GS, using presigned url: %s", info.PresignedURL)
 	return "", nil
 }
 
@@ -46,6 +49,7 @@ func (util *snowflakeGcsClient) getFileHeader(meta *fileMetadata, filename strin
 			digest:             meta.gcsFileHeaderDigest,
 			contentLength:      meta.gcsFileHeaderContentLength,
 			encryptionMetadata: meta.gcsFileHeaderEncryptionMeta,
+			storageChecksums:   meta.gcsFileHeaderChecksums,
 		}, nil
 	}
 	if meta.presignedURL != nil {
@@ -78,7 +82,7 @@ func (util *snowflakeGcsClient) getFileHeader(meta *fileMetadata, filename strin
 			}
 			resp, err := client.Do(req)
 			if err != nil && strings.HasSuffix(err.Error(), "EOF") {
-				logger.Debug("Retrying HEAD request because of EOF")
+				getConnectionLogger(util.cfg).Debug("Retrying HEAD request because of EOF")
 				resp, err = client.Do(req)
 			}
 			return resp, err
@@ -113,7 +117,7 @@ func (util *snowflakeGcsClient) getFileHeader(meta *fileMetadata, filename strin
 			var encryptData *encryptionData
 			err := json.Unmarshal([]byte(resp.Header.Get(gcsMetadataEncryptionDataProp)), &encryptData)
 			if err != nil {
-				logger.Error(err)
+				getConnectionLogger(util.cfg).Error(err)
 			}
 			if encryptData != nil {
 				encryptionMeta = &encryptMetadata{
@@ -130,11 +134,25 @@ func (util *snowflakeGcsClient) getFileHeader(meta *fileMetadata, filename strin
 			digest:             digest,
 			contentLength:      int64(contentLength),
 			encryptionMetadata: encryptionMeta,
+			storageChecksums:   parseGcsHash(resp.Header.Values(gcsHash)),
 		}, nil
 	}
 	return nil, nil
 }
 
+// getContentChecksums returns the checksums of the uploaded content, which GCS validates with the x-goog-hash header.
+func (util *snowflakeGcsClient) getContentChecksums(meta *fileMetadata, dataFile string) (*contentChecksums, error) {
+	if meta.srcStream != nil {
+		return computeContentChecksums(bytes.NewReader(cmp.Or(meta.realSrcStream, meta.srcStream).Bytes()))
+	}
+	f, err := os.Open(dataFile)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return computeContentChecksums(f)
+}
+
 type gcsAPI interface {
 	Do(req *http.Request) (*http.Response, error)
 }
@@ -214,12 +232,21 @@ func (util *snowflakeGcsClient) uploadFile(
 			return err
 		}
 	}
+	if accessToken != "" && meta.verifyChecksums() {
+		// presigned URLs do not allow additional headers
+		checksums, err := util.getContentChecksums(meta, dataFile)
+		if err != nil {
+			return err
+		}
+		gcsHeaders[gcsHash] = checksums.gcsHash()
+	}
 
 	resp, err := withCloudStorageTimeout(util.cfg, func(ctx context.Context) (*http.Response, error) {
 		req, err := http.NewRequestWithContext(ctx, "PUT", uploadURL.String(), uploadSrc)
 		if err != nil {
 			return nil, err
 		}
+		req.Body = meta.progress.readCloser(req.Body)
 		for k, v := range gcsHeaders {
 			req.Header.Add(k, v)
 		}
@@ -252,6 +279,7 @@ func (util *snowflakeGcsClient) uploadFile(
 
 	if meta.options.putCallback != nil {
 		meta.options.putCallback = &snowflakeProgressPercentage{
+			cfg:             util.cfg,
 			filename:        dataFile,
 			fileSize:        float64(meta.srcFileSize),
 			outputStream:    meta.options.putCallbackOutputStream,
@@ -264,6 +292,7 @@ func (util *snowflakeGcsClient) uploadFile(
 
 	meta.gcsFileHeaderDigest = gcsHeaders[gcsFileHeaderDigest]
 	meta.gcsFileHeaderContentLength = meta.uploadSize
+	meta.gcsFileHeaderChecksums = parseGcsHash(resp.Header.Values(gcsHash))
 	if err = json.Unmarshal([]byte(gcsHeaders[gcsMetadataEncryptionDataProp]), &meta.encryptMeta); err != nil {
 		return err
 	}
@@ -276,6 +305,63 @@ func (util *snowflakeGcsClient) nativeDownloadFile(
 	meta *fileMetadata,
 	fullDstFileName string,
 	maxConcurrency int64) error {
+	req, accessToken, err := util.newDownloadRequest(meta)
+	if err != nil {
+		return err
+	}
+	resp, err := withCloudStorageTimeout(util.cfg, func(ctx context.Context) (*http.Response, error) {
+		return util.getObject(ctx, meta, req, accessToken)
+	})
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if meta.options.GetFileToStream {
+		if _, err := io.Copy(meta.dstStream, meta.progress.reader(resp.Body)); err != nil {
+			return err
+		}
+	} else {
+		f, err := os.OpenFile(fullDstFileName, os.O_CREATE|os.O_WRONLY, readWriteFileMode)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		if _, err = io.Copy(f, meta.progress.reader(resp.Body)); err != nil {
+			return err
+		}
+		fi, err := os.Stat(fullDstFileName)
+		if err != nil {
+			return err
+		}
+		meta.srcFileSize = fi.Size()
+	}
+	return util.setDownloadedFileHeader(meta, resp)
+}
+
+// downloadStream implements cloudDownloadStreamUtil.
+func (util *snowflakeGcsClient) downloadStream(meta *fileMetadata) (io.ReadCloser, error) {
+	req, accessToken, err := util.newDownloadRequest(meta)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := cloudStorageContext(util.cfg)
+	resp, err := util.getObject(ctx, meta, req, accessToken)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	if err = util.setDownloadedFileHeader(meta, resp); err != nil {
+		resp.Body.Close()
+		cancel()
+		return nil, err
+	}
+	return &readCloserWithCancel{resp.Body, cancel}, nil
+}
+
+// newDownloadRequest returns the GET request of the downloaded file and the access token it is authorized with,
+// which is empty for presigned URLs.
+func (util *snowflakeGcsClient) newDownloadRequest(meta *fileMetadata) (*http.Request, string, error) {
 	downloadURL := meta.presignedURL
 	var accessToken string
 	var err error
@@ -284,36 +370,39 @@ func (util *snowflakeGcsClient) nativeDownloadFile(
 	if downloadURL == nil || downloadURL.String() == "" {
 		downloadURL, err = util.generateFileURL(meta.stageInfo, strings.TrimLeft(meta.srcFileName, "/"))
 		if err != nil {
-			return err
+			return nil, "", err
 		}
 		var ok bool
 		accessToken, ok = meta.client.(string)
 		if !ok {
-			return fmt.Errorf("interface convertion. expected type string but got %T", meta.client)
+			return nil, "", fmt.Errorf("interface convertion. expected type string but got %T", meta.client)
 		}
 		if accessToken != "" {
 			gcsHeaders["Authorization"] = "Bearer " + accessToken
 		}
 	}
 
-	resp, err := withCloudStorageTimeout(util.cfg, func(ctx context.Context) (*http.Response, error) {
-		req, err := http.NewRequestWithContext(ctx, "GET", downloadURL.String(), nil)
-		if err != nil {
-			return nil, err
-		}
-		for k, v := range gcsHeaders {
-			req.Header.Add(k, v)
-		}
-		client := newGcsClient(util.cfg)
-		// for testing only
-		if meta.mockGcsClient != nil {
-			client = meta.mockGcsClient
-		}
-		return client.Do(req)
-	})
+	req, err := http.NewRequest("GET", downloadURL.String(), nil)
+	if err != nil {
+		return nil, "", err
+	}
+	for k, v := range gcsHeaders {
+		req.Header.Add(k, v)
+	}
+	return req, accessToken, nil
+}
 
+// getObject sends the GET request of the downloaded file. If the response is not successful,
+// it sets the result status of meta and returns meta.lastError.
+func (util *snowflakeGcsClient) getObject(ctx context.Context, meta *fileMetadata, req *http.Request, accessToken string) (*http.Response, error) {
+	client := newGcsClient(util.cfg)
+	// for testing only
+	if meta.mockGcsClient != nil {
+		client = meta.mockGcsClient
+	}
+	resp, err := client.Do(req.WithContext(ctx))
 	if err != nil {
-		return err
+		return nil, err
 	}
 	if resp.StatusCode != http.StatusOK {
 		if resp.StatusCode == 403 || resp.StatusCode == 408 || resp.StatusCode == 429 || resp.StatusCode == 500 || resp.StatusCode == 503 {
@@ -331,33 +420,18 @@ func (util *snowflakeGcsClient) nativeDownloadFile(
 		} else {
 			meta.lastError = fmt.Errorf("%v", resp.Status)
 		}
-		return meta.lastError
-	}
-
-	if meta.options.GetFileToStream {
-		if _, err := io.Copy(meta.dstStream, resp.Body); err != nil {
-			return err
-		}
-	} else {
-		f, err := os.OpenFile(fullDstFileName, os.O_CREATE|os.O_WRONLY, readWriteFileMode)
-		if err != nil {
-			return err
-		}
-		defer f.Close()
-		if _, err = io.Copy(f, resp.Body); err != nil {
-			return err
-		}
-		fi, err := os.Stat(fullDstFileName)
-		if err != nil {
-			return err
-		}
-		meta.srcFileSize = fi.Size()
+		return nil, meta.lastError
 	}
+	return resp, nil
+}
 
+// setDownloadedFileHeader sets meta to downloaded with the digest, size and encryption metadata of the response,
+// which getFileHeader returns for files downloaded with presigned URLs.
+func (util *snowflakeGcsClient) setDownloadedFileHeader(meta *fileMetadata, resp *http.Response) error {
 	var encryptMeta encryptMetadata
 	if resp.Header.Get(gcsMetadataEncryptionDataProp) != "" {
 		var encryptData *encryptionData
-		if err = json.Unmarshal([]byte(resp.Header.Get(gcsMetadataEncryptionDataProp)), &encryptData); err != nil {
+		if err := json.Unmarshal([]byte(resp.Header.Get(gcsMetadataEncryptionDataProp)), &encryptData); err != nil {
 			return err
 		}
 		if encryptData != nil {
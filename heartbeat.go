@@ -28,10 +28,10 @@ func (hc *heartbeat) run() {
 		case <-hbTicker.C:
 			err := hc.heartbeatMain()
 			if err != nil {
-				logger.Error("failed to heartbeat")
+				hc.restful.getLogger().Error("failed to heartbeat")
 			}
 		case <-hc.shutdownChan:
-			logger.Info("stopping heartbeat")
+			hc.restful.getLogger().Info("stopping heartbeat")
 			return
 		}
 	}
@@ -40,17 +40,17 @@ func (hc *heartbeat) run() {
 func (hc *heartbeat) start() {
 	hc.shutdownChan = make(chan bool)
 	go hc.run()
-	logger.Info("heartbeat started")
+	hc.restful.getLogger().Info("heartbeat started")
 }
 
 func (hc *heartbeat) stop() {
 	hc.shutdownChan <- true
 	close(hc.shutdownChan)
-	logger.Info("heartbeat stopped")
+	hc.restful.getLogger().Info("heartbeat stopped")
 }
 
 func (hc *heartbeat) heartbeatMain() error {
-	logger.Info("Heartbeating!")
+	hc.restful.getLogger().Info("Heartbeating!")
 	params := &url.Values{}
 	params.Set(requestIDKey, NewUUID().String())
 	params.Set(requestGUIDKey, NewUUID().String())
@@ -66,11 +66,11 @@ func (hc *heartbeat) heartbeatMain() error {
 	}
 	defer resp.Body.Close()
 	if resp.StatusCode == http.StatusOK {
-		logger.Infof("heartbeatMain: resp: %v", resp)
+		hc.restful.getLogger().Infof("heartbeatMain: resp: %v", resp)
 		var respd execResponse
 		err = json.NewDecoder(resp.Body).Decode(&respd)
 		if err != nil {
-			logger.Infof("failed to decode JSON. err: %v", err)
+			hc.restful.getLogger().Infof("failed to decode JSON. err: %v", err)
 			return err
 		}
 		if respd.Code == sessionExpiredCode {
@@ -83,11 +83,11 @@ func (hc *heartbeat) heartbeatMain() error {
 	}
 	b, err := io.ReadAll(resp.Body)
 	if err != nil {
-		logger.Errorf("failed to extract HTTP response body. err: %v", err)
+		hc.restful.getLogger().Errorf("failed to extract HTTP response body. err: %v", err)
 		return err
 	}
-	logger.Infof("HTTP: %v, URL: %v, Body: %v", resp.StatusCode, fullURL, b)
-	logger.Infof("Header: %v", resp.Header)
+	hc.restful.getLogger().Infof("HTTP: %v, URL: %v, Body: %v", resp.StatusCode, fullURL, b)
+	hc.restful.getLogger().Infof("Header: %v", resp.Header)
 	return &SnowflakeError{
 		Number:   ErrFailedToHeartbeat,
 		SQLState: SQLStateConnectionFailure,
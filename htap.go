@@ -39,7 +39,7 @@ func (qcc *queryContextCache) add(sc *snowflakeConn, qces ...queryContextEntry)
 		qcc.prune(0)
 	} else {
 		for _, newQce := range qces {
-			logger.Debugf("adding query context: %v", newQce)
+			sc.getLogger().Debugf("adding query context: %v", newQce)
 			newQceProcessed := false
 			for existingQceIdx, existingQce := range qcc.entries {
 				if newQce.ID == existingQce.ID {
@@ -85,7 +85,7 @@ func (qcc *queryContextCache) getQueryContextCacheSize(sc *snowflakeConn) int {
 	if ok {
 		size, err := strconv.Atoi(*sizeStr)
 		if err != nil {
-			logger.Warnf("cannot parse %v as int as query context cache size: %v", sizeStr, err)
+			sc.getLogger().Warnf("cannot parse %v as int as query context cache size: %v", sizeStr, err)
 		} else {
 			return size
 		}
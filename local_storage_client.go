@@ -36,14 +36,27 @@ func (util *localUtil) uploadOneFileWithRetry(meta *fileMetadata) error {
 	if err != nil {
 		return err
 	}
-	if !meta.overwrite {
+	if meta.syncUpload() {
+		unchanged, err := isLocalStageFileUnchanged(filepath.Join(user, meta.dstFileName), meta)
+		if err != nil {
+			return err
+		}
+		if unchanged {
+			meta.dstFileSize = 0
+			meta.resStatus = skipped
+			return nil
+		}
+	} else if !meta.overwrite {
 		if _, err := os.Stat(filepath.Join(user, meta.dstFileName)); err == nil {
 			meta.dstFileSize = 0
 			meta.resStatus = skipped
 			return nil
 		}
 	}
-	output, err := os.OpenFile(filepath.Join(user, meta.dstFileName), os.O_CREATE|os.O_WRONLY, readWriteFileMode)
+	if err = os.MkdirAll(filepath.Dir(filepath.Join(user, meta.dstFileName)), os.ModePerm); err != nil {
+		return err
+	}
+	output, err := os.OpenFile(filepath.Join(user, meta.dstFileName), os.O_CREATE|os.O_WRONLY|os.O_TRUNC, readWriteFileMode)
 	if err != nil {
 		return err
 	}
@@ -58,9 +71,38 @@ func (util *localUtil) uploadOneFileWithRetry(meta *fileMetadata) error {
 			break
 		}
 
-		if _, err = output.Write(data); err != nil {
+		if _, err = output.Write(data[:n]); err != nil {
 			return err
 		}
+		meta.progress.add(int64(n))
+	}
+	meta.dstFileSize = meta.uploadSize
+	meta.resStatus = uploaded
+	return nil
+}
+
+func (util *localUtil) uploadOneStream(meta *fileMetadata, src io.Reader) error {
+	user, err := expandUser(meta.stageInfo.Location)
+	if err != nil {
+		return err
+	}
+	if !meta.overwrite && !meta.syncUpload() {
+		if _, err := os.Stat(filepath.Join(user, meta.dstFileName)); err == nil {
+			meta.dstFileSize = 0
+			meta.resStatus = skipped
+			return nil
+		}
+	}
+	if err = os.MkdirAll(filepath.Dir(filepath.Join(user, meta.dstFileName)), os.ModePerm); err != nil {
+		return err
+	}
+	output, err := os.OpenFile(filepath.Join(user, meta.dstFileName), os.O_CREATE|os.O_WRONLY|os.O_TRUNC, readWriteFileMode)
+	if err != nil {
+		return err
+	}
+	defer output.Close()
+	if _, err = io.Copy(output, meta.progress.reader(src)); err != nil {
+		return err
 	}
 	meta.dstFileSize = meta.uploadSize
 	meta.resStatus = uploaded
@@ -77,11 +119,17 @@ func (util *localUtil) downloadOneFile(meta *fileMetadata) error {
 		return err
 	}
 	fullSrcFileName := path.Join(user, srcFileName)
+	if meta.getFileWriter() != nil {
+		return util.downloadOneFileToWriter(meta, fullSrcFileName)
+	}
 	user, err = expandUser(meta.localLocation)
 	if err != nil {
 		return err
 	}
-	fullDstFileName := path.Join(user, baseName(meta.dstFileName))
+	fullDstFileName, err := downloadFilePath(meta, user)
+	if err != nil {
+		return err
+	}
 	baseDir, err := getDirectory()
 	if err != nil {
 		return err
@@ -96,9 +144,11 @@ func (util *localUtil) downloadOneFile(meta *fileMetadata) error {
 	if err != nil {
 		return err
 	}
+	meta.progress.setSize(int64(len(data)))
 	if err = os.WriteFile(fullDstFileName, data, readWriteFileMode); err != nil {
 		return err
 	}
+	meta.progress.add(int64(len(data)))
 	fi, err := os.Stat(fullDstFileName)
 	if err != nil {
 		return err
@@ -107,3 +157,20 @@ func (util *localUtil) downloadOneFile(meta *fileMetadata) error {
 	meta.resStatus = downloaded
 	return nil
 }
+
+// downloadOneFileToWriter writes the stage file to the writer returned by GetFileWriter.
+func (util *localUtil) downloadOneFileToWriter(meta *fileMetadata, fullSrcFileName string) error {
+	src, err := os.Open(fullSrcFileName)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	if fi, err := src.Stat(); err == nil {
+		meta.progress.setSize(fi.Size())
+	}
+	if _, err = writeDownloadStream(meta, meta.progress.reader(src)); err != nil {
+		return err
+	}
+	meta.resStatus = downloaded
+	return nil
+}
@@ -74,7 +74,7 @@ func TestLocalUpload(t *testing.T) {
 	}
 	fileStream, _ := os.Open(fname)
 	ctx := WithFileStream(context.Background(), fileStream)
-	uploadMeta.srcStream, err = getFileStream(ctx)
+	uploadMeta.srcStream, _, err = getFileStream(ctx, dataSizeThreshold)
 	assertNilF(t, err)
 
 	err = localUtil.uploadOneFileWithRetry(&uploadMeta)
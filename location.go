@@ -9,6 +9,7 @@ import (
 
 var (
 	timezones           map[int]*time.Location
+	namedLocations      map[string]*time.Location
 	updateTimezoneMutex *sync.Mutex
 )
 
@@ -62,6 +63,22 @@ func LocationWithOffsetString(offsets string) (loc *time.Location, err error) {
 	return
 }
 
+// LocationWithName returns an IANA time zone Location object, e.g. America/Los_Angeles. Loaded locations are cached.
+// Build with the sftzdata tag to embed the time zone database when the system one is not available.
+func LocationWithName(name string) (*time.Location, error) {
+	updateTimezoneMutex.Lock()
+	defer updateTimezoneMutex.Unlock()
+	if loc, ok := namedLocations[name]; ok {
+		return loc, nil
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, err
+	}
+	namedLocations[name] = loc
+	return loc, nil
+}
+
 func genTimezone(offset int) *time.Location {
 	var offsetSign string
 	var toffset int
@@ -81,6 +98,7 @@ func genTimezone(offset int) *time.Location {
 func init() {
 	updateTimezoneMutex = &sync.Mutex{}
 	timezones = make(map[int]*time.Location, 48)
+	namedLocations = make(map[string]*time.Location)
 	// pre-generate all common timezones
 	for i := -720; i <= 720; i += 30 {
 		logger.Debugf("offset: %v", i)
@@ -90,15 +108,17 @@ func init() {
 
 // retrieve current location based on connection
 func getCurrentLocation(params map[string]*string) *time.Location {
-	loc := time.Now().Location()
-	var err error
+	var tz *string
 	paramsMutex.Lock()
-	if tz, ok := params["timezone"]; ok && tz != nil {
-		loc, err = time.LoadLocation(*tz)
-		if err != nil {
-			loc = time.Now().Location()
-		}
-	}
+	tz = params["timezone"]
 	paramsMutex.Unlock()
+	if tz == nil {
+		return time.Now().Location()
+	}
+	loc, err := LocationWithName(*tz)
+	if err != nil {
+		logger.Warnf("failed to load location for session timezone %v, falling back to local time zone. err: %v", *tz, err)
+		return time.Now().Location()
+	}
 	return loc
 }
@@ -1,6 +1,8 @@
 package gosnowflake
 
 import (
+	"context"
+	"database/sql/driver"
 	"errors"
 	"fmt"
 	"reflect"
@@ -144,3 +146,42 @@ func TestGetCurrentLocation(t *testing.T) {
 		})
 	}
 }
+
+func TestLocationWithName(t *testing.T) {
+	loc, err := LocationWithName("Europe/Warsaw")
+	assertNilF(t, err)
+	assertEqualE(t, loc.String(), "Europe/Warsaw")
+	cached, err := LocationWithName("Europe/Warsaw")
+	assertNilF(t, err)
+	assertTrueE(t, loc == cached, "location should be cached")
+
+	_, err = LocationWithName("Not/exists")
+	assertNotNilE(t, err)
+}
+
+func TestGetTimestampTzLocation(t *testing.T) {
+	sessionLoc, err := LocationWithName("Pacific/Honolulu")
+	assertNilF(t, err)
+	warsawLoc, err := LocationWithName("Europe/Warsaw")
+	assertNilF(t, err)
+
+	assertNilE(t, getTimestampTzLocation(context.Background(), sessionLoc))
+	assertEqualE(t, getTimestampTzLocation(WithTimestampTZLocation(context.Background(), warsawLoc), sessionLoc), warsawLoc)
+	assertEqualE(t, getTimestampTzLocation(WithTimestampTZLocation(context.Background(), nil), sessionLoc), sessionLoc)
+}
+
+func TestStringToValueTimestampTzWithLocation(t *testing.T) {
+	warsawLoc, err := LocationWithName("Europe/Warsaw")
+	assertNilF(t, err)
+	src := "1549491451.123456789 1500" // +01:00
+	var dest driver.Value
+
+	err = stringToValue(context.Background(), &dest, execResponseRowType{Type: "timestamp_tz"}, &src, nil, nil)
+	assertNilF(t, err)
+	assertEqualE(t, dest.(time.Time).Location().String(), "+0100")
+
+	err = stringToValue(WithTimestampTZLocation(context.Background(), warsawLoc), &dest, execResponseRowType{Type: "timestamp_tz"}, &src, nil, nil)
+	assertNilF(t, err)
+	assertEqualE(t, dest.(time.Time).Location(), warsawLoc)
+	assertEqualE(t, dest.(time.Time).UnixNano(), int64(1549491451123456789))
+}
@@ -59,6 +59,7 @@ type defaultLogger struct {
 	inner   *rlog.Logger
 	enabled bool
 	file    *os.File
+	closer  io.Closer // closed with file, e.g. a rotating log writer
 	mu      sync.Mutex
 }
 
@@ -69,9 +70,40 @@ type sfTextFormatter struct {
 func (f *sfTextFormatter) Format(entry *rlog.Entry) ([]byte, error) {
 	// mask all secrets before calling the default Format method
 	entry.Message = maskSecrets(entry.Message)
+	entry.Data = maskFields(entry.Data)
 	return f.TextFormatter.Format(entry)
 }
 
+type sfJSONFormatter struct {
+	rlog.JSONFormatter
+}
+
+func (f *sfJSONFormatter) Format(entry *rlog.Entry) ([]byte, error) {
+	// mask all secrets before calling the default Format method
+	entry.Message = maskSecrets(entry.Message)
+	entry.Data = maskFields(entry.Data)
+	return f.JSONFormatter.Format(entry)
+}
+
+// maskFields returns a copy of the fields with the secrets masked in strings and errors.
+func maskFields(fields rlog.Fields) rlog.Fields {
+	if len(fields) == 0 {
+		return fields
+	}
+	masked := make(rlog.Fields, len(fields))
+	for key, value := range fields {
+		switch v := value.(type) {
+		case string:
+			masked[key] = maskSecrets(v)
+		case error:
+			masked[key] = maskSecrets(v.Error())
+		default:
+			masked[key] = value
+		}
+	}
+	return masked
+}
+
 // SetLogLevel set logging level for calling defaultLogger
 func (log *defaultLogger) SetLogLevel(level string) error {
 	newEnabled := strings.ToUpper(level) != "OFF"
@@ -117,6 +149,11 @@ func (log *defaultLogger) CloseFileOnLoggerReplace(file *os.File) error {
 func (log *defaultLogger) Replace(newLogger *SFLogger) {
 	SetLogger(newLogger)
 	closeLogFile(log.file)
+	if log.closer != nil {
+		if err := log.closer.Close(); err != nil {
+			logger.Errorf("failed to close log writer: %s", err)
+		}
+	}
 }
 
 func closeLogFile(file *os.File) {
@@ -141,6 +178,7 @@ func CreateDefaultLogger() SFLogger {
 	formatter.CallerPrettyfier = SFCallerPrettyfier
 	rLogger.SetFormatter(formatter)
 	rLogger.SetReportCaller(true)
+	rLogger.SetOutput(&syncWriter{w: os.Stderr})
 	var ret = defaultLogger{inner: rLogger, enabled: true}
 	return &ret //(&ret).(*SFLogger)
 }
@@ -435,9 +473,35 @@ func (log *defaultLogger) SetFormatter(formatter rlog.Formatter) {
 
 // SetOutput sets the logger output.
 func (log *defaultLogger) SetOutput(output io.Writer) {
+	if w, ok := log.inner.Out.(*syncWriter); ok {
+		// setting the syncWriter itself keeps the current output instead of making it write to itself
+		if output != io.Writer(w) {
+			w.setWriter(output)
+		}
+		return
+	}
 	log.inner.SetOutput(output)
 }
 
+// syncWriter is the output of the loggers created by CreateDefaultLogger. It serializes the entries written
+// by the logger with the entries passed to it by the loggers of connections, see levelLoggerHook.
+type syncWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func (w *syncWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.w.Write(p)
+}
+
+func (w *syncWriter) setWriter(output io.Writer) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.w = output
+}
+
 func (log *defaultLogger) SetReportCaller(reportCaller bool) {
 	log.inner.SetReportCaller(reportCaller)
 }
@@ -452,6 +516,142 @@ func GetLogger() SFLogger {
 	return logger
 }
 
+// getConnectionLogger returns the logger of the connection or the global one.
+func getConnectionLogger(cfg *Config) SFLogger {
+	if cfg != nil && cfg.Logger != nil {
+		return cfg.Logger
+	}
+	return logger
+}
+
+// modules of the driver which may log with their own log level set by Easy Logging
+const (
+	logModuleRetry           = "retry"
+	logModuleOCSP            = "ocsp"
+	logModuleChunkDownloader = "chunk_downloader"
+)
+
+var logModules = []string{logModuleRetry, logModuleOCSP, logModuleChunkDownloader}
+
+// moduleLoggers are the loggers of the modules with their own log level.
+var moduleLoggers = struct {
+	sync.RWMutex
+	loggers map[string]SFLogger
+}{}
+
+func setModuleLoggers(loggers map[string]SFLogger) {
+	moduleLoggers.Lock()
+	defer moduleLoggers.Unlock()
+	moduleLoggers.loggers = loggers
+}
+
+// getModuleLogger returns the logger of the connection if it is set. Otherwise it returns the logger
+// of the module if the module has its own log level, or the global logger.
+func getModuleLogger(cfg *Config, module string) SFLogger {
+	if cfg != nil && cfg.Logger != nil {
+		return cfg.Logger
+	}
+	moduleLoggers.RLock()
+	defer moduleLoggers.RUnlock()
+	if moduleLogger, ok := moduleLoggers.loggers[module]; ok {
+		return moduleLogger
+	}
+	return logger
+}
+
+// newModuleLogger returns a logger with the log level of a module, which passes the entries to the global logger.
+func newModuleLogger(level string) (SFLogger, error) {
+	moduleLogger := newLevelLogger(func() SFLogger {
+		return logger
+	})
+	if err := moduleLogger.SetLogLevel(level); err != nil {
+		return nil, err
+	}
+	if level == levelOff {
+		// the entries created by WithContext are not filtered by SetLogLevel
+		moduleLogger.(*defaultLogger).inner.SetLevel(rlog.PanicLevel)
+	}
+	return moduleLogger, nil
+}
+
+// setupConnectionLogger sets the logger of the connection to a logger with Config.LogLevel, which passes its entries
+// to Config.Logger or, if it is not set, to the global logger. The level of the logger receiving the entries
+// is not changed, as it may be shared with other connections.
+func setupConnectionLogger(cfg *Config) error {
+	if cfg.LogLevel == "" {
+		return nil
+	}
+	base := cfg.Logger
+	connLogger := newLevelLogger(func() SFLogger {
+		if base != nil {
+			return base
+		}
+		// resolved for every entry, so that the entries follow SetLogger and the easy logging configuration
+		return logger
+	})
+	if err := connLogger.SetLogLevel(cfg.LogLevel); err != nil {
+		return err
+	}
+	cfg.Logger = connLogger
+	return nil
+}
+
+// newLevelLogger returns a logger with its own level, which passes the entries to the logger returned by base.
+func newLevelLogger(base func() SFLogger) SFLogger {
+	rLogger := rlog.New()
+	rLogger.SetOutput(io.Discard)
+	rLogger.SetFormatter(discardFormatter{})
+	rLogger.SetReportCaller(true)
+	rLogger.AddHook(&levelLoggerHook{base: base})
+	return &defaultLogger{inner: rLogger, enabled: true}
+}
+
+// logrusLogger is implemented by the loggers of the driver.
+type logrusLogger interface {
+	logrusLogger() *rlog.Logger
+}
+
+func (log *defaultLogger) logrusLogger() *rlog.Logger {
+	return log.inner
+}
+
+// levelLoggerHook passes the entries of a logger created by newLevelLogger to its base logger.
+type levelLoggerHook struct {
+	base func() SFLogger
+}
+
+func (h *levelLoggerHook) Levels() []rlog.Level {
+	return rlog.AllLevels
+}
+
+// Fire writes the entry with the formatter, output and hooks of a logger of the driver regardless of its level,
+// so that a connection may log more verbosely than the global logger. Other loggers filter the entry by their level.
+// The entry is written without the lock of the logrus logger, the syncWriter output of the logger serializes
+// the writes instead.
+func (h *levelLoggerHook) Fire(entry *rlog.Entry) error {
+	base := h.base()
+	inner, ok := base.(logrusLogger)
+	if !ok {
+		base.WithFields(entry.Data).WithTime(entry.Time).Log(entry.Level, entry.Message)
+		return nil
+	}
+	l := inner.logrusLogger()
+	baseEntry := entry.Dup()
+	baseEntry.Logger = l
+	baseEntry.Level = entry.Level
+	baseEntry.Message = entry.Message
+	baseEntry.Caller = entry.Caller
+	if err := l.Hooks.Fire(entry.Level, baseEntry); err != nil {
+		return err
+	}
+	b, err := l.Formatter.Format(baseEntry)
+	if err != nil {
+		return err
+	}
+	_, err = l.Out.Write(b)
+	return err
+}
+
 func context2Fields(ctx context.Context) *rlog.Fields {
 	var fields = rlog.Fields{}
 	if ctx == nil {
@@ -0,0 +1,126 @@
+package gosnowflake
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"sort"
+
+	rlog "github.com/sirupsen/logrus"
+)
+
+// slog levels of the logrus levels without an slog counterpart
+const (
+	slogLevelTrace = slog.LevelDebug - 4
+	slogLevelFatal = slog.LevelError + 4
+	slogLevelPanic = slog.LevelError + 8
+)
+
+// slogLogger is an SFLogger writing the log entries to an slog.Handler.
+type slogLogger struct {
+	*defaultLogger
+}
+
+// CreateSlogLogger returns a new instance of SFLogger writing the log entries to the slog.Handler.
+// The fields of the entries, including the ones added by WithContext from LogKeys and
+// RegisterLogContextHook, become attributes of the records and secrets are masked
+// in the messages and in the string attributes. Records are filtered by the level of the
+// SFLogger first and then by the handler. SetOutput and SetFormatter have no effect.
+func CreateSlogLogger(handler slog.Handler) SFLogger {
+	rLogger := rlog.New()
+	rLogger.SetOutput(io.Discard)
+	rLogger.SetFormatter(discardFormatter{})
+	rLogger.SetReportCaller(true)
+	rLogger.AddHook(&slogHook{handler: handler})
+	return &slogLogger{&defaultLogger{inner: rLogger, enabled: true}}
+}
+
+// WithContext return Entry to include fields in context, the context is passed to the handler
+func (log *slogLogger) WithContext(ctx context.Context) *rlog.Entry {
+	entry := log.defaultLogger.WithContext(ctx)
+	if ctx != nil {
+		entry = entry.WithContext(ctx)
+	}
+	return entry
+}
+
+// SetOutput has no effect, the records are written by the slog.Handler
+func (log *slogLogger) SetOutput(io.Writer) {
+}
+
+// SetFormatter has no effect, the records are formatted by the slog.Handler
+func (log *slogLogger) SetFormatter(rlog.Formatter) {
+}
+
+// discardFormatter formats nothing, as the entries are written by slogHook.
+type discardFormatter struct{}
+
+func (discardFormatter) Format(*rlog.Entry) ([]byte, error) {
+	return nil, nil
+}
+
+// slogHook passes the logrus entries to an slog.Handler.
+type slogHook struct {
+	handler slog.Handler
+}
+
+func (h *slogHook) Levels() []rlog.Level {
+	return rlog.AllLevels
+}
+
+func (h *slogHook) Fire(entry *rlog.Entry) error {
+	ctx := entry.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	level := toSlogLevel(entry.Level)
+	if !h.handler.Enabled(ctx, level) {
+		return nil
+	}
+	var pc uintptr
+	if entry.Caller != nil {
+		pc = entry.Caller.PC
+	}
+	record := slog.NewRecord(entry.Time, level, maskSecrets(entry.Message), pc)
+	keys := make([]string, 0, len(entry.Data))
+	for key := range entry.Data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		record.AddAttrs(maskedSlogAttr(key, entry.Data[key]))
+	}
+	return h.handler.Handle(ctx, record)
+}
+
+// toSlogLevel returns the slog level of a logrus level.
+func toSlogLevel(level rlog.Level) slog.Level {
+	switch level {
+	case rlog.TraceLevel:
+		return slogLevelTrace
+	case rlog.DebugLevel:
+		return slog.LevelDebug
+	case rlog.InfoLevel:
+		return slog.LevelInfo
+	case rlog.WarnLevel:
+		return slog.LevelWarn
+	case rlog.ErrorLevel:
+		return slog.LevelError
+	case rlog.FatalLevel:
+		return slogLevelFatal
+	default:
+		return slogLevelPanic
+	}
+}
+
+// maskedSlogAttr returns the attribute of a field with the secrets masked in strings and errors.
+func maskedSlogAttr(key string, value interface{}) slog.Attr {
+	switch v := value.(type) {
+	case string:
+		return slog.String(key, maskSecrets(v))
+	case error:
+		return slog.String(key, maskSecrets(v.Error()))
+	default:
+		return slog.Any(key, value)
+	}
+}
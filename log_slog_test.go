@@ -0,0 +1,89 @@
+package gosnowflake
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+
+	rlog "github.com/sirupsen/logrus"
+)
+
+func readSlogRecords(t *testing.T, buf *bytes.Buffer) []map[string]interface{} {
+	var records []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		record := map[string]interface{}{}
+		assertNilF(t, json.Unmarshal([]byte(line), &record))
+		records = append(records, record)
+	}
+	return records
+}
+
+func TestSlogLoggerContextFields(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger := CreateSlogLogger(slog.NewJSONHandler(buf, nil))
+
+	RegisterLogContextHook("REQUEST_ID", func(ctx context.Context) string {
+		if requestID, ok := ctx.Value(testRequestIDCtxKey{}).(string); ok {
+			return requestID
+		}
+		return ""
+	})
+	defer delete(clientLogContextHooks, "REQUEST_ID")
+
+	ctx := context.WithValue(context.Background(), SFSessionIDKey, "sessionID")
+	ctx = context.WithValue(ctx, SFSessionUserKey, "testUser")
+	ctx = context.WithValue(ctx, testRequestIDCtxKey{}, "requestID")
+	logger.WithContext(ctx).WithField("count", 3).Infof("test %v", 1)
+
+	records := readSlogRecords(t, buf)
+	assertEqualF(t, len(records), 1)
+	assertEqualE(t, records[0]["msg"], "test 1")
+	assertEqualE(t, records[0]["level"], "INFO")
+	assertEqualE(t, records[0][string(SFSessionIDKey)], "sessionID")
+	assertEqualE(t, records[0][string(SFSessionUserKey)], "testUser")
+	assertEqualE(t, records[0]["REQUEST_ID"], "requestID")
+	assertEqualE(t, records[0]["count"], 3.0)
+}
+
+func TestSlogLoggerMaskSecrets(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger := CreateSlogLogger(slog.NewJSONHandler(buf, nil))
+
+	logger.WithField("query", "create user testuser password='testpassword'").
+		WithError(errors.New("token=abcdefgh12345678")).
+		Info("Query: create user testuser password='testpassword'")
+
+	records := readSlogRecords(t, buf)
+	assertEqualF(t, len(records), 1)
+	for _, key := range []string{"msg", "query", rlog.ErrorKey} {
+		value, ok := records[0][key].(string)
+		assertTrueF(t, ok, key)
+		assertFalseE(t, strings.Contains(value, "testpassword"), value)
+		assertFalseE(t, strings.Contains(value, "abcdefgh12345678"), value)
+	}
+}
+
+func TestSlogLoggerLevels(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger := CreateSlogLogger(slog.NewJSONHandler(buf, &slog.HandlerOptions{Level: slogLevelTrace}))
+
+	logger.Debug("filtered by the logger")
+	assertNilF(t, logger.SetLogLevel("trace"))
+	logger.Trace("trace")
+	logger.Warn("warn")
+	assertNilF(t, logger.SetLogLevel("OFF"))
+	logger.Error("disabled")
+
+	records := readSlogRecords(t, buf)
+	assertEqualF(t, len(records), 2)
+	assertEqualE(t, records[0]["level"], "DEBUG-4")
+	assertEqualE(t, records[0]["msg"], "trace")
+	assertEqualE(t, records[1]["level"], "WARN")
+}
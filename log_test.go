@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -336,3 +337,125 @@ func TestLogMaskSecrets(t *testing.T) {
 		t.Fatalf("expected that password would be masked. WithContext was used, but got: %v", strbuf)
 	}
 }
+
+func TestGetConnectionLogger(t *testing.T) {
+	connLogger := CreateDefaultLogger()
+	assertEqualE(t, getConnectionLogger(&Config{Logger: connLogger}), connLogger)
+	assertEqualE(t, getConnectionLogger(&Config{}), logger)
+	assertEqualE(t, getConnectionLogger(nil), logger)
+	assertEqualE(t, (*snowflakeConn)(nil).getLogger(), logger)
+	assertEqualE(t, (&snowflakeRestful{Logger: connLogger}).getLogger(), connLogger)
+}
+
+func TestSetupConnectionLogger(t *testing.T) {
+	cfg := &Config{}
+	assertNilF(t, setupConnectionLogger(cfg))
+	assertNilE(t, cfg.Logger)
+
+	assertNotNilE(t, setupConnectionLogger(&Config{LogLevel: "unknown"}))
+}
+
+func TestConnectionLogLevelWithGlobalLogger(t *testing.T) {
+	orgLogger := GetLogger()
+	defer SetLogger(&orgLogger)
+	var buf bytes.Buffer
+	globalLogger := CreateDefaultLogger()
+	globalLogger.SetOutput(&buf)
+	assertNilF(t, globalLogger.SetLogLevel("error"))
+	SetLogger(&globalLogger)
+
+	cfg := &Config{LogLevel: "debug"}
+	assertNilF(t, setupConnectionLogger(cfg))
+	assertEqualE(t, cfg.Logger.GetLogLevel(), "debug")
+	cfg.Logger.Debugf("connection message: create user testuser password='%v'", "secret")
+	logger.Debug("global message")
+	assertEqualE(t, globalLogger.GetLogLevel(), "error", "the level of the global logger should not change")
+	assertStringContainsE(t, buf.String(), "connection message")
+	assertTrueE(t, !strings.Contains(buf.String(), "secret"), "the formatter of the global logger should mask secrets")
+	assertTrueE(t, !strings.Contains(buf.String(), "global message"))
+
+	// the entries follow the global logger when it is replaced
+	var replacedBuf bytes.Buffer
+	replacedLogger := CreateDefaultLogger()
+	replacedLogger.SetOutput(&replacedBuf)
+	SetLogger(&replacedLogger)
+	cfg.Logger.Info("after replacement")
+	assertStringContainsE(t, replacedBuf.String(), "after replacement")
+}
+
+func TestConnectionLoggersWriteConcurrently(t *testing.T) {
+	var buf bytes.Buffer
+	sharedLogger := CreateDefaultLogger()
+	sharedLogger.SetOutput(&buf)
+	assertNilF(t, sharedLogger.SetLogLevel("info"))
+	cfg := &Config{Logger: sharedLogger, LogLevel: "debug"}
+	assertNilF(t, setupConnectionLogger(cfg))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				cfg.Logger.Debug("connection message")
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				sharedLogger.Info("shared message")
+			}
+		}()
+	}
+	wg.Wait()
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	assertEqualF(t, len(lines), 2000)
+	for _, line := range lines {
+		assertTrueF(t, strings.HasPrefix(line, "time=") && strings.Count(line, "msg=") == 1, "interleaved line: "+line)
+	}
+}
+
+func TestSetOwnOutputOfDefaultLogger(t *testing.T) {
+	var buf bytes.Buffer
+	sfLogger := CreateDefaultLogger().(*defaultLogger)
+	sfLogger.SetOutput(&buf)
+	sfLogger.SetOutput(sfLogger.inner.Out)
+	sfLogger.Info("kept")
+	assertStringContainsE(t, buf.String(), "msg=kept")
+}
+
+func TestConnectionLogLevelWithSharedLogger(t *testing.T) {
+	var buf bytes.Buffer
+	sharedLogger := CreateDefaultLogger()
+	sharedLogger.SetOutput(&buf)
+	assertNilF(t, sharedLogger.SetLogLevel("info"))
+
+	cfg := &Config{Logger: sharedLogger, LogLevel: "warn"}
+	assertNilF(t, setupConnectionLogger(cfg))
+	assertNotEqualE(t, cfg.Logger, sharedLogger)
+	assertEqualE(t, sharedLogger.GetLogLevel(), "info", "the level of the shared logger should not change")
+	cfg.Logger.Info("filtered message")
+	cfg.Logger.WithContext(context.Background()).Warn("connection warning")
+	sharedLogger.Info("shared message")
+	assertTrueE(t, !strings.Contains(buf.String(), "filtered message"))
+	assertStringContainsE(t, buf.String(), "connection warning")
+	assertStringContainsE(t, buf.String(), "shared message")
+}
+
+func TestConnectionLoggerOfChunkDownloader(t *testing.T) {
+	connLogger := CreateDefaultLogger()
+	assertNilF(t, connLogger.SetLogLevel("info"))
+	buf := &bytes.Buffer{}
+	connLogger.SetOutput(buf)
+	scd := &snowflakeChunkDownloader{
+		sc: &snowflakeConn{
+			cfg:  &Config{Logger: connLogger},
+			rest: &snowflakeRestful{RequestTimeout: defaultRequestTimeout},
+		},
+		ctx:        context.Background(),
+		ChunkMetas: []execResponseChunk{{URL: "dummyURL1", RowCount: rowsInChunk}},
+		FuncGet:    getChunkTestErrorStatus,
+	}
+	assertNotNilF(t, downloadChunkHelper(scd.ctx, scd, 0))
+	assertStringContainsE(t, buf.String(), "HTTP: 502, URL: dummyURL1")
+}
@@ -0,0 +1,123 @@
+package gosnowflake
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// statuses of the operations reported to MetricsRecorder
+const (
+	MetricsStatusSuccess  = "success"
+	MetricsStatusError    = "error"
+	MetricsStatusCanceled = "canceled"
+)
+
+// MetricsRecorder receives the metrics of the driver. Its methods are called concurrently
+// and synchronously with the measured operations, so they should return quickly.
+// Set it in Config.MetricsRecorder for a connection or with SetMetricsRecorder for the whole process.
+// A recorder can also implement RecordTelemetryDropped(count int) to count the telemetry events
+// which were not sent to Snowflake.
+type MetricsRecorder interface {
+	// RecordLogin is called when the authentication of a new connection finishes.
+	RecordLogin(ctx context.Context, duration time.Duration, status string)
+	// RecordQuery is called when a query request finishes, including polling for its result.
+	RecordQuery(ctx context.Context, duration time.Duration, status string)
+	// RecordRetry is called when an HTTP request is retried. endpoint is the path of a Snowflake endpoint
+	// or "other", reason is the retry reason sent to Snowflake: the HTTP status code or 0 for connection errors.
+	RecordRetry(ctx context.Context, endpoint string, reason int)
+	// RecordChunkDownload is called when a chunk of a result set is downloaded with the number of its bytes.
+	RecordChunkDownload(ctx context.Context, bytes int64, duration time.Duration, status string)
+	// RecordOCSPCacheLookup is called when the OCSP response of a certificate is looked up in the cache.
+	// hit is true when the cache has a response of the certificate, even if the response is expired or revoked.
+	// The OCSP cache is shared by all connections, so only the recorder set by SetMetricsRecorder receives it.
+	RecordOCSPCacheLookup(hit bool)
+	// RecordFileTransfer is called when a file is transferred by PUT or GET. command is "PUT" or "GET"
+	// and status is the status of the file, e.g. UPLOADED, SKIPPED or ERROR.
+	RecordFileTransfer(ctx context.Context, command string, bytes int64, status string)
+}
+
+// noopMetricsRecorder is the MetricsRecorder used by default.
+type noopMetricsRecorder struct{}
+
+func (noopMetricsRecorder) RecordLogin(context.Context, time.Duration, string)                {}
+func (noopMetricsRecorder) RecordQuery(context.Context, time.Duration, string)                {}
+func (noopMetricsRecorder) RecordRetry(context.Context, string, int)                          {}
+func (noopMetricsRecorder) RecordChunkDownload(context.Context, int64, time.Duration, string) {}
+func (noopMetricsRecorder) RecordOCSPCacheLookup(bool)                                        {}
+func (noopMetricsRecorder) RecordFileTransfer(context.Context, string, int64, string)         {}
+
+var (
+	defaultMetricsRecorder     MetricsRecorder = noopMetricsRecorder{}
+	defaultMetricsRecorderLock sync.RWMutex
+)
+
+// SetMetricsRecorder sets the MetricsRecorder of the connections without Config.MetricsRecorder
+// and of the OCSP cache. A nil recorder disables the metrics.
+func SetMetricsRecorder(recorder MetricsRecorder) {
+	if recorder == nil {
+		recorder = noopMetricsRecorder{}
+	}
+	defaultMetricsRecorderLock.Lock()
+	defer defaultMetricsRecorderLock.Unlock()
+	defaultMetricsRecorder = recorder
+}
+
+func getDefaultMetricsRecorder() MetricsRecorder {
+	defaultMetricsRecorderLock.RLock()
+	defer defaultMetricsRecorderLock.RUnlock()
+	return defaultMetricsRecorder
+}
+
+// getMetricsRecorder returns the MetricsRecorder of the connection or the default one.
+func getMetricsRecorder(cfg *Config) MetricsRecorder {
+	if cfg != nil && cfg.MetricsRecorder != nil {
+		return cfg.MetricsRecorder
+	}
+	return getDefaultMetricsRecorder()
+}
+
+// metricsStatus returns the status of an operation which failed with err.
+func metricsStatus(err error) string {
+	switch {
+	case err == nil:
+		return MetricsStatusSuccess
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return MetricsStatusCanceled
+	default:
+		return MetricsStatusError
+	}
+}
+
+// metricsEndpoints are the endpoints reported by RecordRetry, other paths would make too many distinct values.
+var metricsEndpoints = []string{
+	loginRequestPath,
+	queryRequestPath,
+	tokenRequestPath,
+	abortRequestPath,
+	authenticatorRequestPath,
+	monitoringQueriesPath,
+	heartBeatPath,
+	consoleLoginRequestPath,
+	telemetryPath,
+	sessionRequestPath,
+}
+
+// metricsEndpoint returns the endpoint of the URL reported by RecordRetry.
+func metricsEndpoint(u *url.URL) string {
+	if u == nil {
+		return "other"
+	}
+	for _, endpoint := range metricsEndpoints {
+		if u.Path == endpoint {
+			return endpoint
+		}
+	}
+	if strings.HasPrefix(u.Path, "/queries/") && strings.HasSuffix(u.Path, "/result") {
+		return "/queries/{queryId}/result"
+	}
+	return "other"
+}
@@ -0,0 +1,112 @@
+// Package otel provides a gosnowflake.MetricsRecorder which records the metrics of the driver with OpenTelemetry instruments.
+package otel
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	sf "github.com/snowflakedb/gosnowflake"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+const meterName = "github.com/snowflakedb/gosnowflake"
+
+// attributes of the recorded metrics
+const (
+	attrMetricsStatus   = attribute.Key("snowflake.status")
+	attrMetricsEndpoint = attribute.Key("snowflake.endpoint")
+	attrMetricsReason   = attribute.Key("snowflake.retry_reason")
+	attrMetricsCommand  = attribute.Key("snowflake.command")
+	attrMetricsHit      = attribute.Key("snowflake.cache_hit")
+)
+
+// Recorder is a gosnowflake.MetricsRecorder recording the metrics of the driver with OpenTelemetry instruments.
+type Recorder struct {
+	loginDuration     metric.Float64Histogram
+	queryDuration     metric.Float64Histogram
+	retries           metric.Int64Counter
+	chunkBytes        metric.Int64Counter
+	chunkDuration     metric.Float64Histogram
+	ocspCacheLookups  metric.Int64Counter
+	fileTransferBytes metric.Int64Counter
+	telemetryDropped  metric.Int64Counter
+}
+
+// NewRecorder creates the instruments of the driver metrics with a meter of the provider.
+func NewRecorder(provider metric.MeterProvider) (*Recorder, error) {
+	meter := provider.Meter(meterName, metric.WithInstrumentationVersion(sf.SnowflakeGoDriverVersion))
+	r := &Recorder{}
+	var err error
+	if r.loginDuration, err = meter.Float64Histogram("snowflake.login.duration",
+		metric.WithDescription("Duration of the authentication of new connections"), metric.WithUnit("s")); err != nil {
+		return nil, err
+	}
+	if r.queryDuration, err = meter.Float64Histogram("snowflake.query.duration",
+		metric.WithDescription("Duration of query requests including polling for their results"), metric.WithUnit("s")); err != nil {
+		return nil, err
+	}
+	if r.retries, err = meter.Int64Counter("snowflake.http.retries",
+		metric.WithDescription("Number of retried HTTP requests")); err != nil {
+		return nil, err
+	}
+	if r.chunkBytes, err = meter.Int64Counter("snowflake.chunk.download.size",
+		metric.WithDescription("Number of downloaded bytes of result set chunks"), metric.WithUnit("By")); err != nil {
+		return nil, err
+	}
+	if r.chunkDuration, err = meter.Float64Histogram("snowflake.chunk.download.duration",
+		metric.WithDescription("Duration of downloads of result set chunks"), metric.WithUnit("s")); err != nil {
+		return nil, err
+	}
+	if r.ocspCacheLookups, err = meter.Int64Counter("snowflake.ocsp.cache.lookups",
+		metric.WithDescription("Number of lookups of OCSP responses in the cache")); err != nil {
+		return nil, err
+	}
+	if r.fileTransferBytes, err = meter.Int64Counter("snowflake.file_transfer.size",
+		metric.WithDescription("Number of bytes transferred by PUT and GET"), metric.WithUnit("By")); err != nil {
+		return nil, err
+	}
+	if r.telemetryDropped, err = meter.Int64Counter("snowflake.telemetry.dropped",
+		metric.WithDescription("Number of telemetry events which were not sent to Snowflake")); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// RecordLogin implements gosnowflake.MetricsRecorder.
+func (r *Recorder) RecordLogin(ctx context.Context, duration time.Duration, status string) {
+	r.loginDuration.Record(ctx, duration.Seconds(), metric.WithAttributes(attrMetricsStatus.String(status)))
+}
+
+// RecordQuery implements gosnowflake.MetricsRecorder.
+func (r *Recorder) RecordQuery(ctx context.Context, duration time.Duration, status string) {
+	r.queryDuration.Record(ctx, duration.Seconds(), metric.WithAttributes(attrMetricsStatus.String(status)))
+}
+
+// RecordRetry implements gosnowflake.MetricsRecorder.
+func (r *Recorder) RecordRetry(ctx context.Context, endpoint string, reason int) {
+	r.retries.Add(ctx, 1, metric.WithAttributes(attrMetricsEndpoint.String(endpoint), attrMetricsReason.String(strconv.Itoa(reason))))
+}
+
+// RecordChunkDownload implements gosnowflake.MetricsRecorder.
+func (r *Recorder) RecordChunkDownload(ctx context.Context, bytes int64, duration time.Duration, status string) {
+	attrs := metric.WithAttributes(attrMetricsStatus.String(status))
+	r.chunkBytes.Add(ctx, bytes, attrs)
+	r.chunkDuration.Record(ctx, duration.Seconds(), attrs)
+}
+
+// RecordOCSPCacheLookup implements gosnowflake.MetricsRecorder.
+func (r *Recorder) RecordOCSPCacheLookup(hit bool) {
+	r.ocspCacheLookups.Add(context.Background(), 1, metric.WithAttributes(attrMetricsHit.Bool(hit)))
+}
+
+// RecordFileTransfer implements gosnowflake.MetricsRecorder.
+func (r *Recorder) RecordFileTransfer(ctx context.Context, command string, bytes int64, status string) {
+	r.fileTransferBytes.Add(ctx, bytes, metric.WithAttributes(attrMetricsCommand.String(command), attrMetricsStatus.String(status)))
+}
+
+// RecordTelemetryDropped counts the telemetry events which were not sent to Snowflake.
+func (r *Recorder) RecordTelemetryDropped(count int) {
+	r.telemetryDropped.Add(context.Background(), int64(count))
+}
@@ -0,0 +1,20 @@
+package otel
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	sf "github.com/snowflakedb/gosnowflake"
+	"go.opentelemetry.io/otel/metric/noop"
+)
+
+func TestNewRecorder(t *testing.T) {
+	recorder, err := NewRecorder(noop.NewMeterProvider())
+	if err != nil {
+		t.Fatal(err)
+	}
+	recorder.RecordQuery(context.Background(), time.Second, sf.MetricsStatusSuccess)
+	recorder.RecordChunkDownload(context.Background(), 100, time.Second, sf.MetricsStatusSuccess)
+	recorder.RecordOCSPCacheLookup(false)
+}
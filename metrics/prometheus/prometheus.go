@@ -0,0 +1,138 @@
+// Package prometheus provides a gosnowflake.MetricsRecorder which exports the metrics of the driver to Prometheus.
+package prometheus
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const namespace = "snowflake"
+
+// Recorder is a gosnowflake.MetricsRecorder and a prometheus.Collector of the metrics of the driver.
+// Register it with a prometheus.Registerer to export the metrics.
+type Recorder struct {
+	loginDuration     *prometheus.HistogramVec
+	queryDuration     *prometheus.HistogramVec
+	retries           *prometheus.CounterVec
+	chunkBytes        *prometheus.CounterVec
+	chunkDuration     *prometheus.HistogramVec
+	ocspCacheLookups  *prometheus.CounterVec
+	fileTransferBytes *prometheus.CounterVec
+	telemetryDropped  prometheus.Counter
+}
+
+// NewRecorder creates the collectors of the driver metrics.
+func NewRecorder() *Recorder {
+	return &Recorder{
+		loginDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "login_duration_seconds",
+			Help:      "Duration of the authentication of new connections.",
+		}, []string{"status"}),
+		queryDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "query_duration_seconds",
+			Help:      "Duration of query requests including polling for their results.",
+		}, []string{"status"}),
+		retries: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "http_retries_total",
+			Help:      "Number of retried HTTP requests.",
+		}, []string{"endpoint", "reason"}),
+		chunkBytes: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "chunk_download_bytes_total",
+			Help:      "Number of downloaded bytes of result set chunks.",
+		}, []string{"status"}),
+		chunkDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "chunk_download_duration_seconds",
+			Help:      "Duration of downloads of result set chunks.",
+		}, []string{"status"}),
+		ocspCacheLookups: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "ocsp_cache_lookups_total",
+			Help:      "Number of lookups of OCSP responses in the cache.",
+		}, []string{"result"}),
+		fileTransferBytes: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "file_transfer_bytes_total",
+			Help:      "Number of bytes transferred by PUT and GET.",
+		}, []string{"command", "status"}),
+		telemetryDropped: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "telemetry_events_dropped_total",
+			Help:      "Number of telemetry events which were not sent to Snowflake.",
+		}),
+	}
+}
+
+func (r *Recorder) collectors() []prometheus.Collector {
+	return []prometheus.Collector{
+		r.loginDuration,
+		r.queryDuration,
+		r.retries,
+		r.chunkBytes,
+		r.chunkDuration,
+		r.ocspCacheLookups,
+		r.fileTransferBytes,
+		r.telemetryDropped,
+	}
+}
+
+// Describe implements prometheus.Collector.
+func (r *Recorder) Describe(ch chan<- *prometheus.Desc) {
+	for _, c := range r.collectors() {
+		c.Describe(ch)
+	}
+}
+
+// Collect implements prometheus.Collector.
+func (r *Recorder) Collect(ch chan<- prometheus.Metric) {
+	for _, c := range r.collectors() {
+		c.Collect(ch)
+	}
+}
+
+// RecordLogin implements gosnowflake.MetricsRecorder.
+func (r *Recorder) RecordLogin(_ context.Context, duration time.Duration, status string) {
+	r.loginDuration.WithLabelValues(status).Observe(duration.Seconds())
+}
+
+// RecordQuery implements gosnowflake.MetricsRecorder.
+func (r *Recorder) RecordQuery(_ context.Context, duration time.Duration, status string) {
+	r.queryDuration.WithLabelValues(status).Observe(duration.Seconds())
+}
+
+// RecordRetry implements gosnowflake.MetricsRecorder.
+func (r *Recorder) RecordRetry(_ context.Context, endpoint string, reason int) {
+	r.retries.WithLabelValues(endpoint, strconv.Itoa(reason)).Inc()
+}
+
+// RecordChunkDownload implements gosnowflake.MetricsRecorder.
+func (r *Recorder) RecordChunkDownload(_ context.Context, bytes int64, duration time.Duration, status string) {
+	r.chunkBytes.WithLabelValues(status).Add(float64(bytes))
+	r.chunkDuration.WithLabelValues(status).Observe(duration.Seconds())
+}
+
+// RecordOCSPCacheLookup implements gosnowflake.MetricsRecorder.
+func (r *Recorder) RecordOCSPCacheLookup(hit bool) {
+	result := "miss"
+	if hit {
+		result = "hit"
+	}
+	r.ocspCacheLookups.WithLabelValues(result).Inc()
+}
+
+// RecordFileTransfer implements gosnowflake.MetricsRecorder.
+func (r *Recorder) RecordFileTransfer(_ context.Context, command string, bytes int64, status string) {
+	r.fileTransferBytes.WithLabelValues(command, status).Add(float64(bytes))
+}
+
+// RecordTelemetryDropped counts the telemetry events which were not sent to Snowflake.
+func (r *Recorder) RecordTelemetryDropped(count int) {
+	r.telemetryDropped.Add(float64(count))
+}
@@ -0,0 +1,31 @@
+package prometheus
+
+import (
+	"context"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	sf "github.com/snowflakedb/gosnowflake"
+)
+
+func TestRecorder(t *testing.T) {
+	var recorder sf.MetricsRecorder = NewRecorder()
+	recorder.RecordRetry(context.Background(), "/queries/v1/query-request", 429)
+	recorder.RecordRetry(context.Background(), "/queries/v1/query-request", 429)
+	recorder.RecordOCSPCacheLookup(true)
+	recorder.RecordFileTransfer(context.Background(), "PUT", 100, "UPLOADED")
+
+	r := recorder.(*Recorder)
+	if v := testutil.ToFloat64(r.retries.WithLabelValues("/queries/v1/query-request", "429")); v != 2 {
+		t.Errorf("unexpected number of retries: %v", v)
+	}
+	if v := testutil.ToFloat64(r.ocspCacheLookups.WithLabelValues("hit")); v != 1 {
+		t.Errorf("unexpected number of OCSP cache hits: %v", v)
+	}
+	if v := testutil.ToFloat64(r.fileTransferBytes.WithLabelValues("PUT", "UPLOADED")); v != 100 {
+		t.Errorf("unexpected number of transferred bytes: %v", v)
+	}
+	if n := testutil.CollectAndCount(r, "snowflake_http_retries_total"); n != 1 {
+		t.Errorf("unexpected number of retry metrics: %v", n)
+	}
+}
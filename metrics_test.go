@@ -0,0 +1,97 @@
+package gosnowflake
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+// testMetricsRecorder records the calls of the driver as strings.
+type testMetricsRecorder struct {
+	noopMetricsRecorder
+	mu      sync.Mutex
+	records []string
+}
+
+func (r *testMetricsRecorder) add(format string, args ...any) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.records = append(r.records, fmt.Sprintf(format, args...))
+}
+
+func (r *testMetricsRecorder) RecordChunkDownload(_ context.Context, bytes int64, _ time.Duration, status string) {
+	r.add("chunk %v %v", bytes, status)
+}
+
+func (r *testMetricsRecorder) RecordFileTransfer(_ context.Context, command string, bytes int64, status string) {
+	r.add("%v %v %v", command, bytes, status)
+}
+
+func TestMetricsEndpoint(t *testing.T) {
+	testcases := map[string]string{
+		"https://a.snowflakecomputing.com/queries/v1/query-request?requestId=1": queryRequestPath,
+		"https://a.snowflakecomputing.com/session/v1/login-request":             loginRequestPath,
+		"https://a.snowflakecomputing.com/queries/01b2c3/result":                "/queries/{queryId}/result",
+		"https://bucket.s3.amazonaws.com/results/01b2c3/main/data_0_0_0":        "other",
+	}
+	for rawURL, endpoint := range testcases {
+		u, err := url.Parse(rawURL)
+		assertNilF(t, err)
+		assertEqualE(t, metricsEndpoint(u), endpoint)
+	}
+}
+
+func TestMetricsStatus(t *testing.T) {
+	assertEqualE(t, metricsStatus(nil), MetricsStatusSuccess)
+	assertEqualE(t, metricsStatus(fmt.Errorf("wrapped: %w", context.Canceled)), MetricsStatusCanceled)
+	assertEqualE(t, metricsStatus(&SnowflakeError{Number: ErrFailedToPostQuery}), MetricsStatusError)
+}
+
+func TestGetMetricsRecorder(t *testing.T) {
+	recorder := &testMetricsRecorder{}
+	assertEqualE(t, getMetricsRecorder(&Config{MetricsRecorder: recorder}), MetricsRecorder(recorder))
+	assertEqualE(t, getMetricsRecorder(nil), MetricsRecorder(noopMetricsRecorder{}))
+
+	SetMetricsRecorder(recorder)
+	defer SetMetricsRecorder(nil)
+	assertEqualE(t, getMetricsRecorder(&Config{}), MetricsRecorder(recorder))
+}
+
+func TestRecordChunkDownloadMetrics(t *testing.T) {
+	recorder := &testMetricsRecorder{}
+	scd := &snowflakeChunkDownloader{
+		sc: &snowflakeConn{
+			cfg:  &Config{MetricsRecorder: recorder},
+			rest: &snowflakeRestful{RequestTimeout: defaultRequestTimeout},
+		},
+		ctx:        context.Background(),
+		ChunkMetas: []execResponseChunk{{URL: "dummyURL1", RowCount: rowsInChunk}},
+		FuncGet:    getChunkTestErrorStatus,
+	}
+	assertNotNilF(t, downloadChunkHelper(scd.ctx, scd, 0))
+	assertDeepEqualE(t, recorder.records, []string{"chunk 2 error"})
+}
+
+func TestRecordFileTransferMetrics(t *testing.T) {
+	tmpDir := t.TempDir()
+	srcFile := filepath.Join(tmpDir, "data.csv")
+	assertNilF(t, os.WriteFile(srcFile, []byte("1,2,3\n"), readWriteFileMode))
+	recorder := &testMetricsRecorder{}
+	sc := &snowflakeConn{cfg: &Config{TmpDirPath: tmpDir, MetricsRecorder: recorder}}
+	_, err := sc.processFileTransfer(context.Background(), &execResponse{Data: execResponseData{
+		Command:           string(uploadCommand),
+		SrcLocations:      []string{srcFile},
+		SourceCompression: "none",
+		StageInfo: execResponseStageInfo{
+			LocationType: string(local),
+			Location:     filepath.Join(tmpDir, "stage"),
+		},
+	}}, "PUT 'file://data.csv' '@~'", false)
+	assertNilF(t, err)
+	assertDeepEqualE(t, recorder.records, []string{"PUT 6 UPLOADED"})
+}
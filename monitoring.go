@@ -113,6 +113,9 @@ type SnowflakeQueryStatus struct {
 // SnowflakeConnection is a wrapper to snowflakeConn that exposes API functions
 type SnowflakeConnection interface {
 	GetQueryStatus(ctx context.Context, queryID string) (*SnowflakeQueryStatus, error)
+	Put(ctx context.Context, req PutRequest) ([]FileTransferResult, error)
+	Get(ctx context.Context, req GetRequest) ([]FileTransferResult, error)
+	QueryHistory() []QueryHistoryEntry
 }
 
 // checkQueryStatus returns the status given the query ID. If successful,
@@ -139,18 +142,18 @@ func (sc *snowflakeConn) checkQueryStatus(
 
 	res, err := sc.rest.FuncGet(ctx, sc.rest, url, headers, sc.rest.RequestTimeout)
 	if err != nil {
-		logger.WithContext(ctx).Errorf("failed to get response. err: %v", err)
+		sc.getLogger().WithContext(ctx).Errorf("failed to get response. err: %v", err)
 		return nil, err
 	}
 	defer res.Body.Close()
 	var statusResp = statusResponse{}
 	if err = json.NewDecoder(res.Body).Decode(&statusResp); err != nil {
-		logger.WithContext(ctx).Errorf("failed to decode JSON. err: %v", err)
+		sc.getLogger().WithContext(ctx).Errorf("failed to decode JSON. err: %v", err)
 		return nil, err
 	}
 
 	if !statusResp.Success || len(statusResp.Data.Queries) == 0 {
-		logger.WithContext(ctx).Errorf("status query returned not-success or no status returned.")
+		sc.getLogger().WithContext(ctx).Errorf("status query returned not-success or no status returned.")
 		return nil, (&SnowflakeError{
 			Number:  ErrQueryStatus,
 			Message: "status query returned not-success or no status returned. Please retry",
@@ -215,7 +218,7 @@ func (sc *snowflakeConn) getQueryResultResp(
 
 	respd, err := getQueryResultWithRetriesForAsyncMode(ctx, sc.rest, url, headers, sc.rest.RequestTimeout)
 	if err != nil {
-		logger.WithContext(ctx).Errorf("error: %v", err)
+		sc.getLogger().WithContext(ctx).Errorf("error: %v", err)
 		return nil, err
 	}
 	return respd, nil
@@ -228,7 +231,7 @@ func (sc *snowflakeConn) rowsForRunningQuery(
 	resultPath := fmt.Sprintf(urlQueriesResultFmt, qid)
 	resp, err := sc.getQueryResultResp(ctx, resultPath)
 	if err != nil {
-		logger.WithContext(ctx).Errorf("error: %v", err)
+		sc.getLogger().WithContext(ctx).Errorf("error: %v", err)
 		return err
 	}
 
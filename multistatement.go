@@ -49,7 +49,7 @@ func (sc *snowflakeConn) handleMultiExec(
 		if isDml(childResultType) {
 			childData, err := sc.getQueryResultResp(ctx, resultPath)
 			if err != nil {
-				logger.WithContext(ctx).Errorf("error: %v", err)
+				sc.getLogger().WithContext(ctx).Errorf("error: %v", err)
 				return nil, err
 			}
 			if childData != nil && !childData.Success {
@@ -66,13 +66,13 @@ func (sc *snowflakeConn) handleMultiExec(
 			}
 			count, err := updateRows(childData.Data)
 			if err != nil {
-				logger.WithContext(ctx).Errorf("error: %v", err)
+				sc.getLogger().WithContext(ctx).Errorf("error: %v", err)
 				return nil, err
 			}
 			updatedRows += count
 		}
 	}
-	logger.WithContext(ctx).Infof("number of updated rows: %#v", updatedRows)
+	sc.getLogger().WithContext(ctx).Infof("number of updated rows: %#v", updatedRows)
 	return &snowflakeResult{
 		affectedRows: updatedRows,
 		insertID:     -1,
@@ -181,6 +181,11 @@ var hashOIDs = map[crypto.Hash]asn1.ObjectIdentifier{
 	crypto.SHA512: asn1.ObjectIdentifier([]int{2, 16, 840, 1, 101, 3, 4, 2, 3}),
 }
 
+// ocspLogger returns the logger of the OCSP module.
+func ocspLogger() SFLogger {
+	return getModuleLogger(nil, logModuleOCSP)
+}
+
 // copied from crypto/ocsp
 func getOIDFromHashAlgorithm(target crypto.Hash) asn1.ObjectIdentifier {
 	for hash, oid := range hashOIDs {
@@ -188,7 +193,7 @@ func getOIDFromHashAlgorithm(target crypto.Hash) asn1.ObjectIdentifier {
 			return oid
 		}
 	}
-	logger.Errorf("no valid OID is found for the hash algorithm. %#v", target)
+	ocspLogger().Errorf("no valid OID is found for the hash algorithm. %#v", target)
 	return nil
 }
 
@@ -198,7 +203,7 @@ func getHashAlgorithmFromOID(target pkix.AlgorithmIdentifier) crypto.Hash {
 			return hash
 		}
 	}
-	logger.Errorf("no valid hash algorithm is found for the oid. Falling back to SHA1: %#v", target)
+	ocspLogger().Errorf("no valid hash algorithm is found for the oid. Falling back to SHA1: %#v", target)
 	return crypto.SHA1
 }
 
@@ -397,28 +402,28 @@ func checkOCSPCacheServer(
 	headers := make(map[string]string)
 	res, err := newRetryHTTP(ctx, client, req, ocspServerHost, headers, totalTimeout, OcspMaxRetryCount, defaultTimeProvider, nil).execute()
 	if err != nil {
-		logger.WithContext(ctx).Errorf("failed to get OCSP cache from OCSP Cache Server. %v", err)
+		ocspLogger().WithContext(ctx).Errorf("failed to get OCSP cache from OCSP Cache Server. %v", err)
 		return nil, &ocspStatus{
 			code: ocspFailedSubmit,
 			err:  err,
 		}
 	}
 	defer res.Body.Close()
-	logger.WithContext(ctx).Debugf("StatusCode from OCSP Cache Server: %v", res.StatusCode)
+	ocspLogger().WithContext(ctx).Debugf("StatusCode from OCSP Cache Server: %v", res.StatusCode)
 	if res.StatusCode != http.StatusOK {
 		return nil, &ocspStatus{
 			code: ocspFailedResponse,
 			err:  fmt.Errorf("HTTP code is not OK. %v: %v", res.StatusCode, res.Status),
 		}
 	}
-	logger.WithContext(ctx).Debugf("reading contents")
+	ocspLogger().WithContext(ctx).Debugf("reading contents")
 
 	dec := json.NewDecoder(res.Body)
 	for {
 		if err := dec.Decode(&respd); err == io.EOF {
 			break
 		} else if err != nil {
-			logger.WithContext(ctx).Errorf("failed to decode OCSP cache. %v", err)
+			ocspLogger().WithContext(ctx).Errorf("failed to decode OCSP cache. %v", err)
 			return nil, &ocspStatus{
 				code: ocspFailedExtractResponse,
 				err:  err,
@@ -466,7 +471,7 @@ func retryOCSP(
 		}
 	}
 	defer res.Body.Close()
-	logger.WithContext(ctx).Debugf("StatusCode from OCSP Server: %v\n", res.StatusCode)
+	ocspLogger().WithContext(ctx).Debugf("StatusCode from OCSP Server: %v\n", res.StatusCode)
 	if res.StatusCode != http.StatusOK {
 		return ocspRes, ocspResBytes, &ocspStatus{
 			code: ocspFailedResponse,
@@ -485,18 +490,18 @@ func retryOCSP(
 		_, ok1 := err.(asn1.StructuralError)
 		_, ok2 := err.(asn1.SyntaxError)
 		if ok1 || ok2 {
-			logger.WithContext(ctx).Warnf("error when parsing ocsp response: %v", err)
-			logger.WithContext(ctx).Warnf("performing GET fallback request to OCSP")
+			ocspLogger().WithContext(ctx).Warnf("error when parsing ocsp response: %v", err)
+			ocspLogger().WithContext(ctx).Warnf("performing GET fallback request to OCSP")
 			return fallbackRetryOCSPToGETRequest(ctx, client, req, ocspHost, headers, issuer, totalTimeout)
 		}
-		logger.Warnf("Unknown response status from OCSP responder: %v", err)
+		ocspLogger().Warnf("Unknown response status from OCSP responder: %v", err)
 		return nil, nil, &ocspStatus{
 			code: ocspStatusUnknown,
 			err:  err,
 		}
 	}
 
-	logger.WithContext(ctx).Debugf("OCSP Status from server: %v", printStatus(ocspRes))
+	ocspLogger().WithContext(ctx).Debugf("OCSP Status from server: %v", printStatus(ocspRes))
 	return ocspRes, ocspResBytes, &ocspStatus{
 		code: ocspSuccess,
 	}
@@ -528,7 +533,7 @@ func fallbackRetryOCSPToGETRequest(
 		}
 	}
 	defer res.Body.Close()
-	logger.WithContext(ctx).Debugf("GET fallback StatusCode from OCSP Server: %v", res.StatusCode)
+	ocspLogger().WithContext(ctx).Debugf("GET fallback StatusCode from OCSP Server: %v", res.StatusCode)
 	if res.StatusCode != http.StatusOK {
 		return ocspRes, ocspResBytes, &ocspStatus{
 			code: ocspFailedResponse,
@@ -550,7 +555,7 @@ func fallbackRetryOCSPToGETRequest(
 		}
 	}
 
-	logger.WithContext(ctx).Debugf("GET fallback OCSP Status from server: %v", printStatus(ocspRes))
+	ocspLogger().WithContext(ctx).Debugf("GET fallback OCSP Status from server: %v", printStatus(ocspRes))
 	return ocspRes, ocspResBytes, &ocspStatus{
 		code: ocspSuccess,
 	}
@@ -582,7 +587,7 @@ func fullOCSPURL(url *url.URL) string {
 
 // getRevocationStatus checks the certificate revocation status for subject using issuer certificate.
 func getRevocationStatus(ctx context.Context, subject, issuer *x509.Certificate) *ocspStatus {
-	logger.WithContext(ctx).Tracef("Subject: %v, Issuer: %v", subject.Subject, issuer.Subject)
+	ocspLogger().WithContext(ctx).Tracef("Subject: %v, Issuer: %v", subject.Subject, issuer.Subject)
 
 	status, ocspReq, encodedCertID := validateWithCache(subject, issuer)
 	if isValidOCSPStatus(status.code) {
@@ -591,8 +596,8 @@ func getRevocationStatus(ctx context.Context, subject, issuer *x509.Certificate)
 	if ocspReq == nil || encodedCertID == nil {
 		return status
 	}
-	logger.WithContext(ctx).Infof("cache missed")
-	logger.WithContext(ctx).Infof("OCSP Server: %v", subject.OCSPServer)
+	ocspLogger().WithContext(ctx).Infof("cache missed")
+	ocspLogger().WithContext(ctx).Infof("OCSP Server: %v", subject.OCSPServer)
 	testResponderURL := os.Getenv(ocspTestResponderURLEnv)
 	if (len(subject.OCSPServer) == 0 || isTestNoOCSPURL()) && testResponderURL == "" {
 		return &ocspStatus{
@@ -627,8 +632,8 @@ func getRevocationStatus(ctx context.Context, subject, issuer *x509.Certificate)
 		hostname = fullOCSPURL(u)
 	}
 
-	logger.WithContext(ctx).Debugf("Fetching OCSP response from server: %v", u)
-	logger.WithContext(ctx).Debugf("Host in headers: %v", hostname)
+	ocspLogger().WithContext(ctx).Debugf("Fetching OCSP response from server: %v", u)
+	ocspLogger().WithContext(ctx).Debugf("Host in headers: %v", hostname)
 
 	headers := make(map[string]string)
 	headers[httpHeaderContentType] = "application/ocsp-request"
@@ -679,8 +684,8 @@ func verifyPeerCertificate(ctx context.Context, verifiedChains [][]*x509.Certifi
 	for i := 0; i < len(verifiedChains); i++ {
 		// Certificate signed by Root CA. This should be one before the last in the Certificate Chain
 		numberOfNoneRootCerts := len(verifiedChains[i]) - 1
-		logger.Tracef("checking cert, %v, %v, isCa: %v, rawIssuer: %v, rawSubject: %v", i, numberOfNoneRootCerts, verifiedChains[i][numberOfNoneRootCerts].IsCA, string(verifiedChains[i][numberOfNoneRootCerts].RawIssuer), string(verifiedChains[i][numberOfNoneRootCerts].RawSubject))
-		logger.Tracef("checking cert, base64, rawIssuer: %v, rawSubject: %v", base64.StdEncoding.EncodeToString(verifiedChains[i][numberOfNoneRootCerts].RawIssuer), base64.StdEncoding.EncodeToString(verifiedChains[i][numberOfNoneRootCerts].RawSubject))
+		ocspLogger().Tracef("checking cert, %v, %v, isCa: %v, rawIssuer: %v, rawSubject: %v", i, numberOfNoneRootCerts, verifiedChains[i][numberOfNoneRootCerts].IsCA, string(verifiedChains[i][numberOfNoneRootCerts].RawIssuer), string(verifiedChains[i][numberOfNoneRootCerts].RawSubject))
+		ocspLogger().Tracef("checking cert, base64, rawIssuer: %v, rawSubject: %v", base64.StdEncoding.EncodeToString(verifiedChains[i][numberOfNoneRootCerts].RawIssuer), base64.StdEncoding.EncodeToString(verifiedChains[i][numberOfNoneRootCerts].RawSubject))
 		if !verifiedChains[i][numberOfNoneRootCerts].IsCA || string(verifiedChains[i][numberOfNoneRootCerts].RawIssuer) != string(verifiedChains[i][numberOfNoneRootCerts].RawSubject) {
 			// Check if the last Non Root Cert is also a CA or is self signed.
 			// if the last certificate is not, add it to the list
@@ -734,7 +739,7 @@ func canEarlyExitForOCSP(results []*ocspStatus, chainSize int) *ocspStatus {
 		}
 	}
 	if len(msg) > 0 {
-		logger.Debugf("OCSP responder didn't respond correctly. Assuming certificate is not revoked. Detail: %v", msg[1:])
+		ocspLogger().Debugf("OCSP responder didn't respond correctly. Assuming certificate is not revoked. Detail: %v", msg[1:])
 	}
 	return nil
 }
@@ -755,7 +760,7 @@ func validateWithCacheForAllCertificates(verifiedChains []*x509.Certificate) boo
 func validateWithCache(subject, issuer *x509.Certificate) (*ocspStatus, []byte, *certIDKey) {
 	ocspReq, err := ocsp.CreateRequest(subject, issuer, &ocsp.RequestOptions{})
 	if err != nil {
-		logger.Errorf("failed to create OCSP request from the certificates.\n")
+		ocspLogger().Errorf("failed to create OCSP request from the certificates.\n")
 		return &ocspStatus{
 			code: ocspFailedComposeRequest,
 			err:  errors.New("failed to create a OCSP request"),
@@ -763,13 +768,16 @@ func validateWithCache(subject, issuer *x509.Certificate) (*ocspStatus, []byte,
 	}
 	encodedCertID, ocspS := extractCertIDKeyFromRequest(ocspReq)
 	if ocspS.code != ocspSuccess {
-		logger.Errorf("failed to extract CertID from OCSP Request.\n")
+		ocspLogger().Errorf("failed to extract CertID from OCSP Request.\n")
 		return &ocspStatus{
 			code: ocspFailedComposeRequest,
 			err:  errors.New("failed to extract cert ID Key"),
 		}, ocspReq, nil
 	}
 	status := checkOCSPResponseCache(encodedCertID, subject, issuer)
+	if status.code != ocspNoServer {
+		getDefaultMetricsRecorder().RecordOCSPCacheLookup(status.code != ocspMissedCache)
+	}
 	return status, ocspReq, encodedCertID
 }
 
@@ -785,7 +793,7 @@ func downloadOCSPCacheServer() {
 	if err != nil {
 		return
 	}
-	logger.Infof("downloading OCSP Cache from server %v", ocspCacheServerURL)
+	ocspLogger().Infof("downloading OCSP Cache from server %v", ocspCacheServerURL)
 	timeoutStr := os.Getenv(ocspTestResponseCacheServerTimeoutEnv)
 	timeout := OcspCacheServerTimeout
 	if timeoutStr != "" {
@@ -857,10 +865,10 @@ func initOCSPCache() {
 		return
 	}
 
-	logger.Infof("reading OCSP Response cache file. %v\n", cacheFileName)
+	ocspLogger().Infof("reading OCSP Response cache file. %v\n", cacheFileName)
 	f, err := os.OpenFile(cacheFileName, os.O_CREATE|os.O_RDONLY, readWriteFileMode)
 	if err != nil {
-		logger.Debugf("failed to open. Ignored. %v\n", err)
+		ocspLogger().Debugf("failed to open. Ignored. %v\n", err)
 		return
 	}
 	defer f.Close()
@@ -872,7 +880,7 @@ func initOCSPCache() {
 		if err = dec.Decode(&buf); err == io.EOF {
 			break
 		} else if err != nil {
-			logger.Debugf("failed to read. Ignored. %v\n", err)
+			ocspLogger().Debugf("failed to read. Ignored. %v\n", err)
 			return
 		}
 	}
@@ -897,12 +905,12 @@ func initOCSPCache() {
 func extractTsAndOcspRespBase64(value []interface{}) (bool, float64, string) {
 	ts, ok := value[0].(float64)
 	if !ok {
-		logger.Warnf("cannot cast %v as float64", value[0])
+		ocspLogger().Warnf("cannot cast %v as float64", value[0])
 		return false, -1, ""
 	}
 	ocspRespBase64, ok := value[1].(string)
 	if !ok {
-		logger.Warnf("cannot cast %v as string", value[1])
+		ocspLogger().Warnf("cannot cast %v as string", value[1])
 		return false, -1, ""
 	}
 	return true, ts, ocspRespBase64
@@ -939,7 +947,7 @@ func extractOCSPCacheResponseValue(certIDKey *certIDKey, certCacheValue *certCac
 	}
 	status, ok := ocspParsedRespCache[cacheKey]
 	if !ok {
-		logger.Debugf("OCSP status not found in cache; certIdKey: %v", certIDKey)
+		ocspLogger().Debugf("OCSP status not found in cache; certIdKey: %v", certIDKey)
 		var err error
 		var b []byte
 		b, err = base64.StdEncoding.DecodeString(certCacheValue.ocspRespBase64)
@@ -953,7 +961,7 @@ func extractOCSPCacheResponseValue(certIDKey *certIDKey, certCacheValue *certCac
 		ocspResponse, err := ocsp.ParseResponse(b, issuer)
 
 		if err != nil {
-			logger.Warnf("the second cache element is not a valid OCSP Response. Ignored. subject: %v\n", subjectName)
+			ocspLogger().Warnf("the second cache element is not a valid OCSP Response. Ignored. subject: %v\n", subjectName)
 			return &ocspStatus{
 				code: ocspFailedParseResponse,
 				err:  fmt.Errorf("failed to parse OCSP Respose. subject: %v, err: %v", subjectName, err),
@@ -962,7 +970,7 @@ func extractOCSPCacheResponseValue(certIDKey *certIDKey, certCacheValue *certCac
 		status = validateOCSP(ocspResponse)
 		ocspParsedRespCache[cacheKey] = status
 	}
-	logger.Tracef("OCSP status found in cache: %v; certIdKey: %v", status, certIDKey)
+	ocspLogger().Tracef("OCSP status found in cache: %v; certIdKey: %v", status, certIDKey)
 	return status
 }
 
@@ -972,32 +980,32 @@ func writeOCSPCacheFile() {
 	if strings.EqualFold(os.Getenv(cacheServerEnabledEnv), "false") {
 		return
 	}
-	logger.Infof("writing OCSP Response cache file. %v\n", cacheFileName)
+	ocspLogger().Infof("writing OCSP Response cache file. %v\n", cacheFileName)
 	cacheLockFileName := cacheFileName + ".lck"
 	err := os.Mkdir(cacheLockFileName, 0600)
 	switch {
 	case os.IsExist(err):
 		statinfo, err := os.Stat(cacheLockFileName)
 		if err != nil {
-			logger.Debugf("failed to get file info for cache lock file. file: %v, err: %v. ignored.\n", cacheLockFileName, err)
+			ocspLogger().Debugf("failed to get file info for cache lock file. file: %v, err: %v. ignored.\n", cacheLockFileName, err)
 			return
 		}
 		if time.Since(statinfo.ModTime()) < 15*time.Minute {
-			logger.Debugf("other process locks the cache file. %v. ignored.\n", cacheLockFileName)
+			ocspLogger().Debugf("other process locks the cache file. %v. ignored.\n", cacheLockFileName)
 			return
 		}
 		if err = os.Remove(cacheLockFileName); err != nil {
-			logger.Debugf("failed to delete lock file. file: %v, err: %v. ignored.\n", cacheLockFileName, err)
+			ocspLogger().Debugf("failed to delete lock file. file: %v, err: %v. ignored.\n", cacheLockFileName, err)
 			return
 		}
 		if err = os.Mkdir(cacheLockFileName, 0600); err != nil {
-			logger.Debugf("failed to create lock file. file: %v, err: %v. ignored.\n", cacheLockFileName, err)
+			ocspLogger().Debugf("failed to create lock file. file: %v, err: %v. ignored.\n", cacheLockFileName, err)
 			return
 		}
 	}
 	// if mkdir fails for any other reason: permission denied, operation not permitted, I/O error, too many open files, etc.
 	if err != nil {
-		logger.Debugf("failed to create lock file. file %v, err: %v. ignored.\n", cacheLockFileName, err)
+		ocspLogger().Debugf("failed to create lock file. file %v, err: %v. ignored.\n", cacheLockFileName, err)
 		return
 	}
 	defer os.RemoveAll(cacheLockFileName)
@@ -1010,11 +1018,11 @@ func writeOCSPCacheFile() {
 
 	j, err := json.Marshal(buf)
 	if err != nil {
-		logger.Debugf("failed to convert OCSP Response cache to JSON. ignored.")
+		ocspLogger().Debugf("failed to convert OCSP Response cache to JSON. ignored.")
 		return
 	}
 	if err = os.WriteFile(cacheFileName, j, 0644); err != nil {
-		logger.Debugf("failed to write OCSP Response cache. err: %v. ignored.\n", err)
+		ocspLogger().Debugf("failed to write OCSP Response cache. err: %v. ignored.\n", err)
 	}
 }
 
@@ -1044,7 +1052,7 @@ func readCACerts() {
 // createOCSPCacheDir creates OCSP response cache directory and set the cache file name.
 func createOCSPCacheDir() {
 	if strings.EqualFold(os.Getenv(cacheServerEnabledEnv), "false") {
-		logger.Info(`OCSP Cache Server disabled. All further access and use of
+		ocspLogger().Info(`OCSP Cache Server disabled. All further access and use of
 			OCSP Cache will be disabled for this OCSP Status Query`)
 		return
 	}
@@ -1059,13 +1067,13 @@ func createOCSPCacheDir() {
 		case "darwin":
 			home := os.Getenv("HOME")
 			if home == "" {
-				logger.Info("HOME is blank.")
+				ocspLogger().Info("HOME is blank.")
 			}
 			cacheDir = filepath.Join(home, "Library", "Caches", "Snowflake")
 		default:
 			home := os.Getenv("HOME")
 			if home == "" {
-				logger.Info("HOME is blank")
+				ocspLogger().Info("HOME is blank")
 			}
 			cacheDir = filepath.Join(home, ".cache", "snowflake")
 		}
@@ -1073,11 +1081,11 @@ func createOCSPCacheDir() {
 
 	if _, err := os.Stat(cacheDir); os.IsNotExist(err) {
 		if err = os.MkdirAll(cacheDir, os.ModePerm); err != nil {
-			logger.Debugf("failed to create cache directory. %v, err: %v. ignored\n", cacheDir, err)
+			ocspLogger().Debugf("failed to create cache directory. %v, err: %v. ignored\n", cacheDir, err)
 		}
 	}
 	cacheFileName = filepath.Join(cacheDir, cacheFileBaseName)
-	logger.Infof("reset OCSP cache file. %v", cacheFileName)
+	ocspLogger().Infof("reset OCSP cache file. %v", cacheFileName)
 }
 
 func initOCSPCacheClearer() {
@@ -1085,12 +1093,12 @@ func initOCSPCacheClearer() {
 	if intervalFromEnv := os.Getenv(ocspResponseCacheClearingIntervalInSecondsEnv); intervalFromEnv != "" {
 		intervalAsSeconds, err := strconv.Atoi(intervalFromEnv)
 		if err != nil {
-			logger.Warnf("unparsable %v value: %v", ocspResponseCacheClearingIntervalInSecondsEnv, intervalFromEnv)
+			ocspLogger().Warnf("unparsable %v value: %v", ocspResponseCacheClearingIntervalInSecondsEnv, intervalFromEnv)
 		} else {
 			interval = time.Duration(intervalAsSeconds) * time.Second
 		}
 	}
-	logger.Debugf("initializing OCSP cache clearer to %v", interval)
+	ocspLogger().Debugf("initializing OCSP cache clearer to %v", interval)
 	go GoroutineWrapper(context.Background(), func() {
 		ticker := time.NewTicker(interval)
 		for {
@@ -1098,7 +1106,7 @@ func initOCSPCacheClearer() {
 			case <-ticker.C:
 				clearOCSPCaches()
 			case <-stopOCSPCacheClearing:
-				logger.Debug("stopped clearing OCSP cache")
+				ocspLogger().Debug("stopped clearing OCSP cache")
 				ticker.Stop()
 				return
 			}
@@ -1111,7 +1119,7 @@ func stopOCSPCacheClearer() {
 }
 
 func clearOCSPCaches() {
-	logger.Debugf("clearing OCSP caches")
+	ocspLogger().Debugf("clearing OCSP caches")
 	func() {
 		ocspResponseCacheLock.Lock()
 		defer ocspResponseCacheLock.Unlock()
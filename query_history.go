@@ -0,0 +1,163 @@
+package gosnowflake
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// QueryHistoryEntry is a statement recorded in the query history of a connection or of the process.
+type QueryHistoryEntry struct {
+	SQLText      string    `json:"sqlText"` // SQL text with the secrets masked
+	QueryID      string    `json:"queryId,omitempty"`
+	RequestID    string    `json:"requestId"`
+	StartTime    time.Time `json:"startTime"`
+	EndTime      time.Time `json:"endTime"`
+	Rows         int64     `json:"rows"`  // total number of rows of the result
+	Bytes        int64     `json:"bytes"` // uncompressed size of the result chunks, rows returned with the response are not counted
+	Retries      int       `json:"retries"`
+	ResultFormat string    `json:"resultFormat,omitempty"`
+	Error        string    `json:"error,omitempty"`
+}
+
+// queryHistory is a ring buffer of the most recent statements.
+type queryHistory struct {
+	mu      sync.Mutex
+	entries []QueryHistoryEntry
+	next    int
+	full    bool
+}
+
+func newQueryHistory(size int) *queryHistory {
+	if size <= 0 {
+		return nil
+	}
+	return &queryHistory{entries: make([]QueryHistoryEntry, size)}
+}
+
+func (h *queryHistory) add(entry QueryHistoryEntry) {
+	if h == nil {
+		return
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.entries[h.next] = entry
+	h.next = (h.next + 1) % len(h.entries)
+	if h.next == 0 {
+		h.full = true
+	}
+}
+
+// list returns the entries from the oldest to the most recent one.
+func (h *queryHistory) list() []QueryHistoryEntry {
+	if h == nil {
+		return nil
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if !h.full {
+		return append([]QueryHistoryEntry(nil), h.entries[:h.next]...)
+	}
+	entries := make([]QueryHistoryEntry, 0, len(h.entries))
+	entries = append(entries, h.entries[h.next:]...)
+	return append(entries, h.entries[:h.next]...)
+}
+
+var (
+	defaultQueryHistory     *queryHistory
+	defaultQueryHistoryLock sync.RWMutex
+)
+
+// SetQueryHistorySize sets the number of recent statements of all connections kept in the
+// process-wide query history returned by GetQueryHistory. 0 disables it, which is the default.
+// The recorded statements are discarded.
+func SetQueryHistorySize(size int) {
+	defaultQueryHistoryLock.Lock()
+	defer defaultQueryHistoryLock.Unlock()
+	defaultQueryHistory = newQueryHistory(size)
+}
+
+func getDefaultQueryHistory() *queryHistory {
+	defaultQueryHistoryLock.RLock()
+	defer defaultQueryHistoryLock.RUnlock()
+	return defaultQueryHistory
+}
+
+// GetQueryHistory returns the recent statements of all connections from the oldest to the most recent one.
+func GetQueryHistory() []QueryHistoryEntry {
+	return getDefaultQueryHistory().list()
+}
+
+// NewQueryHistoryHandler returns an http.Handler writing the entries returned by history as JSON,
+// e.g. GetQueryHistory or SnowflakeConnection.QueryHistory exposed on a debug endpoint.
+func NewQueryHistoryHandler(history func() []QueryHistoryEntry) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		entries := history()
+		if entries == nil {
+			entries = []QueryHistoryEntry{}
+		}
+		w.Header().Set(httpHeaderContentType, headerContentTypeApplicationJSON)
+		if err := json.NewEncoder(w).Encode(entries); err != nil {
+			logger.Warnf("failed to write query history. err: %v", err)
+		}
+	})
+}
+
+// QueryHistory returns the recent statements of the connection from the oldest to the most recent one.
+// It is empty unless Config.QueryHistorySize is set.
+func (sc *snowflakeConn) QueryHistory() []QueryHistoryEntry {
+	return sc.queryHistory.list()
+}
+
+// queryHistoryEnabled checks if the statements of the connection are recorded in a query history.
+func (sc *snowflakeConn) queryHistoryEnabled() bool {
+	return sc.queryHistory != nil || getDefaultQueryHistory() != nil
+}
+
+// withQueryRetryCounter returns a context counting the retries of the requests of a statement.
+func withQueryRetryCounter(ctx context.Context) (context.Context, *int32) {
+	counter := new(int32)
+	return context.WithValue(ctx, queryRetryCounter, counter), counter
+}
+
+// countQueryRetry increments the retry counter of the statement of the context, if any.
+func countQueryRetry(ctx context.Context) {
+	if counter, ok := ctx.Value(queryRetryCounter).(*int32); ok {
+		atomic.AddInt32(counter, 1)
+	}
+}
+
+// newQueryHistoryEntry returns the entry of a finished statement.
+func newQueryHistoryEntry(query string, requestID UUID, startTime time.Time, retries *int32, data *execResponse, err error) QueryHistoryEntry {
+	entry := QueryHistoryEntry{
+		SQLText:   maskSecrets(query),
+		RequestID: requestID.String(),
+		StartTime: startTime,
+		EndTime:   time.Now(),
+		Retries:   int(atomic.LoadInt32(retries)),
+	}
+	if data != nil {
+		entry.QueryID = data.Data.QueryID
+		entry.Rows = data.Data.Total
+		entry.ResultFormat = data.Data.QueryResultFormat
+		for _, chunk := range data.Data.Chunks {
+			entry.Bytes += chunk.UncompressedSize
+		}
+	}
+	if err != nil {
+		entry.Error = maskSecrets(err.Error())
+		if se, ok := err.(*SnowflakeError); ok && entry.QueryID == "" {
+			entry.QueryID = se.QueryID
+		}
+	}
+	return entry
+}
+
+// recordQueryHistory adds a finished statement to the query history of the connection and of the process.
+func (sc *snowflakeConn) recordQueryHistory(entry QueryHistoryEntry) {
+	sc.queryHistory.add(entry)
+	getDefaultQueryHistory().add(entry)
+}
@@ -0,0 +1,115 @@
+package gosnowflake
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestQueryHistoryRingBuffer(t *testing.T) {
+	assertNilE(t, newQueryHistory(0))
+	assertEqualE(t, len((*queryHistory)(nil).list()), 0)
+
+	h := newQueryHistory(3)
+	h.add(QueryHistoryEntry{QueryID: "1"})
+	h.add(QueryHistoryEntry{QueryID: "2"})
+	assertDeepEqualE(t, h.list(), []QueryHistoryEntry{{QueryID: "1"}, {QueryID: "2"}})
+	h.add(QueryHistoryEntry{QueryID: "3"})
+	h.add(QueryHistoryEntry{QueryID: "4"})
+	h.add(QueryHistoryEntry{QueryID: "5"})
+	assertDeepEqualE(t, h.list(), []QueryHistoryEntry{{QueryID: "3"}, {QueryID: "4"}, {QueryID: "5"}})
+}
+
+func TestQueryHistoryOfConnection(t *testing.T) {
+	SetQueryHistorySize(10)
+	defer SetQueryHistorySize(0)
+	postQueryMock := func(ctx context.Context, _ *snowflakeRestful, _ *url.Values, _ map[string]string,
+		_ []byte, _ time.Duration, _ UUID, _ *Config) (*execResponse, error) {
+		countQueryRetry(ctx)
+		return &execResponse{
+			Data: execResponseData{
+				QueryID:           "01b2c3",
+				Total:             5,
+				QueryResultFormat: "arrow",
+				Chunks:            []execResponseChunk{{UncompressedSize: 100}, {UncompressedSize: 200}},
+			},
+			Code:    "0",
+			Success: true,
+		}, nil
+	}
+	sc := &snowflakeConn{
+		cfg:               &Config{Params: map[string]*string{}},
+		rest:              &snowflakeRestful{FuncPostQuery: postQueryMock},
+		queryContextCache: (&queryContextCache{}).init(),
+		queryHistory:      newQueryHistory(10),
+	}
+	requestID := NewUUID()
+	_, err := sc.exec(WithRequestID(context.Background(), requestID),
+		"create user testuser password='testpassword'", false, false, false, nil)
+	assertNilF(t, err)
+	_, err = sc.exec(context.Background(), "select 1", false, true /* isInternal */, false, nil)
+	assertNilF(t, err)
+	_, err = sc.exec(context.Background(), "select ?", false, false, true /* describeOnly */, nil)
+	assertNilF(t, err)
+
+	history := sc.QueryHistory()
+	assertEqualF(t, len(history), 1)
+	entry := history[0]
+	assertEqualE(t, entry.SQLText, "create user testuser password='****")
+	assertEqualE(t, entry.QueryID, "01b2c3")
+	assertEqualE(t, entry.RequestID, requestID.String())
+	assertEqualE(t, entry.Rows, int64(5))
+	assertEqualE(t, entry.Bytes, int64(300))
+	assertEqualE(t, entry.Retries, 1)
+	assertEqualE(t, entry.ResultFormat, "arrow")
+	assertEqualE(t, entry.Error, "")
+	assertFalseE(t, entry.EndTime.Before(entry.StartTime))
+	assertDeepEqualE(t, GetQueryHistory(), history)
+}
+
+func TestQueryHistoryOfFailedQuery(t *testing.T) {
+	postQueryMock := func(context.Context, *snowflakeRestful, *url.Values, map[string]string,
+		[]byte, time.Duration, UUID, *Config) (*execResponse, error) {
+		return &execResponse{
+			Data:    execResponseData{QueryID: "01b2c4", SQLState: "42000"},
+			Message: "SQL compilation error",
+			Code:    "1003",
+			Success: false,
+		}, nil
+	}
+	sc := &snowflakeConn{
+		cfg:               &Config{Params: map[string]*string{}},
+		rest:              &snowflakeRestful{FuncPostQuery: postQueryMock},
+		telemetry:         &snowflakeTelemetry{enabled: false},
+		queryContextCache: (&queryContextCache{}).init(),
+		queryHistory:      newQueryHistory(10),
+	}
+	_, err := sc.exec(context.Background(), "selec 1", false, false, false, nil)
+	assertNotNilF(t, err)
+
+	history := sc.QueryHistory()
+	assertEqualF(t, len(history), 1)
+	assertEqualE(t, history[0].QueryID, "01b2c4")
+	assertEqualE(t, history[0].Error, err.Error())
+}
+
+func TestQueryHistoryHandler(t *testing.T) {
+	handler := NewQueryHistoryHandler(func() []QueryHistoryEntry {
+		return []QueryHistoryEntry{{SQLText: "select 1", QueryID: "01b2c3"}}
+	})
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/snowflake/queries", nil))
+	assertEqualE(t, rec.Code, http.StatusOK)
+	assertEqualE(t, rec.Header().Get(httpHeaderContentType), headerContentTypeApplicationJSON)
+	var entries []QueryHistoryEntry
+	assertNilF(t, json.Unmarshal(rec.Body.Bytes(), &entries))
+	assertDeepEqualE(t, entries, []QueryHistoryEntry{{SQLText: "select 1", QueryID: "01b2c3"}})
+
+	rec = httptest.NewRecorder()
+	NewQueryHistoryHandler(GetQueryHistory).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	assertEqualE(t, rec.Body.String(), "[]\n")
+}
@@ -9,6 +9,8 @@ import (
 	"net/url"
 	"strconv"
 	"time"
+
+	"go.opentelemetry.io/otel/trace"
 )
 
 // HTTP headers
@@ -64,6 +66,8 @@ type snowflakeRestful struct {
 	JWTClient     *http.Client
 	TokenAccessor TokenAccessor
 	HeartBeat     *heartbeat
+	Logger        SFLogger
+	EventHooks    EventHooks
 
 	Connection *snowflakeConn
 
@@ -82,6 +86,14 @@ type snowflakeRestful struct {
 	FuncGetSSO       func(context.Context, *snowflakeRestful, *url.Values, map[string]string, string, time.Duration) ([]byte, error)
 }
 
+// getLogger returns the logger of the connection or the global one.
+func (sr *snowflakeRestful) getLogger() SFLogger {
+	if sr == nil || sr.Logger == nil {
+		return logger
+	}
+	return sr.Logger
+}
+
 func (sr *snowflakeRestful) getURL() *url.URL {
 	return &url.URL{
 		Scheme: sr.Protocol,
@@ -121,11 +133,21 @@ func (sr *snowflakeRestful) renewExpiredSessionToken(ctx context.Context, timeou
 	currentToken, _, _ := sr.TokenAccessor.GetTokens()
 	if expiredToken == currentToken || currentToken == "" {
 		// Only renew the session if the current token is still the expired token or current token is empty
-		return sr.FuncRenewSession(ctx, sr, timeout)
+		return sr.renewSession(ctx, timeout)
 	}
 	return nil
 }
 
+// renewSession renews the session token and notifies the event hooks of the connection.
+func (sr *snowflakeRestful) renewSession(ctx context.Context, timeout time.Duration) error {
+	startTime := time.Now()
+	err := sr.FuncRenewSession(ctx, sr, timeout)
+	if sr.EventHooks != nil {
+		sr.EventHooks.OnSessionRenewal(ctx, SessionRenewalEvent{StartTime: startTime, Duration: time.Since(startTime), Err: err})
+	}
+	return err
+}
+
 type renewSessionResponse struct {
 	Data    renewSessionResponseMain `json:"data"`
 	Message string                   `json:"message"`
@@ -208,6 +230,19 @@ func postRestfulQuery(
 	cfg *Config) (
 	data *execResponse, err error) {
 
+	ctx, span := startSpan(ctx, cfg, spanQuery, trace.SpanKindClient, attrRequestID.String(requestID.String()))
+	start := time.Now()
+	defer func() {
+		status := metricsStatus(err)
+		if data != nil {
+			span.SetAttributes(attrQueryID.String(data.Data.QueryID))
+			if !data.Success {
+				status = MetricsStatusError
+			}
+		}
+		getMetricsRecorder(cfg).RecordQuery(ctx, time.Since(start), status)
+		endSpan(span, err)
+	}()
 	data, err = sr.FuncPostQueryHelper(ctx, sr, params, headers, body, timeout, requestID, cfg)
 
 	// errors other than context timeout and cancel would be returned to upper layers
@@ -231,7 +266,7 @@ func postRestfulQueryHelper(
 	requestID UUID,
 	cfg *Config) (
 	data *execResponse, err error) {
-	logger.WithContext(ctx).Infof("params: %v", params)
+	sr.getLogger().WithContext(ctx).Infof("params: %v", params)
 	params.Set(requestIDKey, requestID.String())
 	params.Set(requestGUIDKey, NewUUID().String())
 	token, _, _ := sr.TokenAccessor.GetTokens()
@@ -248,10 +283,10 @@ func postRestfulQueryHelper(
 	defer resp.Body.Close()
 
 	if resp.StatusCode == http.StatusOK {
-		logger.WithContext(ctx).Infof("postQuery: resp: %v", resp)
+		sr.getLogger().WithContext(ctx).Infof("postQuery: resp: %v", resp)
 		var respd execResponse
 		if err = json.NewDecoder(resp.Body).Decode(&respd); err != nil {
-			logger.WithContext(ctx).Errorf("failed to decode JSON. err: %v", err)
+			sr.getLogger().WithContext(ctx).Errorf("failed to decode JSON. err: %v", err)
 			return nil, err
 		}
 		if respd.Code == sessionExpiredCode {
@@ -279,20 +314,20 @@ func postRestfulQueryHelper(
 				fullURL = sr.getFullURL(respd.Data.GetResultURL, nil)
 			}
 
-			logger.WithContext(ctx).Info("ping pong")
+			sr.getLogger().WithContext(ctx).Info("ping pong")
 			token, _, _ = sr.TokenAccessor.GetTokens()
 			headers[headerAuthorizationKey] = fmt.Sprintf(headerSnowflakeToken, token)
 
 			resp, err = sr.FuncGet(ctx, sr, fullURL, headers, timeout)
 			if err != nil {
-				logger.WithContext(ctx).Errorf("failed to get response. err: %v", err)
+				sr.getLogger().WithContext(ctx).Errorf("failed to get response. err: %v", err)
 				return nil, err
 			}
 			respd = execResponse{} // reset the response
 			err = json.NewDecoder(resp.Body).Decode(&respd)
 			resp.Body.Close()
 			if err != nil {
-				logger.WithContext(ctx).Errorf("failed to decode JSON. err: %v", err)
+				sr.getLogger().WithContext(ctx).Errorf("failed to decode JSON. err: %v", err)
 				return nil, err
 			}
 			if respd.Code == sessionExpiredCode {
@@ -308,11 +343,11 @@ func postRestfulQueryHelper(
 	}
 	b, err := io.ReadAll(resp.Body)
 	if err != nil {
-		logger.WithContext(ctx).Errorf("failed to extract HTTP response body. err: %v", err)
+		sr.getLogger().WithContext(ctx).Errorf("failed to extract HTTP response body. err: %v", err)
 		return nil, err
 	}
-	logger.WithContext(ctx).Infof("HTTP: %v, URL: %v, Body: %v", resp.StatusCode, fullURL, b)
-	logger.WithContext(ctx).Infof("Header: %v", resp.Header)
+	sr.getLogger().WithContext(ctx).Infof("HTTP: %v, URL: %v, Body: %v", resp.StatusCode, fullURL, b)
+	sr.getLogger().WithContext(ctx).Infof("Header: %v", resp.Header)
 	return nil, &SnowflakeError{
 		Number:      ErrFailedToPostQuery,
 		SQLState:    SQLStateConnectionFailure,
@@ -322,7 +357,7 @@ func postRestfulQueryHelper(
 }
 
 func closeSession(ctx context.Context, sr *snowflakeRestful, timeout time.Duration) error {
-	logger.WithContext(ctx).Info("close session")
+	sr.getLogger().WithContext(ctx).Info("close session")
 	params := &url.Values{}
 	params.Set("delete", "true")
 	params.Set(requestIDKey, getOrGenerateRequestIDFromContext(ctx).String())
@@ -341,7 +376,7 @@ func closeSession(ctx context.Context, sr *snowflakeRestful, timeout time.Durati
 	if resp.StatusCode == http.StatusOK {
 		var respd renewSessionResponse
 		if err = json.NewDecoder(resp.Body).Decode(&respd); err != nil {
-			logger.WithContext(ctx).Errorf("failed to decode JSON. err: %v", err)
+			sr.getLogger().WithContext(ctx).Errorf("failed to decode JSON. err: %v", err)
 			return err
 		}
 		if !respd.Success && respd.Code != sessionExpiredCode {
@@ -358,11 +393,11 @@ func closeSession(ctx context.Context, sr *snowflakeRestful, timeout time.Durati
 	}
 	b, err := io.ReadAll(resp.Body)
 	if err != nil {
-		logger.WithContext(ctx).Errorf("failed to extract HTTP response body. err: %v", err)
+		sr.getLogger().WithContext(ctx).Errorf("failed to extract HTTP response body. err: %v", err)
 		return err
 	}
-	logger.WithContext(ctx).Infof("HTTP: %v, URL: %v, Body: %v", resp.StatusCode, fullURL, b)
-	logger.WithContext(ctx).Infof("Header: %v", resp.Header)
+	sr.getLogger().WithContext(ctx).Infof("HTTP: %v, URL: %v, Body: %v", resp.StatusCode, fullURL, b)
+	sr.getLogger().WithContext(ctx).Infof("Header: %v", resp.Header)
 	return &SnowflakeError{
 		Number:      ErrFailedToCloseSession,
 		SQLState:    SQLStateConnectionFailure,
@@ -372,7 +407,7 @@ func closeSession(ctx context.Context, sr *snowflakeRestful, timeout time.Durati
 }
 
 func renewRestfulSession(ctx context.Context, sr *snowflakeRestful, timeout time.Duration) error {
-	logger.WithContext(ctx).Info("start renew session")
+	sr.getLogger().WithContext(ctx).Info("start renew session")
 	params := &url.Values{}
 	params.Set(requestIDKey, getOrGenerateRequestIDFromContext(ctx).String())
 	params.Set(requestGUIDKey, NewUUID().String())
@@ -401,7 +436,7 @@ func renewRestfulSession(ctx context.Context, sr *snowflakeRestful, timeout time
 		var respd renewSessionResponse
 		err = json.NewDecoder(resp.Body).Decode(&respd)
 		if err != nil {
-			logger.WithContext(ctx).Errorf("failed to decode JSON. err: %v", err)
+			sr.getLogger().WithContext(ctx).Errorf("failed to decode JSON. err: %v", err)
 			return err
 		}
 		if !respd.Success {
@@ -419,11 +454,11 @@ func renewRestfulSession(ctx context.Context, sr *snowflakeRestful, timeout time
 	}
 	b, err := io.ReadAll(resp.Body)
 	if err != nil {
-		logger.WithContext(ctx).Errorf("failed to extract HTTP response body. err: %v", err)
+		sr.getLogger().WithContext(ctx).Errorf("failed to extract HTTP response body. err: %v", err)
 		return err
 	}
-	logger.WithContext(ctx).Infof("HTTP: %v, URL: %v, Body: %v", resp.StatusCode, fullURL, b)
-	logger.WithContext(ctx).Infof("Header: %v", resp.Header)
+	sr.getLogger().WithContext(ctx).Infof("HTTP: %v, URL: %v, Body: %v", resp.StatusCode, fullURL, b)
+	sr.getLogger().WithContext(ctx).Infof("Header: %v", resp.Header)
 	return &SnowflakeError{
 		Number:      ErrFailedToRenewSession,
 		SQLState:    SQLStateConnectionFailure,
@@ -445,7 +480,7 @@ func getCancelRetry(ctx context.Context) int {
 }
 
 func cancelQuery(ctx context.Context, sr *snowflakeRestful, requestID UUID, timeout time.Duration) error {
-	logger.WithContext(ctx).Info("cancel query")
+	sr.getLogger().WithContext(ctx).Info("cancel query")
 	params := &url.Values{}
 	params.Set(requestIDKey, getOrGenerateRequestIDFromContext(ctx).String())
 	params.Set(requestGUIDKey, NewUUID().String())
@@ -472,12 +507,12 @@ func cancelQuery(ctx context.Context, sr *snowflakeRestful, requestID UUID, time
 	if resp.StatusCode == http.StatusOK {
 		var respd cancelQueryResponse
 		if err = json.NewDecoder(resp.Body).Decode(&respd); err != nil {
-			logger.WithContext(ctx).Errorf("failed to decode JSON. err: %v", err)
+			sr.getLogger().WithContext(ctx).Errorf("failed to decode JSON. err: %v", err)
 			return err
 		}
 		ctxRetry := getCancelRetry(ctx)
 		if !respd.Success && respd.Code == sessionExpiredCode {
-			if err = sr.FuncRenewSession(ctx, sr, timeout); err != nil {
+			if err = sr.renewSession(ctx, timeout); err != nil {
 				return err
 			}
 			return sr.FuncCancelQuery(ctx, sr, requestID, timeout)
@@ -498,11 +533,11 @@ func cancelQuery(ctx context.Context, sr *snowflakeRestful, requestID UUID, time
 	}
 	b, err := io.ReadAll(resp.Body)
 	if err != nil {
-		logger.WithContext(ctx).Errorf("failed to extract HTTP response body. err: %v", err)
+		sr.getLogger().WithContext(ctx).Errorf("failed to extract HTTP response body. err: %v", err)
 		return err
 	}
-	logger.WithContext(ctx).Infof("HTTP: %v, URL: %v, Body: %v", resp.StatusCode, fullURL, b)
-	logger.WithContext(ctx).Infof("Header: %v", resp.Header)
+	sr.getLogger().WithContext(ctx).Infof("HTTP: %v, URL: %v, Body: %v", resp.StatusCode, fullURL, b)
+	sr.getLogger().WithContext(ctx).Infof("Header: %v", resp.Header)
 	return &SnowflakeError{
 		Number:      ErrFailedToCancelQuery,
 		SQLState:    SQLStateConnectionFailure,
@@ -13,6 +13,9 @@ import (
 	"strings"
 	"sync"
 	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type waitAlgo struct {
@@ -294,8 +297,17 @@ func (r *retryHTTP) setBodyCreator(bodyCreator bodyCreatorType) *retryHTTP {
 
 func (r *retryHTTP) execute() (res *http.Response, err error) {
 	totalTimeout := r.timeout
-	logger.WithContext(r.ctx).Infof("retryHTTP.totalTimeout: %v", totalTimeout)
+	getModuleLogger(r.cfg, logModuleRetry).WithContext(r.ctx).Infof("retryHTTP.totalTimeout: %v", totalTimeout)
 	retryCounter := 0
+	ctx, span := startSpan(r.ctx, r.cfg, spanHTTPRequest, trace.SpanKindClient,
+		attrHTTPMethod.String(r.method), attrHTTPURLPath.String(r.fullURL.Path))
+	defer func() {
+		span.SetAttributes(attrRetryCount.Int(retryCounter))
+		if res != nil {
+			span.SetAttributes(attrHTTPStatusCode.Int(res.StatusCode))
+		}
+		endSpan(span, err)
+	}()
 	sleepTime := time.Duration(time.Second)
 	clientStartTime := strconv.FormatInt(r.currentTimeProvider.currentTime(), 10)
 
@@ -304,7 +316,7 @@ func (r *retryHTTP) execute() (res *http.Response, err error) {
 	var retryReasonUpdater retryReasonUpdater
 
 	for {
-		logger.WithContext(r.ctx).Debugf("retry count: %v", retryCounter)
+		getModuleLogger(r.cfg, logModuleRetry).WithContext(r.ctx).Debugf("retry count: %v", retryCounter)
 		body, err := r.bodyCreator()
 		if err != nil {
 			return nil, err
@@ -315,7 +327,7 @@ func (r *retryHTTP) execute() (res *http.Response, err error) {
 		}
 		if req != nil {
 			// req can be nil in tests
-			req = req.WithContext(r.ctx)
+			req = req.WithContext(ctx)
 		}
 		for k, v := range r.headers {
 			req.Header.Set(k, v)
@@ -327,15 +339,16 @@ func (r *retryHTTP) execute() (res *http.Response, err error) {
 			return res, err
 		}
 		if err != nil {
-			logger.WithContext(r.ctx).Warningf(
+			getModuleLogger(r.cfg, logModuleRetry).WithContext(r.ctx).Warningf(
 				"failed http connection. err: %v. retrying...\n", err)
 		} else {
-			logger.WithContext(r.ctx).Warningf(
+			getModuleLogger(r.cfg, logModuleRetry).WithContext(r.ctx).Warningf(
 				"failed http connection. HTTP Status: %v. retrying...\n", res.StatusCode)
 			res.Body.Close()
 		}
 		// uses exponential jitter backoff
 		retryCounter++
+		countQueryRetry(r.ctx)
 		if isLoginRequest(req) {
 			sleepTime = defaultWaitAlgo.calculateWaitBeforeRetryForAuthRequest(retryCounter, sleepTime)
 		} else {
@@ -343,7 +356,7 @@ func (r *retryHTTP) execute() (res *http.Response, err error) {
 		}
 
 		if totalTimeout > 0 {
-			logger.WithContext(r.ctx).Infof("to timeout: %v", totalTimeout)
+			getModuleLogger(r.cfg, logModuleRetry).WithContext(r.ctx).Infof("to timeout: %v", totalTimeout)
 			// if any timeout is set
 			totalTimeout -= sleepTime
 			if totalTimeout <= 0 || retryCounter > r.maxRetryCount {
@@ -373,8 +386,15 @@ func (r *retryHTTP) execute() (res *http.Response, err error) {
 		}
 		r.fullURL = retryReasonUpdater.replaceOrAdd(retryReason)
 		r.fullURL = ensureClientStartTimeIsSet(r.fullURL, clientStartTime)
-		logger.WithContext(r.ctx).Infof("sleeping %v. to timeout: %v. retrying", sleepTime, totalTimeout)
-		logger.WithContext(r.ctx).Infof("retry count: %v, retry reason: %v", retryCounter, retryReason)
+		getModuleLogger(r.cfg, logModuleRetry).WithContext(r.ctx).Infof("sleeping %v. to timeout: %v. retrying", sleepTime, totalTimeout)
+		getModuleLogger(r.cfg, logModuleRetry).WithContext(r.ctx).Infof("retry count: %v, retry reason: %v", retryCounter, retryReason)
+		retryAttrs := []attribute.KeyValue{attrRetryCount.Int(retryCounter), attrRetryReason.Int(retryReason)}
+		if res != nil {
+			retryAttrs = append(retryAttrs, attrHTTPStatusCode.Int(res.StatusCode))
+		}
+		span.AddEvent("retry", trace.WithAttributes(retryAttrs...))
+		getMetricsRecorder(r.cfg).RecordRetry(ctx, metricsEndpoint(r.fullURL), retryReason)
+		getEventHooks(r.cfg).OnRetry(ctx, newRetryEvent(r.fullURL.Path, res, err, retryCounter, sleepTime))
 
 		await := time.NewTimer(sleepTime)
 		select {
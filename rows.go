@@ -45,6 +45,8 @@ type snowflakeRows struct {
 	location            *time.Location
 	ctx                 context.Context
 	format              resultFormat
+	typeConverters      []*TypeConverter
+	typeConvertersFor   chunkDownloader
 }
 
 func (rows *snowflakeRows) getLocation() *time.Location {
@@ -54,6 +56,46 @@ func (rows *snowflakeRows) getLocation() *time.Location {
 	return rows.location
 }
 
+// getTypeConverters returns the custom converters of the current result set columns, nil for columns without one.
+func (rows *snowflakeRows) getTypeConverters() []*TypeConverter {
+	if rows.typeConvertersFor == rows.ChunkDownloader {
+		return rows.typeConverters
+	}
+	rows.typeConvertersFor = rows.ChunkDownloader
+	rows.typeConverters = nil
+	registry := getTypeMappingRegistry(rows.ctx, rows.sc)
+	if registry == nil {
+		return nil
+	}
+	rowType := rows.ChunkDownloader.getRowType()
+	converters := make([]*TypeConverter, len(rowType))
+	for i := range rowType {
+		if converters[i] = registry.lookup(newTypeMappingColumn(rowType[i])); converters[i] != nil {
+			rows.typeConverters = converters
+		}
+	}
+	return rows.typeConverters
+}
+
+func (rows *snowflakeRows) applyTypeConverters(dest []driver.Value) error {
+	converters := rows.getTypeConverters()
+	if converters == nil {
+		return nil
+	}
+	rowType := rows.ChunkDownloader.getRowType()
+	for i, converter := range converters {
+		if converter == nil || dest[i] == nil {
+			continue
+		}
+		value, err := converter.Convert(dest[i], newTypeMappingColumn(rowType[i]))
+		if err != nil {
+			return err
+		}
+		dest[i] = value
+	}
+	return nil
+}
+
 type snowflakeValue interface{}
 
 type chunkRowType struct {
@@ -76,7 +118,7 @@ func (rows *snowflakeRows) Close() (err error) {
 	if err := rows.waitForAsyncQueryStatus(); err != nil {
 		return err
 	}
-	logger.WithContext(rows.sc.ctx).Debugln("Rows.Close")
+	rows.sc.getLogger().WithContext(rows.sc.ctx).Debugln("Rows.Close")
 	return nil
 }
 
@@ -136,7 +178,7 @@ func (rows *snowflakeRows) Columns() []string {
 	if err := rows.waitForAsyncQueryStatus(); err != nil {
 		return make([]string, 0)
 	}
-	logger.WithContext(rows.ctx).Debug("Rows.Columns")
+	rows.sc.getLogger().WithContext(rows.ctx).Debug("Rows.Columns")
 	ret := make([]string, len(rows.ChunkDownloader.getRowType()))
 	for i, n := 0, len(rows.ChunkDownloader.getRowType()); i < n; i++ {
 		ret[i] = rows.ChunkDownloader.getRowType()[i].Name
@@ -148,6 +190,9 @@ func (rows *snowflakeRows) ColumnTypeScanType(index int) reflect.Type {
 	if err := rows.waitForAsyncQueryStatus(); err != nil {
 		return nil
 	}
+	if converters := rows.getTypeConverters(); converters != nil && converters[index] != nil {
+		return converters[index].ScanType
+	}
 	return snowflakeTypeToGo(rows.ctx, getSnowflakeType(rows.ChunkDownloader.getRowType()[index].Type), rows.ChunkDownloader.getRowType()[index].Scale, rows.ChunkDownloader.getRowType()[index].Fields)
 }
 
@@ -201,7 +246,7 @@ func (rows *snowflakeRows) Next(dest []driver.Value) (err error) {
 			}
 		}
 	}
-	return err
+	return rows.applyTypeConverters(dest)
 }
 
 func (rows *snowflakeRows) HasNextResultSet() bool {
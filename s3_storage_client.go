@@ -7,14 +7,20 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"math"
 	"net/http"
+	"net/url"
 	"os"
+	"slices"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/credentials"
 	"github.com/aws/aws-sdk-go-v2/feature/s3/manager"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
 	"github.com/aws/smithy-go"
 	"github.com/aws/smithy-go/logging"
 )
@@ -28,6 +34,8 @@ const (
 	notFound             = "NotFound"
 	expiredToken         = "ExpiredToken"
 	errNoWsaeconnaborted = "10053"
+
+	s3MaxCopyObjectSize int64 = 5 * 1024 * 1024 * 1024
 )
 
 type snowflakeS3Client struct {
@@ -46,7 +54,7 @@ var S3LoggingMode aws.ClientLogMode
 
 func (util *snowflakeS3Client) createClient(info *execResponseStageInfo, useAccelerateEndpoint bool) (cloudClient, error) {
 	stageCredentials := info.Creds
-	s3Logger := logging.LoggerFunc(s3LoggingFunc)
+	s3Logger := logging.LoggerFunc(util.s3LoggingFunc)
 	endPoint := getS3CustomEndpoint(info)
 
 	return s3.New(s3.Options{
@@ -89,12 +97,12 @@ func getS3CustomEndpoint(info *execResponseStageInfo) *string {
 	return endPoint
 }
 
-func s3LoggingFunc(classification logging.Classification, format string, v ...interface{}) {
+func (util *snowflakeS3Client) s3LoggingFunc(classification logging.Classification, format string, v ...interface{}) {
 	switch classification {
 	case logging.Debug:
-		logger.WithField("logger", "S3").Debugf(format, v...)
+		getConnectionLogger(util.cfg).WithField("logger", "S3").Debugf(format, v...)
 	case logging.Warn:
-		logger.WithField("logger", "S3").Warnf(format, v...)
+		getConnectionLogger(util.cfg).WithField("logger", "S3").Warnf(format, v...)
 	}
 }
 
@@ -108,6 +116,9 @@ func (util *snowflakeS3Client) getFileHeader(meta *fileMetadata, filename string
 	if err != nil {
 		return nil, err
 	}
+	if meta.verifyChecksums() {
+		headObjInput.ChecksumMode = types.ChecksumModeEnabled
+	}
 	var s3Cli s3HeaderAPI
 	s3Cli, ok := meta.client.(*s3.Client)
 	if !ok {
@@ -153,9 +164,19 @@ func (util *snowflakeS3Client) getFileHeader(meta *fileMetadata, filename string
 		out.Metadata[sfcDigest],
 		contentLength,
 		&encMeta,
+		util.getStorageChecksums(out),
 	}, nil
 }
 
+// getStorageChecksums returns the CRC32C which S3 computed of the object. Multipart uploads have a checksum
+// of the part checksums instead, which cannot be compared with the content.
+func (util *snowflakeS3Client) getStorageChecksums(out *s3.HeadObjectOutput) *contentChecksums {
+	if out.ChecksumCRC32C == nil || strings.Contains(*out.ChecksumCRC32C, "-") {
+		return nil
+	}
+	return &contentChecksums{crc32c: *out.ChecksumCRC32C}
+}
+
 // SNOW-974548 remove this function after upgrading AWS SDK
 func convertContentLength(contentLength any) int64 {
 	switch t := contentLength.(type) {
@@ -179,15 +200,7 @@ func (util *snowflakeS3Client) uploadFile(
 	meta *fileMetadata,
 	maxConcurrency int,
 	multiPartThreshold int64) error {
-	s3Meta := map[string]string{
-		httpHeaderContentType: httpHeaderValueOctetStream,
-		sfcDigest:             meta.sha256Digest,
-	}
-	if meta.encryptMeta != nil {
-		s3Meta[amzIv] = meta.encryptMeta.iv
-		s3Meta[amzKey] = meta.encryptMeta.key
-		s3Meta[amzMatdesc] = meta.encryptMeta.matdesc
-	}
+	s3Meta := util.getS3Metadata(meta)
 
 	s3loc, err := util.extractBucketNameAndPath(meta.stageInfo.Location)
 	if err != nil {
@@ -211,14 +224,32 @@ func (util *snowflakeS3Client) uploadFile(
 		uploader = meta.mockUploader
 	}
 
+	if meta.uploadJournal != nil && meta.srcStream == nil {
+		var multipart s3MultipartAPI = client
+		// for testing only
+		if meta.mockMultipart != nil {
+			multipart = meta.mockMultipart
+		}
+		partSize := int64Max(multiPartThreshold, manager.DefaultUploadPartSize)
+		err = util.uploadFileResumable(dataFile, meta, multipart, s3loc.bucketName, s3path, s3Meta, maxConcurrency, partSize)
+		if err != nil {
+			util.setUploadErrorStatus(meta, err)
+			return err
+		}
+		meta.dstFileSize = meta.uploadSize
+		meta.resStatus = uploaded
+		return nil
+	}
+
 	_, err = withCloudStorageTimeout(util.cfg, func(ctx context.Context) (any, error) {
 		if meta.srcStream != nil {
 			uploadStream := cmp.Or(meta.realSrcStream, meta.srcStream)
 			return uploader.Upload(ctx, &s3.PutObjectInput{
-				Bucket:   &s3loc.bucketName,
-				Key:      &s3path,
-				Body:     bytes.NewBuffer(uploadStream.Bytes()),
-				Metadata: s3Meta,
+				Bucket:            &s3loc.bucketName,
+				Key:               &s3path,
+				Body:              meta.progress.reader(bytes.NewBuffer(uploadStream.Bytes())),
+				Metadata:          s3Meta,
+				ChecksumAlgorithm: util.getChecksumAlgorithm(meta),
 			})
 		}
 		var file *os.File
@@ -227,28 +258,221 @@ func (util *snowflakeS3Client) uploadFile(
 			return nil, err
 		}
 		return uploader.Upload(ctx, &s3.PutObjectInput{
-			Bucket:   &s3loc.bucketName,
-			Key:      &s3path,
-			Body:     file,
-			Metadata: s3Meta,
+			Bucket:            &s3loc.bucketName,
+			Key:               &s3path,
+			Body:              meta.progress.readerAtSeeker(file),
+			Metadata:          s3Meta,
+			ChecksumAlgorithm: util.getChecksumAlgorithm(meta),
 		})
 
 	})
 
 	if err != nil {
-		var ae smithy.APIError
-		if errors.As(err, &ae) {
-			if ae.ErrorCode() == expiredToken {
-				meta.resStatus = renewToken
-				return err
-			} else if strings.Contains(ae.ErrorCode(), errNoWsaeconnaborted) {
-				meta.lastError = err
-				meta.resStatus = needRetryWithLowerConcurrency
-				return err
+		util.setUploadErrorStatus(meta, err)
+		return err
+	}
+	meta.dstFileSize = meta.uploadSize
+	meta.resStatus = uploaded
+	return nil
+}
+
+func (util *snowflakeS3Client) getS3Metadata(meta *fileMetadata) map[string]string {
+	s3Meta := map[string]string{
+		httpHeaderContentType: httpHeaderValueOctetStream,
+		sfcDigest:             meta.sha256Digest,
+	}
+	if meta.encryptMeta != nil {
+		s3Meta[amzIv] = meta.encryptMeta.iv
+		s3Meta[amzKey] = meta.encryptMeta.key
+		s3Meta[amzMatdesc] = meta.encryptMeta.matdesc
+	}
+	return s3Meta
+}
+
+// getChecksumAlgorithm returns the checksum S3 validates when the content is received if checksums are verified.
+func (util *snowflakeS3Client) getChecksumAlgorithm(meta *fileMetadata) types.ChecksumAlgorithm {
+	if meta.verifyChecksums() {
+		return types.ChecksumAlgorithmCrc32c
+	}
+	return ""
+}
+
+func (util *snowflakeS3Client) setUploadErrorStatus(meta *fileMetadata, err error) {
+	var ae smithy.APIError
+	if errors.As(err, &ae) {
+		if ae.ErrorCode() == expiredToken {
+			meta.resStatus = renewToken
+			return
+		} else if strings.Contains(ae.ErrorCode(), errNoWsaeconnaborted) {
+			meta.lastError = err
+			meta.resStatus = needRetryWithLowerConcurrency
+			return
+		}
+	}
+	meta.lastError = err
+	meta.resStatus = needRetry
+}
+
+type s3MultipartAPI interface {
+	CreateMultipartUpload(ctx context.Context, params *s3.CreateMultipartUploadInput, optFns ...func(*s3.Options)) (*s3.CreateMultipartUploadOutput, error)
+	UploadPart(ctx context.Context, params *s3.UploadPartInput, optFns ...func(*s3.Options)) (*s3.UploadPartOutput, error)
+	CompleteMultipartUpload(ctx context.Context, params *s3.CompleteMultipartUploadInput, optFns ...func(*s3.Options)) (*s3.CompleteMultipartUploadOutput, error)
+	AbortMultipartUpload(ctx context.Context, params *s3.AbortMultipartUploadInput, optFns ...func(*s3.Options)) (*s3.AbortMultipartUploadOutput, error)
+}
+
+// abortMultipartUpload aborts the multipart upload of a file in the stage of meta, deleting its uploaded parts.
+func (util *snowflakeS3Client) abortMultipartUpload(meta *fileMetadata, fileName string, uploadID string) error {
+	s3loc, err := util.extractBucketNameAndPath(meta.stageInfo.Location)
+	if err != nil {
+		return err
+	}
+	key := s3loc.s3Path + fileName
+	var client s3MultipartAPI
+	if meta.mockMultipart != nil {
+		// for testing only
+		client = meta.mockMultipart
+	} else {
+		s3Cli, ok := meta.client.(*s3.Client)
+		if !ok {
+			return &SnowflakeError{
+				Message: "failed to cast to s3 client",
 			}
 		}
-		meta.lastError = err
-		meta.resStatus = needRetry
+		client = s3Cli
+	}
+	_, err = withCloudStorageTimeout(util.cfg, func(ctx context.Context) (*s3.AbortMultipartUploadOutput, error) {
+		return client.AbortMultipartUpload(ctx, &s3.AbortMultipartUploadInput{
+			Bucket:   &s3loc.bucketName,
+			Key:      &key,
+			UploadId: &uploadID,
+		})
+	})
+	var noSuchUpload *types.NoSuchUpload
+	if errors.As(err, &noSuchUpload) {
+		return nil
+	}
+	return err
+}
+
+// uploadFileResumable uploads the file in parts recorded in the upload journal,
+// so that a failed upload continues with the parts which were not uploaded yet.
+func (util *snowflakeS3Client) uploadFileResumable(
+	dataFile string,
+	meta *fileMetadata,
+	client s3MultipartAPI,
+	bucket string,
+	key string,
+	s3Meta map[string]string,
+	maxConcurrency int,
+	partSize int64) error {
+	journal := meta.uploadJournal
+	if journal.UploadID == "" {
+		stat, err := os.Stat(dataFile)
+		if err != nil {
+			return err
+		}
+		out, err := withCloudStorageTimeout(util.cfg, func(ctx context.Context) (*s3.CreateMultipartUploadOutput, error) {
+			return client.CreateMultipartUpload(ctx, &s3.CreateMultipartUploadInput{
+				Bucket:   &bucket,
+				Key:      &key,
+				Metadata: s3Meta,
+			})
+		})
+		if err != nil {
+			return err
+		}
+		if err = journal.start(aws.ToString(out.UploadId), getMultipartPartSize(stat.Size(), partSize, int64(manager.MaxUploadParts))); err != nil {
+			return err
+		}
+	}
+	uploadID := journal.UploadID
+	meta.progress.set(journal.uploadedBytes())
+	err := uploadMissingParts(journal, dataFile, maxConcurrency, func(number int32, body *io.SectionReader) (uploadJournalPart, error) {
+		out, err := withCloudStorageTimeout(util.cfg, func(ctx context.Context) (*s3.UploadPartOutput, error) {
+			return client.UploadPart(ctx, &s3.UploadPartInput{
+				Bucket:        &bucket,
+				Key:           &key,
+				UploadId:      &uploadID,
+				PartNumber:    &number,
+				Body:          body,
+				ContentLength: aws.Int64(body.Size()),
+			})
+		})
+		if err != nil {
+			return uploadJournalPart{}, err
+		}
+		meta.progress.add(body.Size())
+		return uploadJournalPart{Number: number, Size: body.Size(), ETag: aws.ToString(out.ETag)}, nil
+	})
+	if err == nil {
+		parts := journal.sortedParts()
+		completedParts := make([]types.CompletedPart, len(parts))
+		for i, part := range parts {
+			completedParts[i] = types.CompletedPart{ETag: aws.String(part.ETag), PartNumber: aws.Int32(part.Number)}
+		}
+		_, err = withCloudStorageTimeout(util.cfg, func(ctx context.Context) (*s3.CompleteMultipartUploadOutput, error) {
+			return client.CompleteMultipartUpload(ctx, &s3.CompleteMultipartUploadInput{
+				Bucket:          &bucket,
+				Key:             &key,
+				UploadId:        &uploadID,
+				MultipartUpload: &types.CompletedMultipartUpload{Parts: completedParts},
+			})
+		})
+	}
+	if err != nil {
+		var noSuchUpload *types.NoSuchUpload
+		if errors.As(err, &noSuchUpload) {
+			// the upload was aborted or expired on the storage, the next attempt starts from the beginning
+			journal.reset()
+		}
+		return err
+	}
+	journal.remove()
+	return nil
+}
+
+type s3CopyAPI interface {
+	CopyObject(ctx context.Context, params *s3.CopyObjectInput, optFns ...func(*s3.Options)) (*s3.CopyObjectOutput, error)
+	UploadPartCopy(ctx context.Context, params *s3.UploadPartCopyInput, optFns ...func(*s3.Options)) (*s3.UploadPartCopyOutput, error)
+}
+
+// cloudStreamUtil implementation
+func (util *snowflakeS3Client) uploadStream(
+	meta *fileMetadata,
+	src io.Reader,
+	maxConcurrency int,
+	partSize int64) error {
+	s3loc, err := util.extractBucketNameAndPath(meta.stageInfo.Location)
+	if err != nil {
+		return err
+	}
+	key := s3loc.s3Path + strings.TrimLeft(meta.dstFileName, "/")
+
+	client, ok := meta.client.(*s3.Client)
+	if !ok {
+		return &SnowflakeError{
+			Message: "failed to cast to s3 client",
+		}
+	}
+	var multipart s3MultipartAPI = client
+	var copier s3CopyAPI = client
+	// for testing only
+	if meta.mockMultipart != nil {
+		multipart = meta.mockMultipart
+	}
+	if meta.mockCopier != nil {
+		copier = meta.mockCopier
+	}
+
+	object := &countingReader{r: meta.progress.reader(src)}
+	partSize = int64Max(partSize, manager.MinUploadPartSize)
+	err = util.uploadStreamMultipart(meta, object, multipart, s3loc.bucketName, key, maxConcurrency, partSize)
+	if err == nil {
+		// the digest is known once the whole stream was read, so it is set by copying the object onto itself
+		err = util.replaceMetadata(meta, copier, multipart, s3loc.bucketName, key, object.n)
+	}
+	if err != nil {
+		util.setUploadErrorStatus(meta, err)
 		return err
 	}
 	meta.dstFileSize = meta.uploadSize
@@ -256,6 +480,179 @@ func (util *snowflakeS3Client) uploadFile(
 	return nil
 }
 
+// uploadStreamMultipart uploads the stream in a multipart upload, uploading the parts as they are read,
+// see uploadStreamParts. The multipart upload is aborted when a part cannot be uploaded.
+func (util *snowflakeS3Client) uploadStreamMultipart(
+	meta *fileMetadata,
+	src io.Reader,
+	client s3MultipartAPI,
+	bucket string,
+	key string,
+	maxConcurrency int,
+	partSize int64) error {
+	out, err := withCloudStorageTimeout(util.cfg, func(ctx context.Context) (*s3.CreateMultipartUploadOutput, error) {
+		return client.CreateMultipartUpload(ctx, &s3.CreateMultipartUploadInput{
+			Bucket:            &bucket,
+			Key:               &key,
+			Metadata:          util.getS3Metadata(meta),
+			ChecksumAlgorithm: util.getChecksumAlgorithm(meta),
+		})
+	})
+	if err != nil {
+		return err
+	}
+	uploadID := out.UploadId
+	var mu sync.Mutex
+	var completedParts []types.CompletedPart
+	_, err = uploadStreamParts(src, partSize, maxConcurrency, int(manager.MaxUploadParts), func(number int, part []byte) error {
+		partNumber := int32(number)
+		etag, err := util.uploadPartWithRetry(meta, client, &s3.UploadPartInput{
+			Bucket:            &bucket,
+			Key:               &key,
+			UploadId:          uploadID,
+			PartNumber:        &partNumber,
+			ContentLength:     aws.Int64(int64(len(part))),
+			ChecksumAlgorithm: util.getChecksumAlgorithm(meta),
+		}, part)
+		if err != nil {
+			return err
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		completedParts = append(completedParts, types.CompletedPart{ETag: etag, PartNumber: &partNumber})
+		return nil
+	})
+	if err == nil {
+		slices.SortFunc(completedParts, func(a, b types.CompletedPart) int {
+			return int(*a.PartNumber - *b.PartNumber)
+		})
+		_, err = withCloudStorageTimeout(util.cfg, func(ctx context.Context) (*s3.CompleteMultipartUploadOutput, error) {
+			return client.CompleteMultipartUpload(ctx, &s3.CompleteMultipartUploadInput{
+				Bucket:          &bucket,
+				Key:             &key,
+				UploadId:        uploadID,
+				MultipartUpload: &types.CompletedMultipartUpload{Parts: completedParts},
+			})
+		})
+	}
+	if err != nil {
+		if _, abortErr := withCloudStorageTimeout(util.cfg, func(ctx context.Context) (*s3.AbortMultipartUploadOutput, error) {
+			return client.AbortMultipartUpload(ctx, &s3.AbortMultipartUploadInput{
+				Bucket:   &bucket,
+				Key:      &key,
+				UploadId: uploadID,
+			})
+		}); abortErr != nil {
+			getConnectionLogger(util.cfg).Warnf("failed to abort the upload of %v. err: %v", key, abortErr)
+		}
+	}
+	return err
+}
+
+// uploadPartWithRetry uploads a part of a stream, retrying it as uploadOneFile retries files, and returns its ETag.
+// Expired tokens are not retried, as the stream cannot be uploaded again with new credentials.
+func (util *snowflakeS3Client) uploadPartWithRetry(meta *fileMetadata, client s3MultipartAPI, input *s3.UploadPartInput, part []byte) (*string, error) {
+	var err error
+	for retry := 0; retry < defaultMaxRetry; retry++ {
+		if retry > 0 && !meta.noSleepingTime {
+			sleepingTime := intMin(int(math.Exp2(float64(retry))), 16)
+			time.Sleep(time.Second * time.Duration(sleepingTime))
+		}
+		partInput := *input
+		partInput.Body = bytes.NewReader(part)
+		var out *s3.UploadPartOutput
+		out, err = withCloudStorageTimeout(util.cfg, func(ctx context.Context) (*s3.UploadPartOutput, error) {
+			return client.UploadPart(ctx, &partInput)
+		})
+		if err == nil {
+			return out.ETag, nil
+		}
+		var ae smithy.APIError
+		if errors.As(err, &ae) && ae.ErrorCode() == expiredToken {
+			return nil, err
+		}
+		getConnectionLogger(util.cfg).Debugf("error uploading part %v of %v, retrying. err: %v", retry+1, meta.dstFileName, err)
+	}
+	return nil, err
+}
+
+// replaceMetadata replaces the metadata of an uploaded object with the current digest and encryption metadata of meta
+// by copying the object onto itself.
+func (util *snowflakeS3Client) replaceMetadata(
+	meta *fileMetadata,
+	copier s3CopyAPI,
+	multipart s3MultipartAPI,
+	bucket string,
+	key string,
+	size int64) error {
+	copySource := bucket + "/" + (&url.URL{Path: key}).EscapedPath()
+	contentType := httpHeaderValueOctetStream
+	if size <= s3MaxCopyObjectSize {
+		_, err := withCloudStorageTimeout(util.cfg, func(ctx context.Context) (*s3.CopyObjectOutput, error) {
+			return copier.CopyObject(ctx, &s3.CopyObjectInput{
+				Bucket:            &bucket,
+				Key:               &key,
+				CopySource:        &copySource,
+				ContentType:       &contentType,
+				Metadata:          util.getS3Metadata(meta),
+				MetadataDirective: types.MetadataDirectiveReplace,
+				ChecksumAlgorithm: util.getChecksumAlgorithm(meta),
+			})
+		})
+		return err
+	}
+
+	// objects larger than 5 GiB can be copied only in parts
+	_, err := withCloudStorageTimeout(util.cfg, func(ctx context.Context) (any, error) {
+		upload, err := multipart.CreateMultipartUpload(ctx, &s3.CreateMultipartUploadInput{
+			Bucket:      &bucket,
+			Key:         &key,
+			ContentType: &contentType,
+			Metadata:    util.getS3Metadata(meta),
+		})
+		if err != nil {
+			return nil, err
+		}
+		var parts []types.CompletedPart
+		for start := int64(0); start < size; start += s3MaxCopyObjectSize {
+			partNumber := int32(len(parts) + 1)
+			copySourceRange := fmt.Sprintf("bytes=%v-%v", start, min(start+s3MaxCopyObjectSize, size)-1)
+			var out *s3.UploadPartCopyOutput
+			out, err = copier.UploadPartCopy(ctx, &s3.UploadPartCopyInput{
+				Bucket:          &bucket,
+				Key:             &key,
+				UploadId:        upload.UploadId,
+				PartNumber:      &partNumber,
+				CopySource:      &copySource,
+				CopySourceRange: &copySourceRange,
+			})
+			if err != nil {
+				break
+			}
+			parts = append(parts, types.CompletedPart{ETag: out.CopyPartResult.ETag, PartNumber: &partNumber})
+		}
+		if err == nil {
+			_, err = multipart.CompleteMultipartUpload(ctx, &s3.CompleteMultipartUploadInput{
+				Bucket:          &bucket,
+				Key:             &key,
+				UploadId:        upload.UploadId,
+				MultipartUpload: &types.CompletedMultipartUpload{Parts: parts},
+			})
+		}
+		if err != nil {
+			if _, abortErr := multipart.AbortMultipartUpload(ctx, &s3.AbortMultipartUploadInput{
+				Bucket:   &bucket,
+				Key:      &key,
+				UploadId: upload.UploadId,
+			}); abortErr != nil {
+				getConnectionLogger(util.cfg).Warnf("failed to abort copying of %v. err: %v", key, abortErr)
+			}
+		}
+		return nil, err
+	})
+	return err
+}
+
 type s3DownloadAPI interface {
 	Download(ctx context.Context, w io.WriterAt, params *s3.GetObjectInput, optFns ...func(*manager.Downloader)) (int64, error)
 }
@@ -290,13 +687,13 @@ func (util *snowflakeS3Client) nativeDownloadFile(
 	_, err = withCloudStorageTimeout(util.cfg, func(ctx context.Context) (any, error) {
 		if meta.options.GetFileToStream {
 			buf := manager.NewWriteAtBuffer([]byte{})
-			_, err = downloader.Download(ctx, buf, &s3.GetObjectInput{
+			_, err = downloader.Download(ctx, meta.progress.writerAt(buf), &s3.GetObjectInput{
 				Bucket: s3Obj.Bucket,
 				Key:    s3Obj.Key,
 			})
 			meta.dstStream = bytes.NewBuffer(buf.Bytes())
 		} else {
-			_, err = downloader.Download(ctx, f, &s3.GetObjectInput{
+			_, err = downloader.Download(ctx, meta.progress.writerAt(f), &s3.GetObjectInput{
 				Bucket: s3Obj.Bucket,
 				Key:    s3Obj.Key,
 			})
@@ -305,26 +702,62 @@ func (util *snowflakeS3Client) nativeDownloadFile(
 	})
 
 	if err != nil {
-		var ae smithy.APIError
-		if errors.As(err, &ae) {
-			if ae.ErrorCode() == expiredToken {
-				meta.resStatus = renewToken
-				return err
-			} else if strings.Contains(ae.ErrorCode(), errNoWsaeconnaborted) {
-				meta.lastError = err
-				meta.resStatus = needRetryWithLowerConcurrency
-				return err
-			}
+		return setDownloadErrorStatus(meta, err)
+	}
+	meta.resStatus = downloaded
+	return nil
+}
+
+// setDownloadErrorStatus sets the result status of a failed download depending on the error.
+func setDownloadErrorStatus(meta *fileMetadata, err error) error {
+	var ae smithy.APIError
+	if errors.As(err, &ae) {
+		if ae.ErrorCode() == expiredToken {
+			meta.resStatus = renewToken
+			return err
+		} else if strings.Contains(ae.ErrorCode(), errNoWsaeconnaborted) {
 			meta.lastError = err
-			meta.resStatus = errStatus
+			meta.resStatus = needRetryWithLowerConcurrency
 			return err
 		}
 		meta.lastError = err
-		meta.resStatus = needRetry
+		meta.resStatus = errStatus
 		return err
 	}
-	meta.resStatus = downloaded
-	return nil
+	meta.lastError = err
+	meta.resStatus = needRetry
+	return err
+}
+
+type s3GetObjectAPI interface {
+	GetObject(ctx context.Context, params *s3.GetObjectInput, optFns ...func(*s3.Options)) (*s3.GetObjectOutput, error)
+}
+
+// downloadStream implements cloudDownloadStreamUtil with a single GET request of the object,
+// whose body is read sequentially instead of the concurrent ranged requests of nativeDownloadFile.
+func (util *snowflakeS3Client) downloadStream(meta *fileMetadata) (io.ReadCloser, error) {
+	s3Obj, _ := util.getS3Object(meta, meta.srcFileName)
+	client, ok := meta.client.(*s3.Client)
+	if !ok {
+		return nil, &SnowflakeError{
+			Message: "failed to cast to s3 client",
+		}
+	}
+	var getter s3GetObjectAPI = client
+	// for testing only
+	if meta.mockGetter != nil {
+		getter = meta.mockGetter
+	}
+	ctx, cancel := cloudStorageContext(util.cfg)
+	out, err := getter.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: s3Obj.Bucket,
+		Key:    s3Obj.Key,
+	})
+	if err != nil {
+		cancel()
+		return nil, setDownloadErrorStatus(meta, err)
+	}
+	return &readCloserWithCancel{out.Body, cancel}, nil
 }
 
 func (util *snowflakeS3Client) extractBucketNameAndPath(location string) (*s3Location, error) {
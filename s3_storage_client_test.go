@@ -3,16 +3,21 @@ package gosnowflake
 import (
 	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/base64"
 	"errors"
 	"fmt"
 	"io"
 	"os"
 	"path"
 	"strconv"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/feature/s3/manager"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
 	"github.com/aws/smithy-go"
 )
 
@@ -767,6 +772,90 @@ func TestS3UploadStreamFailed(t *testing.T) {
 	}
 }
 
+type mockCopyObjectAPI struct {
+	copyObjectInputs     []*s3.CopyObjectInput
+	uploadPartCopyInputs []*s3.UploadPartCopyInput
+}
+
+func (m *mockCopyObjectAPI) CopyObject(_ context.Context, params *s3.CopyObjectInput, _ ...func(*s3.Options)) (*s3.CopyObjectOutput, error) {
+	m.copyObjectInputs = append(m.copyObjectInputs, params)
+	return &s3.CopyObjectOutput{}, nil
+}
+
+func (m *mockCopyObjectAPI) UploadPartCopy(_ context.Context, params *s3.UploadPartCopyInput, _ ...func(*s3.Options)) (*s3.UploadPartCopyOutput, error) {
+	m.uploadPartCopyInputs = append(m.uploadPartCopyInputs, params)
+	etag := strconv.Itoa(int(*params.PartNumber))
+	return &s3.UploadPartCopyOutput{CopyPartResult: &types.CopyPartResult{ETag: &etag}}, nil
+}
+
+func TestS3UploadStreamInParts(t *testing.T) {
+	info := execResponseStageInfo{
+		Location:     "sfc-customer-stage/rwyi-testacco/users/9220/",
+		LocationType: "S3",
+	}
+	s3Cli, err := new(snowflakeS3Client).createClient(&info, false)
+	assertNilF(t, err)
+	data := bytes.Repeat([]byte("some streamed data "), int(2*manager.MinUploadPartSize)/19+100)
+	multipart := &mockMultipartUploadAPI{uploadedParts: make(map[int32][]byte), failPart: 2}
+	copier := &mockCopyObjectAPI{}
+	uploadMeta := fileMetadata{
+		name:              "data1.txt",
+		stageLocationType: "S3",
+		client:            s3Cli,
+		stageInfo:         &info,
+		dstFileName:       "data1.txt",
+		overwrite:         true,
+		noSleepingTime:    true,
+		options: &SnowflakeFileTransferOptions{
+			MultiPartThreshold: dataSizeThreshold,
+		},
+		mockMultipart: multipart,
+		mockCopier:    copier,
+	}
+	stream, err := newUploadStream(&uploadMeta, bytes.NewReader(data), s3Client)
+	assertNilF(t, err)
+	defer stream.Close()
+
+	err = (&snowflakeS3Client{cfg: &Config{}}).uploadStream(&uploadMeta, stream, 4, 0)
+	assertNilF(t, err)
+	assertEqualE(t, uploadMeta.resStatus, uploaded)
+	assertEqualE(t, multipart.createCount, 1)
+	assertNotNilF(t, multipart.completeInput)
+	parts := multipart.completeInput.MultipartUpload.Parts
+	assertEqualF(t, len(parts), 3)
+	var uploaded []byte
+	for i, part := range parts {
+		assertEqualE(t, *part.PartNumber, int32(i+1))
+		uploaded = append(uploaded, multipart.uploadedParts[*part.PartNumber]...)
+	}
+	assertBytesEqualE(t, uploaded, data, "failed parts should be uploaded again")
+	assertEqualE(t, len(multipart.abortedIDs), 0)
+
+	assertNotEqualE(t, uploadMeta.sha256Digest, "")
+	assertEqualF(t, len(copier.copyObjectInputs), 1)
+	copyInput := copier.copyObjectInputs[0]
+	assertEqualE(t, *copyInput.CopySource, "sfc-customer-stage/rwyi-testacco/users/9220/data1.txt")
+	assertEqualE(t, copyInput.MetadataDirective, types.MetadataDirectiveReplace)
+	assertEqualE(t, copyInput.Metadata[sfcDigest], uploadMeta.sha256Digest)
+}
+
+func TestS3ReplaceMetadataOfLargeObject(t *testing.T) {
+	multipart := &mockMultipartUploadAPI{uploadedParts: make(map[int32][]byte)}
+	copier := &mockCopyObjectAPI{}
+	meta := &fileMetadata{sha256Digest: "digest", options: &SnowflakeFileTransferOptions{}}
+	size := 2*s3MaxCopyObjectSize + 10
+	err := (&snowflakeS3Client{cfg: &Config{}}).replaceMetadata(meta, copier, multipart, "bucket", "path/data 1.txt", size)
+	assertNilF(t, err)
+	assertEqualE(t, len(copier.copyObjectInputs), 0)
+	assertEqualE(t, multipart.createCount, 1)
+	assertEqualF(t, len(copier.uploadPartCopyInputs), 3)
+	assertEqualE(t, *copier.uploadPartCopyInputs[0].CopySource, "bucket/path/data%201.txt")
+	assertEqualE(t, *copier.uploadPartCopyInputs[0].CopySourceRange, fmt.Sprintf("bytes=0-%v", s3MaxCopyObjectSize-1))
+	assertEqualE(t, *copier.uploadPartCopyInputs[2].CopySourceRange, fmt.Sprintf("bytes=%v-%v", 2*s3MaxCopyObjectSize, size-1))
+	assertNotNilF(t, multipart.completeInput)
+	assertEqualE(t, len(multipart.completeInput.MultipartUpload.Parts), 3)
+}
+
 func TestConvertContentLength(t *testing.T) {
 	someInt := int64(1)
 	tcs := []struct {
@@ -896,3 +985,179 @@ func TestGetS3Endpoint(t *testing.T) {
 		})
 	}
 }
+
+type mockMultipartUploadAPI struct {
+	mu            sync.Mutex
+	createCount   int
+	uploadedParts map[int32][]byte
+	failPart      int32
+	completeInput *s3.CompleteMultipartUploadInput
+	abortedIDs    []string
+}
+
+func (m *mockMultipartUploadAPI) CreateMultipartUpload(_ context.Context, params *s3.CreateMultipartUploadInput, _ ...func(*s3.Options)) (*s3.CreateMultipartUploadOutput, error) {
+	m.createCount++
+	uploadID := "upload-id-" + strconv.Itoa(m.createCount)
+	return &s3.CreateMultipartUploadOutput{UploadId: &uploadID}, nil
+}
+
+func (m *mockMultipartUploadAPI) UploadPart(_ context.Context, params *s3.UploadPartInput, _ ...func(*s3.Options)) (*s3.UploadPartOutput, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if *params.PartNumber == m.failPart {
+		m.failPart = 0
+		return nil, errors.New("connection reset")
+	}
+	data, err := io.ReadAll(params.Body)
+	if err != nil {
+		return nil, err
+	}
+	m.uploadedParts[*params.PartNumber] = data
+	etag := "etag-" + strconv.Itoa(int(*params.PartNumber))
+	return &s3.UploadPartOutput{ETag: &etag}, nil
+}
+
+func (m *mockMultipartUploadAPI) CompleteMultipartUpload(_ context.Context, params *s3.CompleteMultipartUploadInput, _ ...func(*s3.Options)) (*s3.CompleteMultipartUploadOutput, error) {
+	m.completeInput = params
+	return &s3.CompleteMultipartUploadOutput{}, nil
+}
+
+func (m *mockMultipartUploadAPI) AbortMultipartUpload(_ context.Context, params *s3.AbortMultipartUploadInput, _ ...func(*s3.Options)) (*s3.AbortMultipartUploadOutput, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.abortedIDs = append(m.abortedIDs, *params.UploadId)
+	return &s3.AbortMultipartUploadOutput{}, nil
+}
+
+func TestS3ResumableUpload(t *testing.T) {
+	info := execResponseStageInfo{
+		Location:     "sfc-customer-stage/rwyi-testacco/users/9220/",
+		LocationType: "S3",
+	}
+	s3Cli, err := new(snowflakeS3Client).createClient(&info, false)
+	assertNilF(t, err)
+	tmpDir := t.TempDir()
+	cfg := &Config{TmpDirPath: tmpDir, UploadJournalRetention: time.Hour}
+	data := bytes.Repeat([]byte("0123456789"), int(manager.DefaultUploadPartSize)*2/10+100)
+	dataFile := path.Join(tmpDir, "data1.txt")
+	assertNilF(t, os.WriteFile(dataFile, data, readWriteFileMode))
+
+	multipart := &mockMultipartUploadAPI{uploadedParts: make(map[int32][]byte), failPart: 2}
+	uploadMeta := fileMetadata{
+		name:              "data1.txt",
+		stageLocationType: "S3",
+		client:            s3Cli,
+		stageInfo:         &info,
+		dstFileName:       "data1.txt",
+		sha256Digest:      "digest",
+		uploadSize:        int64(len(data)),
+		overwrite:         true,
+		options: &SnowflakeFileTransferOptions{
+			MultiPartThreshold: 1024,
+		},
+		mockMultipart: multipart,
+	}
+	s3Util := &snowflakeS3Client{cfg: cfg}
+	uploadMeta.uploadJournal = openUploadJournal(cfg, &uploadMeta)
+	assertNotNilF(t, uploadMeta.uploadJournal)
+	err = s3Util.uploadFile(dataFile, &uploadMeta, 1, 1024)
+	assertNotNilF(t, err)
+	assertEqualE(t, uploadMeta.resStatus, needRetry)
+	assertEqualE(t, len(multipart.uploadedParts), 1)
+
+	// the next attempt reads the journal from disk and uploads the missing parts only
+	uploadMeta.uploadJournal = openUploadJournal(cfg, &uploadMeta)
+	assertEqualE(t, uploadMeta.uploadJournal.UploadID, "upload-id-1")
+	delete(multipart.uploadedParts, 1)
+	err = s3Util.uploadFile(dataFile, &uploadMeta, 4, 1024)
+	assertNilF(t, err)
+	assertEqualE(t, uploadMeta.resStatus, uploaded)
+	assertEqualE(t, multipart.createCount, 1)
+	assertEqualE(t, len(multipart.uploadedParts), 2, "uploaded part should not be uploaded again")
+	assertBytesEqualE(t, multipart.uploadedParts[3], data[2*manager.DefaultUploadPartSize:])
+
+	assertNotNilF(t, multipart.completeInput)
+	assertEqualE(t, *multipart.completeInput.UploadId, "upload-id-1")
+	parts := multipart.completeInput.MultipartUpload.Parts
+	assertEqualF(t, len(parts), 3)
+	for i, part := range parts {
+		assertEqualE(t, *part.PartNumber, int32(i+1))
+		assertEqualE(t, *part.ETag, "etag-"+strconv.Itoa(i+1))
+	}
+	_, err = os.Stat(uploadMeta.uploadJournal.path)
+	assertTrueE(t, os.IsNotExist(err), "journal should be removed after the upload")
+}
+
+type mockGetObjectAPI func(ctx context.Context, params *s3.GetObjectInput, optFns ...func(*s3.Options)) (*s3.GetObjectOutput, error)
+
+func (m mockGetObjectAPI) GetObject(
+	ctx context.Context,
+	params *s3.GetObjectInput,
+	optFns ...func(*s3.Options)) (*s3.GetObjectOutput, error) {
+	return m(ctx, params, optFns...)
+}
+
+func TestDownloadFileFromS3ToFileWriter(t *testing.T) {
+	info := execResponseStageInfo{
+		Location:     "sfc-teststage/rwyitestacco/users/1234/",
+		LocationType: "S3",
+	}
+	s3Cli, err := new(snowflakeS3Client).createClient(&info, false)
+	assertNilF(t, err)
+	encMat := snowflakeFileEncryption{
+		"ztke8tIdVt1zmlQIZm0BMA==",
+		"123873c7-3a66-40c4-ab89-e3722fbccce1",
+		3112,
+	}
+	data := bytes.Repeat([]byte("1,2,3\n"), 20000)
+	var encrypted bytes.Buffer
+	encMeta, err := encryptStreamCBC(&encMat, bytes.NewReader(data), &encrypted, 0)
+	assertNilF(t, err)
+	digest := sha256.Sum256(data)
+
+	w := &testGetFileWriter{}
+	tmpDir := t.TempDir()
+	downloadMeta := fileMetadata{
+		name:               "data1.txt",
+		stageLocationType:  "S3",
+		client:             s3Cli,
+		stageInfo:          &info,
+		dstFileName:        "data1.txt",
+		srcFileName:        "data1.txt",
+		tmpDir:             tmpDir,
+		encryptionMaterial: &encMat,
+		options: &SnowflakeFileTransferOptions{
+			VerifyChecksums: true,
+			GetFileWriter: func(name string) (io.WriteCloser, error) {
+				return w, nil
+			},
+		},
+		mockGetter: mockGetObjectAPI(func(ctx context.Context, params *s3.GetObjectInput, optFns ...func(*s3.Options)) (*s3.GetObjectOutput, error) {
+			return &s3.GetObjectOutput{Body: io.NopCloser(bytes.NewReader(encrypted.Bytes()))}, nil
+		}),
+		mockDownloader: mockDownloadObjectAPI(func(ctx context.Context, w io.WriterAt, params *s3.GetObjectInput, optFns ...func(*manager.Downloader)) (int64, error) {
+			return 0, errors.New("files passed to GetFileWriter should not be downloaded to disk")
+		}),
+		mockHeader: mockHeaderAPI(func(ctx context.Context, params *s3.HeadObjectInput, optFns ...func(*s3.Options)) (*s3.HeadObjectOutput, error) {
+			return &s3.HeadObjectOutput{Metadata: map[string]string{
+				sfcDigest:  base64.StdEncoding.EncodeToString(digest[:]),
+				amzKey:     encMeta.key,
+				amzIv:      encMeta.iv,
+				amzMatdesc: encMeta.matdesc,
+			}}, nil
+		}),
+		sfa: &snowflakeFileTransferAgent{
+			sc: &snowflakeConn{
+				cfg: &Config{},
+			},
+		},
+	}
+	assertNilF(t, new(remoteStorageUtil).downloadOneFile(&downloadMeta))
+	assertEqualE(t, downloadMeta.resStatus, downloaded)
+	assertTrueE(t, w.closed, "writer should be closed")
+	assertBytesEqualE(t, w.Bytes(), data)
+	assertEqualE(t, downloadMeta.dstFileSize, int64(len(data)))
+	entries, err := os.ReadDir(tmpDir)
+	assertNilF(t, err)
+	assertEqualE(t, len(entries), 0, "nothing should be written to the temporary directory")
+}
@@ -1,6 +1,10 @@
 package gosnowflake
 
-import "regexp"
+import (
+	"database/sql/driver"
+	"regexp"
+	"sync"
+)
 
 const (
 	awsKeyPattern          = `(?i)(aws_key_id|aws_secret_key|access_key_id|secret_access_key)\s*=\s*'([^']+)'`
@@ -50,12 +54,88 @@ func maskPrivateKeyData(text string) string {
 	return privateKeyDataRegexp.ReplaceAllString(text, `"privateKeyData": "XXXX"`)
 }
 
+// SecretMaskingRule masks the secrets in a text. The rules are applied to log messages, log fields
+// and the messages of SnowflakeError.
+type SecretMaskingRule func(text string) string
+
+// RegexpMaskingRule returns a SecretMaskingRule replacing the matches of the regular expression
+// with the replacement, which can refer to submatches as in regexp.Regexp.ReplaceAllString.
+func RegexpMaskingRule(re *regexp.Regexp, replacement string) SecretMaskingRule {
+	return func(text string) string {
+		return re.ReplaceAllString(text, replacement)
+	}
+}
+
+var (
+	secretMaskingRules       []SecretMaskingRule
+	clientConfigMaskingRules []SecretMaskingRule // mask_patterns of the client config file, replaced on reload
+	secretMaskingRulesLock   sync.RWMutex
+)
+
+// RegisterSecretMaskingRule registers a rule masking secrets which are not recognized by the driver,
+// e.g. internal tokens or personal data in SQL text. The rules are applied after the builtin ones
+// in the order of registration.
+func RegisterSecretMaskingRule(rule SecretMaskingRule) {
+	secretMaskingRulesLock.Lock()
+	defer secretMaskingRulesLock.Unlock()
+	secretMaskingRules = append(secretMaskingRules, rule)
+}
+
+// setClientConfigMaskingRules replaces the rules registered by the client config file.
+func setClientConfigMaskingRules(rules []SecretMaskingRule) {
+	secretMaskingRulesLock.Lock()
+	defer secretMaskingRulesLock.Unlock()
+	clientConfigMaskingRules = rules
+}
+
 func maskSecrets(text string) string {
-	return maskConnectionToken(
+	text = maskConnectionToken(
 		maskPassword(
 			maskPrivateKeyData(
 				maskPrivateKey(
 					maskAwsToken(
 						maskSasToken(
 							maskAwsKey(text)))))))
+	secretMaskingRulesLock.RLock()
+	defer secretMaskingRulesLock.RUnlock()
+	for _, rule := range secretMaskingRules {
+		text = rule(text)
+	}
+	for _, rule := range clientConfigMaskingRules {
+		text = rule(text)
+	}
+	return text
+}
+
+// maskedBindValue replaces the values of bindings in logs.
+const maskedBindValue = "****"
+
+// namedValuesForLog returns the bindings of a query to be logged, with masked values unless logValues is set.
+func namedValuesForLog(args []driver.NamedValue, logValues bool) []driver.NamedValue {
+	if logValues || len(args) == 0 {
+		return args
+	}
+	masked := make([]driver.NamedValue, len(args))
+	for i, arg := range args {
+		masked[i] = arg
+		if arg.Value != nil {
+			masked[i].Value = maskedBindValue
+		}
+	}
+	return masked
+}
+
+// bindParametersForLog returns the bindings of a query request to be logged, with masked values unless logValues is set.
+func bindParametersForLog(bindings map[string]execBindParameter, logValues bool) map[string]execBindParameter {
+	if logValues || len(bindings) == 0 {
+		return bindings
+	}
+	masked := make(map[string]execBindParameter, len(bindings))
+	for key, binding := range bindings {
+		if binding.Value != nil {
+			binding.Value = maskedBindValue
+		}
+		masked[key] = binding
+	}
+	return masked
 }
@@ -1,6 +1,10 @@
 package gosnowflake
 
 import (
+	"bytes"
+	"context"
+	"database/sql/driver"
+	"regexp"
 	"strings"
 	"testing"
 )
@@ -72,3 +76,70 @@ func TestTokenPassword(t *testing.T) {
 		t.Errorf("mask unsuccessful. expected: %v, got: %v", expected, text)
 	}
 }
+
+func registerTestSecretMaskingRules(t *testing.T, rules ...SecretMaskingRule) {
+	secretMaskingRulesLock.Lock()
+	previous := secretMaskingRules
+	secretMaskingRulesLock.Unlock()
+	t.Cleanup(func() {
+		secretMaskingRulesLock.Lock()
+		defer secretMaskingRulesLock.Unlock()
+		secretMaskingRules = previous
+	})
+	for _, rule := range rules {
+		RegisterSecretMaskingRule(rule)
+	}
+}
+
+func TestRegisterSecretMaskingRule(t *testing.T) {
+	registerTestSecretMaskingRules(t,
+		RegexpMaskingRule(regexp.MustCompile(`(internal_token=)\w+`), "${1}****"),
+		func(text string) string {
+			return strings.ReplaceAll(text, "john.doe@example.com", "<email>")
+		})
+
+	text := maskSecrets("internal_token=abc123 select * from t where email = 'john.doe@example.com' and password='" + randomPassword + "'")
+	expected := "internal_token=**** select * from t where email = '<email>' and password='****"
+	if text != expected {
+		t.Errorf("mask unsuccessful. expected: %v, got: %v", expected, text)
+	}
+}
+
+func TestMaskSecretsInLogFields(t *testing.T) {
+	registerTestSecretMaskingRules(t, RegexpMaskingRule(regexp.MustCompile(`ssn=\d+`), "ssn=****"))
+	logger := CreateDefaultLogger()
+	buf := &bytes.Buffer{}
+	logger.SetOutput(buf)
+
+	logger.WithContext(context.Background()).WithField("query", "select 1 where ssn=123456789").Info("ssn=987654321")
+	assertStringContainsE(t, buf.String(), `msg="ssn=****"`)
+	assertStringContainsE(t, buf.String(), `query="select 1 where ssn=****"`)
+}
+
+func TestMaskSecretsInSnowflakeError(t *testing.T) {
+	registerTestSecretMaskingRules(t, RegexpMaskingRule(regexp.MustCompile(`ssn=\d+`), "ssn=****"))
+	err := &SnowflakeError{
+		Number:      ErrFailedToPostQuery,
+		Message:     "failed query: %v",
+		MessageArgs: []interface{}{"select 1 where ssn=123456789 and password='" + randomPassword + "'"},
+	}
+	expected := "261000: failed query: select 1 where ssn=**** and password='****"
+	if err.Error() != expected {
+		t.Errorf("mask unsuccessful. expected: %v, got: %v", expected, err.Error())
+	}
+}
+
+func TestMaskBindValues(t *testing.T) {
+	args := []driver.NamedValue{{Ordinal: 1, Value: "secret"}, {Ordinal: 2, Value: nil}}
+	assertDeepEqualE(t, namedValuesForLog(args, false), []driver.NamedValue{{Ordinal: 1, Value: maskedBindValue}, {Ordinal: 2, Value: nil}})
+	assertDeepEqualE(t, namedValuesForLog(args, true), args)
+	assertEqualE(t, args[0].Value, "secret")
+
+	bindings := map[string]execBindParameter{"1": {Type: "TEXT", Value: "secret"}, "2": {Type: "TEXT"}}
+	assertDeepEqualE(t, bindParametersForLog(bindings, false), map[string]execBindParameter{
+		"1": {Type: "TEXT", Value: maskedBindValue},
+		"2": {Type: "TEXT"},
+	})
+	assertDeepEqualE(t, bindParametersForLog(bindings, true), bindings)
+	assertEqualE(t, bindings["1"].Value, "secret")
+}
@@ -19,24 +19,24 @@ type snowflakeStmt struct {
 }
 
 func (stmt *snowflakeStmt) Close() error {
-	logger.WithContext(stmt.sc.ctx).Infoln("Stmt.Close")
+	stmt.sc.getLogger().WithContext(stmt.sc.ctx).Infoln("Stmt.Close")
 	// noop
 	return nil
 }
 
 func (stmt *snowflakeStmt) NumInput() int {
-	logger.WithContext(stmt.sc.ctx).Infoln("Stmt.NumInput")
+	stmt.sc.getLogger().WithContext(stmt.sc.ctx).Infoln("Stmt.NumInput")
 	// Go Snowflake doesn't know the number of binding parameters.
 	return -1
 }
 
 func (stmt *snowflakeStmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
-	logger.WithContext(stmt.sc.ctx).Infoln("Stmt.ExecContext")
+	stmt.sc.getLogger().WithContext(stmt.sc.ctx).Infoln("Stmt.ExecContext")
 	return stmt.execInternal(ctx, args)
 }
 
 func (stmt *snowflakeStmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
-	logger.WithContext(stmt.sc.ctx).Infoln("Stmt.QueryContext")
+	stmt.sc.getLogger().WithContext(stmt.sc.ctx).Infoln("Stmt.QueryContext")
 	rows, err := stmt.sc.QueryContext(ctx, stmt.query, args)
 	if err != nil {
 		stmt.setQueryIDFromError(err)
@@ -51,12 +51,12 @@ func (stmt *snowflakeStmt) QueryContext(ctx context.Context, args []driver.Named
 }
 
 func (stmt *snowflakeStmt) Exec(args []driver.Value) (driver.Result, error) {
-	logger.WithContext(stmt.sc.ctx).Infoln("Stmt.Exec")
+	stmt.sc.getLogger().WithContext(stmt.sc.ctx).Infoln("Stmt.Exec")
 	return stmt.execInternal(context.Background(), toNamedValues(args))
 }
 
 func (stmt *snowflakeStmt) execInternal(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
-	logger.WithContext(stmt.sc.ctx).Debugln("Stmt.execInternal")
+	stmt.sc.getLogger().WithContext(stmt.sc.ctx).Debugln("Stmt.execInternal")
 	if ctx == nil {
 		ctx = context.Background()
 	}
@@ -80,7 +80,7 @@ func (stmt *snowflakeStmt) execInternal(ctx context.Context, args []driver.Named
 }
 
 func (stmt *snowflakeStmt) Query(args []driver.Value) (driver.Rows, error) {
-	logger.WithContext(stmt.sc.ctx).Infoln("Stmt.Query")
+	stmt.sc.getLogger().WithContext(stmt.sc.ctx).Infoln("Stmt.Query")
 	rows, err := stmt.sc.Query(stmt.query, args)
 	if err != nil {
 		stmt.setQueryIDFromError(err)
@@ -2,9 +2,9 @@ package gosnowflake
 
 import (
 	"fmt"
+	"io"
 	"math"
 	"os"
-	"path"
 	"path/filepath"
 	"time"
 )
@@ -18,6 +18,7 @@ const (
 type storageUtil interface {
 	createClient(*execResponseStageInfo, bool, *Config) (cloudClient, error)
 	uploadOneFileWithRetry(*fileMetadata) error
+	uploadOneStream(*fileMetadata, io.Reader) error
 	downloadOneFile(*fileMetadata) error
 }
 
@@ -29,6 +30,18 @@ type cloudUtil interface {
 	nativeDownloadFile(*fileMetadata, string, int64) error
 }
 
+// implemented by cloud utils which can upload a stream of unknown size in parts as they are read
+// and set the metadata with the digest once the whole stream was uploaded
+type cloudStreamUtil interface {
+	uploadStream(meta *fileMetadata, src io.Reader, maxConcurrency int, partSize int64) error
+}
+
+// implemented by cloud utils which can download a file as a stream, so that the files passed to
+// GetFileWriter are written to it as they are downloaded instead of being stored on disk first
+type cloudDownloadStreamUtil interface {
+	downloadStream(meta *fileMetadata) (io.ReadCloser, error)
+}
+
 type cloudClient interface{}
 
 type remoteStorageUtil struct {
@@ -59,17 +72,30 @@ func (rsu *remoteStorageUtil) createClient(info *execResponseStageInfo, useAccel
 }
 
 func (rsu *remoteStorageUtil) uploadOneFile(meta *fileMetadata) error {
-	utilClass := rsu.getNativeCloudType(meta.stageInfo.LocationType, meta.sfa.sc.cfg)
+	utilClass := rsu.getNativeCloudType(meta.stageInfo.LocationType, meta.sfa.storageConfig())
 	maxConcurrency := int(meta.parallel)
 	var lastErr error
 	maxRetry := defaultMaxRetry
 	for retry := 0; retry < maxRetry; retry++ {
-		if !meta.overwrite {
+		if retry > 0 {
+			meta.progress.retry(lastErr)
+		}
+		if meta.syncUpload() {
+			unchanged, err := isStageFileUnchanged(utilClass, meta)
+			if err != nil {
+				return err
+			}
+			if unchanged {
+				meta.dstFileSize = 0
+				meta.resStatus = skipped
+				return nil
+			}
+		} else if !meta.overwrite {
 			header, err := utilClass.getFileHeader(meta, meta.dstFileName)
 			if meta.resStatus == notFoundFile {
 				err := utilClass.uploadFile(meta.realSrcFileName, meta, maxConcurrency, meta.options.MultiPartThreshold)
 				if err != nil {
-					logger.Warnf("Error uploading %v. err: %v", meta.realSrcFileName, err)
+					getConnectionLogger(rsu.cfg).Warnf("Error uploading %v. err: %v", meta.realSrcFileName, err)
 				}
 			} else if err != nil {
 				return err
@@ -80,10 +106,10 @@ func (rsu *remoteStorageUtil) uploadOneFile(meta *fileMetadata) error {
 				return nil
 			}
 		}
-		if meta.overwrite || meta.resStatus == notFoundFile {
+		if meta.overwrite || meta.syncUpload() || meta.resStatus == notFoundFile {
 			err := utilClass.uploadFile(meta.realSrcFileName, meta, maxConcurrency, meta.options.MultiPartThreshold)
 			if err != nil {
-				logger.Debugf("Error uploading %v. err: %v", meta.realSrcFileName, err)
+				getConnectionLogger(rsu.cfg).Debugf("Error uploading %v. err: %v", meta.realSrcFileName, err)
 			}
 		}
 		if meta.resStatus == uploaded || meta.resStatus == renewToken || meta.resStatus == renewPresignedURL {
@@ -123,8 +149,9 @@ func (rsu *remoteStorageUtil) uploadOneFileWithRetry(meta *fileMetadata) error {
 		if meta.resStatus == uploaded || meta.resStatus == skipped {
 			for j := 0; j < 10; j++ {
 				status := meta.resStatus
-				if _, err := utilClass.getFileHeader(meta, meta.dstFileName); err != nil {
-					logger.Infof("error while getting file %v header. %v", meta.dstFileSize, err)
+				header, err := utilClass.getFileHeader(meta, meta.dstFileName)
+				if err != nil {
+					getConnectionLogger(rsu.cfg).Infof("error while getting file %v header. %v", meta.dstFileSize, err)
 				}
 				// check file header status and verify upload/skip
 				if meta.resStatus == notFoundFile {
@@ -133,6 +160,12 @@ func (rsu *remoteStorageUtil) uploadOneFileWithRetry(meta *fileMetadata) error {
 				} else {
 					retryInner = false
 					meta.resStatus = status
+					if status == uploaded && meta.verifyChecksums() {
+						if err = verifyUploadedChecksums(getConnectionLogger(rsu.cfg), meta, header); err != nil {
+							meta.resStatus = errStatus
+							return err
+						}
+					}
 					break
 				}
 			}
@@ -151,9 +184,55 @@ func (rsu *remoteStorageUtil) uploadOneFileWithRetry(meta *fileMetadata) error {
 	return nil
 }
 
+func (rsu *remoteStorageUtil) uploadOneStream(meta *fileMetadata, src io.Reader) error {
+	utilClass := rsu.getNativeCloudType(meta.stageInfo.LocationType, rsu.cfg)
+	// the digest of a large stream is known only once it is uploaded, so it is always uploaded by a sync
+	if !meta.overwrite && !meta.syncUpload() {
+		header, err := utilClass.getFileHeader(meta, meta.dstFileName)
+		if header != nil && meta.resStatus == uploaded {
+			meta.dstFileSize = 0
+			meta.resStatus = skipped
+			return nil
+		} else if err != nil && meta.resStatus != notFoundFile {
+			return err
+		}
+	}
+	streamUtil, ok := utilClass.(cloudStreamUtil)
+	if !ok {
+		// the digest has to be sent before the content, so the stream is written to a temporary file first
+		fileName, err := spoolUploadStream(meta, src)
+		if err != nil {
+			return err
+		}
+		meta.realSrcFileName = fileName
+		return rsu.uploadOneFileWithRetry(meta)
+	}
+	if err := streamUtil.uploadStream(meta, src, int(meta.parallel), meta.options.MultiPartThreshold); err != nil {
+		return err
+	}
+	if meta.resStatus == uploaded && meta.verifyChecksums() {
+		header, err := utilClass.getFileHeader(meta, meta.dstFileName)
+		if err != nil {
+			return err
+		}
+		if err = verifyUploadedChecksums(getConnectionLogger(rsu.cfg), meta, header); err != nil {
+			meta.resStatus = errStatus
+			return err
+		}
+	}
+	return nil
+}
+
 func (rsu *remoteStorageUtil) downloadOneFile(meta *fileMetadata) error {
-	fullDstFileName := path.Join(meta.localLocation, baseName(meta.dstFileName))
-	fullDstFileName, err := expandUser(fullDstFileName)
+	utilClass := rsu.getNativeCloudType(meta.stageInfo.LocationType, meta.sfa.storageConfig())
+	if streamUtil, ok := utilClass.(cloudDownloadStreamUtil); ok && meta.getFileWriter() != nil {
+		return rsu.downloadOneFileToWriter(meta, utilClass, streamUtil)
+	}
+	fullDstFileName, err := downloadFilePath(meta, meta.localLocation)
+	if err != nil {
+		return err
+	}
+	fullDstFileName, err = expandUser(fullDstFileName)
 	if err != nil {
 		return err
 	}
@@ -174,19 +253,22 @@ func (rsu *remoteStorageUtil) downloadOneFile(meta *fileMetadata) error {
 		}
 	}
 
-	utilClass := rsu.getNativeCloudType(meta.stageInfo.LocationType, meta.sfa.sc.cfg)
 	header, err := utilClass.getFileHeader(meta, meta.srcFileName)
 	if err != nil {
 		return err
 	}
 	if header != nil {
 		meta.srcFileSize = header.contentLength
+		meta.progress.setSize(header.contentLength)
 	}
 
 	maxConcurrency := meta.parallel
 	var lastErr error
 	maxRetry := defaultMaxRetry
 	for retry := 0; retry < maxRetry; retry++ {
+		if retry > 0 {
+			meta.progress.retry(lastErr)
+		}
 		if err = utilClass.nativeDownloadFile(meta, fullDstFileName, maxConcurrency); err != nil {
 			return err
 		}
@@ -223,6 +305,12 @@ func (rsu *remoteStorageUtil) downloadOneFile(meta *fileMetadata) error {
 					meta.dstFileSize = fi.Size()
 				}
 			}
+			if meta.verifyChecksums() {
+				if err = verifyDownloadedDigest(getConnectionLogger(rsu.cfg), meta, header, fullDstFileName); err != nil {
+					meta.resStatus = errStatus
+					return err
+				}
+			}
 			return nil
 		}
 		lastErr = meta.lastError
@@ -232,3 +320,44 @@ func (rsu *remoteStorageUtil) downloadOneFile(meta *fileMetadata) error {
 	}
 	return fmt.Errorf("unkown error downloading %v", fullDstFileName)
 }
+
+// downloadOneFileToWriter downloads the file as a stream, which is decrypted and written to the writer
+// returned by GetFileWriter as it is read.
+func (rsu *remoteStorageUtil) downloadOneFileToWriter(meta *fileMetadata, utilClass cloudUtil, streamUtil cloudDownloadStreamUtil) error {
+	header, err := utilClass.getFileHeader(meta, meta.srcFileName)
+	if err != nil {
+		return err
+	}
+	if header != nil {
+		meta.srcFileSize = header.contentLength
+		meta.progress.setSize(header.contentLength)
+	}
+	body, err := streamUtil.downloadStream(meta)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+	var src io.Reader = meta.progress.reader(body)
+	if meta.encryptionMaterial != nil {
+		if meta.presignedURL != nil {
+			if header, err = utilClass.getFileHeader(meta, meta.srcFileName); err != nil {
+				return err
+			}
+		}
+		if src, err = newDecryptingReaderCBC(header.encryptionMetadata, meta.encryptionMaterial, 0, src); err != nil {
+			return err
+		}
+	}
+	digest, err := writeDownloadStream(meta, src)
+	if err != nil {
+		return err
+	}
+	meta.resStatus = downloaded
+	if meta.verifyChecksums() {
+		if err = verifyDownloadedStreamDigest(getConnectionLogger(rsu.cfg), meta, header, digest); err != nil {
+			meta.resStatus = errStatus
+			return err
+		}
+	}
+	return nil
+}
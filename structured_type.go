@@ -239,15 +239,11 @@ func (sowc *structuredObjectWriterContext) WriteTime(fieldName string, value tim
 		return err
 	}
 	typ := driverTypeToSnowflake[snowflakeType]
-	sfFormat, err := dateTimeInputFormatByType(typ, sowc.params)
+	s, err := timeToString(value, typ, sowc.params)
 	if err != nil {
 		return err
 	}
-	goFormat, err := snowflakeFormatToGoFormat(sfFormat)
-	if err != nil {
-		return err
-	}
-	return sowc.writeTime(fieldName, value.Format(goFormat), typ)
+	return sowc.writeTime(fieldName, s, typ)
 }
 
 func (sowc *structuredObjectWriterContext) WriteNullTime(fieldName string, value sql.NullTime, tsmode []byte) error {
@@ -966,15 +962,7 @@ func (st *structuredType) GetNullTime(fieldName string) (sql.NullTime, error) {
 		if err != nil {
 			return sql.NullTime{}, err
 		}
-		format, err := dateTimeOutputFormatByType(fieldMetadata.Type, st.params)
-		if err != nil {
-			return sql.NullTime{}, err
-		}
-		goFormat, err := snowflakeFormatToGoFormat(format)
-		if err != nil {
-			return sql.NullTime{}, err
-		}
-		time, err := time.Parse(goFormat, s)
+		time, err := stringToTime(s, fieldMetadata.Type, st.params)
 		return sql.NullTime{Valid: true, Time: time}, err
 	}
 	time, _, err := getType[time.Time](st, fieldName, time.Time{})
@@ -1301,3 +1301,58 @@ func TestBindingMapWithNillableValues(t *testing.T) {
 		}
 	})
 }
+
+func TestBindingArrayOfStructuredTypes(t *testing.T) {
+	testBindingArrayOfStructuredTypes(t, false)
+}
+
+func TestBindingBulkArrayOfStructuredTypes(t *testing.T) {
+	if runningOnGithubAction() {
+		t.Skip("client_stage_array_binding_threshold value is internal")
+	}
+	testBindingArrayOfStructuredTypes(t, true)
+}
+
+func testBindingArrayOfStructuredTypes(t *testing.T, bulk bool) {
+	ctx := WithStructuredTypesEnabled(context.Background())
+	runDBTest(t, func(dbt *DBTest) {
+		dbt.enableStructuredTypesBinding()
+		dbt.mustExec("CREATE OR REPLACE TABLE test_structured_array_binding (id INTEGER, o OBJECT(s VARCHAR, i INTEGER), a ARRAY(INTEGER), m MAP(VARCHAR, BOOLEAN))")
+		defer func() {
+			dbt.mustExecT(t, "DROP TABLE IF EXISTS test_structured_array_binding")
+		}()
+		if bulk {
+			dbt.mustExecT(t, "ALTER SESSION SET CLIENT_STAGE_ARRAY_BINDING_THRESHOLD = 1")
+		}
+
+		ids := []int{1, 2}
+		objects := []*simpleObject{{s: "some string", i: 123}, nil}
+		arrays := [][]int64{{1, 2}, nil}
+		maps := []map[string]bool{{"a": true, "b": false}, nil}
+		dbt.mustExecT(t, "INSERT INTO test_structured_array_binding SELECT ?, ?, ?, ?", Array(&ids), Array(&objects), Array(&arrays), Array(&maps))
+
+		rows := dbt.mustQueryContextT(ctx, t, "SELECT o, a, m FROM test_structured_array_binding ORDER BY id")
+		defer rows.Close()
+
+		assertTrueF(t, rows.Next())
+		var o simpleObject
+		var a []int64
+		var m map[string]bool
+		err := rows.Scan(&o, &a, &m)
+		assertNilF(t, err)
+		assertDeepEqualE(t, &o, objects[0])
+		assertDeepEqualE(t, a, arrays[0])
+		assertDeepEqualE(t, m, maps[0])
+
+		assertTrueF(t, rows.Next())
+		var nullObject *simpleObject
+		var nullArray []int64
+		var nullMap map[string]bool
+		err = rows.Scan(&nullObject, &nullArray, &nullMap)
+		assertNilF(t, err)
+		assertNilE(t, nullObject)
+		assertNilE(t, nullArray)
+		assertNilE(t, nullMap)
+		assertFalseE(t, rows.Next())
+	})
+}
@@ -6,13 +6,16 @@ import (
 	"fmt"
 	"net/http"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
 const (
-	telemetryPath           = "/telemetry/send"
-	defaultTelemetryTimeout = 10 * time.Second
-	defaultFlushSize        = 100
+	telemetryPath                 = "/telemetry/send"
+	defaultTelemetryTimeout       = 10 * time.Second
+	defaultFlushSize              = 100
+	defaultTelemetryFlushInterval = 10 * time.Second
+	defaultTelemetryQueueSize     = 1000
 )
 
 const (
@@ -39,22 +42,94 @@ type telemetryData struct {
 	Message   map[string]string `json:"message,omitempty"`
 }
 
+// TelemetryEvent is an event reported by the driver to Snowflake telemetry.
+type TelemetryEvent struct {
+	Timestamp time.Time
+	Message   map[string]string
+}
+
+// TelemetrySink receives the telemetry events of a connection in addition to Snowflake, e.g. to write
+// them to a logger or to OpenTelemetry. Set it in Config.TelemetrySink.
+type TelemetrySink interface {
+	// Send is called from the background goroutine flushing the telemetry of a connection
+	// with the batch of events sent to Snowflake.
+	Send(events []TelemetryEvent)
+}
+
+// loggerTelemetrySink writes the telemetry events to a logger.
+type loggerTelemetrySink struct {
+	logger SFLogger
+}
+
+// NewLoggerTelemetrySink returns a TelemetrySink writing each telemetry event to the logger at debug level.
+func NewLoggerTelemetrySink(logger SFLogger) TelemetrySink {
+	return &loggerTelemetrySink{logger: logger}
+}
+
+func (s *loggerTelemetrySink) Send(events []TelemetryEvent) {
+	for _, event := range events {
+		s.logger.WithField("timestamp", event.Timestamp).Debugf("telemetry event: %v", event.Message)
+	}
+}
+
+// snowflakeTelemetry queues the telemetry events of a connection. Once started, a background
+// goroutine sends them to Snowflake every flush interval or when flushSize events are queued,
+// otherwise, or after close, addLog sends them synchronously. Events are dropped when the queue is full.
 type snowflakeTelemetry struct {
-	logs      []*telemetryData
-	flushSize int
-	sr        *snowflakeRestful
-	mutex     *sync.Mutex
-	enabled   bool
+	logs          []*telemetryData
+	flushSize     int
+	flushInterval time.Duration
+	maxQueueSize  int
+	sr            *snowflakeRestful
+	mutex         *sync.Mutex
+	enabled       bool
+	sink          TelemetrySink
+	recorder      MetricsRecorder
+	dropped       int64
+
+	// guarded by mutex, flushChan is set while the background goroutine runs
+	closed    bool
+	flushChan chan struct{}
+	stopChan  chan struct{}
+	doneChan  chan struct{}
+}
+
+// telemetryDropRecorder is implemented by MetricsRecorders counting the dropped telemetry events.
+type telemetryDropRecorder interface {
+	RecordTelemetryDropped(count int)
+}
+
+func (st *snowflakeTelemetry) isEnabled() bool {
+	if st.mutex == nil {
+		return st.enabled
+	}
+	st.mutex.Lock()
+	defer st.mutex.Unlock()
+	return st.enabled
+}
+
+// disable stops sending telemetry after Snowflake rejected it, the queued events are dropped.
+func (st *snowflakeTelemetry) disable(dropped int) {
+	st.mutex.Lock()
+	st.enabled = false
+	st.mutex.Unlock()
+	st.drop(dropped)
 }
 
 func (st *snowflakeTelemetry) addLog(data *telemetryData) error {
-	if !st.enabled {
+	if !st.isEnabled() {
 		return fmt.Errorf("telemetry disabled; not adding log")
 	}
 	st.mutex.Lock()
+	if st.maxQueueSize > 0 && len(st.logs) >= st.maxQueueSize {
+		st.mutex.Unlock()
+		st.drop(1)
+		return nil
+	}
 	st.logs = append(st.logs, data)
+	queued := len(st.logs)
 	st.mutex.Unlock()
-	if len(st.logs) >= st.flushSize {
+	if queued >= st.flushSize && !st.requestFlush() {
 		if err := st.sendBatch(); err != nil {
 			return err
 		}
@@ -62,10 +137,107 @@ func (st *snowflakeTelemetry) addLog(data *telemetryData) error {
 	return nil
 }
 
+// drop counts telemetry events which are not sent to Snowflake.
+func (st *snowflakeTelemetry) drop(count int) {
+	atomic.AddInt64(&st.dropped, int64(count))
+	if recorder, ok := st.recorder.(telemetryDropRecorder); ok {
+		recorder.RecordTelemetryDropped(count)
+	}
+}
+
+// droppedCount returns the number of telemetry events which were not sent to Snowflake.
+func (st *snowflakeTelemetry) droppedCount() int64 {
+	return atomic.LoadInt64(&st.dropped)
+}
+
+// start starts the background goroutine flushing the telemetry, unless the telemetry is closed.
+func (st *snowflakeTelemetry) start() {
+	if !st.isEnabled() {
+		return
+	}
+	st.mutex.Lock()
+	if st.closed || st.flushChan != nil {
+		st.mutex.Unlock()
+		return
+	}
+	flushChan := make(chan struct{}, 1)
+	stopChan := make(chan struct{})
+	doneChan := make(chan struct{})
+	st.flushChan, st.stopChan, st.doneChan = flushChan, stopChan, doneChan
+	st.mutex.Unlock()
+	flushInterval := st.flushInterval
+	if flushInterval <= 0 {
+		flushInterval = defaultTelemetryFlushInterval
+	}
+	go func() {
+		defer close(doneChan)
+		ticker := time.NewTicker(flushInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+			case <-flushChan:
+			case <-stopChan:
+				return
+			}
+			if err := st.sendBatch(); err != nil {
+				st.sr.getLogger().Debugf("failed to flush telemetry. err: %v", err)
+			}
+		}
+	}()
+}
+
+// requestFlush makes the background goroutine send the queued telemetry without waiting for the flush interval.
+// It returns false if the goroutine does not run, so the caller has to send the telemetry.
+func (st *snowflakeTelemetry) requestFlush() bool {
+	if st.mutex == nil {
+		return false
+	}
+	st.mutex.Lock()
+	defer st.mutex.Unlock()
+	if st.flushChan == nil {
+		return false
+	}
+	select {
+	case st.flushChan <- struct{}{}:
+	default:
+		// a flush is already pending
+	}
+	return true
+}
+
+// close stops the background goroutine and sends the queued telemetry. The telemetry added later is sent synchronously.
+func (st *snowflakeTelemetry) close() error {
+	if st.mutex != nil {
+		st.mutex.Lock()
+		st.closed = true
+		stopChan, doneChan := st.stopChan, st.doneChan
+		st.flushChan, st.stopChan, st.doneChan = nil, nil, nil
+		st.mutex.Unlock()
+		if stopChan != nil {
+			close(stopChan)
+			<-doneChan
+		}
+	}
+	err := st.sendBatch()
+	if dropped := st.droppedCount(); dropped > 0 {
+		st.sr.getLogger().Debugf("%v telemetry events were dropped", dropped)
+	}
+	return err
+}
+
+func telemetryEvents(logs []*telemetryData) []TelemetryEvent {
+	events := make([]TelemetryEvent, len(logs))
+	for i, data := range logs {
+		events[i] = TelemetryEvent{Timestamp: time.UnixMilli(data.Timestamp), Message: data.Message}
+	}
+	return events
+}
+
 func (st *snowflakeTelemetry) sendBatch() error {
-	if !st.enabled {
+	if !st.isEnabled() {
 		err := fmt.Errorf("telemetry disabled; not sending log")
-		logger.Debug(err)
+		st.sr.getLogger().Debug(err)
 		return err
 	}
 	type telemetry struct {
@@ -78,16 +250,19 @@ func (st *snowflakeTelemetry) sendBatch() error {
 	st.mutex.Unlock()
 
 	if len(logsToSend) == 0 {
-		logger.Debug("nothing to send to telemetry")
+		st.sr.getLogger().Debug("nothing to send to telemetry")
 		return nil
 	}
+	if st.sink != nil {
+		st.sink.Send(telemetryEvents(logsToSend))
+	}
 
 	s := &telemetry{logsToSend}
 	body, err := json.Marshal(s)
 	if err != nil {
 		return err
 	}
-	logger.Debugf("sending %v logs to telemetry. inband telemetry payload "+
+	st.sr.getLogger().Debugf("sending %v logs to telemetry. inband telemetry payload "+
 		"being sent: %v", len(logsToSend), string(body))
 
 	headers := getHeaders()
@@ -98,30 +273,31 @@ func (st *snowflakeTelemetry) sendBatch() error {
 		st.sr.getFullURL(telemetryPath, nil), headers, body,
 		defaultTelemetryTimeout, defaultTimeProvider, nil)
 	if err != nil {
-		logger.Info("failed to upload metrics to telemetry. err: %v", err)
+		st.sr.getLogger().Info("failed to upload metrics to telemetry. err: %v", err)
+		st.drop(len(logsToSend))
 		return err
 	}
 	defer resp.Body.Close()
 	if resp.StatusCode != http.StatusOK {
 		err = fmt.Errorf("non-successful response from telemetry server: %v. "+
 			"disabling telemetry", resp.StatusCode)
-		logger.Info(err)
-		st.enabled = false
+		st.sr.getLogger().Info(err)
+		st.disable(len(logsToSend))
 		return err
 	}
 	var respd telemetryResponse
 	if err = json.NewDecoder(resp.Body).Decode(&respd); err != nil {
-		logger.Info(err)
-		st.enabled = false
+		st.sr.getLogger().Info(err)
+		st.disable(len(logsToSend))
 		return err
 	}
 	if !respd.Success {
 		err = fmt.Errorf("telemetry send failed with error code: %v, message: %v",
 			respd.Code, respd.Message)
-		logger.Info(err)
-		st.enabled = false
+		st.sr.getLogger().Info(err)
+		st.disable(len(logsToSend))
 		return err
 	}
-	logger.Debug("successfully uploaded metrics to telemetry")
+	st.sr.getLogger().Debug("successfully uploaded metrics to telemetry")
 	return nil
 }
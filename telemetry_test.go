@@ -1,12 +1,17 @@
 package gosnowflake
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
+	"io"
 	"math/rand"
 	"net/http"
 	"net/url"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 )
@@ -269,3 +274,160 @@ func TestAddLogError(t *testing.T) {
 		}
 	})
 }
+
+type testTelemetrySink struct {
+	mu     sync.Mutex
+	events []TelemetryEvent
+}
+
+func (s *testTelemetrySink) Send(events []TelemetryEvent) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.events = append(s.events, events...)
+}
+
+func (s *testTelemetrySink) count() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.events)
+}
+
+type testTelemetryDropRecorder struct {
+	noopMetricsRecorder
+	dropped int64
+}
+
+func (r *testTelemetryDropRecorder) RecordTelemetryDropped(count int) {
+	atomic.AddInt64(&r.dropped, int64(count))
+}
+
+func newTestTelemetry(sink TelemetrySink, sent *int64) *snowflakeTelemetry {
+	return &snowflakeTelemetry{
+		sr: &snowflakeRestful{
+			FuncPost: func(_ context.Context, _ *snowflakeRestful, _ *url.URL, _ map[string]string, body []byte, _ time.Duration, _ currentTimeProvider, _ *Config) (*http.Response, error) {
+				var payload struct {
+					Logs []*telemetryData `json:"logs"`
+				}
+				if err := json.Unmarshal(body, &payload); err != nil {
+					return nil, err
+				}
+				atomic.AddInt64(sent, int64(len(payload.Logs)))
+				return &http.Response{
+					StatusCode: http.StatusOK,
+					Body:       io.NopCloser(strings.NewReader(`{"success":true}`)),
+				}, nil
+			},
+			TokenAccessor: getSimpleTokenAccessor(),
+		},
+		mutex:     &sync.Mutex{},
+		enabled:   true,
+		flushSize: defaultFlushSize,
+		sink:      sink,
+	}
+}
+
+func newTestTelemetryData() *telemetryData {
+	return &telemetryData{
+		Message: map[string]string{
+			typeKey:    "client_telemetry_type",
+			queryIDKey: "123",
+		},
+		Timestamp: time.Now().UnixNano() / int64(time.Millisecond),
+	}
+}
+
+func TestTelemetryFlushInterval(t *testing.T) {
+	var sent int64
+	sink := &testTelemetrySink{}
+	st := newTestTelemetry(sink, &sent)
+	st.flushInterval = 10 * time.Millisecond
+	st.start()
+	defer st.close()
+
+	assertNilF(t, st.addLog(newTestTelemetryData()))
+	assertNilF(t, st.addLog(newTestTelemetryData()))
+	assertTrueF(t, waitForCondition(func() bool { return atomic.LoadInt64(&sent) == 2 }, time.Second), "telemetry was not flushed")
+	assertEqualE(t, sink.count(), 2)
+	assertEqualE(t, sink.events[0].Message[queryIDKey], "123")
+}
+
+func TestTelemetryFlushSizeDoesNotBlock(t *testing.T) {
+	var sent int64
+	st := newTestTelemetry(nil, &sent)
+	st.flushSize = 2
+	st.flushInterval = time.Hour
+	st.start()
+	defer st.close()
+
+	for i := 0; i < 3; i++ {
+		assertNilF(t, st.addLog(newTestTelemetryData()))
+	}
+	assertTrueF(t, waitForCondition(func() bool { return atomic.LoadInt64(&sent) >= 2 }, time.Second), "telemetry was not flushed")
+}
+
+func TestTelemetryCloseFlushes(t *testing.T) {
+	var sent int64
+	st := newTestTelemetry(nil, &sent)
+	st.flushInterval = time.Hour
+	st.start()
+
+	assertNilF(t, st.addLog(newTestTelemetryData()))
+	assertEqualE(t, atomic.LoadInt64(&sent), int64(0))
+	assertNilF(t, st.close())
+	assertEqualE(t, atomic.LoadInt64(&sent), int64(1))
+	assertEqualE(t, len(st.logs), 0)
+}
+
+func TestTelemetryAddLogAfterClose(t *testing.T) {
+	var sent int64
+	st := newTestTelemetry(nil, &sent)
+	st.flushSize = 1
+	st.flushInterval = time.Hour
+	st.start()
+	assertNilF(t, st.close())
+
+	st.start()
+	assertTrueE(t, st.flushChan == nil, "closed telemetry should not be restarted")
+	assertNilF(t, st.addLog(newTestTelemetryData()))
+	assertEqualE(t, atomic.LoadInt64(&sent), int64(1), "telemetry added after close should be sent synchronously")
+	assertEqualE(t, len(st.logs), 0)
+}
+
+func TestTelemetryQueueDropsEvents(t *testing.T) {
+	var sent int64
+	recorder := &testTelemetryDropRecorder{}
+	st := newTestTelemetry(nil, &sent)
+	st.maxQueueSize = 2
+	st.recorder = recorder
+
+	for i := 0; i < 5; i++ {
+		assertNilF(t, st.addLog(newTestTelemetryData()))
+	}
+	assertEqualE(t, len(st.logs), 2)
+	assertEqualE(t, st.droppedCount(), int64(3))
+	assertEqualE(t, atomic.LoadInt64(&recorder.dropped), int64(3))
+
+	st.sr.FuncPost = funcPostTelemetryRespFail
+	assertNotNilE(t, st.close())
+	assertEqualE(t, st.droppedCount(), int64(5))
+}
+
+func TestLoggerTelemetrySink(t *testing.T) {
+	sinkLogger := CreateDefaultLogger()
+	assertNilF(t, sinkLogger.SetLogLevel("debug"))
+	buf := &bytes.Buffer{}
+	sinkLogger.SetOutput(buf)
+	NewLoggerTelemetrySink(sinkLogger).Send(telemetryEvents([]*telemetryData{newTestTelemetryData()}))
+	assertStringContainsE(t, buf.String(), "telemetry event: map[QueryID:123 type:client_telemetry_type]")
+}
+
+func waitForCondition(condition func() bool, timeout time.Duration) bool {
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		if condition() {
+			return true
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	return condition()
+}
@@ -0,0 +1,63 @@
+package gosnowflake
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+const tracerName = "github.com/snowflakedb/gosnowflake"
+
+// names of the spans created by the driver
+const (
+	spanLogin        = "snowflake.login"
+	spanQuery        = "snowflake.query"
+	spanAsyncQuery   = "snowflake.async_query"
+	spanChunk        = "snowflake.chunk_download"
+	spanHTTPRequest  = "snowflake.http_request"
+	spanFileUpload   = "snowflake.file_upload"
+	spanFileDownload = "snowflake.file_download"
+)
+
+// attributes of the spans created by the driver
+const (
+	attrAuthenticator  = attribute.Key("snowflake.authenticator")
+	attrQueryID        = attribute.Key("snowflake.query_id")
+	attrRequestID      = attribute.Key("snowflake.request_id")
+	attrRetryCount     = attribute.Key("snowflake.retry_count")
+	attrRetryReason    = attribute.Key("retry.reason")
+	attrChunkIndex     = attribute.Key("snowflake.chunk_index")
+	attrChunkRows      = attribute.Key("snowflake.chunk_rows")
+	attrBytes          = attribute.Key("snowflake.bytes")
+	attrFileName       = attribute.Key("snowflake.file_name")
+	attrFileStatus     = attribute.Key("snowflake.file_status")
+	attrStageLocation  = attribute.Key("snowflake.stage_location_type")
+	attrHTTPMethod     = attribute.Key("http.request.method")
+	attrHTTPURLPath    = attribute.Key("url.path")
+	attrHTTPStatusCode = attribute.Key("http.response.status_code")
+)
+
+// startSpan starts a span of the driver as a child of the span of ctx if the TracerProvider of cfg is set.
+// Otherwise ctx is returned with a span which records nothing.
+func startSpan(ctx context.Context, cfg *Config, name string, kind trace.SpanKind, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	if cfg == nil || cfg.TracerProvider == nil {
+		return ctx, noop.Span{}
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	tracer := cfg.TracerProvider.Tracer(tracerName, trace.WithInstrumentationVersion(SnowflakeGoDriverVersion))
+	return tracer.Start(ctx, name, trace.WithSpanKind(kind), trace.WithAttributes(attrs...))
+}
+
+// endSpan ends the span and records err as its error status.
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
@@ -0,0 +1,175 @@
+package gosnowflake
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+// testTracerProvider records the spans started by the driver.
+type testTracerProvider struct {
+	noop.TracerProvider
+	mu    sync.Mutex
+	spans []*testSpan
+}
+
+func (tp *testTracerProvider) Tracer(string, ...trace.TracerOption) trace.Tracer {
+	return &testTracer{tp: tp}
+}
+
+func (tp *testTracerProvider) span(name string) *testSpan {
+	tp.mu.Lock()
+	defer tp.mu.Unlock()
+	for _, span := range tp.spans {
+		if span.name == name {
+			return span
+		}
+	}
+	return nil
+}
+
+type testTracer struct {
+	noop.Tracer
+	tp *testTracerProvider
+}
+
+func (t *testTracer) Start(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	span := &testSpan{
+		name:   name,
+		parent: trace.SpanFromContext(ctx),
+		attrs:  make(map[attribute.Key]attribute.Value),
+	}
+	cfg := trace.NewSpanStartConfig(opts...)
+	for _, attr := range cfg.Attributes() {
+		span.attrs[attr.Key] = attr.Value
+	}
+	t.tp.mu.Lock()
+	t.tp.spans = append(t.tp.spans, span)
+	t.tp.mu.Unlock()
+	return trace.ContextWithSpan(ctx, span), span
+}
+
+type testSpan struct {
+	noop.Span
+	name   string
+	parent trace.Span
+	attrs  map[attribute.Key]attribute.Value
+	status codes.Code
+	ended  bool
+	events []map[attribute.Key]attribute.Value
+}
+
+func (s *testSpan) AddEvent(_ string, opts ...trace.EventOption) {
+	attrs := make(map[attribute.Key]attribute.Value)
+	cfg := trace.NewEventConfig(opts...)
+	for _, attr := range cfg.Attributes() {
+		attrs[attr.Key] = attr.Value
+	}
+	s.events = append(s.events, attrs)
+}
+
+func (s *testSpan) SetAttributes(attrs ...attribute.KeyValue) {
+	for _, attr := range attrs {
+		s.attrs[attr.Key] = attr.Value
+	}
+}
+
+func (s *testSpan) SetStatus(code codes.Code, _ string) {
+	s.status = code
+}
+
+func (s *testSpan) End(...trace.SpanEndOption) {
+	s.ended = true
+}
+
+func TestStartSpanWithoutTracerProvider(t *testing.T) {
+	ctx := context.Background()
+	spanCtx, span := startSpan(ctx, &Config{}, spanQuery, trace.SpanKindClient)
+	assertEqualE(t, spanCtx, ctx)
+	assertFalseE(t, span.IsRecording())
+	endSpan(span, nil)
+}
+
+func TestTraceHTTPRetries(t *testing.T) {
+	tp := &testTracerProvider{}
+	appCtx, appSpan := (&testTracer{tp: tp}).Start(context.Background(), "application")
+	client := &fakeHTTPClient{
+		cnt:        3,
+		success:    true,
+		statusCode: 429,
+		t:          t,
+	}
+	urlPtr, err := url.Parse("https://fakeaccountretrysuccess.snowflakecomputing.com:443/queries/v1/query-request?" + requestIDKey + "=testid")
+	assertNilF(t, err)
+	_, err = newRetryHTTP(appCtx, client, emptyRequest, urlPtr, make(map[string]string), 60*time.Second, 3,
+		constTimeProvider(123456), &Config{TracerProvider: tp}).doPost().setBody([]byte{0}).execute()
+	assertNilF(t, err)
+
+	span := tp.span(spanHTTPRequest)
+	assertNotNilF(t, span)
+	assertTrueE(t, span.ended)
+	assertEqualE(t, span.parent, trace.Span(appSpan), "driver span should be a child of the application span")
+	assertEqualE(t, span.attrs[attrHTTPURLPath].AsString(), "/queries/v1/query-request")
+	assertEqualE(t, span.attrs[attrRetryCount].AsInt64(), int64(2))
+	assertEqualE(t, span.attrs[attrHTTPStatusCode].AsInt64(), int64(200))
+	assertEqualF(t, len(span.events), 2)
+	assertEqualE(t, span.events[0][attrRetryReason].AsInt64(), int64(429))
+	assertEqualE(t, span.events[0][attrHTTPStatusCode].AsInt64(), int64(429))
+}
+
+func TestTraceHTTPRetryAfterConnectionError(t *testing.T) {
+	tp := &testTracerProvider{}
+	client := &fakeHTTPClient{
+		cnt:     2,
+		success: true,
+		timeout: true,
+		t:       t,
+	}
+	urlPtr, err := url.Parse("https://fakeaccountretrysuccess.snowflakecomputing.com:443/queries/v1/query-request?" + requestIDKey + "=testid")
+	assertNilF(t, err)
+	_, err = newRetryHTTP(context.Background(), client, emptyRequest, urlPtr, make(map[string]string), 60*time.Second, 3,
+		constTimeProvider(123456), &Config{TracerProvider: tp}).doPost().setBody([]byte{0}).execute()
+	assertNilF(t, err)
+
+	span := tp.span(spanHTTPRequest)
+	assertNotNilF(t, span)
+	assertEqualF(t, len(span.events), 1)
+	assertEqualE(t, span.events[0][attrRetryReason].AsInt64(), int64(0))
+	_, ok := span.events[0][attrHTTPStatusCode]
+	assertFalseE(t, ok, "a connection error has no status code")
+}
+
+func TestTraceChunkDownloadError(t *testing.T) {
+	tp := &testTracerProvider{}
+	cm := make([]execResponseChunk, 0)
+	for i := 0; i < 2; i++ {
+		cm = append(cm, execResponseChunk{URL: fmt.Sprintf("dummyURL%v", i+1), RowCount: rowsInChunk})
+	}
+	scd := &snowflakeChunkDownloader{
+		sc: &snowflakeConn{
+			cfg:  &Config{TracerProvider: tp},
+			rest: &snowflakeRestful{RequestTimeout: defaultRequestTimeout},
+		},
+		ctx:        context.Background(),
+		ChunkMetas: cm,
+		FuncGet:    getChunkTestErrorStatus,
+	}
+	err := downloadChunkHelper(scd.ctx, scd, 1)
+	assertNotNilF(t, err)
+
+	span := tp.span(spanChunk)
+	assertNotNilF(t, span)
+	assertTrueE(t, span.ended)
+	assertEqualE(t, span.status, codes.Error)
+	assertEqualE(t, span.attrs[attrChunkIndex].AsInt64(), int64(1))
+	assertEqualE(t, span.attrs[attrChunkRows].AsInt64(), int64(rowsInChunk))
+	assertEqualE(t, span.attrs[attrBytes].AsInt64(), int64(2))
+}
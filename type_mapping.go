@@ -0,0 +1,125 @@
+package gosnowflake
+
+import (
+	"context"
+	"database/sql/driver"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// TypeMappingColumn describes the result column a TypeConverter is applied to.
+type TypeMappingColumn struct {
+	Name      string
+	Type      string // database type name, as returned by ColumnTypeDatabaseTypeName, e.g. FIXED or TIMESTAMP_NTZ
+	Precision int64
+	Scale     int64
+	Length    int64
+	Nullable  bool
+}
+
+// TypeConverter converts values of a Snowflake type to a custom Go type.
+type TypeConverter struct {
+	// ScanType is returned by ColumnTypeScanType for columns handled by this converter.
+	ScanType reflect.Type
+	// Convert receives the value the driver would return without the converter, e.g. string for FIXED in JSON results,
+	// time.Time for TIMESTAMP_NTZ or []byte for BINARY. It is not called for NULL values.
+	Convert func(value driver.Value, column TypeMappingColumn) (driver.Value, error)
+}
+
+type typeMappingKey struct {
+	typ       snowflakeType
+	precision int64
+	scale     int64
+	exact     bool
+}
+
+// TypeMappingRegistry holds TypeConverters used to convert query results.
+// It can be set per connection with Config.TypeMappings or per query with WithTypeMappings.
+// A registry is safe for concurrent use.
+type TypeMappingRegistry struct {
+	mu         sync.RWMutex
+	converters map[typeMappingKey]*TypeConverter
+}
+
+// NewTypeMappingRegistry creates an empty TypeMappingRegistry.
+func NewTypeMappingRegistry() *TypeMappingRegistry {
+	return &TypeMappingRegistry{
+		converters: make(map[typeMappingKey]*TypeConverter),
+	}
+}
+
+// Register sets the converter for all columns of the given Snowflake type, e.g. FIXED, TIMESTAMP_NTZ or BINARY.
+func (r *TypeMappingRegistry) Register(sfType string, converter TypeConverter) error {
+	typ, err := typeMappingSnowflakeType(sfType)
+	if err != nil {
+		return err
+	}
+	return r.register(typeMappingKey{typ: typ}, converter)
+}
+
+// RegisterWithPrecisionScale sets the converter for columns of the given Snowflake type with exactly this precision and scale,
+// e.g. FIXED with precision 38 and scale 0. It takes priority over converters registered with Register.
+// For TIME and TIMESTAMP types the fractional seconds precision is reported as the scale and the precision is 0.
+func (r *TypeMappingRegistry) RegisterWithPrecisionScale(sfType string, precision int64, scale int64, converter TypeConverter) error {
+	typ, err := typeMappingSnowflakeType(sfType)
+	if err != nil {
+		return err
+	}
+	return r.register(typeMappingKey{typ: typ, precision: precision, scale: scale, exact: true}, converter)
+}
+
+func (r *TypeMappingRegistry) register(key typeMappingKey, converter TypeConverter) error {
+	if converter.Convert == nil || converter.ScanType == nil {
+		return errTypeConverterIncomplete()
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.converters[key] = &converter
+	return nil
+}
+
+func (r *TypeMappingRegistry) lookup(column TypeMappingColumn) *TypeConverter {
+	if r == nil {
+		return nil
+	}
+	typ := getSnowflakeType(column.Type)
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if converter, ok := r.converters[typeMappingKey{typ: typ, precision: column.Precision, scale: column.Scale, exact: true}]; ok {
+		return converter
+	}
+	return r.converters[typeMappingKey{typ: typ}]
+}
+
+func typeMappingSnowflakeType(sfType string) (snowflakeType, error) {
+	typ, ok := snowflakeToDriverType[strings.ToUpper(sfType)]
+	if !ok || typ >= nullType {
+		return unSupportedType, errUnsupportedTypeMapping(sfType)
+	}
+	return typ, nil
+}
+
+func newTypeMappingColumn(rowType execResponseRowType) TypeMappingColumn {
+	return TypeMappingColumn{
+		Name:      rowType.Name,
+		Type:      strings.ToUpper(rowType.Type),
+		Precision: rowType.Precision,
+		Scale:     rowType.Scale,
+		Length:    rowType.Length,
+		Nullable:  rowType.Nullable,
+	}
+}
+
+// getTypeMappingRegistry returns the registry set in the context, falling back to the connection one.
+func getTypeMappingRegistry(ctx context.Context, sc *snowflakeConn) *TypeMappingRegistry {
+	if ctx != nil {
+		if registry, ok := ctx.Value(typeMappings).(*TypeMappingRegistry); ok && registry != nil {
+			return registry
+		}
+	}
+	if sc != nil && sc.cfg != nil {
+		return sc.cfg.TypeMappings
+	}
+	return nil
+}
@@ -0,0 +1,141 @@
+package gosnowflake
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/hex"
+	"io"
+	"math/big"
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+func newTypeMappingTestRows(ctx context.Context, cfg *Config) *snowflakeRows {
+	v1 := "12345678901234567890"
+	v2 := "42"
+	v3 := "abcd"
+	rt := []execResponseRowType{
+		{Name: "big", Type: "fixed", Precision: 38, Scale: 0, Nullable: true},
+		{Name: "small", Type: "fixed", Precision: 10, Scale: 0},
+		{Name: "bin", Type: "binary", Length: 2},
+	}
+	sc := &snowflakeConn{cfg: cfg}
+	rows := &snowflakeRows{sc: sc, ctx: ctx}
+	rows.ChunkDownloader = &snowflakeChunkDownloader{
+		sc:            sc,
+		ctx:           ctx,
+		Total:         2,
+		ChunkMetas:    []execResponseChunk{},
+		TotalRowIndex: int64(-1),
+		RowSet: rowSetType{RowType: rt, JSON: [][]*string{
+			{&v1, &v2, &v3},
+			{nil, &v2, &v3},
+		}},
+		QueryResultFormat: "json",
+	}
+	return rows
+}
+
+func newTestTypeMappingRegistry(t *testing.T) *TypeMappingRegistry {
+	registry := NewTypeMappingRegistry()
+	assertNilF(t, registry.RegisterWithPrecisionScale("FIXED", 38, 0, TypeConverter{
+		ScanType: reflect.TypeOf(&big.Int{}),
+		Convert: func(value driver.Value, column TypeMappingColumn) (driver.Value, error) {
+			bigInt, ok := new(big.Int).SetString(value.(string), 10)
+			if !ok {
+				return nil, io.ErrUnexpectedEOF
+			}
+			return bigInt, nil
+		},
+	}))
+	assertNilF(t, registry.Register("fixed", TypeConverter{
+		ScanType: reflect.TypeOf(int64(0)),
+		Convert: func(value driver.Value, column TypeMappingColumn) (driver.Value, error) {
+			return strconv.ParseInt(value.(string), 10, 64)
+		},
+	}))
+	assertNilF(t, registry.Register("BINARY", TypeConverter{
+		ScanType: reflect.TypeOf(""),
+		Convert: func(value driver.Value, column TypeMappingColumn) (driver.Value, error) {
+			return hex.EncodeToString(value.([]byte)), nil
+		},
+	}))
+	return registry
+}
+
+func TestTypeMappingRegistryRegister(t *testing.T) {
+	registry := NewTypeMappingRegistry()
+	err := registry.Register("VARCHAR2", TypeConverter{ScanType: reflect.TypeOf(""), Convert: func(v driver.Value, _ TypeMappingColumn) (driver.Value, error) { return v, nil }})
+	assertNotNilF(t, err)
+	assertEqualE(t, err.(*SnowflakeError).Number, ErrInvalidTypeMapping)
+
+	err = registry.Register("TEXT", TypeConverter{ScanType: reflect.TypeOf("")})
+	assertNotNilF(t, err)
+	assertEqualE(t, err.(*SnowflakeError).Number, ErrInvalidTypeMapping)
+
+	var nilRegistry *TypeMappingRegistry
+	assertNilE(t, nilRegistry.lookup(TypeMappingColumn{Type: "TEXT"}))
+}
+
+func TestTypeMappingRegistryPerConnection(t *testing.T) {
+	rows := newTypeMappingTestRows(context.Background(), &Config{
+		Params:       make(map[string]*string),
+		TypeMappings: newTestTypeMappingRegistry(t),
+	})
+	assertNilF(t, rows.ChunkDownloader.start())
+
+	assertEqualE(t, rows.ColumnTypeScanType(0), reflect.TypeOf(&big.Int{}))
+	assertEqualE(t, rows.ColumnTypeScanType(1), reflect.TypeOf(int64(0)))
+	assertEqualE(t, rows.ColumnTypeScanType(2), reflect.TypeOf(""))
+
+	dest := make([]driver.Value, 3)
+	assertNilF(t, rows.Next(dest))
+	expected, _ := new(big.Int).SetString("12345678901234567890", 10)
+	assertEqualE(t, dest[0].(*big.Int).Cmp(expected), 0)
+	assertEqualE(t, dest[1], int64(42))
+	assertEqualE(t, dest[2], "abcd")
+
+	assertNilF(t, rows.Next(dest))
+	assertNilE(t, dest[0], "converters should not be called for NULL values")
+	assertEqualE(t, rows.Next(dest), io.EOF)
+}
+
+func TestTypeMappingRegistryPerQuery(t *testing.T) {
+	connectionRegistry := newTestTypeMappingRegistry(t)
+	queryRegistry := NewTypeMappingRegistry()
+	assertNilF(t, queryRegistry.Register("FIXED", TypeConverter{
+		ScanType: reflect.TypeOf(float64(0)),
+		Convert: func(value driver.Value, column TypeMappingColumn) (driver.Value, error) {
+			return strconv.ParseFloat(value.(string), 64)
+		},
+	}))
+	ctx := WithTypeMappings(context.Background(), queryRegistry)
+	rows := newTypeMappingTestRows(ctx, &Config{
+		Params:       make(map[string]*string),
+		TypeMappings: connectionRegistry,
+	})
+	assertNilF(t, rows.ChunkDownloader.start())
+
+	assertEqualE(t, rows.ColumnTypeScanType(0), reflect.TypeOf(float64(0)))
+	assertEqualE(t, rows.ColumnTypeScanType(2), reflect.TypeOf([]byte{}), "connection registry should not be used")
+
+	dest := make([]driver.Value, 3)
+	assertNilF(t, rows.Next(dest))
+	assertEqualE(t, dest[1], float64(42))
+	assertDeepEqualE(t, dest[2], []byte{0xab, 0xcd})
+}
+
+func TestTypeMappingConverterError(t *testing.T) {
+	registry := NewTypeMappingRegistry()
+	assertNilF(t, registry.Register("FIXED", TypeConverter{
+		ScanType: reflect.TypeOf(int8(0)),
+		Convert: func(value driver.Value, column TypeMappingColumn) (driver.Value, error) {
+			return strconv.ParseInt(value.(string), 10, 8)
+		},
+	}))
+	rows := newTypeMappingTestRows(WithTypeMappings(context.Background(), registry), &Config{Params: make(map[string]*string)})
+	assertNilF(t, rows.ChunkDownloader.start())
+	dest := make([]driver.Value, 3)
+	assertNotNilE(t, rows.Next(dest))
+}
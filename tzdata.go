@@ -0,0 +1,7 @@
+//go:build sftzdata
+
+package gosnowflake
+
+// Embeds a copy of the IANA time zone database, so session TIMEZONE values can be resolved
+// on systems without zoneinfo files (e.g. scratch or Windows containers).
+import _ "time/tzdata"
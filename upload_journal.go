@@ -0,0 +1,365 @@
+package gosnowflake
+
+import (
+	"cmp"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	uploadJournalDirName  = "snowflake_upload_journal"
+	uploadJournalFileMode = 0600
+	uploadJournalDirMode  = 0700
+	uploadJournalSuffix   = ".json"
+)
+
+// uploadJournalPart is a part (S3) or block (Azure) which was uploaded successfully.
+type uploadJournalPart struct {
+	Number  int32  `json:"number"`
+	Size    int64  `json:"size"`
+	ETag    string `json:"etag,omitempty"`
+	BlockID string `json:"blockId,omitempty"`
+}
+
+// uploadJournal is the state of a multipart upload persisted under TmpDirPath.
+// It allows resuming an upload in a retry or in a subsequent PUT of the same file to the same location.
+// The journal is only valid for the same content, so the digest and size of the uploaded data are stored,
+// together with the wrapped file key, so that the content can be encrypted to the same bytes again.
+type uploadJournal struct {
+	path string
+	cfg  *Config
+	mu   sync.Mutex
+
+	Target     string              `json:"target"`
+	Stage      string              `json:"stage"`    // location type and location of the stage
+	FileName   string              `json:"fileName"` // name of the file in the stage
+	Digest     string              `json:"digest"`
+	UploadSize int64               `json:"uploadSize"`
+	SMKID      int64               `json:"smkId,omitempty"`
+	Key        string              `json:"key,omitempty"`
+	Iv         string              `json:"iv,omitempty"`
+	Matdesc    string              `json:"matdesc,omitempty"`
+	UploadID   string              `json:"uploadId,omitempty"`
+	PartSize   int64               `json:"partSize,omitempty"`
+	Parts      []uploadJournalPart `json:"parts,omitempty"`
+}
+
+// getUploadJournalRetention returns how long the journal of an unfinished upload is kept.
+// Resumable uploads are disabled unless the retention is positive.
+func getUploadJournalRetention(cfg *Config) time.Duration {
+	if cfg == nil {
+		return 0
+	}
+	return cfg.UploadJournalRetention
+}
+
+func getUploadJournalDir(cfg *Config) string {
+	tmpDir := ""
+	if cfg != nil {
+		tmpDir = cfg.TmpDirPath
+	}
+	return filepath.Join(cmp.Or(tmpDir, os.TempDir()), uploadJournalDirName)
+}
+
+// openUploadJournal returns the journal of the file upload. An existing journal is resumed
+// if it was written for the same content, otherwise a new journal is returned.
+// It returns nil if the upload cannot be resumed, i.e. the journal is disabled,
+// the stage is not on S3 or Azure or the file is not uploaded in multiple parts.
+func openUploadJournal(cfg *Config, meta *fileMetadata) *uploadJournal {
+	retention := getUploadJournalRetention(cfg)
+	if retention <= 0 || meta.srcStream != nil || meta.uploadSize <= meta.options.MultiPartThreshold {
+		return nil
+	}
+	if meta.stageLocationType != s3Client && meta.stageLocationType != azureClient {
+		return nil
+	}
+	dir := getUploadJournalDir(cfg)
+	if err := os.MkdirAll(dir, uploadJournalDirMode); err != nil {
+		getConnectionLogger(cfg).Warnf("cannot create the upload journal directory %v, the upload cannot be resumed. err: %v", dir, err)
+		return nil
+	}
+	stage := string(meta.stageLocationType) + ":" + meta.stageInfo.Location
+	removeExpiredUploadJournals(cfg, meta, dir, stage, retention)
+
+	fileName := strings.TrimLeft(meta.dstFileName, "/")
+	target := stage + fileName
+	hash := sha256.Sum256([]byte(target))
+	journal := &uploadJournal{
+		path:       filepath.Join(dir, hex.EncodeToString(hash[:])+uploadJournalSuffix),
+		cfg:        cfg,
+		Target:     target,
+		Stage:      stage,
+		FileName:   fileName,
+		Digest:     meta.sha256Digest,
+		UploadSize: meta.uploadSize,
+	}
+	if meta.encryptionMaterial != nil {
+		journal.SMKID = meta.encryptionMaterial.SMKID
+	}
+	existing, err := readUploadJournal(journal.path)
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			getConnectionLogger(cfg).Warnf("cannot read the upload journal %v, starting a new upload. err: %v", journal.path, err)
+		}
+		return journal
+	}
+	if existing.Target != journal.Target || existing.Digest != journal.Digest ||
+		existing.UploadSize != journal.UploadSize || existing.SMKID != journal.SMKID {
+		getConnectionLogger(cfg).Debugf("upload journal %v was written for different content, starting a new upload", journal.path)
+		existing.path = journal.path
+		existing.cfg = cfg
+		existing.discard(meta)
+		return journal
+	}
+	getConnectionLogger(cfg).Infof("resuming the upload of %v with %v uploaded parts", meta.dstFileName, len(existing.Parts))
+	existing.path = journal.path
+	existing.cfg = cfg
+	return existing
+}
+
+func readUploadJournal(path string) (*uploadJournal, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	journal := &uploadJournal{}
+	if err = json.Unmarshal(data, journal); err != nil {
+		return nil, err
+	}
+	return journal, nil
+}
+
+// removeExpiredUploadJournals discards the expired journals of the stage of meta, aborting their uploads.
+// The journals of other stages are kept until a file is uploaded to their stage, as the credentials
+// to abort their uploads are not available.
+func removeExpiredUploadJournals(cfg *Config, meta *fileMetadata, dir string, stage string, retention time.Duration) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return
+	}
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), uploadJournalSuffix) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil || time.Since(info.ModTime()) <= retention {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+		journal, err := readUploadJournal(path)
+		if err == nil && journal.Stage != stage {
+			continue
+		}
+		getConnectionLogger(cfg).Debugf("removing expired upload journal %v", entry.Name())
+		if err != nil {
+			journal = &uploadJournal{}
+		}
+		journal.path = path
+		journal.cfg = cfg
+		journal.discard(meta)
+	}
+}
+
+// encryptMetadata returns the encryption metadata of the content uploaded so far, nil if there is none.
+func (j *uploadJournal) encryptMetadata() *encryptMetadata {
+	if j == nil || j.Key == "" {
+		return nil
+	}
+	return &encryptMetadata{key: j.Key, iv: j.Iv, matdesc: j.Matdesc}
+}
+
+func (j *uploadJournal) setEncryptMetadata(meta *encryptMetadata) {
+	if j == nil || meta == nil {
+		return
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.Key = meta.key
+	j.Iv = meta.iv
+	j.Matdesc = meta.matdesc
+}
+
+// start records a new multipart upload, discarding the parts of the previous one.
+func (j *uploadJournal) start(uploadID string, partSize int64) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.UploadID = uploadID
+	j.PartSize = partSize
+	j.Parts = nil
+	return j.save()
+}
+
+// reset forgets the multipart upload, e.g. when it expired on the storage, keeping the encryption metadata.
+func (j *uploadJournal) reset() {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.UploadID = ""
+	j.PartSize = 0
+	j.Parts = nil
+	if err := j.save(); err != nil {
+		getConnectionLogger(j.cfg).Warnf("cannot reset the upload journal %v. err: %v", j.path, err)
+	}
+}
+
+func (j *uploadJournal) addPart(part uploadJournalPart) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.Parts = append(j.Parts, part)
+	return j.save()
+}
+
+// uploadedParts returns the uploaded parts by their numbers.
+func (j *uploadJournal) uploadedParts() map[int32]uploadJournalPart {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	parts := make(map[int32]uploadJournalPart, len(j.Parts))
+	for _, part := range j.Parts {
+		parts[part.Number] = part
+	}
+	return parts
+}
+
+// uploadedBytes returns the number of bytes of the uploaded parts.
+func (j *uploadJournal) uploadedBytes() int64 {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	var size int64
+	for _, part := range j.Parts {
+		size += part.Size
+	}
+	return size
+}
+
+// sortedParts returns the uploaded parts ordered by their numbers.
+func (j *uploadJournal) sortedParts() []uploadJournalPart {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	parts := append([]uploadJournalPart(nil), j.Parts...)
+	sort.Slice(parts, func(a, b int) bool {
+		return parts[a].Number < parts[b].Number
+	})
+	return parts
+}
+
+// save writes the journal atomically, so that an interrupted write does not corrupt it.
+func (j *uploadJournal) save() error {
+	data, err := json.Marshal(j)
+	if err != nil {
+		return err
+	}
+	tmpFile := j.path + ".tmp"
+	if err = os.WriteFile(tmpFile, data, uploadJournalFileMode); err != nil {
+		return err
+	}
+	return os.Rename(tmpFile, j.path)
+}
+
+// discard aborts the upload of the journal on the storage and removes the journal.
+// meta is a file uploaded to the stage of the journal.
+func (j *uploadJournal) discard(meta *fileMetadata) {
+	j.abortUpload(meta)
+	j.remove()
+}
+
+// abortUpload aborts the multipart upload of the journal on the storage, so that its parts are not kept,
+// and billed, until the storage expires them. meta is a file uploaded to the stage of the journal.
+func (j *uploadJournal) abortUpload(meta *fileMetadata) {
+	if j == nil || j.UploadID == "" || j.FileName == "" {
+		return
+	}
+	var err error
+	switch meta.stageLocationType {
+	case s3Client:
+		err = (&snowflakeS3Client{j.cfg}).abortMultipartUpload(meta, j.FileName, j.UploadID)
+	case azureClient:
+		err = (&snowflakeAzureClient{j.cfg}).abortBlockUpload(meta, j.FileName)
+	}
+	if err != nil {
+		getConnectionLogger(j.cfg).Warnf("cannot abort the unfinished upload of %v, its parts are kept until the storage expires them. err: %v", j.FileName, err)
+	}
+}
+
+func (j *uploadJournal) remove() {
+	if err := os.Remove(j.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		getConnectionLogger(j.cfg).Warnf("cannot remove the upload journal %v. err: %v", j.path, err)
+	}
+}
+
+// getMultipartPartSize returns the part size so that the upload fits in maxParts parts.
+func getMultipartPartSize(uploadSize int64, partSize int64, maxParts int64) int64 {
+	if uploadSize > partSize*maxParts {
+		partSize = (uploadSize + maxParts - 1) / maxParts
+	}
+	return partSize
+}
+
+// uploadMissingParts uploads the parts of the file which are not in the journal yet,
+// at most maxConcurrency at a time, and records every uploaded part in the journal.
+// It stops uploading after the first error.
+func uploadMissingParts(
+	journal *uploadJournal,
+	dataFile string,
+	maxConcurrency int,
+	upload func(number int32, body *io.SectionReader) (uploadJournalPart, error)) error {
+	file, err := os.Open(dataFile)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	stat, err := file.Stat()
+	if err != nil {
+		return err
+	}
+	size := stat.Size()
+	uploaded := journal.uploadedParts()
+	numbers := make(chan int32)
+	go func() {
+		defer close(numbers)
+		for number, offset := int32(1), int64(0); offset < size; number, offset = number+1, offset+journal.PartSize {
+			if _, ok := uploaded[number]; !ok {
+				numbers <- number
+			}
+		}
+	}()
+
+	var mu sync.Mutex
+	var firstErr error
+	var wg sync.WaitGroup
+	for i := 0; i < max(maxConcurrency, 1); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for number := range numbers {
+				mu.Lock()
+				failed := firstErr != nil
+				mu.Unlock()
+				if failed {
+					continue
+				}
+				offset := int64(number-1) * journal.PartSize
+				part, err := upload(number, io.NewSectionReader(file, offset, min(journal.PartSize, size-offset)))
+				if err == nil {
+					err = journal.addPart(part)
+				}
+				if err != nil {
+					mu.Lock()
+					if firstErr == nil {
+						firstErr = err
+					}
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	return firstErr
+}
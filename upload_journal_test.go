@@ -0,0 +1,116 @@
+package gosnowflake
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func newUploadJournalTestMeta(digest string) *fileMetadata {
+	return &fileMetadata{
+		stageLocationType: s3Client,
+		stageInfo:         &execResponseStageInfo{Location: "bucket/stage/"},
+		dstFileName:       "data.csv.gz",
+		sha256Digest:      digest,
+		uploadSize:        200,
+		encryptionMaterial: &snowflakeFileEncryption{
+			SMKID: 92019681909886,
+		},
+		options:       &SnowflakeFileTransferOptions{MultiPartThreshold: 100},
+		mockMultipart: &mockMultipartUploadAPI{},
+	}
+}
+
+func TestUploadJournalResume(t *testing.T) {
+	cfg := &Config{TmpDirPath: t.TempDir(), UploadJournalRetention: time.Hour}
+	journal := openUploadJournal(cfg, newUploadJournalTestMeta("digest"))
+	assertNotNilF(t, journal)
+	assertEqualE(t, journal.UploadID, "")
+	journal.setEncryptMetadata(&encryptMetadata{key: "key", iv: "iv", matdesc: "matdesc"})
+	assertNilF(t, journal.start("upload", 100))
+	assertNilF(t, journal.addPart(uploadJournalPart{Number: 2, Size: 100, ETag: "etag2"}))
+
+	info, err := os.Stat(journal.path)
+	assertNilF(t, err)
+	assertEqualE(t, info.Mode().Perm(), os.FileMode(uploadJournalFileMode))
+
+	resumed := openUploadJournal(cfg, newUploadJournalTestMeta("digest"))
+	assertNotNilF(t, resumed)
+	assertEqualE(t, resumed.UploadID, "upload")
+	assertEqualE(t, resumed.PartSize, int64(100))
+	assertDeepEqualE(t, resumed.encryptMetadata(), &encryptMetadata{key: "key", iv: "iv", matdesc: "matdesc"})
+	assertDeepEqualE(t, resumed.uploadedParts(), map[int32]uploadJournalPart{2: {Number: 2, Size: 100, ETag: "etag2"}})
+
+	changedMeta := newUploadJournalTestMeta("other digest")
+	changed := openUploadJournal(cfg, changedMeta)
+	assertNotNilF(t, changed)
+	assertDeepEqualE(t, changedMeta.mockMultipart.(*mockMultipartUploadAPI).abortedIDs, []string{"upload"},
+		"upload of different content should be aborted")
+	assertEqualE(t, changed.UploadID, "", "journal of different content should not be resumed")
+	assertNilE(t, changed.encryptMetadata())
+	_, err = os.Stat(journal.path)
+	assertTrueE(t, os.IsNotExist(err), "journal of different content should be removed")
+}
+
+func TestUploadJournalDisabled(t *testing.T) {
+	meta := newUploadJournalTestMeta("digest")
+	assertNilE(t, openUploadJournal(&Config{TmpDirPath: t.TempDir()}, meta), "resuming should be disabled by default")
+	assertNilE(t, openUploadJournal(&Config{TmpDirPath: t.TempDir(), UploadJournalRetention: -1}, meta))
+
+	cfg := &Config{TmpDirPath: t.TempDir(), UploadJournalRetention: time.Hour}
+	meta.uploadSize = 100
+	assertNilE(t, openUploadJournal(cfg, meta), "single part uploads should not be journaled")
+
+	meta = newUploadJournalTestMeta("digest")
+	meta.stageLocationType = gcsClient
+	assertNilE(t, openUploadJournal(cfg, meta))
+}
+
+func TestUploadJournalRetention(t *testing.T) {
+	cfg := &Config{TmpDirPath: t.TempDir(), UploadJournalRetention: time.Hour}
+	journal := openUploadJournal(cfg, newUploadJournalTestMeta("digest"))
+	assertNilF(t, journal.start("upload", 100))
+	expired := time.Now().Add(-2 * time.Hour)
+	assertNilF(t, os.Chtimes(journal.path, expired, expired))
+
+	otherStageMeta := newUploadJournalTestMeta("digest")
+	otherStageMeta.stageInfo = &execResponseStageInfo{Location: "bucket/other/"}
+	assertNotNilF(t, openUploadJournal(cfg, otherStageMeta))
+	_, err := os.Stat(journal.path)
+	assertNilE(t, err, "expired journal of another stage should be kept")
+	assertEqualE(t, len(otherStageMeta.mockMultipart.(*mockMultipartUploadAPI).abortedIDs), 0)
+
+	meta := newUploadJournalTestMeta("digest")
+	resumed := openUploadJournal(cfg, meta)
+	assertEqualE(t, resumed.UploadID, "", "expired journal should not be resumed")
+	assertDeepEqualE(t, meta.mockMultipart.(*mockMultipartUploadAPI).abortedIDs, []string{"upload"},
+		"upload of expired journal should be aborted")
+	entries, err := os.ReadDir(filepath.Dir(journal.path))
+	assertNilF(t, err)
+	assertEqualE(t, len(entries), 0)
+}
+
+func TestGetMultipartPartSize(t *testing.T) {
+	assertEqualE(t, getMultipartPartSize(1000, 100, 10000), int64(100))
+	assertEqualE(t, getMultipartPartSize(1000, 100, 8), int64(125))
+	assertEqualE(t, getMultipartPartSize(1001, 100, 10), int64(101))
+}
+
+func TestUploadJournalAbortAzureUpload(t *testing.T) {
+	info := &execResponseStageInfo{Location: "azblob/storage/users/456/", LocationType: "AZURE"}
+	azureCli, err := new(snowflakeAzureClient).createClient(info, false)
+	assertNilF(t, err)
+	for _, exists := range []bool{false, true} {
+		blockClient := &azureBlockAPIMock{stagedBlocks: map[string][]byte{"block": []byte("data")}, exists: exists}
+		meta := &fileMetadata{
+			stageLocationType: azureClient,
+			stageInfo:         info,
+			client:            azureCli,
+			mockAzureClient:   blockClient,
+		}
+		journal := &uploadJournal{cfg: &Config{}, FileName: "data.csv.gz", UploadID: "upload"}
+		journal.abortUpload(meta)
+		assertEqualE(t, blockClient.deleted, !exists, "only a blob created to discard the blocks should be deleted")
+	}
+}
@@ -34,6 +34,8 @@ const (
 	enableStructuredTypes            contextKey = "ENABLE_STRUCTURED_TYPES"
 	mapValuesNullable                contextKey = "MAP_VALUES_NULLABLE"
 	arrayValuesNullable              contextKey = "ARRAY_VALUES_NULLABLE"
+	timestampTzLocation              contextKey = "TIMESTAMP_TZ_LOCATION"
+	typeMappings                     contextKey = "TYPE_MAPPINGS"
 )
 
 const (
@@ -41,6 +43,8 @@ const (
 	internalQuery       contextKey = "INTERNAL_QUERY"
 	cancelRetry         contextKey = "CANCEL_RETRY"
 	streamChunkDownload contextKey = "STREAM_CHUNK_DOWNLOAD"
+	fileTransferResults contextKey = "FILE_TRANSFER_RESULTS"
+	queryRetryCounter   contextKey = "QUERY_RETRY_COUNTER"
 )
 
 var (
@@ -172,6 +176,20 @@ func WithArrayValuesNullable(ctx context.Context) context.Context {
 	return context.WithValue(ctx, arrayValuesNullable, true)
 }
 
+// WithTimestampTZLocation changes how TIMESTAMP_TZ values are returned.
+// By default they keep the fixed offset stored with each value.
+// With this context they are converted to the given location, e.g. one returned by LocationWithName.
+// If loc is nil, the location of the session TIMEZONE parameter is used.
+func WithTimestampTZLocation(ctx context.Context, loc *time.Location) context.Context {
+	return context.WithValue(ctx, timestampTzLocation, loc)
+}
+
+// WithTypeMappings sets the type mapping registry used to convert the query results.
+// It takes priority over the registry set in Config.TypeMappings.
+func WithTypeMappings(ctx context.Context, registry *TypeMappingRegistry) context.Context {
+	return context.WithValue(ctx, typeMappings, registry)
+}
+
 // WithInternal sets the internal query flag.
 func WithInternal(ctx context.Context) context.Context {
 	return context.WithValue(ctx, internalQuery, true)
@@ -0,0 +1,305 @@
+package gosnowflake
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// maskedWireValue replaces the secrets in recorded exchanges.
+const maskedWireValue = "****"
+
+// headers which are always masked in recorded exchanges
+var secretWireHeaders = []string{
+	"Authorization",
+	"Cookie",
+	"Set-Cookie",
+	"X-Amz-Security-Token",
+	"X-Amz-Server-Side-Encryption-Customer-Key",
+	"X-Goog-Encryption-Key",
+	"X-Ms-Encryption-Key",
+}
+
+// URL query parameters containing these words are always masked in recorded exchanges
+var secretWireParams = []string{"token", "signature", "credential", "sig", "password", "passcode"}
+
+// secretJSONFields are the JSON fields of the requests and responses of Snowflake holding secrets,
+// e.g. the session tokens of login responses, the stage credentials and the master keys of the stage
+// encryption of PUT and GET responses. They are matched case-insensitively.
+var secretJSONFields = []string{
+	"AWS_KEY_ID",
+	"AWS_SECRET_KEY",
+	"AWS_TOKEN",
+	"AWS_KEY",
+	"AZURE_SAS_TOKEN",
+	"GCS_ACCESS_TOKEN",
+	"queryStageMasterKey",
+	"token",
+	"masterToken",
+	"sessionToken",
+	"oldSessionToken",
+	"idToken",
+	"mfaToken",
+	"remMeToken",
+	"cookieToken",
+	"proofKey",
+	"PASSWORD",
+	"PASSCODE",
+	"RAW_SAML_RESPONSE",
+	"samlResponse",
+	"privateKey",
+}
+
+// secretJSONKeyPattern matches the secretJSONFields and the other JSON keys named like tokens, secrets and passwords.
+var secretJSONKeyPattern = `(?:` + strings.Join(secretJSONFields, "|") +
+	`|[a-z_]*(?:token|secret|password|passcode|privatekey[a-z_]*))`
+
+var secretJSONKeyRegexp = regexp.MustCompile(`(?i)^` + secretJSONKeyPattern + `$`)
+
+// jsonSecretRegexp matches the values of the secret JSON keys in text bodies which are not valid JSON.
+var jsonSecretRegexp = regexp.MustCompile(`(?i)("` + secretJSONKeyPattern + `"\s*:\s*")[^"]*(")`)
+
+// RecordedExchange is an HTTP request and its response recorded by RecordingTransport.
+type RecordedExchange struct {
+	Method         string      `json:"method"`
+	URL            string      `json:"url"`
+	RequestHeader  http.Header `json:"requestHeader,omitempty"`
+	RequestBody    []byte      `json:"requestBody,omitempty"`
+	StatusCode     int         `json:"statusCode,omitempty"`
+	ResponseHeader http.Header `json:"responseHeader,omitempty"`
+	ResponseBody   []byte      `json:"responseBody,omitempty"`
+	Error          string      `json:"error,omitempty"`
+}
+
+// RecordingTransport is an http.RoundTripper writing the requests sent through another RoundTripper
+// and their responses to a writer, one RecordedExchange in JSON per line. Set it in Config.Transporter
+// to record the login, query, chunk download and stage requests of the connections. Secrets are masked
+// in the headers, the URL query parameters and the text bodies, so the recorded responses cannot be
+// used to authenticate or to read the stage files. JSON bodies are recorded re-encoded with the values
+// of the secret fields, e.g. the stage credentials and master keys, masked. The bodies are buffered in memory.
+type RecordingTransport struct {
+	transport http.RoundTripper
+	mu        sync.Mutex
+	encoder   *json.Encoder
+}
+
+// NewRecordingTransport returns a RecordingTransport sending the requests with the transport,
+// e.g. SnowflakeTransport, and writing the exchanges to w.
+func NewRecordingTransport(transport http.RoundTripper, w io.Writer) *RecordingTransport {
+	return &RecordingTransport{transport: transport, encoder: json.NewEncoder(w)}
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *RecordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	exchange := RecordedExchange{
+		Method:        req.Method,
+		URL:           maskWireURL(req.URL),
+		RequestHeader: maskWireHeader(req.Header),
+	}
+	if req.Body != nil && req.Body != http.NoBody {
+		body, err := io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		req.Body = io.NopCloser(bytes.NewReader(body))
+		exchange.RequestBody = maskWireBody(body)
+	}
+	resp, err := t.transport.RoundTrip(req)
+	if err != nil {
+		exchange.Error = maskSecrets(err.Error())
+		t.write(&exchange)
+		return nil, err
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	exchange.StatusCode = resp.StatusCode
+	exchange.ResponseHeader = maskWireHeader(resp.Header)
+	exchange.ResponseBody = maskWireBody(body)
+	t.write(&exchange)
+	return resp, nil
+}
+
+func (t *RecordingTransport) write(exchange *RecordedExchange) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if err := t.encoder.Encode(exchange); err != nil {
+		logger.Warnf("failed to record HTTP exchange. err: %v", err)
+	}
+}
+
+func maskWireHeader(header http.Header) http.Header {
+	if len(header) == 0 {
+		return nil
+	}
+	masked := header.Clone()
+	for _, name := range secretWireHeaders {
+		if masked.Get(name) != "" {
+			masked.Set(name, maskedWireValue)
+		}
+	}
+	return masked
+}
+
+func maskWireURL(u *url.URL) string {
+	masked := *u
+	query := masked.Query()
+	for name := range query {
+		for _, secret := range secretWireParams {
+			if strings.Contains(strings.ToLower(name), secret) {
+				query.Set(name, maskedWireValue)
+				break
+			}
+		}
+	}
+	masked.RawQuery = query.Encode()
+	return maskSecrets(masked.String())
+}
+
+// maskWireBody masks the secrets in text bodies, binary bodies like compressed chunks are recorded unchanged.
+func maskWireBody(body []byte) []byte {
+	if len(body) == 0 {
+		return nil
+	}
+	if !utf8.Valid(body) {
+		return body
+	}
+	if masked, ok := maskJSONBody(body); ok {
+		return masked
+	}
+	text := jsonSecretRegexp.ReplaceAllString(string(body), "${1}"+maskedWireValue+"${2}")
+	return []byte(maskSecrets(text))
+}
+
+// maskJSONBody masks the values of the secret keys and the secrets in the other strings of a JSON body.
+// The masking rules for text could consume the JSON syntax around a secret, so the strings are masked one by one.
+func maskJSONBody(body []byte) ([]byte, bool) {
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil || decoder.More() {
+		return nil, false
+	}
+	buf := &bytes.Buffer{}
+	encoder := json.NewEncoder(buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(maskJSONValue(value)); err != nil {
+		return nil, false
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), true
+}
+
+func maskJSONValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, field := range v {
+			if _, ok := field.(string); ok && secretJSONKeyRegexp.MatchString(key) {
+				v[key] = maskedWireValue
+			} else {
+				v[key] = maskJSONValue(field)
+			}
+		}
+		return v
+	case []interface{}:
+		for i, element := range v {
+			v[i] = maskJSONValue(element)
+		}
+		return v
+	case string:
+		return maskSecrets(v)
+	default:
+		return value
+	}
+}
+
+// ReplayTransport is an http.RoundTripper serving the responses recorded by RecordingTransport,
+// so the driver can be exercised without a Snowflake account. Set it in Config.Transporter.
+// A request gets the response of the next recorded exchange with the same method and URL path,
+// in the order of the recording. The query parameters and the bodies of the requests are ignored,
+// as they contain values generated for each request, like request IDs.
+type ReplayTransport struct {
+	mu        sync.Mutex
+	exchanges map[string][]*RecordedExchange
+}
+
+// NewReplayTransport reads the exchanges written by RecordingTransport from r.
+func NewReplayTransport(r io.Reader) (*ReplayTransport, error) {
+	t := &ReplayTransport{exchanges: make(map[string][]*RecordedExchange)}
+	decoder := json.NewDecoder(bufio.NewReader(r))
+	for {
+		exchange := &RecordedExchange{}
+		if err := decoder.Decode(exchange); err == io.EOF {
+			return t, nil
+		} else if err != nil {
+			return nil, fmt.Errorf("failed to read recorded HTTP exchanges. err: %w", err)
+		}
+		u, err := url.Parse(exchange.URL)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse URL of recorded HTTP exchange %v. err: %w", exchange.URL, err)
+		}
+		key := replayKey(exchange.Method, u)
+		t.exchanges[key] = append(t.exchanges[key], exchange)
+	}
+}
+
+func replayKey(method string, u *url.URL) string {
+	return method + " " + u.Host + u.Path
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *ReplayTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body != nil {
+		req.Body.Close()
+	}
+	key := replayKey(req.Method, req.URL)
+	t.mu.Lock()
+	exchanges := t.exchanges[key]
+	if len(exchanges) == 0 {
+		t.mu.Unlock()
+		return nil, fmt.Errorf("no recorded HTTP exchange left for %v", key)
+	}
+	exchange := exchanges[0]
+	t.exchanges[key] = exchanges[1:]
+	t.mu.Unlock()
+	if exchange.Error != "" {
+		return nil, fmt.Errorf("recorded HTTP exchange failed: %v", exchange.Error)
+	}
+	header := exchange.ResponseHeader.Clone()
+	if header == nil {
+		header = http.Header{}
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", exchange.StatusCode, http.StatusText(exchange.StatusCode)),
+		StatusCode:    exchange.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(exchange.ResponseBody)),
+		ContentLength: int64(len(exchange.ResponseBody)),
+		Request:       req,
+	}, nil
+}
+
+// Remaining returns the number of recorded exchanges which were not replayed yet.
+func (t *ReplayTransport) Remaining() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	remaining := 0
+	for _, exchanges := range t.exchanges {
+		remaining += len(exchanges)
+	}
+	return remaining
+}
@@ -0,0 +1,150 @@
+package gosnowflake
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func newTestWireServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case loginRequestPath:
+			w.Header().Set(httpHeaderContentType, headerContentTypeApplicationJSON)
+			_, _ = w.Write([]byte(`{"data":{"token":"ver:1-hint:1234-ETMsDgAAAX","masterToken":"ver:1-hint:1234-ETMsDgAAAY"},"success":true}`))
+		case queryRequestPath:
+			w.Header().Set(httpHeaderContentType, headerContentTypeApplicationJSON)
+			_, _ = w.Write([]byte(`{"data":{"queryId":"01b2c3","stageInfo":{"creds":{"AWS_KEY_ID":"AKIAEXAMPLE","AWS_SECRET_KEY":"secretkey"}}},"success":true}`))
+		default:
+			w.Header().Set(httpHeaderContentType, httpHeaderValueOctetStream)
+			_, _ = w.Write([]byte{0x1f, 0x8b, 0xff, 0xfe})
+		}
+	}))
+}
+
+func TestRecordingTransportMasksSecrets(t *testing.T) {
+	server := newTestWireServer()
+	defer server.Close()
+	buf := &bytes.Buffer{}
+	client := &http.Client{Transport: NewRecordingTransport(http.DefaultTransport, buf)}
+
+	req, err := http.NewRequest(http.MethodPost, server.URL+loginRequestPath+"?requestId=1&token=abc",
+		strings.NewReader(`{"data":{"LOGIN_NAME":"u","PASSWORD":"testpassword"}}`))
+	assertNilF(t, err)
+	req.Header.Set("Authorization", `Snowflake Token="secret"`)
+	resp, err := client.Do(req)
+	assertNilF(t, err)
+	body, err := io.ReadAll(resp.Body)
+	assertNilF(t, err)
+	assertStringContainsE(t, string(body), "ETMsDgAAAX", "the response of the driver should not be masked")
+
+	resp, err = client.Post(server.URL+queryRequestPath, headerContentTypeApplicationJSON, strings.NewReader(`{"sqlText":"select 1"}`))
+	assertNilF(t, err)
+	resp.Body.Close()
+	resp, err = client.Get(server.URL + "/results/data_0_0_0?X-Amz-Signature=abcdef")
+	assertNilF(t, err)
+	resp.Body.Close()
+
+	recorded := buf.String()
+	decoder := json.NewDecoder(buf)
+	var exchanges []RecordedExchange
+	for decoder.More() {
+		var exchange RecordedExchange
+		assertNilF(t, decoder.Decode(&exchange))
+		exchanges = append(exchanges, exchange)
+		recorded += string(exchange.RequestBody) + string(exchange.ResponseBody)
+	}
+	for _, secret := range []string{"testpassword", "ETMsDgAAAX", "ETMsDgAAAY", "AKIAEXAMPLE", "secretkey", "abcdef", "Token=\\\"secret"} {
+		assertFalseE(t, strings.Contains(recorded, secret), secret)
+	}
+	assertEqualF(t, len(exchanges), 3)
+	assertEqualE(t, exchanges[0].RequestHeader.Get("Authorization"), maskedWireValue)
+	assertStringContainsE(t, string(exchanges[1].RequestBody), "select 1")
+	assertDeepEqualE(t, exchanges[2].ResponseBody, []byte{0x1f, 0x8b, 0xff, 0xfe})
+}
+
+func TestRecordingTransportMasksFileTransferSecrets(t *testing.T) {
+	putResponse := `{"data":{"command":"UPLOAD","src_locations":["/tmp/data.csv"],` +
+		`"encryptionMaterial":{"queryStageMasterKey":"c3RhZ2VtYXN0ZXJrZXk=","queryId":"01b2c3","smkId":1234},` +
+		`"stageInfo":{"locationType":"S3","location":"bucket/stage/","region":"us-west-2",` +
+		`"creds":{"AWS_KEY_ID":"AKIAEXAMPLEKEYID","AWS_SECRET_KEY":"awsSecretAccessKeyValue","AWS_TOKEN":"awsSessionTokenValue",` +
+		`"AZURE_SAS_TOKEN":"azureSasTokenValue","GCS_ACCESS_TOKEN":"gcsAccessTokenValue"}}},` +
+		`"code":null,"message":null,"success":true}`
+	loginResponse := `{"data":{"token":"sessionTokenValue","masterToken":"masterTokenValue","idToken":"idTokenValue",` +
+		`"mfaToken":"mfaTokenValue","proofKey":"proofKeyValue"},"success":true}`
+	loginRequest := `{"data":{"PASSWORD":"passwordValue","PASSCODE":"passcodeValue","RAW_SAML_RESPONSE":"samlResponseValue"}}`
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(httpHeaderContentType, headerContentTypeApplicationJSON)
+		if r.URL.Path == loginRequestPath {
+			_, _ = w.Write([]byte(loginResponse))
+		} else {
+			_, _ = w.Write([]byte(putResponse))
+		}
+	}))
+	defer server.Close()
+	buf := &bytes.Buffer{}
+	client := &http.Client{Transport: NewRecordingTransport(http.DefaultTransport, buf)}
+	resp, err := client.Post(server.URL+loginRequestPath, headerContentTypeApplicationJSON, strings.NewReader(loginRequest))
+	assertNilF(t, err)
+	resp.Body.Close()
+	resp, err = client.Post(server.URL+queryRequestPath, headerContentTypeApplicationJSON,
+		strings.NewReader(`{"sqlText":"PUT file:///tmp/data.csv @~"}`))
+	assertNilF(t, err)
+	body, err := io.ReadAll(resp.Body)
+	assertNilF(t, err)
+	assertEqualE(t, string(body), putResponse, "the response of the driver should not be masked")
+
+	var recorded strings.Builder
+	recorded.WriteString(buf.String())
+	decoder := json.NewDecoder(buf)
+	for decoder.More() {
+		var exchange RecordedExchange
+		assertNilF(t, decoder.Decode(&exchange))
+		var decoded map[string]interface{}
+		assertNilE(t, json.Unmarshal(exchange.RequestBody, &decoded), "masked request should stay valid JSON")
+		assertNilE(t, json.Unmarshal(exchange.ResponseBody, &decoded), "masked response should stay valid JSON")
+		recorded.Write(exchange.RequestBody)
+		recorded.Write(exchange.ResponseBody)
+	}
+	for _, secret := range []string{"c3RhZ2VtYXN0ZXJrZXk=", "AKIAEXAMPLEKEYID", "awsSecretAccessKeyValue", "awsSessionTokenValue",
+		"azureSasTokenValue", "gcsAccessTokenValue", "sessionTokenValue", "masterTokenValue", "idTokenValue",
+		"mfaTokenValue", "proofKeyValue", "passwordValue", "passcodeValue", "samlResponseValue"} {
+		assertFalseE(t, strings.Contains(recorded.String(), secret), secret)
+	}
+	assertStringContainsE(t, recorded.String(), `"queryId":"01b2c3"`)
+}
+
+func TestReplayTransport(t *testing.T) {
+	server := newTestWireServer()
+	buf := &bytes.Buffer{}
+	client := &http.Client{Transport: NewRecordingTransport(http.DefaultTransport, buf)}
+	for _, path := range []string{loginRequestPath, queryRequestPath, queryRequestPath} {
+		resp, err := client.Post(server.URL+path+"?requestId="+NewUUID().String(), headerContentTypeApplicationJSON, nil)
+		assertNilF(t, err)
+		resp.Body.Close()
+	}
+	server.Close()
+
+	replay, err := NewReplayTransport(buf)
+	assertNilF(t, err)
+	assertEqualE(t, replay.Remaining(), 3)
+	client = &http.Client{Transport: replay}
+	resp, err := client.Post(server.URL+queryRequestPath+"?requestId="+NewUUID().String(), headerContentTypeApplicationJSON, nil)
+	assertNilF(t, err)
+	body, err := io.ReadAll(resp.Body)
+	assertNilF(t, err)
+	assertEqualE(t, resp.StatusCode, http.StatusOK)
+	assertEqualE(t, resp.Header.Get(httpHeaderContentType), headerContentTypeApplicationJSON)
+	assertStringContainsE(t, string(body), `"queryId":"01b2c3"`)
+	assertEqualE(t, replay.Remaining(), 2)
+
+	_, err = client.Get(server.URL + loginRequestPath)
+	assertNotNilE(t, err, "no GET request was recorded")
+	_, err = NewReplayTransport(strings.NewReader("not json"))
+	assertNotNilE(t, err)
+}